@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/config"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/recorder"
+	"github.com/dharmjit/k8s-dra-resources/pkg/sarif"
+	"github.com/dharmjit/k8s-dra-resources/pkg/schema"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func runAnalyze(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: analyze drain-candidates|class-drift|spot-risk|flaky-devices|gpu-labels|cuda-compat|overcommit|kubelet-devices|stranded|plugins|<plugin> [flags]")
+	}
+
+	switch args[0] {
+	case "drain-candidates":
+		return runAnalyzeDrainCandidates(ctx, client, args[1:])
+	case "class-drift":
+		return runAnalyzeClassDrift(ctx, args[1:])
+	case "spot-risk":
+		return runAnalyzeSpotRisk(ctx, client, args[1:])
+	case "flaky-devices":
+		return runAnalyzeFlakyDevices(ctx, client, args[1:])
+	case "cuda-compat":
+		return runAnalyzeCUDACompat(ctx, client, args[1:])
+	case "overcommit":
+		return runAnalyzeOvercommit(ctx, client, args[1:])
+	case "kubelet-devices":
+		return runAnalyzeKubeletDevices(ctx, client, args[1:])
+	case "gpu-labels":
+		return runAnalyzeGPULabels(ctx, client, args[1:])
+	case "stranded":
+		return runAnalyzeStranded(ctx, client, args[1:])
+	case "plugins":
+		for _, plugin := range analyze.FindPlugins() {
+			fmt.Printf("%s\t%s\n", plugin.Name, plugin.Path)
+		}
+		return nil
+	default:
+		plugin, ok := analyze.FindPlugin(args[0])
+		if !ok {
+			return fmt.Errorf("unknown analyze command %q and no %s%s plugin on PATH", args[0], analyze.PluginPrefix, args[0])
+		}
+		return runAnalyzePlugin(ctx, client, plugin, args[1:])
+	}
+}
+
+// analyzeOutputUsage documents the -o flag of the built-in analyzers, which
+// all accept the same formats.
+const analyzeOutputUsage = "output format: table, json or sarif"
+
+func checkAnalyzeOutput(output string) error {
+	switch output {
+	case "table", "json", "sarif":
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", output)
+}
+
+// runAnalyzePlugin feeds the v1alpha1 node snapshot to an external analyzer
+// and prints the report sections it returns.
+func runAnalyzePlugin(ctx context.Context, client resourceClient.ResourceClient, plugin analyze.Plugin, args []string) error {
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := schema.Nodes(schema.VersionV1Alpha1, nodeInfoList)
+	if err != nil {
+		return err
+	}
+
+	report, err := plugin.Run(ctx, snapshot, args)
+	if err != nil {
+		return err
+	}
+
+	display.DisplayReport(report)
+	return nil
+}
+
+func runAnalyzeDrainCandidates(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze drain-candidates", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "show at most this many nodes (0 for all)")
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	candidates := analyze.DrainCandidates(nodeInfoList)
+	if *limit > 0 && len(candidates) > *limit {
+		candidates = candidates[:*limit]
+	}
+
+	switch *output {
+	case "json":
+		return display.DisplayJSON(candidates)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromDrainCandidates(candidates))
+	}
+	display.DisplayDrainCandidates(candidates)
+	return nil
+}
+
+// runAnalyzeClassDrift compares the DeviceClasses of several contexts of the
+// kubeconfig.
+func runAnalyzeClassDrift(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("analyze class-drift", flag.ExitOnError)
+	contexts := fs.String("contexts", "", "comma-separated kubeconfig contexts to compare (at least two)")
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	names := strings.Split(*contexts, ",")
+	if *contexts == "" || len(names) < 2 {
+		return errors.New("usage: analyze class-drift --contexts ctx-a,ctx-b[,...]")
+	}
+
+	classes := make(map[string][]types.DeviceClassInfo)
+	for _, name := range names {
+		client, err := resourceClient.NewResourceClientForContext(kubeconfigPath, name)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", name, err)
+		}
+		classes[name], err = client.GetDeviceClasses(ctx)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", name, err)
+		}
+	}
+
+	drifts := analyze.ClassDrift(classes)
+	switch *output {
+	case "json":
+		return display.DisplayJSON(drifts)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromClassDrift(drifts))
+	}
+	if len(drifts) == 0 {
+		fmt.Println("DeviceClasses are identical in all contexts.")
+		return nil
+	}
+	display.DisplayClassDrift(drifts)
+	return nil
+}
+
+// runAnalyzeSpotRisk lists the claims that lose their devices when a spot
+// node is reclaimed.
+func runAnalyzeSpotRisk(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze spot-risk", flag.ExitOnError)
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	risks, err := client.GetSpotRisk(ctx)
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "json":
+		return display.DisplayJSON(risks)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromSpotRisk(risks))
+	}
+	if len(risks) == 0 {
+		fmt.Println("No allocated claims on spot or preemptible nodes.")
+		return nil
+	}
+	display.DisplaySpotRisk(risks)
+	return nil
+}
+
+// runAnalyzeFlakyDevices flags devices with unusual allocation churn or
+// repeated health flaps in the history recorded by "watch --record".
+func runAnalyzeFlakyDevices(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze flaky-devices", flag.ExitOnError)
+	db := fs.String("db", "", "SQLite database or PostgreSQL URL recorded by watch --record")
+	period := fs.Duration("period", 7*24*time.Hour, "analyze the history of this period")
+	churnFactor := fs.Float64("churn-factor", 3, "flag devices allocated at least this many times as often as the median device of their product")
+	minAllocations := fs.Int("min-allocations", 5, "do not flag churn of devices allocated fewer times than this")
+	minFlaps := fs.Int("min-health-flaps", 2, "flag devices that became unhealthy at least this many times")
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if *db == "" {
+		return errors.New("analyze flaky-devices needs the database recorded by watch --record, see --db")
+	}
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	rec, err := recorder.Open(*db, nil)
+	if err != nil {
+		return err
+	}
+	defer rec.Close()
+	since := time.Now().Add(-*period)
+	histories, err := rec.DeviceHistories(ctx, since)
+	if err != nil {
+		return err
+	}
+	devices, err := client.GetDevices(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	flaky := analyze.FlakyDevices(histories, devices, analyze.FlakyThresholds{
+		Since:          since,
+		ChurnFactor:    *churnFactor,
+		MinAllocations: *minAllocations,
+		MinHealthFlaps: *minFlaps,
+	})
+	switch *output {
+	case "json":
+		return display.DisplayJSON(flaky)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromFlakyDevices(flaky))
+	}
+	if len(flaky) == 0 {
+		fmt.Println("No flaky devices found.")
+		return nil
+	}
+	display.DisplayFlakyDevices(flaky)
+	return nil
+}
+
+// runAnalyzeGPULabels reports GPU node labels disagreeing with the GPUs the
+// nodes publish in ResourceSlices.
+func runAnalyzeGPULabels(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze gpu-labels", flag.ExitOnError)
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+	devices, err := client.GetDevices(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	mismatches := analyze.GPULabelMismatches(nodeInfoList, devices)
+	switch *output {
+	case "json":
+		return display.DisplayJSON(mismatches)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromLabelMismatches(mismatches))
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("GPU node labels match the published devices.")
+		return nil
+	}
+	display.DisplayLabelMismatches(mismatches)
+	return nil
+}
+
+// runAnalyzeCUDACompat flags containers needing a newer CUDA version than
+// the driver of their pod's devices supports.
+func runAnalyzeCUDACompat(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze cuda-compat", flag.ExitOnError)
+	var mappings stringSliceFlag
+	fs.Var(&mappings, "cuda-image", "prefix=version: CUDA version needed by images starting with prefix, e.g. nvcr.io/nvidia/pytorch:24.03=12.4 (repeatable)")
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+	images := make(map[string]string)
+	for _, mapping := range mappings {
+		// image prefixes may contain "=" only in unusual registries, so the
+		// version follows the last one
+		i := strings.LastIndex(mapping, "=")
+		if i <= 0 || i == len(mapping)-1 {
+			return fmt.Errorf("invalid --cuda-image %q, expected prefix=version", mapping)
+		}
+		images[mapping[:i]] = mapping[i+1:]
+	}
+
+	pods, err := client.GetDevicePods(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+	devices, err := client.GetDevices(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	incompatible := analyze.CUDAIncompatibilities(pods, devices, images)
+	switch *output {
+	case "json":
+		return display.DisplayJSON(incompatible)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromCUDAIncompatibilities(incompatible))
+	}
+	if len(incompatible) == 0 {
+		fmt.Println("No container needs a newer CUDA version than its devices' driver supports.")
+		return nil
+	}
+	display.DisplayCUDAIncompatibilities(incompatible)
+	return nil
+}
+
+// runAnalyzeOvercommit flags shared devices, such as GPUs partitioned into
+// memory slices, whose allocated partitions consume more than they provide.
+func runAnalyzeOvercommit(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze overcommit", flag.ExitOnError)
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	overcommit, err := client.GetCounterOvercommit(ctx)
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "json":
+		return display.DisplayJSON(overcommit)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromCounterOvercommit(overcommit))
+	}
+	if len(overcommit) == 0 {
+		fmt.Println("No shared device is overcommitted.")
+		return nil
+	}
+	display.DisplayCounterOvercommit(overcommit)
+	return nil
+}
+
+// runAnalyzeKubeletDevices flags containers whose devices, as the kubelet
+// reports them, differ from the allocation of their claims.
+func runAnalyzeKubeletDevices(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze kubelet-devices", flag.ExitOnError)
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	mismatches, err := client.GetKubeletDeviceMismatches(ctx)
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "json":
+		return display.DisplayJSON(mismatches)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromKubeletDeviceMismatches(mismatches))
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("The devices the kubelet reports match the claims' allocations.")
+		return nil
+	}
+	display.DisplayKubeletDeviceMismatches(mismatches)
+	return nil
+}
+
+// runAnalyzeStranded lists the free devices that pods of a reference shape
+// cannot use because their nodes lack CPU or memory.
+func runAnalyzeStranded(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("analyze stranded", flag.ExitOnError)
+	profile := fs.String("profile", "", "workload profile of the configuration file to use as the reference pod; --cpu, --memory and --devices override it")
+	cpu := fs.String("cpu", "4", "CPU requested by the reference pod")
+	memory := fs.String("memory", "16Gi", "memory requested by the reference pod")
+	devices := fs.Int("devices", 1, "devices of one product claimed by the reference pod")
+	output := fs.String("o", "table", analyzeOutputUsage)
+	fs.Parse(args)
+	if err := checkAnalyzeOutput(*output); err != nil {
+		return err
+	}
+
+	shape := analyze.PodShape{Devices: *devices}
+	var err error
+	if shape.CPU, err = resource.ParseQuantity(*cpu); err != nil {
+		return fmt.Errorf("invalid --cpu %q: %w", *cpu, err)
+	}
+	if shape.Memory, err = resource.ParseQuantity(*memory); err != nil {
+		return fmt.Errorf("invalid --memory %q: %w", *memory, err)
+	}
+	if *profile != "" {
+		base, err := workloadProfile(*profile)
+		if err != nil {
+			return err
+		}
+		// flags given explicitly override the profile
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "cpu":
+				base.CPU = shape.CPU
+			case "memory":
+				base.Memory = shape.Memory
+			case "devices":
+				base.Devices = shape.Devices
+			}
+		})
+		shape = base
+	}
+	if shape.Devices < 1 {
+		return fmt.Errorf("invalid --devices %d, expected at least 1", shape.Devices)
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	stranded := analyze.Stranded(nodeInfoList, shape)
+	switch *output {
+	case "json":
+		return display.DisplayJSON(stranded)
+	case "sarif":
+		return sarif.Write(os.Stdout, sarif.FromStranded(stranded))
+	}
+	if len(stranded) == 0 {
+		fmt.Printf("No free devices are stranded for pods of %s.\n", shape)
+		return nil
+	}
+	if !display.Quiet {
+		fmt.Printf("Reference pod: %s\n\n", shape)
+	}
+	display.DisplayStrandedDevices(stranded)
+	return nil
+}
+
+// workloadProfile returns the pod shape of the named workload profile of the
+// configuration file.
+func workloadProfile(name string) (analyze.PodShape, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return analyze.PodShape{}, err
+	}
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return analyze.PodShape{}, err
+	}
+	return analyze.PodShape{CPU: profile.CPU, Memory: profile.Memory, Devices: profile.Devices, Products: profile.Products}, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os/user"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/audit"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/config"
+)
+
+// auditSink is the audit log sink given with -audit-log, overriding the
+// configuration file.
+var auditSink string
+
+// openAuditLog returns the audit log of -audit-log or the configuration file,
+// or nil if neither sets a sink. Mutating commands open it before changing
+// anything, so a broken sink stops them early.
+func openAuditLog() (*audit.Logger, error) {
+	sink := auditSink
+	if sink == "" && configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Audit != nil {
+			sink = cfg.Audit.Sink
+		}
+	}
+	if sink == "" {
+		return nil, nil
+	}
+	logger, err := audit.Open(sink)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := user.Current(); err == nil {
+		logger.User = u.Username
+	}
+	// in-cluster runs have no kubeconfig; the pod's service account is
+	// recorded by the API server's own audit log
+	logger.KubeUser, _ = resourceClient.CurrentUser(kubeconfigPath)
+	return logger, nil
+}
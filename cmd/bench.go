@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/synthetic"
+)
+
+// runBench measures the aggregation of a synthetic cluster generated in
+// memory, without talking to a cluster.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	nodes := fs.Int("nodes", 1000, "number of nodes to generate")
+	devices := fs.Int("devices", 8, "number of devices per node")
+	claims := fs.Int("claims", 4000, "number of claims to generate, each consumed by one pod")
+	fs.Parse(args)
+	if *nodes < 0 || *devices < 0 || *claims < 0 {
+		return errors.New("--nodes, --devices and --claims must not be negative")
+	}
+
+	cluster := synthetic.Generate(synthetic.Options{Nodes: *nodes, DevicesPerNode: *devices, Claims: *claims})
+	resourceSlices := model.FromV1beta1ResourceSlices(cluster.ResourceSlices)
+	resourceClaims := model.FromV1beta1ResourceClaims(cluster.ResourceClaims)
+
+	var benchErr error
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := resourceClient.Aggregate(context.Background(), cluster.Nodes, resourceSlices, resourceClaims, cluster.Pods); err != nil {
+				benchErr = err
+				b.SkipNow()
+			}
+		}
+	})
+	if benchErr != nil {
+		return benchErr
+	}
+
+	perSecond := 0.0
+	if ns := result.NsPerOp(); ns > 0 {
+		perSecond = 1e9 / float64(ns)
+	}
+	fmt.Printf("Cluster: %d nodes, %d devices, %d claims\n", *nodes, *nodes*(*devices), *claims)
+	fmt.Printf("Aggregation: %s  %s\n", result.String(), result.MemString())
+	fmt.Printf("Throughput: %.1f snapshots/s\n", perSecond)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/check"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/sarif"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// runCheck evaluates the built-in checks, and the policies of --policies, and
+// fails if any is violated, for use in CI and cron jobs.
+func runCheck(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	output := fs.String("o", "table", "output format: table, junit or sarif")
+	suite := fs.String("suite", "dra-resources", "test suite name of the JUnit report, e.g. the cluster name")
+	policies := fs.String("policies", "", "directory of YAML files with CEL policies to evaluate besides the built-in checks")
+	fs.Parse(args)
+
+	if *output != "table" && *output != "junit" && *output != "sarif" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	checks := check.Builtin
+	if *policies != "" {
+		policyChecks, err := check.LoadPolicies(*policies)
+		if err != nil {
+			return err
+		}
+		checks = append(checks[:len(checks):len(checks)], policyChecks...)
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+	claims, err := client.GetResourceClaims(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	duplicates, err := client.GetDuplicateDevices(ctx)
+	if err != nil {
+		return err
+	}
+
+	kubeletMismatches, err := client.GetKubeletDeviceMismatches(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &check.Snapshot{Nodes: nodeInfoList, Claims: claims, Duplicates: duplicates, KubeletMismatches: kubeletMismatches}
+	if check.NeedDevices(checks) {
+		if snapshot.Devices, err = client.GetDevices(ctx, resourceClient.ListOptions{}); err != nil {
+			return err
+		}
+	}
+
+	results := check.Run(checks, snapshot)
+	switch *output {
+	case "junit":
+		if err := check.WriteJUnit(os.Stdout, *suite, results); err != nil {
+			return err
+		}
+	case "sarif":
+		if err := sarif.Write(os.Stdout, sarif.FromCheckResults(results)); err != nil {
+			return err
+		}
+	default:
+		display.DisplayCheckResults(results)
+	}
+	return checkError(results)
+}
+
+// checkError returns an error if any check failed, so the exit status tells
+// CI whether the cluster is healthy.
+func checkError(results []types.CheckResult) error {
+	failed := 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/audit"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+func runClaims(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: claims describe|cleanup|templates|explain-mutations [flags]")
+	}
+
+	switch args[0] {
+	case "describe":
+		return runClaimsDescribe(ctx, client, args[1:])
+	case "cleanup":
+		return runClaimsCleanup(ctx, client, args[1:])
+	case "templates":
+		return runClaimsTemplates(ctx, client, args[1:])
+	case "explain-mutations":
+		return runClaimsExplainMutations(ctx, client, args[1:])
+	default:
+		return fmt.Errorf("unknown claims command %q", args[0])
+	}
+}
+
+func runClaimsCleanup(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("claims cleanup", flag.ExitOnError)
+	orphans := fs.Bool("orphans", false, "select allocated claims whose consumers no longer exist")
+	mutation := addMutationFlags(fs)
+	fs.Parse(args)
+
+	if !*orphans {
+		return errors.New("claims cleanup needs a selector, e.g. --orphans")
+	}
+
+	claims, err := client.GetOrphanedResourceClaims(ctx)
+	if err != nil {
+		return err
+	}
+	if len(claims) == 0 {
+		fmt.Println("No orphaned claims found.")
+		return nil
+	}
+
+	auditLog, err := openAuditLog()
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+
+	display.DisplayClaims(claims)
+
+	fmt.Println()
+	ok, err := mutation.confirm(fmt.Sprintf("delete %d claim(s)", len(claims)))
+	if err != nil {
+		return err
+	}
+	entry := func(claim *types.ClaimInfo) audit.Entry {
+		return audit.Entry{Command: "claims cleanup", Verb: "delete", Resource: "resourceclaims", Namespace: claim.Namespace, Name: claim.Name, DryRun: mutation.dryRun}
+	}
+	if !ok {
+		if mutation.dryRun {
+			for _, claim := range claims {
+				if err := auditLog.Log(ctx, entry(claim)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, claim := range claims {
+		deleteErr := client.DeleteResourceClaim(ctx, claim)
+		e := entry(claim)
+		if deleteErr != nil {
+			e.Error = deleteErr.Error()
+		}
+		// an unrecorded change must not be followed by more
+		if err := auditLog.Log(ctx, e); err != nil {
+			return err
+		}
+		// the claim may no longer be orphaned, or be another one by now
+		if errors.Is(deleteErr, resourceClient.ErrChangedSinceListing) {
+			fmt.Printf("resourceclaim %s/%s changed since listing, not deleted\n", claim.Namespace, claim.Name)
+			continue
+		}
+		if deleteErr != nil {
+			return deleteErr
+		}
+		fmt.Printf("resourceclaim %s/%s deleted\n", claim.Namespace, claim.Name)
+	}
+	return nil
+}
+
+// runClaimsTemplates reports how the claims generated from each
+// ResourceClaimTemplate fare, and templates referencing missing DeviceClasses.
+func runClaimsTemplates(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("claims templates", flag.ExitOnError)
+	fs.Parse(args)
+
+	stats, err := client.GetClaimTemplateStats(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Println("No ResourceClaimTemplates found.")
+		return nil
+	}
+	display.DisplayClaimTemplateStats(stats)
+	return nil
+}
+
+// runClaimsExplainMutations shows how a stored claim differs from the
+// template or manifest it was created from, e.g. through defaulting or
+// mutating webhooks, to debug unexpected allocations.
+func runClaimsExplainMutations(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("claims explain-mutations", flag.ExitOnError)
+	output := fs.String("o", "table", "output format: table or json")
+
+	namespace, name := parseClaimArgs(fs, args, client.Namespace())
+	if name == "" {
+		return errors.New("usage: claims explain-mutations [<namespace>/]<name> [-o table|json]")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	mutations, err := client.GetClaimMutations(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return display.DisplayJSON(mutations)
+	}
+	display.DisplayClaimMutations(mutations)
+	return nil
+}
+
+// runClaimsDescribe prints a claim with the device allocated for each request
+// and the driver configuration of its allocation.
+func runClaimsDescribe(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("claims describe", flag.ExitOnError)
+	output := fs.String("o", "table", "output format: table or json")
+	namespace, name := parseClaimArgs(fs, args, client.Namespace())
+	if name == "" {
+		return errors.New("usage: claims describe [<namespace>/]<name> [-o table|json]")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	description, err := client.GetClaimDescription(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return display.DisplayJSON(description)
+	}
+	display.DisplayClaimDescription(description)
+	return nil
+}
+
+// parseClaimArgs parses the flags of fs and the [<namespace>/]<name> claim
+// argument, given before or after the flags. The namespace defaults to
+// namespace; the name is empty if no claim was given.
+func parseClaimArgs(fs *flag.FlagSet, args []string, namespace string) (string, string) {
+	var ref string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ref, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if ref == "" {
+		ref = fs.Arg(0)
+	}
+	if claimNamespace, name, ok := strings.Cut(ref, "/"); ok {
+		return claimNamespace, name
+	}
+	return namespace, ref
+}
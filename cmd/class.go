@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+)
+
+// runClass describes a DeviceClass and, with --consumers, the claims and
+// workloads that changing it would affect.
+func runClass(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("class", flag.ExitOnError)
+	consumers := fs.Bool("consumers", false, "list the claims, templates and workloads referencing the class")
+	output := fs.String("o", "table", "output format: table or json")
+
+	// accept the class name before or after the flags
+	var className string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		className, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if className == "" {
+		className = fs.Arg(0)
+	}
+	if className == "" {
+		return errors.New("usage: class <name> [--consumers] [-o table|json]")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	if *consumers {
+		classConsumers, err := client.GetClassConsumers(ctx, className)
+		if err != nil {
+			return err
+		}
+		if *output == "json" {
+			return display.DisplayJSON(classConsumers)
+		}
+		display.DisplayClassConsumers(classConsumers)
+		return nil
+	}
+
+	classes, err := client.GetDeviceClasses(ctx)
+	if err != nil {
+		return err
+	}
+	for _, class := range classes {
+		if class.Name == className {
+			if *output == "json" {
+				return display.DisplayJSON(class)
+			}
+			display.DisplayDeviceClass(class)
+			return nil
+		}
+	}
+	return fmt.Errorf("DeviceClass %s not found", className)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// mutationFlags holds the flags shared by every command that changes cluster
+// state. Mutating commands default to a dry run and ask for confirmation
+// before acting unless --yes is given.
+type mutationFlags struct {
+	dryRun bool
+	yes    bool
+}
+
+func addMutationFlags(fs *flag.FlagSet) *mutationFlags {
+	m := &mutationFlags{}
+	fs.BoolVar(&m.dryRun, "dry-run", true, "only print what would be changed")
+	fs.BoolVar(&m.yes, "yes", false, "skip the confirmation prompt")
+	return m
+}
+
+// confirm decides whether the described action may proceed. In dry-run mode
+// it reports what would happen and returns false. Otherwise it prompts on
+// stdin, refusing to guess when stdin is not a terminal and --yes is unset.
+func (m *mutationFlags) confirm(action string) (bool, error) {
+	if m.dryRun {
+		fmt.Printf("Dry run: would %s. Re-run with --dry-run=false to apply.\n", action)
+		return false, nil
+	}
+	if m.yes {
+		return true, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return false, errors.New("stdin is not a terminal; pass --yes to confirm non-interactively")
+	}
+	// the pager reads the terminal too; let the user finish reading first
+	stdoutPager.stop()
+	return prompt(os.Stdin, fmt.Sprintf("%s?", capitalize(action)))
+}
+
+func prompt(in io.Reader, question string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "y" || answer == "yes" {
+		return true, nil
+	}
+	fmt.Println("Aborted.")
+	return false, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
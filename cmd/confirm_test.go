@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// failingReader fails every read.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestPrompt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "y\n", expected: true},
+		{input: "YES\n", expected: true},
+		{input: " yes \n", expected: true},
+		{input: "y", expected: true},
+		{input: "n\n", expected: false},
+		{input: "yep\n", expected: false},
+		{input: "\n", expected: false},
+		{input: "", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Quote(tt.input), func(t *testing.T) {
+			got, err := prompt(strings.NewReader(tt.input), "Delete 1 claim(s)?")
+			if err != nil {
+				t.Fatalf("prompt() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("prompt() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := prompt(failingReader{}, "Delete 1 claim(s)?"); err == nil {
+		t.Errorf("expected an error when reading the answer fails")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    mutationFlags
+		expected bool
+	}{
+		{name: "dry run", flags: mutationFlags{dryRun: true}, expected: false},
+		{name: "dry run wins over yes", flags: mutationFlags{dryRun: true, yes: true}, expected: false},
+		{name: "yes", flags: mutationFlags{yes: true}, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.flags.confirm("delete 1 claim(s)")
+			if err != nil {
+				t.Fatalf("confirm() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("confirm() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
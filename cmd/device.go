@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/recorder"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+func runDevice(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: device describe|history <driver>/<pool>/<device> [flags]")
+	}
+
+	switch args[0] {
+	case "describe":
+		return runDeviceDescribe(ctx, client, args[1:])
+	case "history":
+		return runDeviceHistory(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown device command %q", args[0])
+	}
+}
+
+// runDeviceHistory shows the claims and pods that held a device over time,
+// as recorded by "watch --record".
+func runDeviceHistory(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("device history", flag.ExitOnError)
+	db := fs.String("db", "", "SQLite database or PostgreSQL URL recorded by watch --record")
+	output := fs.String("o", "table", "output format: table or json")
+
+	ref, driver, pool, device, ok := parseDeviceArgs(fs, args)
+	if !ok {
+		return errors.New("usage: device history <driver>/<pool>/<device> --db <path> [-o table|json]")
+	}
+	if *db == "" {
+		return errors.New("device history needs the database recorded by watch --record, see --db")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	rec, err := recorder.Open(*db, nil)
+	if err != nil {
+		return err
+	}
+	defer rec.Close()
+	history, err := rec.DeviceHistory(ctx, driver, pool, device)
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return display.DisplayJSON(history)
+	}
+	if len(history) == 0 {
+		fmt.Printf("No history recorded for device %s.\n", ref)
+		return nil
+	}
+	display.DisplayDeviceHistory(history)
+	return nil
+}
+
+// runDeviceDescribe shows a single device and, with --driver-logs, the last
+// log lines of its driver on the node when the device is unhealthy.
+func runDeviceDescribe(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("device describe", flag.ExitOnError)
+	driverLogs := fs.Bool("driver-logs", false, "include recent log lines of the driver pod on the node if the device is unhealthy")
+	logLines := fs.Int64("log-lines", 50, "number of driver log lines to include with --driver-logs")
+	output := fs.String("o", "table", "output format: table or json")
+
+	ref, driver, pool, device, ok := parseDeviceArgs(fs, args)
+	if !ok {
+		return errors.New("usage: device describe <driver>/<pool>/<device> [--driver-logs] [--log-lines N] [-o table|json]")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	if *logLines <= 0 {
+		return errors.New("--log-lines must be positive")
+	}
+
+	devices, err := client.GetDevices(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(devices, func(dev types.DeviceInfo) bool {
+		return dev.Driver == driver && dev.Pool == pool && dev.Name == device
+	})
+	if i < 0 {
+		return fmt.Errorf("device %s not found", ref)
+	}
+	description := types.DeviceDescription{DeviceInfo: devices[i]}
+	// only node-local devices have a driver pod next to them
+	if *driverLogs && description.Unhealthy && description.NodeName != "" {
+		description.DriverLogs, err = client.GetDriverLogs(ctx, driver, description.NodeName, *logLines)
+		if err != nil {
+			// the device itself is still worth showing
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if *output == "json" {
+		return display.DisplayJSON(description)
+	}
+	display.DisplayDeviceDescription(description)
+	return nil
+}
+
+// parseDeviceArgs parses the flags and the <driver>/<pool>/<device> argument
+// of a device command, which may come before or after the flags.
+func parseDeviceArgs(fs *flag.FlagSet, args []string) (ref, driver, pool, device string, ok bool) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ref, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if ref == "" {
+		ref = fs.Arg(0)
+	}
+	// pool names may contain slashes, driver and device names cannot
+	driver, rest, _ := strings.Cut(ref, "/")
+	i := strings.LastIndex(rest, "/")
+	if driver == "" || i <= 0 || i == len(rest)-1 {
+		return ref, "", "", "", false
+	}
+	return ref, driver, rest[:i], rest[i+1:], true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// runDevices lists every device in the cluster.
+func runDevices(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("devices", flag.ExitOnError)
+	output := fs.String("o", "", "output format: empty for the default columns, or wide to add firmware and VBIOS versions")
+	groupBy := fs.String("group-by", "", "summarize devices instead of listing them: product, sorted by available count")
+	node := fs.String("node", "", "only list the devices of this node")
+	driver := fs.String("driver", "", "only list the devices of this driver")
+	fs.Parse(args)
+
+	var wideAttributes []string
+	switch *output {
+	case "":
+	case "wide":
+		wideAttributes = resourceClient.FirmwareAttributes
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	switch *groupBy {
+	case "", "product":
+	default:
+		return fmt.Errorf("unknown --group-by %q, expected product", *groupBy)
+	}
+
+	devices, err := client.GetDevices(ctx, resourceClient.ListOptions{NodeName: *node, Driver: *driver, NodeSelector: tableOptions.NodeSelector})
+	if err != nil {
+		return err
+	}
+	if tableOptions.ExcludeSpot {
+		devices = slices.DeleteFunc(devices, func(dev types.DeviceInfo) bool { return dev.Spot })
+	}
+	if len(devices) == 0 {
+		fmt.Println("No devices found.")
+		return nil
+	}
+	if *groupBy == "product" {
+		display.DisplayProductInventory(analyze.ProductInventory(devices))
+		return nil
+	}
+	display.DisplayDevices(devices, wideAttributes)
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/config"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/notify"
+)
+
+// defaultConfigPath returns the configuration file in the user's
+// configuration directory, or nothing if there is none.
+func defaultConfigPath() string {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// runEmailReport emails the capacity report to the recipients of the
+// configuration file, for scheduled runs such as a CronJob.
+func runEmailReport(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("email-report", flag.ExitOnError)
+	format := fs.String("format", "html", "report format: html, sent as the body, or csv, sent as an attachment")
+	title := fs.String("title", "DRA capacity report", "title of the report, and subject unless configured")
+	charts := fs.Bool("charts", false, "embed availability charts in the HTML report")
+	dryRun := fs.Bool("dry-run", false, "print the email instead of sending it")
+	fs.Parse(args)
+
+	if *format != "html" && *format != "csv" {
+		return fmt.Errorf("unknown format %q, expected html or csv", *format)
+	}
+	if configPath == "" {
+		return fmt.Errorf("no configuration file, set -config")
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Email == nil {
+		return fmt.Errorf("no email section in configuration file %s", configPath)
+	}
+	password, err := cfg.Email.Password()
+	if err != nil && !*dryRun {
+		return err
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	msg := &notify.Message{
+		From:    cfg.Email.From,
+		To:      cfg.Email.To,
+		Subject: cfg.Email.Subject,
+	}
+	if msg.Subject == "" {
+		msg.Subject = *title
+	}
+	var buf bytes.Buffer
+	switch *format {
+	case "html":
+		if err := display.WriteHTMLReport(&buf, nodeInfoList, tableOptions, *title, *charts); err != nil {
+			return err
+		}
+		msg.Body, msg.BodyType = buf.String(), "text/html"
+	case "csv":
+		if err := display.WriteCSV(&buf, nodeInfoList, tableOptions); err != nil {
+			return err
+		}
+		msg.Body = fmt.Sprintf("%s, generated %s, is attached.\n", *title, time.Now().UTC().Format(time.RFC1123))
+		msg.Attachments = []notify.Attachment{{
+			Filename:    "dra-capacity-" + time.Now().UTC().Format("2006-01-02") + ".csv",
+			ContentType: "text/csv",
+			Data:        buf.Bytes(),
+		}}
+	}
+
+	if *dryRun {
+		data, err := msg.Bytes(time.Now())
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	sender := notify.NewSMTPSender(cfg.Email.Host, cfg.Email.Port, cfg.Email.Username, password)
+	if err := sender.Send(msg); err != nil {
+		return err
+	}
+	if !display.Quiet {
+		fmt.Printf("Sent %s to %d recipients.\n", *title, len(msg.To))
+	}
+	return nil
+}
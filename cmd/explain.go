@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+)
+
+// requestPlanner records the requests of the command instead of sending them
+// when -explain-requests is set.
+var requestPlanner *resourceClient.RequestPlanner
+
+// explainCtx is the context the requests are estimated with when the
+// command exits early.
+var explainCtx context.Context
+
+// explainStdout is the standard output while the command's own output is
+// discarded.
+var explainStdout *os.File
+
+// startExplaining returns a client recording the requests made through it,
+// and discards the command's output until finishExplaining.
+func startExplaining(client resourceClient.ResourceClient) (resourceClient.ResourceClient, error) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	requestPlanner = resourceClient.NewRequestPlanner(client)
+	explainStdout, os.Stdout = os.Stdout, devNull
+	return requestPlanner, nil
+}
+
+// finishExplaining prints the recorded requests with their estimated cost.
+// stopped is the error the command stopped at, if any: the planner answers
+// like an empty cluster, so e.g. a named node is not found, and requests
+// after that point are missing.
+func finishExplaining(ctx context.Context, stopped string) {
+	os.Stdout.Close()
+	os.Stdout = explainStdout
+
+	requests, err := requestPlanner.Requests(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	display.DisplayRequestPlan(requests)
+	if stopped != "" {
+		fmt.Fprintf(os.Stderr, "\nWarning: the dry run stopped early at this error, later requests are missing:\n%s", stopped)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stringSliceFlag collects the values of a flag that may be repeated.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// daysDuration is a duration flag also accepting whole days, e.g. 30d, which
+// time.ParseDuration does not.
+type daysDuration time.Duration
+
+func (d *daysDuration) String() string {
+	return time.Duration(*d).String()
+}
+
+func (d *daysDuration) Set(value string) error {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of days %q", value)
+		}
+		*d = daysDuration(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = daysDuration(duration)
+	return nil
+}
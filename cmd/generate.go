@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/metrics"
+	"sigs.k8s.io/yaml"
+)
+
+// runGenerate prints manifests derived from this tool, such as alert rules
+// for the metrics of serve.
+func runGenerate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: generate alerts [flags]")
+	}
+
+	switch args[0] {
+	case "alerts":
+		return runGenerateAlerts(args[1:])
+	default:
+		return fmt.Errorf("unknown generate command %q", args[0])
+	}
+}
+
+func runGenerateAlerts(args []string) error {
+	fs := flag.NewFlagSet("generate alerts", flag.ExitOnError)
+	name := fs.String("name", "dra-resources", "name of the PrometheusRule")
+	namespace := fs.String("namespace", "", "namespace of the PrometheusRule")
+	fs.Parse(args)
+
+	data, err := yaml.Marshal(metrics.AlertRules(*name, *namespace))
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert rules: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
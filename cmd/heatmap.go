@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+)
+
+// runHeatmap prints one cell per node shaded by device allocation or
+// utilization, for fleets too large to scan as a table.
+func runHeatmap(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	metric := fs.String("metric", analyze.SortByAllocation, "shade nodes by allocation or utilization")
+	width := fs.Int("width", 64, "nodes per row")
+	var util utilizationFlags
+	util.register(fs)
+	fs.Parse(args)
+
+	if *metric != analyze.SortByAllocation && *metric != analyze.SortByUtilization {
+		return fmt.Errorf("unknown metric %q, expected allocation or utilization", *metric)
+	}
+	if *metric == analyze.SortByUtilization && util.prometheusURL == "" {
+		return fmt.Errorf("the utilization heatmap requires --prometheus-url")
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+	utilization, err := util.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	usage := analyze.TopNodes(nodeInfoList, utilization, *metric, 0)
+	if len(usage) == 0 {
+		fmt.Println("No nodes with devices found.")
+		return nil
+	}
+	color := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	display.DisplayHeatmap(usage, *metric == analyze.SortByUtilization, *width, color)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// leaderElectionFlags holds the flags shared by the long-running modes that
+// can be deployed with several replicas.
+type leaderElectionFlags struct {
+	enabled   bool
+	leaseName string
+	namespace string
+}
+
+func addLeaderElectionFlags(fs *flag.FlagSet, defaultLeaseName string) *leaderElectionFlags {
+	l := &leaderElectionFlags{}
+	fs.BoolVar(&l.enabled, "leader-elect", false, "only act while holding a Lease, so several replicas can run for availability")
+	fs.StringVar(&l.leaseName, "leader-elect-lease-name", defaultLeaseName, "name of the Lease replicas compete for")
+	fs.StringVar(&l.namespace, "leader-elect-namespace", "", "namespace of the Lease (defaults to the kubeconfig's namespace)")
+	return l
+}
+
+// run calls fn directly when leader election is disabled, and otherwise only
+// while this replica is the leader.
+func (l *leaderElectionFlags) run(ctx context.Context, client resourceClient.ResourceClient, fn func(ctx context.Context) error) error {
+	if !l.enabled {
+		return fn(ctx)
+	}
+	config, err := l.config(client)
+	if err != nil {
+		return err
+	}
+	return client.RunLeaderElected(ctx, config, fn)
+}
+
+func (l *leaderElectionFlags) config(client resourceClient.ResourceClient) (resourceClient.LeaderElectionConfig, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return resourceClient.LeaderElectionConfig{}, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	namespace := l.namespace
+	if namespace == "" {
+		namespace = client.Namespace()
+	}
+	return resourceClient.LeaderElectionConfig{
+		LeaseName:      l.leaseName,
+		LeaseNamespace: namespace,
+		Identity:       hostname + "_" + string(uuid.NewUUID()),
+	}, nil
+}
@@ -1,33 +1,383 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
 	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
 	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/schema"
+	"github.com/dharmjit/k8s-dra-resources/pkg/synthetic"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// commands maps subcommand names to their implementations. Running without a
+// subcommand prints the node table.
+var commands = map[string]func(ctx context.Context, client resourceClient.ResourceClient, args []string) error{
+	"analyze":      runAnalyze,
+	"check":        runCheck,
+	"claims":       runClaims,
+	"class":        runClass,
+	"device":       runDevice,
+	"devices":      runDevices,
+	"email-report": runEmailReport,
+	"heatmap":      runHeatmap,
+	"my":           runMy,
+	"node":         runNode,
+	"operator":     runOperator,
+	"pods":         runPods,
+	"report":       runReport,
+	"reservations": runReservations,
+	"serve":        runServe,
+	"simulate":     runSimulate,
+	"top":          runTop,
+	"versions":     runVersions,
+	"watch":        runWatch,
+	"workloads":    runWorkloads,
+}
+
+// tableOptions holds the node table options given on the command line, for
+// commands that render the node table.
+var tableOptions display.Options
+
+// longRunningCommands keep running until interrupted. They do not show fetch
+// progress, which would interleave with their own output.
+var longRunningCommands = map[string]bool{
+	"operator": true,
+	"serve":    true,
+	"top":      true,
+	"watch":    true,
+}
+
+// progress shows fetch progress on stderr when it is a terminal.
+var progress = &progressPrinter{out: os.Stderr}
+
+// kubeconfigPath is the kubeconfig file in use, for commands that connect to
+// other contexts than the current one.
+var kubeconfigPath string
+
+// configPath is the configuration file, for commands sending reports or
+// alerts.
+var configPath string
+
+// localCommands do not talk to the cluster and run without a kubeconfig.
+var localCommands = map[string]func(args []string) error{
+	"bench":    runBench,
+	"generate": runGenerate,
+	"record":   runRecord,
+	"schema":   runSchema,
+}
+
 func main() {
 	kubeconfig := flag.String("kubeconfig", os.Getenv("KUBECONFIG"), "path to the kubeconfig file")
+	capacityKeys := flag.String("capacity-keys", "memory", "comma-separated device capacity names to show, or \"all\"")
+	showPools := flag.Bool("pools", false, "print per-pool device availability")
+	excludeUnschedulable := flag.Bool("exclude-unschedulable", false, "count devices on cordoned or NotReady nodes as unavailable")
+	output := flag.String("o", "table", "output format: table, json, csv or html")
+	htmlTitle := flag.String("html-title", "DRA capacity report", "title of the -o html report")
+	htmlCharts := flag.Bool("html-charts", false, "embed availability charts in the -o html report")
+	outputVersion := flag.String("output-version", schema.DefaultVersion, "version of machine-readable output: v1alpha1, or v0 for the legacy unwrapped format")
+	groupBy := flag.String("group-by", "", "print subtotals of capacity and devices per nodepool or instance-type")
+	groupByLabel := flag.String("group-by-label", "", "print subtotals of capacity and devices per value of this node label, e.g. topology.kubernetes.io/zone")
+	excludeSpot := flag.Bool("exclude-spot", false, "leave out nodes on spot or preemptible capacity")
+	nodeOS := flag.String("os", "", "only show nodes running this operating system, e.g. linux to hide Windows nodes")
+	nodeArch := flag.String("arch", "", "only show nodes of this CPU architecture, e.g. amd64 or arm64")
+	nodeName := flag.String("node", "", "only fetch and show this node, using field selectors to skip unrelated data")
+	nodeSelector := flag.String("node-selector", "", "only fetch and show nodes matching this label selector, e.g. nvidia.com/gpu.present=true")
+	explainRequests := flag.Bool("explain-requests", false, "print the API requests the command would make and their estimated cost instead of running it")
+	demo := flag.Bool("demo", false, "show generated sample data instead of connecting to a cluster")
+	cacheTTL := flag.Duration("cache-ttl", 0, "reuse the last fetched snapshot of the current context for this long, e.g. 30s (0 disables the cache)")
+	nodeResources := flag.String("resources", "", "comma-separated node resources to add to the node table, e.g. hugepages-2Mi,ephemeral-storage")
+	compressAbove := flag.Int("compress-above", 1000, "print one row per node shape (role, instance type and devices) instead of one per node above this many nodes (0 never compresses)")
+	columns := flag.String("columns", "", "comma-separated columns of the node table, e.g. NODE,DEVICES,GPU_AVAIL (\"help\" lists them)")
+	locale := flag.String("locale", "", "format numbers in tables for this locale, e.g. en-US or de-DE, or \"auto\" to use LANG (JSON output is unaffected)")
+	flag.StringVar(&configPath, "config", defaultConfigPath(), "path to the configuration file")
+	flag.StringVar(&auditSink, "audit-log", "", "record changes to the cluster as JSON lines in this file, \"-\" for stderr, or an http(s) URL")
+	noPager := flag.Bool("no-pager", false, "do not pipe long output through $PAGER")
+	flag.BoolVar(&display.Timestamps, "timestamps", false, "show creation times as RFC 3339 timestamps instead of relative ages")
+	flag.BoolVar(&display.Quiet, "quiet", false, "suppress status lines and print only the results")
+	flag.BoolVar(&display.NoHeaders, "no-headers", false, "omit table headers and legends, e.g. for awk or cut")
+	var decoratorPlugins stringSliceFlag
+	flag.Var(&decoratorPlugins, "decorator-plugin", "driver=path of an executable decorating the driver's devices (repeatable)")
 	flag.Parse()
 
+	for _, plugin := range decoratorPlugins {
+		driver, path, ok := strings.Cut(plugin, "=")
+		if !ok || driver == "" || path == "" {
+			fmt.Fprintf(os.Stderr, "Error: invalid -decorator-plugin %q, expected driver=path\n", plugin)
+			os.Exit(1)
+		}
+		decorator.Register(driver, &decorator.Exec{Path: path})
+	}
+
+	if err := display.SetLocale(*locale); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *columns == "help" {
+		display.DisplayColumns()
+		return
+	}
+	var selectedColumns []string
+	if *columns != "" {
+		var err error
+		if selectedColumns, err = display.ParseColumns(*columns); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if run, ok := localCommands[flag.Arg(0)]; ok {
+		if err := run(flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if *kubeconfig == "" {
 		*kubeconfig = clientcmd.RecommendedHomeFile
 	}
 
-	client, err := resourceClient.NewResourceClient(*kubeconfig)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating DRA client: %v\n", err)
-		os.Exit(1)
+	kubeconfigPath = *kubeconfig
+
+	var client resourceClient.ResourceClient
+	if *demo {
+		client = resourceClient.NewResourceClientForClientset(fake.NewSimpleClientset(synthetic.Demo().Objects()...), synthetic.Namespace)
+	} else {
+		var err error
+		client, err = resourceClient.NewResourceClient(*kubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating DRA client: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	if err := display.DisplayTabularInfo(client); err != nil {
-		fmt.Fprintf(os.Stderr, "Error displaying node info: %v\n", err)
-		os.Exit(1)
+	if *explainRequests {
+		if longRunningCommands[flag.Arg(0)] {
+			fmt.Fprintf(os.Stderr, "Error: -explain-requests does not support %s, which runs until interrupted\n", flag.Arg(0))
+			os.Exit(1)
+		}
+		var err error
+		if client, err = startExplaining(client); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	fmt.Println("\n------------------------------")
-}
\ No newline at end of file
+	// snapshots are cached without node labels, which -group-by-label needs
+	if *cacheTTL > 0 && !*demo && !*explainRequests && *groupByLabel == "" {
+		contextName, err := resourceClient.CurrentContext(*kubeconfig)
+		if err == nil {
+			var dir string
+			dir, err = resourceClient.DefaultCacheDir()
+			if err == nil {
+				client = resourceClient.NewCachingClient(client, dir, *kubeconfig+"\x00"+contextName, *cacheTTL)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up snapshot cache: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	tableOptions = display.Options{
+		CapacityKeys:         strings.Split(*capacityKeys, ","),
+		ShowPools:            *showPools,
+		ExcludeUnschedulable: *excludeUnschedulable,
+		ExcludeSpot:          *excludeSpot,
+		OS:                   *nodeOS,
+		Arch:                 *nodeArch,
+		Columns:              selectedColumns,
+		Resources:            parseResources(*nodeResources),
+		CompressAbove:        *compressAbove,
+		NodeSelector:         *nodeSelector,
+	}
+	nodeFilter := resourceClient.ListOptions{NodeName: *nodeName, NodeSelector: *nodeSelector}
+
+	ctx := signalContext(context.Background())
+	if requestPlanner != nil {
+		explainCtx = ctx
+		defer finishExplaining(ctx, "")
+	} else if isTerminal(os.Stderr) && !longRunningCommands[flag.Arg(0)] {
+		ctx = resourceClient.WithProgress(ctx, progress.update)
+	}
+
+	if !*noPager && requestPlanner == nil && isTerminal(os.Stdout) && pagedCommands[flag.Arg(0)] {
+		if err := stdoutPager.start(); err != nil {
+			// fall back to writing to the terminal directly
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		defer stdoutPager.stop()
+	}
+
+	switch flag.Arg(0) {
+	case "":
+		if (*groupBy != "" || *groupByLabel != "") && *output != "table" {
+			fatalf("Error: -group-by and -group-by-label only support table output\n")
+		}
+		if *groupBy != "" && *groupByLabel != "" {
+			fatalf("Error: -group-by and -group-by-label are mutually exclusive\n")
+		}
+		if *output == "json" {
+			nodeInfoList, err := client.GetK8sResources(ctx, nodeFilter)
+			if *excludeSpot {
+				nodeInfoList = analyze.ExcludeSpot(nodeInfoList)
+			}
+			if *nodeOS != "" {
+				nodeInfoList = analyze.FilterOS(nodeInfoList, *nodeOS)
+			}
+			if *nodeArch != "" {
+				nodeInfoList = analyze.FilterArch(nodeInfoList, *nodeArch)
+			}
+			var out any
+			if err == nil {
+				out, err = schema.Nodes(*outputVersion, nodeInfoList)
+			}
+			if err == nil {
+				err = display.DisplayJSON(out)
+			}
+			if err != nil {
+				exitOnSignal()
+				fatalf("Error displaying node info: %v\n", err)
+			}
+			return
+		}
+		if *output == "html" {
+			nodeInfoList, err := client.GetK8sResources(ctx, nodeFilter)
+			if err == nil {
+				err = display.DisplayHTMLReport(nodeInfoList, tableOptions, *htmlTitle, *htmlCharts)
+			}
+			if err != nil {
+				exitOnSignal()
+				fatalf("Error displaying node info: %v\n", err)
+			}
+			return
+		}
+		if *output == "csv" {
+			nodeInfoList, err := client.GetK8sResources(ctx, nodeFilter)
+			if err == nil {
+				err = display.DisplayCSV(nodeInfoList, tableOptions)
+			}
+			if err != nil {
+				exitOnSignal()
+				fatalf("Error displaying node info: %v\n", err)
+			}
+			return
+		}
+		if *output != "table" {
+			fatalf("Error: unknown output format %q\n", *output)
+		}
+
+		if *groupBy != "" || *groupByLabel != "" {
+			nodeInfoList, err := client.GetK8sResources(ctx, nodeFilter)
+			if err != nil {
+				exitOnSignal()
+				fatalf("Error displaying node info: %v\n", err)
+			}
+			if *excludeSpot {
+				nodeInfoList = analyze.ExcludeSpot(nodeInfoList)
+			}
+			if *nodeOS != "" {
+				nodeInfoList = analyze.FilterOS(nodeInfoList, *nodeOS)
+			}
+			if *nodeArch != "" {
+				nodeInfoList = analyze.FilterArch(nodeInfoList, *nodeArch)
+			}
+			switch *groupBy {
+			case "":
+				groups := analyze.GroupByLabel(nodeInfoList, *groupByLabel, *excludeUnschedulable)
+				display.DisplayNodeGroups(*groupByLabel, groups, tableOptions)
+			case "nodepool":
+				display.DisplayNodeGroups("nodepool", analyze.GroupByNodePool(nodeInfoList, *excludeUnschedulable), tableOptions)
+			case "instance-type":
+				display.DisplayNodeGroups("instance-type", analyze.GroupByInstanceType(nodeInfoList, *excludeUnschedulable), tableOptions)
+			default:
+				fatalf("Error: unknown -group-by %q, expected nodepool or instance-type\n", *groupBy)
+			}
+			return
+		}
+
+		if *nodeName != "" {
+			nodeInfoList, err := client.GetK8sResources(ctx, nodeFilter)
+			if err != nil {
+				exitOnSignal()
+				fatalf("Error displaying node info: %v\n", err)
+			}
+			display.DisplayNodes(nodeInfoList, tableOptions)
+			return
+		}
+
+		if err := display.DisplayTabularInfoContext(ctx, client, tableOptions); err != nil {
+			exitOnSignal()
+			fatalf("Error displaying node info: %v\n", err)
+		}
+
+		if !display.Quiet {
+			fmt.Println("\n------------------------------")
+		}
+	default:
+		run, ok := commands[flag.Arg(0)]
+		if !ok {
+			fatalf("Error: unknown command %q\n", flag.Arg(0))
+		}
+		err := run(ctx, client, flag.Args()[1:])
+		// long-running modes return cleanly once the signal canceled them
+		exitOnSignal()
+		if err != nil {
+			fatalf("Error: %v\n", err)
+		}
+	}
+}
+
+// fatalf clears the progress line, closes the pager, prints the message to
+// stderr, followed by a hint for errors of the API server among args, and
+// exits with status 1.
+func fatalf(format string, args ...any) {
+	if requestPlanner != nil {
+		finishExplaining(explainCtx, fmt.Sprintf(format, args...))
+		os.Exit(0)
+	}
+	progress.clear()
+	stdoutPager.stop()
+	fmt.Fprintf(os.Stderr, format, args...)
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if hint := errorHint(err); hint != "" {
+				fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+			}
+		}
+	}
+	os.Exit(1)
+}
+
+// errorHint suggests what to do about a failed request to the API server.
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, resourceClient.ErrForbidden):
+		return "your credentials lack permission for this request, see `kubectl auth can-i --list`"
+	case errors.Is(err, resourceClient.ErrAPIUnavailable):
+		return "the API server could not serve the request, retry later or check the kubeconfig context"
+	case errors.Is(err, resourceClient.ErrPartialData):
+		return "the listing expired before it completed, which happens on large clusters under churn; retry"
+	}
+	return ""
+}
+
+// parseResources splits a comma-separated list of node resource names.
+func parseResources(list string) []string {
+	var resources []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			resources = append(resources, name)
+		}
+	}
+	return resources
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/server"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// runMy shows the self-service view for users whose RBAC is limited to their
+// own namespace: their claims and anonymized availability per product.
+// Availability needs claims of all namespaces, so with -server it is read
+// from the API of a serve instance instead.
+func runMy(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("my", flag.ExitOnError)
+	namespace := fs.String("n", client.Namespace(), "namespace to show claims for")
+	serverURL := fs.String("server", "", "URL of a serve instance to read the cluster availability from")
+	key := fs.String("token", os.Getenv("DRA_RESOURCES_TOKEN"), "API key for -server")
+	fs.Parse(args)
+
+	claims, err := client.GetResourceClaims(ctx, resourceClient.ListOptions{Namespace: *namespace})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Claims in namespace %s:\n\n", *namespace)
+	if len(claims) == 0 {
+		fmt.Println("No claims found.")
+	} else {
+		display.DisplayClaims(claims)
+	}
+
+	fmt.Println("\nCluster availability:")
+	fmt.Println()
+	var products []types.ProductAvailability
+	if *serverURL != "" {
+		products, err = server.FetchProductAvailability(ctx, http.DefaultClient, *serverURL, *key)
+	} else {
+		products, err = client.GetProductAvailability(ctx)
+	}
+	if errors.Is(err, resourceClient.ErrForbidden) {
+		fmt.Println("Not visible with your permissions (needs list access to ResourceSlices and to ResourceClaims of all namespaces); use -server to read it from a serve instance.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	display.DisplayProductAvailability(products)
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+func runNode(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("node", flag.ExitOnError)
+	impact := fs.Bool("impact", false, "preview the claims, pods and capacity disrupted by draining the node")
+
+	// accept the node name before or after the flags
+	var nodeName string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		nodeName, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if nodeName == "" {
+		nodeName = fs.Arg(0)
+	}
+	if nodeName == "" {
+		return errors.New("usage: node <name> [--impact]")
+	}
+
+	if *impact {
+		// the impact preview needs the rest of the cluster to place the
+		// displaced allocations
+		nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+		if err != nil {
+			return err
+		}
+		nodeImpact, err := analyze.NodeImpact(nodeInfoList, nodeName)
+		if err != nil {
+			return err
+		}
+		display.DisplayNodeImpact(nodeImpact)
+		return nil
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{NodeName: nodeName})
+	if err != nil {
+		return err
+	}
+	nodeInfo := nodeInfoList[0]
+	opts := tableOptions
+	opts.ShowPools = true
+	display.DisplayNodes([]*types.NodeInfo{nodeInfo}, opts)
+	if features := analyze.NodeFeatures(nodeInfo.Labels); len(features) > 0 {
+		display.DisplayNodeFeatures(features)
+	}
+	return nil
+}
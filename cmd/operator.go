@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/audit"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/config"
+	"github.com/dharmjit/k8s-dra-resources/pkg/notify"
+	"github.com/dharmjit/k8s-dra-resources/pkg/operator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// runOperator watches the cluster and emits Events on nodes whose devices are
+// exhausted or whose ResourceSlices went stale, so event-based alerting picks
+// them up. With an alerts section in the configuration file, it also pages
+// on critical conditions of the cluster.
+func runOperator(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("operator", flag.ExitOnError)
+	interval := fs.Duration("interval", 30*time.Second, "minimum time between evaluations")
+	leaderElection := addLeaderElectionFlags(fs, "dra-resources-operator")
+	fs.Parse(args)
+
+	paging, err := newPaging()
+	if err != nil {
+		return err
+	}
+	auditLog, err := openAuditLog()
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+	return leaderElection.run(ctx, client, func(ctx context.Context) error {
+		return operate(ctx, client, *interval, paging, auditLog)
+	})
+}
+
+// paging pages the configured services when critical conditions of the
+// cluster appear and clear.
+type paging struct {
+	pipeline        *notify.Pipeline
+	driverDownNodes int
+}
+
+// newPaging returns the paging of the configuration file, or nil if it has
+// no alerts section.
+func newPaging() (*paging, error) {
+	if configPath == "" {
+		return nil, nil
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Alerts == nil {
+		return nil, nil
+	}
+
+	source := cfg.Alerts.Source
+	if source == "" {
+		if source, err = resourceClient.CurrentContext(kubeconfigPath); err != nil || source == "" {
+			source = "k8s-dra-resources"
+		}
+	}
+	channels := make(map[string]notify.Pager)
+	if pd := cfg.Alerts.PagerDuty; pd != nil {
+		routingKey, err := pd.RoutingKey()
+		if err != nil {
+			return nil, err
+		}
+		pager := notify.NewPagerDuty(routingKey, source)
+		if pd.URL != "" {
+			pager.URL = pd.URL
+		}
+		channels[config.ChannelPagerDuty] = pager
+	}
+	if og := cfg.Alerts.Opsgenie; og != nil {
+		apiKey, err := og.APIKey()
+		if err != nil {
+			return nil, err
+		}
+		pager := notify.NewOpsgenie(apiKey, source)
+		if og.URL != "" {
+			pager.URL = og.URL
+		}
+		channels[config.ChannelOpsgenie] = pager
+	}
+	routes := make([]notify.Route, len(cfg.Alerts.Routes))
+	for i, route := range cfg.Alerts.Routes {
+		routes[i] = notify.Route{Kinds: route.Kinds, Channels: route.Channels}
+	}
+	pipeline, err := notify.NewPipeline(channels, routes, cfg.Alerts.Cooldown.Duration, cfg.Alerts.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alerts configuration in %s: %w", configPath, err)
+	}
+
+	p := &paging{pipeline: pipeline, driverDownNodes: cfg.Alerts.DriverDownNodes}
+	if p.driverDownNodes == 0 {
+		p.driverDownNodes = notify.DefaultDriverDownNodes
+	}
+	return p, nil
+}
+
+// evaluate pages for the conditions of the nodes. Failures are logged and
+// retried on the next evaluation.
+func (p *paging) evaluate(ctx context.Context, nodeInfoList []*types.NodeInfo) {
+	for _, d := range p.pipeline.Evaluate(ctx, notify.Conditions(nodeInfoList, p.driverDownNodes)) {
+		switch {
+		case d.Err != nil:
+			log.Printf("Error: %v", d.Err)
+		case d.Resolve:
+			log.Printf("Resolved %s incident %s", d.Channel, d.Condition.Key)
+		default:
+			log.Printf("Paged %s: %s", d.Channel, d.Condition.Summary)
+		}
+	}
+}
+
+// operate evaluates the cluster whenever it changes, at most once per
+// interval, until ctx is canceled. Pages due without a change, such as
+// resolutions after the cooldown, get an evaluation of their own. paging and
+// auditLog may be nil.
+func operate(ctx context.Context, client resourceClient.ResourceClient, interval time.Duration, paging *paging, auditLog *audit.Logger) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the mirror is fed by watches, so evaluations do not list the cluster
+	// again
+	changed := make(chan struct{}, 1)
+	mirror, err := client.Mirror(ctx, resourceClient.ListOptions{}, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Operator started, evaluating at most every %s", interval)
+	var prev []*types.NodeInfo
+	for {
+		nodeInfoList, err := mirror.Nodes(ctx)
+		if err != nil {
+			return err
+		}
+		for _, event := range operator.Transitions(prev, nodeInfoList) {
+			log.Printf("Node %s: %s: %s", event.NodeName, event.Reason, event.Message)
+			entry := audit.Entry{
+				Command:   "operator",
+				Verb:      "create",
+				Resource:  "events",
+				Namespace: metav1.NamespaceDefault,
+				Details:   map[string]string{"node": event.NodeName, "reason": event.Reason},
+			}
+			if err := client.EmitNodeEvent(ctx, event.NodeName, event.Type, event.Reason, event.Message); err != nil {
+				log.Printf("Error: %v", err)
+				entry.Error = err.Error()
+			}
+			if err := auditLog.Log(ctx, entry); err != nil {
+				log.Printf("Error: %v", err)
+			}
+		}
+		prev = nodeInfoList
+		if paging != nil {
+			paging.evaluate(ctx, nodeInfoList)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+		var due <-chan time.Time
+		if paging != nil {
+			if at, ok := paging.pipeline.NextDue(); ok {
+				due = time.After(time.Until(at))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-due:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// pagedCommands are the commands whose output is piped through the pager.
+// Long-running and streaming modes are not paged.
+var pagedCommands = map[string]bool{
+	"":             true,
+	"analyze":      true,
+	"check":        true,
+	"claims":       true,
+	"class":        true,
+	"device":       true,
+	"devices":      true,
+	"heatmap":      true,
+	"my":           true,
+	"node":         true,
+	"pods":         true,
+	"report":       true,
+	"reservations": true,
+	"simulate":     true,
+	"versions":     true,
+	"workloads":    true,
+}
+
+// pager pipes stdout through $PAGER, like git does. With the default less
+// options the pager exits right away when the output fits on one screen.
+type pager struct {
+	cmd    *exec.Cmd
+	stdout *os.File
+	pipe   *os.File
+}
+
+var stdoutPager = &pager{}
+
+// start replaces os.Stdout with a pipe into the pager. It does nothing when
+// PAGER is "cat" or the pager cannot be started.
+func (p *pager) start() error {
+	command := os.Getenv("PAGER")
+	if command == "" {
+		command = "less"
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 || args[0] == "cat" {
+		return nil
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create pager pipe: %w", err)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if os.Getenv("LESS") == "" {
+		// quit if one screen, keep colors, don't clear the screen on exit
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return fmt.Errorf("failed to start pager %q: %w", command, err)
+	}
+	r.Close()
+
+	p.cmd, p.stdout, p.pipe = cmd, os.Stdout, w
+	os.Stdout = w
+	return nil
+}
+
+// stop restores stdout and waits for the user to leave the pager. It is safe
+// to call when no pager runs.
+func (p *pager) stop() {
+	if p.cmd == nil {
+		return
+	}
+	os.Stdout = p.stdout
+	p.pipe.Close()
+	_ = p.cmd.Wait()
+	p.cmd = nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// runPods lists the pods consuming ResourceClaims and why those not
+// scheduled yet are waiting.
+func runPods(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("pods", flag.ExitOnError)
+	namespace := fs.String("n", "", "only show pods in this namespace (default all namespaces)")
+	pending := fs.Bool("pending", false, "only show pods not scheduled yet")
+	gangs := fs.Bool("gangs", false, "also show the Volcano PodGroups the pods are gang-scheduled with")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	pods, err := client.GetClaimPods(ctx, resourceClient.ListOptions{Namespace: *namespace})
+	if err != nil {
+		return err
+	}
+	if *pending {
+		var unscheduled []types.PodInfo
+		for _, pod := range pods {
+			if pod.NodeName == "" {
+				unscheduled = append(unscheduled, pod)
+			}
+		}
+		pods = unscheduled
+	}
+
+	if !*gangs {
+		if *output == "json" {
+			return display.DisplayJSON(pods)
+		}
+		if len(pods) == 0 {
+			fmt.Println("No pods with ResourceClaims found.")
+			return nil
+		}
+		display.DisplayPods(pods)
+		return nil
+	}
+
+	groups, err := client.GetPodGroups(ctx, resourceClient.ListOptions{Namespace: *namespace})
+	if errors.Is(err, resourceClient.ErrVolcanoNotInstalled) {
+		groups = []types.PodGroupInfo{}
+	} else if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return display.DisplayJSON(struct {
+			Pods      []types.PodInfo      `json:"pods"`
+			PodGroups []types.PodGroupInfo `json:"podGroups"`
+		}{pods, groups})
+	}
+	if len(pods) == 0 {
+		fmt.Println("No pods with ResourceClaims found.")
+	} else {
+		display.DisplayPods(pods)
+	}
+	if len(groups) > 0 {
+		fmt.Println()
+		display.DisplayPodGroups(groups)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+)
+
+var spinner = []string{"|", "/", "-", `\`}
+
+// progressPrinter shows a single status line on a terminal while resources
+// are listed, so users of large clusters know the tool is not hung.
+type progressPrinter struct {
+	out   *os.File
+	frame int
+	shown bool
+}
+
+func (p *progressPrinter) update(progress resourceClient.Progress) {
+	if progress.Done {
+		p.clear()
+		return
+	}
+
+	var status string
+	switch {
+	case progress.Listed == 0:
+		status = "listing " + progress.Resource
+	case progress.Total > 0:
+		status = fmt.Sprintf("listed %s/%s %s", groupDigits(progress.Listed), groupDigits(progress.Total), progress.Resource)
+	default:
+		status = fmt.Sprintf("listed %s %s", groupDigits(progress.Listed), progress.Resource)
+	}
+	p.frame = (p.frame + 1) % len(spinner)
+	fmt.Fprintf(p.out, "\r\033[K%s %s...", spinner[p.frame], status)
+	p.shown = true
+}
+
+// clear erases the status line.
+func (p *progressPrinter) clear() {
+	if p.shown {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.shown = false
+	}
+}
+
+// groupDigits formats n with comma thousands separators.
+func groupDigits(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/config"
+	"github.com/dharmjit/k8s-dra-resources/pkg/recorder"
+)
+
+// compactInterval is how often watch --record compacts the history database
+// to the retention of the configuration file.
+const compactInterval = time.Hour
+
+// runRecord runs maintenance commands on the history database of watch
+// --record.
+func runRecord(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: record compact|export --db <path> [flags]")
+	}
+
+	switch args[0] {
+	case "compact":
+		return runRecordCompact(args[1:])
+	case "export":
+		return runRecordExport(args[1:])
+	default:
+		return fmt.Errorf("unknown record command %q", args[0])
+	}
+}
+
+// runRecordCompact removes the history the retention does not keep, taking
+// its defaults from the record section of the configuration file.
+func runRecordCompact(args []string) error {
+	defaults, err := recordRetention()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("record compact", flag.ExitOnError)
+	db := fs.String("db", "", "SQLite database or PostgreSQL URL recorded by watch --record")
+	keep := fs.Duration("retention", defaults.Keep, "remove history older than this, 0 to keep it forever")
+	downsampleAfter := fs.Duration("downsample-after", defaults.DownsampleAfter, "thin allocation samples older than this to one per --downsample-interval, 0 to keep every sample")
+	downsampleInterval := fs.Duration("downsample-interval", defaults.DownsampleInterval, "interval allocation samples are thinned to")
+	fs.Parse(args)
+
+	if *db == "" {
+		return errors.New("record compact needs the database recorded by watch --record, see --db")
+	}
+	if *keep < 0 || *downsampleAfter < 0 || *downsampleInterval < 0 {
+		return errors.New("--retention, --downsample-after and --downsample-interval must not be negative")
+	}
+	retention := recorder.Retention{Keep: *keep, DownsampleAfter: *downsampleAfter, DownsampleInterval: *downsampleInterval}
+	if retention.IsZero() {
+		return errors.New("record compact needs --retention or --downsample-after, or a record section in the configuration file")
+	}
+
+	rec, err := recorder.Open(*db, nil)
+	if err != nil {
+		return err
+	}
+	defer rec.Close()
+	result, err := rec.Compact(context.Background(), time.Now(), retention)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d allocation samples and %d device states.\n", result.Samples, result.DeviceStates)
+	return nil
+}
+
+// runRecordExport writes a table of the history as CSV or Parquet, for
+// analysis in notebooks without access to the database.
+func runRecordExport(args []string) error {
+	fs := flag.NewFlagSet("record export", flag.ExitOnError)
+	db := fs.String("db", "", "SQLite database or PostgreSQL URL recorded by watch --record")
+	table := fs.String("table", recorder.TableAllocation, "table to export: allocation, per node and product, or devices, the states of each device")
+	output := fs.String("o", recorder.FormatCSV, "output format: csv or parquet")
+	file := fs.String("file", "", "file to write, stdout by default")
+	since := daysDuration(7 * 24 * time.Hour)
+	fs.Var(&since, "since", "export the history of this period, e.g. 30d or 12h")
+	fs.Parse(args)
+
+	if *db == "" {
+		return errors.New("record export needs the database recorded by watch --record, see --db")
+	}
+	if *output != recorder.FormatCSV && *output != recorder.FormatParquet {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	if *output == recorder.FormatParquet && *file == "" && isTerminal(os.Stdout) {
+		return errors.New("refusing to write Parquet to a terminal, see --file")
+	}
+
+	rec, err := recorder.Open(*db, nil)
+	if err != nil {
+		return err
+	}
+	defer rec.Close()
+
+	var buf bytes.Buffer
+	if err := rec.Export(context.Background(), &buf, *table, *output, time.Now().Add(-time.Duration(since))); err != nil {
+		return err
+	}
+	if *file == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*file, buf.Bytes(), 0o644)
+}
+
+// recordRetention returns the retention of the record section of the
+// configuration file, keeping the whole history without one.
+func recordRetention() (recorder.Retention, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return recorder.Retention{}, err
+	}
+	retention := recorder.Retention{DownsampleInterval: recorder.DefaultDownsampleInterval}
+	if cfg.Record != nil {
+		retention.Keep = cfg.Record.Retention.Duration
+		retention.DownsampleAfter = cfg.Record.DownsampleAfter.Duration
+		if cfg.Record.DownsampleInterval.Duration > 0 {
+			retention.DownsampleInterval = cfg.Record.DownsampleInterval.Duration
+		}
+	}
+	return retention, nil
+}
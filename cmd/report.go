@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+func runReport(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: report zones|long-held|kueue [flags]")
+	}
+
+	switch args[0] {
+	case "zones":
+		return runReportZones(ctx, client, args[1:])
+	case "long-held":
+		return runReportLongHeld(ctx, client, args[1:])
+	case "kueue":
+		return runReportKueue(ctx, client, args[1:])
+	default:
+		return fmt.Errorf("unknown report %q", args[0])
+	}
+}
+
+// runReportZones prints the available/total devices of each product per
+// zone, to spot products concentrated in a single zone.
+func runReportZones(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("report zones", flag.ExitOnError)
+	label := fs.String("label", analyze.ZoneLabel, "node label holding the zone, e.g. topology.kubernetes.io/region for regions")
+	fs.Parse(args)
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	if tableOptions.ExcludeSpot {
+		nodeInfoList = analyze.ExcludeSpot(nodeInfoList)
+	}
+	if tableOptions.OS != "" {
+		nodeInfoList = analyze.FilterOS(nodeInfoList, tableOptions.OS)
+	}
+	if tableOptions.Arch != "" {
+		nodeInfoList = analyze.FilterArch(nodeInfoList, tableOptions.Arch)
+	}
+	matrix := analyze.AvailabilityMatrix(nodeInfoList, *label, tableOptions.ExcludeUnschedulable)
+	if len(matrix.Rows) == 0 {
+		fmt.Println("No devices found.")
+		return nil
+	}
+	display.DisplayAvailabilityMatrix(matrix)
+	return nil
+}
+
+// runReportLongHeld lists the claims holding devices for longer than a
+// duration, per namespace or team, to nudge users to release idle devices.
+func runReportLongHeld(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("report long-held", flag.ExitOnError)
+	olderThan := fs.Duration("older-than", 24*time.Hour, "report claims holding devices for longer than this")
+	teamLabel := fs.String("team-label", "", "group claims by this label of their namespace, e.g. team (default: group by namespace)")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	claims, err := client.GetResourceClaims(ctx, resourceClient.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	label, groupOf := "namespace", func(claim *types.ClaimInfo) string { return claim.Namespace }
+	if *teamLabel != "" {
+		namespaceLabels, err := client.GetNamespaceLabels(ctx)
+		if err != nil {
+			return err
+		}
+		label, groupOf = *teamLabel, func(claim *types.ClaimInfo) string { return namespaceLabels[claim.Namespace][*teamLabel] }
+	}
+
+	groups := analyze.LongHeld(claims, time.Now(), *olderThan, groupOf)
+	if *output == "json" {
+		return display.DisplayJSON(groups)
+	}
+	if len(groups) == 0 {
+		fmt.Printf("No claims have held devices for longer than %s.\n", *olderThan)
+		return nil
+	}
+	display.DisplayHeldClaims(label, groups)
+	return nil
+}
+
+// runReportKueue compares the devices requested by the workloads of each Kueue
+// ClusterQueue with its quota and the devices in the cluster, to help size
+// quotas.
+func runReportKueue(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("report kueue", flag.ExitOnError)
+	var mappings stringSliceFlag
+	fs.Var(&mappings, "quota-resource", "class=resource: quota resource name of a DeviceClass, as in Kueue's deviceClassMappings (repeatable; default: the class name)")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	quotaResources := make(map[string]string)
+	for _, mapping := range mappings {
+		class, res, ok := strings.Cut(mapping, "=")
+		if !ok || class == "" || res == "" {
+			return fmt.Errorf("invalid --quota-resource %q, expected class=resource", mapping)
+		}
+		quotaResources[class] = res
+	}
+
+	demand, err := client.GetQueueDemand(ctx, quotaResources)
+	if errors.Is(err, resourceClient.ErrKueueNotInstalled) {
+		fmt.Println("Kueue is not installed in the cluster.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		return display.DisplayJSON(demand)
+	}
+	if len(demand) == 0 {
+		fmt.Println("No ClusterQueue requests devices.")
+		return nil
+	}
+	display.DisplayQueueDemand(demand)
+	return nil
+}
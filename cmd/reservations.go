@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+)
+
+// runReservations lists the claim reservations needing attention: expired
+// ones still holding devices and those expiring soon.
+func runReservations(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("reservations", flag.ExitOnError)
+	namespace := fs.String("n", "", "only show reservations in this namespace (default all namespaces)")
+	within := fs.Duration("within", 7*24*time.Hour, "show reservations expiring within this duration")
+	all := fs.Bool("all", false, "show every reservation, including released and long-running ones")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	claims, err := client.GetResourceClaims(ctx, resourceClient.ListOptions{Namespace: *namespace})
+	if err != nil {
+		return err
+	}
+	reservations := analyze.Reservations(claims, time.Now(), *within, *all)
+	if *output == "json" {
+		return display.DisplayJSON(reservations)
+	}
+	if len(reservations) == 0 && *all {
+		fmt.Println("No reservations found.")
+		return nil
+	}
+	if len(reservations) == 0 {
+		fmt.Println("No reservations need attention.")
+		return nil
+	}
+	display.DisplayReservations(reservations)
+	return nil
+}
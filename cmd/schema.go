@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/schema"
+)
+
+// runSchema prints the published JSON Schema of a machine-readable output.
+func runSchema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	version := fs.String("output-version", schema.DefaultVersion, "output version to print the schema for")
+
+	// accept the kind before or after the flags
+	var kind string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		kind, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if kind == "" {
+		kind = fs.Arg(0)
+	}
+	if kind == "" {
+		return fmt.Errorf("usage: schema <kind> [--output-version version], known kinds: %v", schema.Kinds(*version))
+	}
+	if fs.NArg() > 1 {
+		return errors.New("schema takes a single kind")
+	}
+
+	data, err := schema.Get(*version, kind)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
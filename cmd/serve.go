@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/config"
+	"github.com/dharmjit/k8s-dra-resources/pkg/metrics"
+	"github.com/dharmjit/k8s-dra-resources/pkg/server"
+)
+
+// shutdownTimeout bounds how long serve waits for in-flight requests when
+// shutting down.
+const shutdownTimeout = 10 * time.Second
+
+// runServe exports the cluster's DRA state as Prometheus metrics and a
+// read-only JSON API. The cluster is queried on every request; combine with
+// -cache-ttl to bound the load on the API server.
+func runServe(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "address to serve metrics on")
+	tlsCertFile := fs.String("tls-cert-file", "", "serve HTTPS with this PEM certificate, reloaded when it changes")
+	tlsKeyFile := fs.String("tls-key-file", "", "PEM private key of --tls-cert-file")
+	tlsClientCAFile := fs.String("tls-client-ca-file", "", "require client certificates signed by a CA in this PEM file for the API and metrics (mTLS)")
+	churnInterval := fs.Duration("churn-report-interval", time.Hour, "log the ResourceSlice updates per driver at this interval (0 disables)")
+	leaderElection := addLeaderElectionFlags(fs, "dra-resources-serve")
+	fs.Parse(args)
+
+	if (*tlsCertFile == "") != (*tlsKeyFile == "") {
+		return errors.New("--tls-cert-file and --tls-key-file must be set together")
+	}
+	if *tlsClientCAFile != "" && *tlsCertFile == "" {
+		return errors.New("--tls-client-ca-file requires --tls-cert-file and --tls-key-file")
+	}
+	var tlsConfig *tls.Config
+	if *tlsCertFile != "" {
+		var err error
+		if tlsConfig, err = server.TLSConfig(*tlsCertFile, *tlsKeyFile, *tlsClientCAFile); err != nil {
+			return err
+		}
+	}
+	// with mTLS, everything but the health check needs a client certificate,
+	// as kubelet probes do not present one
+	protect := func(handler http.Handler) http.Handler {
+		if *tlsClientCAFile == "" {
+			return handler
+		}
+		return server.RequireClientCert(handler)
+	}
+
+	auth, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+
+	// with leader election, only the leader publishes metrics so replicas do
+	// not report the same devices twice; the others serve an empty page
+	var leading atomic.Bool
+	leading.Store(!leaderElection.enabled)
+	if leaderElection.enabled {
+		go func() {
+			for ctx.Err() == nil {
+				err := leaderElection.run(ctx, client, func(ctx context.Context) error {
+					log.Print("Became leader, publishing metrics")
+					leading.Store(true)
+					<-ctx.Done()
+					leading.Store(false)
+					return nil
+				})
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Stopped publishing metrics: %v", err)
+				}
+			}
+		}()
+	}
+
+	churn := metrics.NewSliceChurn()
+	go func() {
+		if err := client.WatchSliceUpdates(ctx, churn.Record); err != nil {
+			log.Printf("Stopped tracking ResourceSlice updates: %v", err)
+		}
+	}()
+	if *churnInterval > 0 {
+		go reportSliceChurn(ctx, churn, *churnInterval, &leading)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", protect(server.NewAPI(client, auth)))
+	mux.Handle("/metrics", protect(server.RequireAllNamespaces(auth, func(w http.ResponseWriter, r *http.Request) {
+		if !leading.Load() {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			return
+		}
+		nodeInfoList, err := client.GetK8sResources(r.Context(), resourceClient.ListOptions{})
+		if err != nil {
+			log.Printf("Error collecting metrics: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		claims, err := client.GetResourceClaims(r.Context(), resourceClient.ListOptions{})
+		if err != nil {
+			log.Printf("Error collecting metrics: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := metrics.Write(w, nodeInfoList, claims); err != nil {
+			log.Printf("Error writing metrics: %v", err)
+		}
+		if err := churn.Write(w); err != nil {
+			log.Printf("Error writing metrics: %v", err)
+		}
+	})))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	httpServer := &http.Server{Addr: *listen, Handler: mux, TLSConfig: tlsConfig}
+	shutdown := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		// let in-flight scrapes finish
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdown <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	scheme := "http"
+	listenAndServe := httpServer.ListenAndServe
+	if tlsConfig != nil {
+		scheme = "https"
+		// the certificate comes from TLSConfig.GetCertificate
+		listenAndServe = func() error { return httpServer.ListenAndServeTLS("", "") }
+	}
+	fmt.Printf("Serving metrics on %s://%s/metrics and the API on %s://%s/api/v1\n", scheme, *listen, scheme, *listen)
+	if err := listenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	if err := <-shutdown; err != nil {
+		return fmt.Errorf("failed to shut down the server: %w", err)
+	}
+	return nil
+}
+
+// reportSliceChurn logs the ResourceSlice updates of every driver that
+// updated any every interval, while leading, so constant republishing shows
+// up in the logs as well as in the metrics.
+func reportSliceChurn(ctx context.Context, churn *metrics.SliceChurn, interval time.Duration, leading *atomic.Bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		recent := churn.Recent()
+		if !leading.Load() {
+			continue
+		}
+		drivers := make([]string, 0, len(recent))
+		for driver := range recent {
+			drivers = append(drivers, driver)
+		}
+		sort.Strings(drivers)
+		for _, driver := range drivers {
+			log.Printf("ResourceSlice churn: %s updated slices %d times in the last %s (%.1f per minute)",
+				driver, recent[driver], interval, float64(recent[driver])/interval.Minutes())
+		}
+	}
+}
+
+// newAuthenticator returns the authenticator of the tokens in the
+// configuration file, or nil if there are none and the API is open.
+func newAuthenticator() (*server.Authenticator, error) {
+	if configPath == "" {
+		return nil, nil
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Serve == nil || len(cfg.Serve.Tokens) == 0 {
+		return nil, nil
+	}
+	tokens := make([]server.Token, len(cfg.Serve.Tokens))
+	for i, token := range cfg.Serve.Tokens {
+		tokens[i] = server.Token{Name: token.Name, SHA256: token.SHA256, Namespaces: token.Namespaces}
+	}
+	auth, err := server.NewAuthenticator(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("invalid serve configuration in %s: %w", configPath, err)
+	}
+	return auth, nil
+}
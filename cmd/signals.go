@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// shutdownSignal records the first SIGINT or SIGTERM received.
+var shutdownSignal atomic.Value
+
+// signalContext returns a context canceled on the first SIGINT or SIGTERM, so
+// long-running modes can stop watching, flush their output and release their
+// resources. A second signal exits immediately.
+func signalContext(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		shutdownSignal.Store(sig)
+		fmt.Fprintf(os.Stderr, "Received %s, shutting down (repeat to force)\n", sig)
+		cancel()
+		sig = <-signals
+		os.Exit(exitCode(sig))
+	}()
+	return ctx
+}
+
+// exitOnSignal exits with the conventional 128+signal status if a shutdown
+// signal was received, so scripts can tell an interruption from a failure.
+// Errors caused by the canceled context are not reported.
+func exitOnSignal() {
+	if sig, ok := shutdownSignal.Load().(os.Signal); ok {
+		stdoutPager.stop()
+		os.Exit(exitCode(sig))
+	}
+}
+
+func exitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
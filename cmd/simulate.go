@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+func runSimulate(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: simulate class-change [flags]")
+	}
+
+	switch args[0] {
+	case "class-change":
+		return runSimulateClassChange(ctx, client, args[1:])
+	default:
+		return fmt.Errorf("unknown simulate command %q", args[0])
+	}
+}
+
+// runSimulateClassChange previews the effect of replacing a DeviceClass with
+// the definition in a manifest, without changing the cluster.
+func runSimulateClassChange(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("simulate class-change", flag.ExitOnError)
+	className := fs.String("class", "", "name of the DeviceClass to change")
+	file := fs.String("file", "", "YAML or JSON manifest of the new DeviceClass")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *className == "" || *file == "" {
+		return errors.New("usage: simulate class-change --class <name> --file <manifest>")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to read DeviceClass manifest: %w", err)
+	}
+	var class resourcev1beta1.DeviceClass
+	if err := yaml.UnmarshalStrict(data, &class); err != nil {
+		return fmt.Errorf("failed to parse DeviceClass manifest %s: %w", *file, err)
+	}
+	if class.Kind != "" && class.Kind != "DeviceClass" {
+		return fmt.Errorf("%s holds a %s, expected a DeviceClass", *file, class.Kind)
+	}
+	if class.Name == "" {
+		class.Name = *className
+	}
+	if class.Name != *className {
+		return fmt.Errorf("%s defines DeviceClass %s, not %s", *file, class.Name, *className)
+	}
+
+	proposed := model.FromV1beta1DeviceClass(&class)
+	change, err := client.SimulateClassChange(ctx, &proposed)
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return display.DisplayJSON(change)
+	}
+	display.DisplayClassChange(change)
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/metrics"
+)
+
+// runTop shows the busiest nodes by device allocation and, with a Prometheus
+// URL, by device utilization, refreshing until interrupted.
+func runTop(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	interval := fs.Duration("interval", 5*time.Second, "time between refreshes")
+	limit := fs.Int("limit", 20, "number of nodes to show (0 shows all)")
+	sortBy := fs.String("sort", analyze.SortByAllocation, "order nodes by allocation or utilization")
+	once := fs.Bool("once", false, "print the nodes once and exit")
+	var util utilizationFlags
+	util.register(fs)
+	fs.Parse(args)
+
+	if *sortBy != analyze.SortByAllocation && *sortBy != analyze.SortByUtilization {
+		return fmt.Errorf("unknown sort order %q, expected allocation or utilization", *sortBy)
+	}
+	if *sortBy == analyze.SortByUtilization && util.prometheusURL == "" {
+		return fmt.Errorf("sorting by utilization requires --prometheus-url")
+	}
+
+	clear := !*once && isTerminal(os.Stdout)
+	for {
+		nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{})
+		if err != nil {
+			return err
+		}
+		utilization, metricsErr := util.fetch(ctx)
+
+		if clear {
+			fmt.Print("\033[H\033[2J")
+		}
+		if !display.Quiet && !*once {
+			fmt.Printf("Every %s: %s\n\n", *interval, time.Now().Format(time.RFC1123))
+		}
+		if metricsErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n\n", metricsErr)
+		}
+		usage := analyze.TopNodes(nodeInfoList, utilization, *sortBy, *limit)
+		if len(usage) == 0 {
+			fmt.Println("No nodes with devices found.")
+		} else {
+			display.DisplayTopNodes(usage, util.prometheusURL != "")
+		}
+
+		if *once {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// utilizationFlags are the flags reading device utilization from Prometheus.
+type utilizationFlags struct {
+	prometheusURL string
+	query         string
+	nodeLabel     string
+}
+
+func (u *utilizationFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&u.prometheusURL, "prometheus-url", "", "Prometheus server to read GPU utilization from, e.g. http://prometheus:9090")
+	fs.StringVar(&u.query, "utilization-query", metrics.DefaultUtilizationQuery, "PromQL query returning the device utilization in percent per node")
+	fs.StringVar(&u.nodeLabel, "utilization-label", metrics.DefaultUtilizationLabel, "label of the query result naming the node")
+}
+
+// fetch returns the utilization per node, or nil without a Prometheus URL.
+func (u *utilizationFlags) fetch(ctx context.Context) (map[string]float64, error) {
+	if u.prometheusURL == "" {
+		return nil, nil
+	}
+	return metrics.QueryNodeUtilization(ctx, &http.Client{Timeout: 10 * time.Second}, u.prometheusURL, u.query, u.nodeLabel)
+}
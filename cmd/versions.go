@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+)
+
+// runVersions reports the distribution of driver, or firmware, version
+// attributes across the fleet.
+func runVersions(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("versions", flag.ExitOnError)
+	attributes := fs.String("attributes", strings.Join(resourceClient.DefaultVersionAttributes, ","), "comma-separated device attributes to inventory")
+	firmware := fs.Bool("firmware", false, "inventory firmware and VBIOS versions instead of driver versions")
+	fs.Parse(args)
+
+	if *firmware {
+		*attributes = strings.Join(resourceClient.FirmwareAttributes, ",")
+	}
+
+	// firmware versions are only comparable between devices of a product
+	inventory, err := client.GetAttributeInventory(ctx, strings.Split(*attributes, ","), *firmware)
+	if err != nil {
+		return err
+	}
+	if len(inventory) == 0 {
+		fmt.Printf("No devices publish %s.\n", *attributes)
+		return nil
+	}
+	display.DisplayAttributeInventory(inventory)
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/recorder"
+)
+
+// trendBuckets is the width of the allocation trend sparklines.
+const trendBuckets = 30
+
+// Snapshots watch --record failed to store are kept, up to
+// recordBacklogCapacity, and retried every recordRetryInterval while the
+// cluster does not change.
+const (
+	recordBacklogCapacity = 300
+	recordRetryInterval   = 30 * time.Second
+)
+
+// shutdownFlushTimeout bounds how long watch --record tries to store the
+// pending snapshots when it is stopped.
+const shutdownFlushTimeout = 5 * time.Second
+
+// runWatch re-renders the node table whenever the cluster changes, at most
+// once per interval.
+func runWatch(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	interval := fs.Duration("interval", 2*time.Second, "minimum time between redraws")
+	record := fs.String("record", "", "record the allocation and device holders in this SQLite database, PostgreSQL URL or Prometheus remote-write URL, and show the allocation trend per node and product")
+	history := fs.Duration("history", time.Hour, "period of the allocation trends shown with --record")
+	var recordLabels stringSliceFlag
+	fs.Var(&recordLabels, "record-label", "name=value label added to the series pushed to a remote-write --record URL, e.g. cluster=prod (repeatable)")
+	fs.Parse(args)
+
+	var rec *recorder.Recorder
+	var backlog *recorder.Backlog
+	var retention recorder.Retention
+	if *record != "" {
+		labels := make(map[string]string)
+		for _, l := range recordLabels {
+			name, value, ok := strings.Cut(l, "=")
+			if !ok || name == "" {
+				return fmt.Errorf("invalid --record-label %q, expected name=value", l)
+			}
+			labels[name] = value
+		}
+		var err error
+		if retention, err = recordRetention(); err != nil {
+			return err
+		}
+		if rec, err = recorder.Open(*record, labels); err != nil {
+			return err
+		}
+		defer rec.Close()
+		backlog = recorder.NewBacklog(rec, recordBacklogCapacity)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the mirror is fed by watches, so redraws do not list the cluster again
+	changed := make(chan struct{}, 1)
+	mirror, err := client.Mirror(ctx, resourceClient.ListOptions{NodeSelector: tableOptions.NodeSelector}, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	clear := isTerminal(os.Stdout)
+	var lastCompact time.Time
+	for {
+		nodeInfoList, err := mirror.Nodes(ctx)
+		if err != nil {
+			return err
+		}
+		if clear {
+			fmt.Print("\033[H\033[2J")
+		}
+		if !display.Quiet {
+			fmt.Printf("Every %s: %s\n\n", *interval, time.Now().Format(time.RFC1123))
+		}
+		display.DisplayNodes(nodeInfoList, tableOptions)
+		if backlog != nil {
+			now := time.Now()
+			devices, err := mirror.Devices(ctx)
+			if err != nil {
+				return err
+			}
+			// the store is retried rather than ending a long-running recorder
+			if err := backlog.Record(ctx, now, nodeInfoList, devices); err != nil {
+				warnRecording(err, backlog, true)
+			}
+			// remote-write endpoints keep their own retention
+			if !retention.IsZero() && now.Sub(lastCompact) >= compactInterval {
+				if _, err := rec.Compact(ctx, now, retention); err != nil && !errors.Is(err, recorder.ErrWriteOnly) {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+				lastCompact = now
+			}
+			// remote-write endpoints cannot be read back
+			trends, err := rec.Trends(ctx, now.Add(-*history), now, trendBuckets)
+			if err != nil && !errors.Is(err, recorder.ErrWriteOnly) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			if err == nil {
+				fmt.Println()
+				display.DisplayAllocationTrends(trends, *history)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			flushBacklog(ctx, backlog)
+			return nil
+		case <-time.After(*interval):
+		}
+		for redraw := false; !redraw; {
+			// pending snapshots are retried while the cluster is quiet
+			var retry <-chan time.Time
+			if backlog != nil && backlog.Pending() > 0 {
+				retry = time.After(recordRetryInterval)
+			}
+			select {
+			case <-ctx.Done():
+				flushBacklog(ctx, backlog)
+				return nil
+			case <-changed:
+				redraw = true
+			case <-retry:
+				if err := backlog.Flush(ctx); err != nil {
+					warnRecording(err, backlog, true)
+				}
+			}
+		}
+	}
+}
+
+// flushBacklog stores the snapshots still pending when watch stops. ctx is
+// already canceled, so the flush only keeps its values and gets
+// shutdownFlushTimeout of its own.
+func flushBacklog(ctx context.Context, backlog *recorder.Backlog) {
+	if backlog == nil || backlog.Pending() == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownFlushTimeout)
+	defer cancel()
+	if err := backlog.Flush(ctx); err != nil {
+		warnRecording(err, backlog, false)
+	}
+}
+
+// warnRecording reports a failure to store the recorded snapshots, which are
+// retried later unless watch is stopping.
+func warnRecording(err error, backlog *recorder.Backlog, retrying bool) {
+	if retrying {
+		fmt.Fprintf(os.Stderr, "Warning: %v; %d snapshot(s) pending, retrying", err, backlog.Pending())
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: %v; %d snapshot(s) not recorded", err, backlog.Pending())
+	}
+	if backlog.Dropped > 0 {
+		fmt.Fprintf(os.Stderr, ", %d dropped", backlog.Dropped)
+	}
+	fmt.Fprintln(os.Stderr)
+}
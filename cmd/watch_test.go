@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/client/fake"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+	"github.com/dharmjit/k8s-dra-resources/pkg/dratest/fixtures"
+)
+
+func TestWatchFlushesBacklogOnShutdown(t *testing.T) {
+	// the remote-write endpoint rejects pushes until it is up
+	var up atomic.Bool
+	var accepted atomic.Int32
+	rejected := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			select {
+			case rejected <- struct{}{}:
+			default:
+			}
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		accepted.Add(1)
+	}))
+	defer server.Close()
+
+	stdout, quiet := os.Stdout, display.Quiet
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	os.Stdout, display.Quiet = devNull, true
+	t.Cleanup(func() {
+		os.Stdout, display.Quiet = stdout, quiet
+		devNull.Close()
+	})
+
+	c := fake.NewCluster().
+		Node("node-1").
+		ResourceSlice("node-1", "gpu.example.com", fixtures.NewDevice("gpu-0", "H100")).
+		Client()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- runWatch(ctx, c, []string{"--record", server.URL, "--interval", "1h"})
+	}()
+
+	// the first snapshot stays pending, and watch is stopped long before
+	// the next retry
+	select {
+	case <-rejected:
+	case <-time.After(10 * time.Second):
+		t.Fatal("watch did not record a snapshot")
+	}
+	up.Store(true)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runWatch() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("runWatch() did not return after cancellation")
+	}
+	if accepted.Load() == 0 {
+		t.Error("the pending snapshot was not stored on shutdown")
+	}
+}
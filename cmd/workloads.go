@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/display"
+)
+
+// runWorkloads lists the devices held per workload, e.g. per training job,
+// rather than per pod.
+func runWorkloads(ctx context.Context, client resourceClient.ResourceClient, args []string) error {
+	fs := flag.NewFlagSet("workloads", flag.ExitOnError)
+	namespace := fs.String("n", "", "only show workloads in this namespace (default all namespaces)")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	workloads, err := client.GetWorkloads(ctx, resourceClient.ListOptions{Namespace: *namespace})
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return display.DisplayJSON(workloads)
+	}
+	if len(workloads) == 0 {
+		fmt.Println("No workloads with ResourceClaims found.")
+		return nil
+	}
+	display.DisplayWorkloads(workloads)
+	return nil
+}
@@ -0,0 +1,408 @@
+// Package aggregate joins nodes, ResourceSlices, ResourceClaims and pods
+// into the per-node and per-device summaries the tool shows. It makes no
+// requests: callers list the objects, e.g. through the client package, and
+// pass them in, so the join can be tested on fixed inputs.
+package aggregate
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PoolKey identifies a resource pool. Pool names are only unique per driver.
+type PoolKey struct {
+	Driver string
+	Pool   string
+}
+
+// Device returns the key of the named device of the pool.
+func (k PoolKey) Device(name string) DeviceKey {
+	return DeviceKey{Driver: k.Driver, Pool: k.Pool, Device: name}
+}
+
+// DeviceKey identifies a single device the same way allocation results do.
+// Using a struct rather than a joined string avoids collisions between names
+// containing the separator, e.g. pool "a-b" of driver "x" and pool "b" of
+// driver "x-a".
+type DeviceKey struct {
+	Driver string
+	Pool   string
+	Device string
+}
+
+// productKey groups the devices of a node. Product names are only
+// meaningful per driver.
+type productKey struct {
+	driver      string
+	productName string
+}
+
+// Allocation records which claim and request a device is allocated to.
+type Allocation struct {
+	ClaimNamespace string
+	ClaimName      string
+	Request        string
+}
+
+// Claim returns the claim as namespace/name.
+func (a Allocation) Claim() string {
+	return a.ClaimNamespace + "/" + a.ClaimName
+}
+
+// PodUsage sums the resource requests and collects the device-consuming
+// pods per node, one pod at a time.
+type PodUsage struct {
+	requestedResources map[string]corev1.ResourceList
+	deviceConsumers    map[string][]string
+}
+
+// NewPodUsage returns an empty PodUsage.
+func NewPodUsage() *PodUsage {
+	return &PodUsage{
+		requestedResources: make(map[string]corev1.ResourceList),
+		deviceConsumers:    make(map[string][]string),
+	}
+}
+
+// Add counts a pod. Pods not bound to a node are ignored.
+func (a *PodUsage) Add(pod *corev1.Pod) {
+	if pod.Spec.NodeName == "" {
+		return
+	}
+	if len(pod.Spec.ResourceClaims) > 0 && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		a.deviceConsumers[pod.Spec.NodeName] = append(a.deviceConsumers[pod.Spec.NodeName], pod.Namespace+"/"+pod.Name)
+	}
+	if _, ok := a.requestedResources[pod.Spec.NodeName]; !ok {
+		a.requestedResources[pod.Spec.NodeName] = make(corev1.ResourceList)
+	}
+	for _, container := range pod.Spec.Containers {
+		for resName, resQuant := range container.Resources.Requests {
+			if _, ok := a.requestedResources[pod.Spec.NodeName][resName]; !ok {
+				a.requestedResources[pod.Spec.NodeName][resName] = resQuant.DeepCopy()
+			} else {
+				existingQuant := a.requestedResources[pod.Spec.NodeName][resName]
+				existingQuant.Add(resQuant)
+				a.requestedResources[pod.Spec.NodeName][resName] = existingQuant
+			}
+		}
+	}
+}
+
+// Nodes aggregates the objects into per-node summaries, sorted by node name.
+// Slices and pods of nodes not in nodes are ignored, and so are slices
+// published for a node selector or all nodes rather than a single node.
+func Nodes(ctx context.Context, nodes []corev1.Node, resourceSlices []model.ResourceSlice, resourceClaims []model.ResourceClaim, pods *PodUsage) ([]*types.NodeInfo, error) {
+	requestedResources := pods.requestedResources
+	deviceConsumers := pods.deviceConsumers
+
+	allocatedDevices := AllocatedDevices(resourceClaims)
+
+	// Map to hold all info per node
+	nodeMap := make(map[string]*types.NodeInfo)
+	for _, node := range nodes {
+		role := "<none>"
+		for k := range node.Labels {
+			if strings.HasPrefix(k, "node-role.kubernetes.io/") {
+				role = strings.TrimPrefix(k, "node-role.kubernetes.io/")
+				break
+			}
+		}
+
+		// Calculate available resources
+		availableCPU := node.Status.Allocatable[corev1.ResourceCPU].DeepCopy()
+		availableMemory := node.Status.Allocatable[corev1.ResourceMemory].DeepCopy()
+		storage := storageResource(&node)
+		availableStorage := node.Status.Allocatable[storage].DeepCopy()
+
+		if reqs, ok := requestedResources[node.Name]; ok {
+			if cpuReq, ok := reqs[corev1.ResourceCPU]; ok {
+				availableCPU.Sub(cpuReq)
+			}
+			if memReq, ok := reqs[corev1.ResourceMemory]; ok {
+				availableMemory.Sub(memReq)
+			}
+			if storageReq, ok := reqs[storage]; ok {
+				availableStorage.Sub(storageReq)
+			}
+		}
+
+		nodeMap[node.Name] = &types.NodeInfo{
+			NodeName:      node.Name,
+			NodeRole:      role,
+			NodePool:      NodePool(node.Labels),
+			InstanceType:  InstanceType(node.Labels),
+			Spot:          IsSpot(node.Labels),
+			OS:            NodeOS(&node),
+			Arch:          NodeArch(&node),
+			Labels:        node.Labels,
+			Unschedulable: isUnschedulable(&node),
+			NotReady:      isNotReady(&node),
+			NodeCapacity: types.NodeCapacity{
+				TotalCPU:         node.Status.Capacity[corev1.ResourceCPU],
+				AvailableCPU:     availableCPU,
+				TotalMemory:      node.Status.Capacity[corev1.ResourceMemory],
+				AvailableMemory:  availableMemory,
+				TotalStorage:     node.Status.Capacity[storage],
+				AvailableStorage: availableStorage,
+				Resources:        otherResources(&node, requestedResources[node.Name]),
+			},
+			Devices:         []types.Device{},
+			DeviceConsumers: deviceConsumers[node.Name],
+		}
+	}
+
+	var nodeSlices []model.ResourceSlice
+	for _, rs := range resourceSlices {
+		if _, ok := nodeMap[rs.Spec.NodeName]; ok {
+			nodeSlices = append(nodeSlices, rs)
+		}
+	}
+	poolGenerations := LatestPoolGenerations(nodeSlices)
+	sliceDecorations, err := DecorateSlices(ctx, nodeSlices)
+	if err != nil {
+		return nil, err
+	}
+
+	// Populate devices for each node. Large pools are split across several
+	// slices, so devices and pools are merged per node before being attached.
+	deviceMaps := make(map[string]map[productKey]types.Device) // node -> driver and productName -> device
+	poolMaps := make(map[string]map[PoolKey]*types.Pool)       // node -> pool
+	nodeClaims := make(map[string]map[string]bool)             // node -> namespace/name of allocated claims
+	seenDevices := make(map[DeviceKey]bool)
+	for si, rs := range nodeSlices {
+		pool := PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		if rs.Spec.Pool.Generation < poolGenerations[pool] {
+			continue
+		}
+
+		if _, ok := deviceMaps[rs.Spec.NodeName]; !ok {
+			deviceMaps[rs.Spec.NodeName] = make(map[productKey]types.Device)
+			poolMaps[rs.Spec.NodeName] = make(map[PoolKey]*types.Pool)
+			nodeClaims[rs.Spec.NodeName] = make(map[string]bool)
+		}
+		deviceMap := deviceMaps[rs.Spec.NodeName]
+
+		poolInfo, ok := poolMaps[rs.Spec.NodeName][pool]
+		if !ok {
+			poolInfo = &types.Pool{
+				Driver:             rs.Spec.Driver,
+				Name:               rs.Spec.Pool.Name,
+				Generation:         rs.Spec.Pool.Generation,
+				ResourceSliceCount: rs.Spec.Pool.ResourceSliceCount,
+			}
+			poolMaps[rs.Spec.NodeName][pool] = poolInfo
+		}
+		poolInfo.ObservedSliceCount++
+		if created := rs.CreationTimestamp.Time; poolInfo.Created.IsZero() || created.Before(poolInfo.Created) {
+			poolInfo.Created = created
+		}
+
+		decorations := sliceDecorations[si]
+		for i, dev := range rs.Spec.Devices {
+			// a device listed by more than one slice of the pool is only counted once
+			if seenDevices[pool.Device(dev.Name)] {
+				continue
+			}
+			seenDevices[pool.Device(dev.Name)] = true
+
+			productName := decorations[i].ProductName
+			product := productKey{driver: rs.Spec.Driver, productName: productName}
+			capacity := decorations[i].Capacity
+			memory := capacity["memory"]
+			unhealthy := decorations[i].Health == decorator.Unhealthy
+
+			// if product is not in deviceMap, initialize it otherwise increment the TotalCount and AvailableCount by 1
+			if _, ok := deviceMap[product]; !ok {
+				deviceMap[product] = types.Device{
+					Driver:         rs.Spec.Driver,
+					ProductName:    productName,
+					TotalCount:     1,
+					AvailableCount: 1,
+					Memory:         memory,
+					Capacity:       capacity,
+				}
+			} else {
+				dev := deviceMap[product]
+				dev.TotalCount++
+				dev.AvailableCount++
+				deviceMap[product] = dev
+			}
+			if unhealthy {
+				dev := deviceMap[product]
+				dev.UnhealthyCount++
+				deviceMap[product] = dev
+			}
+			poolInfo.TotalCount++
+			poolInfo.AvailableCount++
+
+			// if the device is allocated, reduce the available count by 1
+			if allocations, ok := allocatedDevices[pool.Device(dev.Name)]; ok {
+				for _, alloc := range allocations {
+					nodeClaims[rs.Spec.NodeName][alloc.Claim()] = true
+				}
+				dev := deviceMap[product]
+				if dev.AvailableCount > 0 {
+					dev.AvailableCount--
+				}
+				deviceMap[product] = dev
+				poolInfo.AvailableCount--
+			}
+		}
+	}
+
+	// Attach the merged devices and pools in a stable order
+	for nodeName, deviceMap := range deviceMaps {
+		nodeInfo := nodeMap[nodeName]
+		for _, dev := range deviceMap {
+			nodeInfo.Devices = append(nodeInfo.Devices, dev)
+		}
+		sort.Slice(nodeInfo.Devices, func(i, j int) bool {
+			a, b := nodeInfo.Devices[i], nodeInfo.Devices[j]
+			if a.ProductName != b.ProductName {
+				return a.ProductName < b.ProductName
+			}
+			return a.Driver < b.Driver
+		})
+		for _, poolInfo := range poolMaps[nodeName] {
+			nodeInfo.Pools = append(nodeInfo.Pools, *poolInfo)
+		}
+		sort.Slice(nodeInfo.Pools, func(i, j int) bool {
+			if nodeInfo.Pools[i].Driver != nodeInfo.Pools[j].Driver {
+				return nodeInfo.Pools[i].Driver < nodeInfo.Pools[j].Driver
+			}
+			return nodeInfo.Pools[i].Name < nodeInfo.Pools[j].Name
+		})
+		for claim := range nodeClaims[nodeName] {
+			nodeInfo.AllocatedClaims = append(nodeInfo.AllocatedClaims, claim)
+		}
+		sort.Strings(nodeInfo.AllocatedClaims)
+	}
+
+	var nodeInfoList []*types.NodeInfo
+	for _, nodeInfo := range nodeMap {
+		nodeInfoList = append(nodeInfoList, nodeInfo)
+	}
+	sort.Slice(nodeInfoList, func(i, j int) bool {
+		return nodeInfoList[i].NodeName < nodeInfoList[j].NodeName
+	})
+
+	return nodeInfoList, nil
+}
+
+// storageResource returns the resource the node reports its storage as.
+// Nodes, notably Windows nodes, that do not report storage are accounted by
+// their ephemeral storage.
+func storageResource(node *corev1.Node) corev1.ResourceName {
+	if _, ok := node.Status.Capacity[corev1.ResourceStorage]; ok {
+		return corev1.ResourceStorage
+	}
+	return corev1.ResourceEphemeralStorage
+}
+
+// otherResources returns the resources of the node other than CPU, memory
+// and storage, with the amount not requested by its pods.
+func otherResources(node *corev1.Node, requested corev1.ResourceList) map[string]types.ResourceCapacity {
+	resources := make(map[string]types.ResourceCapacity)
+	for name, total := range node.Status.Capacity {
+		if name == corev1.ResourceCPU || name == corev1.ResourceMemory || name == corev1.ResourceStorage {
+			continue
+		}
+		available := node.Status.Allocatable[name].DeepCopy()
+		if req, ok := requested[name]; ok {
+			available.Sub(req)
+		}
+		resources[string(name)] = types.ResourceCapacity{Total: total, Available: available}
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+	return resources
+}
+
+// isUnschedulable reports whether the node is cordoned, either through the
+// spec field or the taint the node controller mirrors it to.
+func isUnschedulable(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == corev1.TaintNodeUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotReady reports whether the node is known to be not ready. A node
+// without a Ready condition is not treated as NotReady.
+func isNotReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status != corev1.ConditionTrue {
+			return true
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == corev1.TaintNodeNotReady || taint.Key == corev1.TaintNodeUnreachable {
+			return true
+		}
+	}
+	return false
+}
+
+// AllocatedDevices indexes the devices allocated by the given claims, with
+// every claim and request a device is allocated for, sorted by claim and
+// request, so devices shared by several claims list all of them.
+// Allocations with admin access are left out: they grant monitoring or
+// maintenance access without taking the device away from other claims.
+func AllocatedDevices(resourceClaims []model.ResourceClaim) map[DeviceKey][]Allocation {
+	allocatedDevices := make(map[DeviceKey][]Allocation)
+	for _, rc := range resourceClaims {
+		if rc.Status.Allocation == nil {
+			continue
+		}
+		for _, ads := range rc.Status.Allocation.Devices.Results {
+			if ads.AdminAccess != nil && *ads.AdminAccess {
+				continue
+			}
+			key := DeviceKey{Driver: ads.Driver, Pool: ads.Pool, Device: ads.Device}
+			allocatedDevices[key] = append(allocatedDevices[key], Allocation{
+				ClaimNamespace: rc.Namespace,
+				ClaimName:      rc.Name,
+				Request:        ads.Request,
+			})
+		}
+	}
+	for _, allocations := range allocatedDevices {
+		sort.Slice(allocations, func(i, j int) bool {
+			a, b := allocations[i], allocations[j]
+			if a.ClaimNamespace != b.ClaimNamespace {
+				return a.ClaimNamespace < b.ClaimNamespace
+			}
+			if a.ClaimName != b.ClaimName {
+				return a.ClaimName < b.ClaimName
+			}
+			return a.Request < b.Request
+		})
+	}
+	return allocatedDevices
+}
+
+// LatestPoolGenerations returns the highest generation of every pool. While a
+// driver republishes a pool, slices of the old and new generation can coexist
+// briefly; only the highest generation of each pool is counted.
+func LatestPoolGenerations(resourceSlices []model.ResourceSlice) map[PoolKey]int64 {
+	poolGenerations := make(map[PoolKey]int64)
+	for _, rs := range resourceSlices {
+		pool := PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		if gen, ok := poolGenerations[pool]; !ok || rs.Spec.Pool.Generation > gen {
+			poolGenerations[pool] = rs.Spec.Pool.Generation
+		}
+	}
+	return poolGenerations
+}
@@ -0,0 +1,287 @@
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func node(name string) corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:     resource.MustParse("8"),
+		corev1.ResourceMemory:  resource.MustParse("32Gi"),
+		corev1.ResourceStorage: resource.MustParse("100G"),
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NodeStatus{Capacity: resources, Allocatable: resources.DeepCopy()},
+	}
+}
+
+func gpu(name, productName string) model.Device {
+	return model.Device{
+		Name:       name,
+		Attributes: map[model.QualifiedName]model.DeviceAttribute{"productName": {StringValue: &productName}},
+		Capacity:   map[model.QualifiedName]model.DeviceCapacity{"memory": {Value: resource.MustParse("80Gi")}},
+	}
+}
+
+// slice returns a slice of the node's pool, one of count slices of the
+// generation.
+func slice(name, nodeName string, generation, count int64, devices ...model.Device) model.ResourceSlice {
+	return model.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: model.ResourceSliceSpec{
+			Driver:   "gpu.nvidia.com",
+			Pool:     model.ResourcePool{Name: nodeName, Generation: generation, ResourceSliceCount: count},
+			NodeName: nodeName,
+			Devices:  devices,
+		},
+	}
+}
+
+func claim(name string, results ...model.DeviceRequestAllocationResult) model.ResourceClaim {
+	return model.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Status: model.ResourceClaimStatus{
+			Allocation: &model.AllocationResult{Devices: model.DeviceAllocationResult{Results: results}},
+		},
+	}
+}
+
+func allocated(pool, device string) model.DeviceRequestAllocationResult {
+	return model.DeviceRequestAllocationResult{Request: "gpu", Driver: "gpu.nvidia.com", Pool: pool, Device: device}
+}
+
+func adminAllocated(pool, device string) model.DeviceRequestAllocationResult {
+	result := allocated(pool, device)
+	adminAccess := true
+	result.AdminAccess = &adminAccess
+	return result
+}
+
+func pod(name, nodeName, cpu string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name:      "main",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}},
+			ResourceClaims: []corev1.PodResourceClaim{{Name: "gpu"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// TestNodes compares the summaries of each case with its golden file in
+// testdata. Run go test ./pkg/aggregate -update after intended changes and
+// review the diff of the golden files.
+func TestNodes(t *testing.T) {
+	partition := func(name, counterSet string) model.Device {
+		dev := gpu(name, "H100 MIG 3g.40gb")
+		dev.ConsumesCounters = []model.DeviceCounterConsumption{{
+			CounterSet: counterSet,
+			Counters:   map[string]model.Counter{"memory": {Value: resource.MustParse("40Gi")}},
+		}}
+		return dev
+	}
+	sharedSlice := slice("node-1-gpu", "node-1", 1, 1, partition("gpu-0-mig-0", "gpu-0"), partition("gpu-0-mig-1", "gpu-0"), partition("gpu-1-mig-0", "gpu-1"))
+	sharedSlice.Spec.SharedCounters = []model.CounterSet{
+		{Name: "gpu-0", Counters: map[string]model.Counter{"memory": {Value: resource.MustParse("80Gi")}}},
+		{Name: "gpu-1", Counters: map[string]model.Counter{"memory": {Value: resource.MustParse("80Gi")}}},
+	}
+	selectorSlice := slice("rack-1-gpu", "", 1, 1, gpu("gpu-0", "H100"))
+	selectorSlice.Spec.Pool.Name = "rack-1"
+
+	tests := []struct {
+		name   string
+		nodes  []corev1.Node
+		slices []model.ResourceSlice
+		claims []model.ResourceClaim
+		pods   []corev1.Pod
+	}{
+		{
+			// a pool split across slices is merged into one pool and one
+			// count per product, and a device listed twice counts once
+			name:  "multi-slice-pool",
+			nodes: []corev1.Node{node("node-1")},
+			slices: []model.ResourceSlice{
+				slice("node-1-gpu-a", "node-1", 2, 2, gpu("gpu-0", "H100"), gpu("gpu-1", "H100")),
+				slice("node-1-gpu-b", "node-1", 2, 2, gpu("gpu-1", "H100"), gpu("gpu-2", "A100")),
+				slice("node-1-gpu-old", "node-1", 1, 1, gpu("gpu-3", "H100")),
+			},
+			claims: []model.ResourceClaim{claim("training", allocated("node-1", "gpu-1"))},
+			pods:   []corev1.Pod{pod("trainer", "node-1", "2")},
+		},
+		{
+			// partitions drawing on shared counters are counted as devices
+			name:   "shared-devices",
+			nodes:  []corev1.Node{node("node-1")},
+			slices: []model.ResourceSlice{sharedSlice},
+			claims: []model.ResourceClaim{claim("inference", allocated("node-1", "gpu-0-mig-1"))},
+		},
+		{
+			// admin access does not take the device away from other claims
+			name:   "admin-access",
+			nodes:  []corev1.Node{node("node-1")},
+			slices: []model.ResourceSlice{slice("node-1-gpu", "node-1", 1, 1, gpu("gpu-0", "H100"), gpu("gpu-1", "H100"))},
+			claims: []model.ResourceClaim{
+				claim("training", allocated("node-1", "gpu-0")),
+				claim("monitoring", adminAllocated("node-1", "gpu-0"), adminAllocated("node-1", "gpu-1")),
+			},
+		},
+		{
+			// slices for a node selector belong to no single node and are
+			// left out of the per-node summaries
+			name:   "node-selector-slices",
+			nodes:  []corev1.Node{node("node-1"), node("node-2")},
+			slices: []model.ResourceSlice{selectorSlice, slice("node-2-gpu", "node-2", 1, 1, gpu("gpu-0", "A100"))},
+			claims: []model.ResourceClaim{claim("training", allocated("rack-1", "gpu-0"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := NewPodUsage()
+			for i := range tt.pods {
+				pods.Add(&tt.pods[i])
+			}
+			nodeInfos, err := Nodes(context.Background(), tt.nodes, tt.slices, tt.claims, pods)
+			if err != nil {
+				t.Fatalf("Nodes() error = %v", err)
+			}
+			got, err := json.MarshalIndent(nodeInfos, "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal node summaries: %v", err)
+			}
+			got = append(got, '\n')
+
+			path := filepath.Join("testdata", tt.name+".json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("failed to write %s: %v", path, err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("summaries differ from %s, run go test ./pkg/aggregate -update if intended (-golden +got):\n%s", path, diff)
+			}
+		})
+	}
+}
+
+func TestAllocatedDevices(t *testing.T) {
+	claims := []model.ResourceClaim{
+		claim("training", allocated("node-1", "gpu-0")),
+		claim("monitoring", adminAllocated("node-1", "gpu-0")),
+		claim("inference", allocated("node-1", "gpu-0"), allocated("node-1", "gpu-1")),
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"}},
+	}
+
+	got := AllocatedDevices(claims)
+
+	expected := map[DeviceKey][]Allocation{
+		{Driver: "gpu.nvidia.com", Pool: "node-1", Device: "gpu-0"}: {
+			{ClaimNamespace: "default", ClaimName: "inference", Request: "gpu"},
+			{ClaimNamespace: "default", ClaimName: "training", Request: "gpu"},
+		},
+		{Driver: "gpu.nvidia.com", Pool: "node-1", Device: "gpu-1"}: {
+			{ClaimNamespace: "default", ClaimName: "inference", Request: "gpu"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestLatestPoolGenerations(t *testing.T) {
+	slices := []model.ResourceSlice{
+		slice("a", "node-1", 1, 1),
+		slice("b", "node-1", 3, 1),
+		slice("c", "node-1", 2, 1),
+		slice("d", "node-2", 0, 1),
+	}
+
+	got := LatestPoolGenerations(slices)
+
+	expected := map[PoolKey]int64{
+		{Driver: "gpu.nvidia.com", Pool: "node-1"}: 3,
+		{Driver: "gpu.nvidia.com", Pool: "node-2"}: 0,
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+// countingDecorator names each device after its position in the batch and
+// counts the batches.
+type countingDecorator struct {
+	calls int
+}
+
+func (d *countingDecorator) Decorate(ctx context.Context, driver string, devices []decorator.Device) ([]decorator.Decoration, error) {
+	d.calls++
+	decorations := make([]decorator.Decoration, len(devices))
+	for i, dev := range devices {
+		decorations[i].ProductName = fmt.Sprintf("%d:%s/%s", i, dev.Pool, dev.Name)
+	}
+	return decorations, ctx.Err()
+}
+
+func TestDecorateSlices(t *testing.T) {
+	d := &countingDecorator{}
+	previous := decorator.For("gpu.nvidia.com")
+	decorator.Register("gpu.nvidia.com", d)
+	defer decorator.Register("gpu.nvidia.com", previous)
+
+	slices := []model.ResourceSlice{
+		slice("a", "node-1", 2, 1, gpu("gpu-0", "A100"), gpu("gpu-1", "A100")),
+		slice("b", "node-1", 1, 1, gpu("gpu-9", "A100")),
+		slice("c", "node-2", 1, 1, gpu("gpu-0", "A100")),
+	}
+
+	got, err := DecorateSlices(context.Background(), slices)
+	if err != nil {
+		t.Fatalf("DecorateSlices() error = %v", err)
+	}
+	var products [][]string
+	for _, decorations := range got {
+		var names []string
+		for _, decoration := range decorations {
+			names = append(names, decoration.ProductName)
+		}
+		products = append(products, names)
+	}
+	expected := [][]string{{"0:node-1/gpu-0", "1:node-1/gpu-1"}, nil, {"2:node-2/gpu-0"}}
+	if diff := cmp.Diff(products, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	if d.calls != 1 {
+		t.Errorf("decorator called %d times, want once per driver", d.calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DecorateSlices(ctx, slices); !errors.Is(err, context.Canceled) {
+		t.Errorf("DecorateSlices() error = %v, want %v", err, context.Canceled)
+	}
+}
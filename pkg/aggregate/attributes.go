@@ -0,0 +1,108 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NormalizeName strips the domain from a qualified attribute or capacity name
+// when it matches the driver, so "gpu.nvidia.com/productName" published by the
+// gpu.nvidia.com driver is treated the same as "productName". Names qualified
+// with a foreign domain are returned unchanged.
+func NormalizeName(driver string, name model.QualifiedName) string {
+	domain, id, found := strings.Cut(string(name), "/")
+	if found && domain == driver {
+		return id
+	}
+	return string(name)
+}
+
+// LookupAttribute finds an attribute by its unqualified name, accepting both
+// the bare and the driver-qualified form of the key.
+func LookupAttribute(attrs map[model.QualifiedName]model.DeviceAttribute, driver, name string) (model.DeviceAttribute, bool) {
+	if attr, ok := attrs[model.QualifiedName(name)]; ok {
+		return attr, true
+	}
+	attr, ok := attrs[model.QualifiedName(driver+"/"+name)]
+	return attr, ok
+}
+
+// AttributeString renders an attribute value, whatever its type, as a string.
+func AttributeString(attr model.DeviceAttribute) string {
+	switch {
+	case attr.StringValue != nil:
+		return *attr.StringValue
+	case attr.VersionValue != nil:
+		return *attr.VersionValue
+	case attr.IntValue != nil:
+		return strconv.FormatInt(*attr.IntValue, 10)
+	case attr.BoolValue != nil:
+		return strconv.FormatBool(*attr.BoolValue)
+	default:
+		return ""
+	}
+}
+
+// DecorateSlices converts the devices of the slices to the decorator model
+// and runs the decorator of each driver once, on the devices of all its
+// slices, so exec plugins are not started per slice. The decorations of each
+// slice are returned at its index. Slices of outdated pool generations are
+// left out and get none.
+func DecorateSlices(ctx context.Context, resourceSlices []model.ResourceSlice) ([][]decorator.Decoration, error) {
+	poolGenerations := LatestPoolGenerations(resourceSlices)
+	// batch holds the devices of one driver and the slices they came from
+	type batch struct {
+		devices []decorator.Device
+		slices  []int
+	}
+	batches := make(map[string]*batch)
+	var drivers []string
+	for i := range resourceSlices {
+		rs := &resourceSlices[i]
+		if rs.Spec.Pool.Generation < poolGenerations[PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}] {
+			continue
+		}
+		b, ok := batches[rs.Spec.Driver]
+		if !ok {
+			b = &batch{}
+			batches[rs.Spec.Driver] = b
+			drivers = append(drivers, rs.Spec.Driver)
+		}
+		for _, dev := range rs.Spec.Devices {
+			device := decorator.Device{
+				Name:       dev.Name,
+				Pool:       rs.Spec.Pool.Name,
+				Attributes: make(map[string]string),
+				Capacity:   make(map[string]resource.Quantity),
+			}
+			for name, attr := range dev.Attributes {
+				device.Attributes[NormalizeName(rs.Spec.Driver, name)] = AttributeString(attr)
+			}
+			for name, c := range dev.Capacity {
+				device.Capacity[NormalizeName(rs.Spec.Driver, name)] = c.Value.DeepCopy()
+			}
+			b.devices = append(b.devices, device)
+		}
+		b.slices = append(b.slices, i)
+	}
+
+	decorations := make([][]decorator.Decoration, len(resourceSlices))
+	for _, driver := range drivers {
+		b := batches[driver]
+		driverDecorations, err := decorator.Decorate(ctx, driver, b.devices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decorate devices of driver %s: %w", driver, err)
+		}
+		for _, i := range b.slices {
+			n := len(resourceSlices[i].Spec.Devices)
+			decorations[i], driverDecorations = driverDecorations[:n:n], driverDecorations[n:]
+		}
+	}
+	return decorations, nil
+}
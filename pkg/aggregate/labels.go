@@ -0,0 +1,73 @@
+package aggregate
+
+import corev1 "k8s.io/api/core/v1"
+
+// nodePoolLabels are the labels managed Kubernetes offerings and node
+// autoscalers put on nodes to name their node pool, in order of precedence.
+var nodePoolLabels = []string{
+	"eks.amazonaws.com/nodegroup",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+	"karpenter.sh/nodepool",
+}
+
+// NodePool returns the node pool named by the first of nodePoolLabels set on
+// the node, or "" for nodes that are not part of a managed pool.
+func NodePool(labels map[string]string) string {
+	for _, label := range nodePoolLabels {
+		if pool := labels[label]; pool != "" {
+			return pool
+		}
+	}
+	return ""
+}
+
+// InstanceType returns the cloud instance type of the node from the
+// well-known label, or its deprecated beta predecessor.
+func InstanceType(labels map[string]string) string {
+	if instanceType := labels[corev1.LabelInstanceTypeStable]; instanceType != "" {
+		return instanceType
+	}
+	return labels[corev1.LabelInstanceType]
+}
+
+// NodeOS returns the operating system of the node from the well-known label,
+// or from the node status for nodes whose kubelet does not set the label.
+func NodeOS(node *corev1.Node) string {
+	if os := node.Labels[corev1.LabelOSStable]; os != "" {
+		return os
+	}
+	return node.Status.NodeInfo.OperatingSystem
+}
+
+// NodeArch returns the CPU architecture of the node from the well-known
+// label, or from the node status for nodes whose kubelet does not set it.
+func NodeArch(node *corev1.Node) string {
+	if arch := node.Labels[corev1.LabelArchStable]; arch != "" {
+		return arch
+	}
+	return node.Status.NodeInfo.Architecture
+}
+
+// spotLabels are the labels cloud providers and node autoscalers put on spot
+// or preemptible nodes, with the value marking them as such.
+var spotLabels = map[string]string{
+	"eks.amazonaws.com/capacityType":        "SPOT",
+	"karpenter.sh/capacity-type":            "spot",
+	"cloud.google.com/gke-spot":             "true",
+	"cloud.google.com/gke-preemptible":      "true",
+	"kubernetes.azure.com/scalesetpriority": "spot",
+	"node.kubernetes.io/lifecycle":          "spot",
+}
+
+// IsSpot reports whether the node runs on interruptible spot or preemptible
+// capacity.
+func IsSpot(labels map[string]string) bool {
+	for label, value := range spotLabels {
+		if labels[label] == value {
+			return true
+		}
+	}
+	return false
+}
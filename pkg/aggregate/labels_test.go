@@ -0,0 +1,37 @@
+package aggregate
+
+import "testing"
+
+func TestNodePool(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		want   string
+	}{
+		{labels: map[string]string{"eks.amazonaws.com/nodegroup": "gpu-ng"}, want: "gpu-ng"},
+		{labels: map[string]string{"cloud.google.com/gke-nodepool": "a3-pool"}, want: "a3-pool"},
+		{labels: map[string]string{"kubernetes.azure.com/agentpool": "gpunp", "agentpool": "gpunp"}, want: "gpunp"},
+		{labels: map[string]string{"karpenter.sh/nodepool": "gpu"}, want: "gpu"},
+		{labels: map[string]string{"node-role.kubernetes.io/worker": ""}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := NodePool(tt.labels); got != tt.want {
+			t.Errorf("NodePool(%v) = %q, want %q", tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestInstanceType(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		want   string
+	}{
+		{labels: map[string]string{"node.kubernetes.io/instance-type": "p5.48xlarge", "beta.kubernetes.io/instance-type": "p4d.24xlarge"}, want: "p5.48xlarge"},
+		{labels: map[string]string{"beta.kubernetes.io/instance-type": "p4d.24xlarge"}, want: "p4d.24xlarge"},
+		{labels: nil, want: ""},
+	}
+	for _, tt := range tests {
+		if got := InstanceType(tt.labels); got != tt.want {
+			t.Errorf("InstanceType(%v) = %q, want %q", tt.labels, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package analyze
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// CUDAVersionAnnotation declares the CUDA version the containers of a pod
+// need, for images that do not reveal it.
+const CUDAVersionAnnotation = "cuda.dra-resources.dharmjit.github.io/version"
+
+// minDriverVersions are the oldest Linux NVIDIA driver versions supporting
+// each CUDA toolkit release, newest first, from the CUDA release notes. They
+// give the CUDA version of devices that publish a driverVersion but no
+// cudaDriverVersion attribute.
+var minDriverVersions = []struct {
+	cuda, driver string
+}{
+	{"13.0", "580.65.06"},
+	{"12.9", "575.51.03"},
+	{"12.8", "570.26"},
+	{"12.6", "560.28.03"},
+	{"12.5", "555.42.02"},
+	{"12.4", "550.54.14"},
+	{"12.3", "545.23.06"},
+	{"12.2", "535.54.03"},
+	{"12.1", "530.30.02"},
+	{"12.0", "525.60.13"},
+	{"11.8", "520.61.05"},
+	{"11.7", "515.43.04"},
+	{"11.6", "510.39.01"},
+	{"11.5", "495.29.05"},
+	{"11.4", "470.42.01"},
+	{"11.3", "465.19.01"},
+	{"11.2", "460.27.03"},
+	{"11.1", "455.23.05"},
+	{"11.0", "450.36.06"},
+}
+
+// requireCUDA matches the CUDA requirement in NVIDIA_REQUIRE_CUDA, which the
+// CUDA base images set, e.g. "cuda>=12.4 brand=tesla,driver>=470".
+var requireCUDA = regexp.MustCompile(`cuda>=([0-9]+\.[0-9]+)`)
+
+// CUDAIncompatibilities returns the containers of pods that need a newer
+// CUDA version than the driver of a device allocated to the pod supports,
+// sorted by namespace, pod and container. The CUDA version a container needs
+// is taken from, in order:
+//   - the CUDAVersionAnnotation of the pod,
+//   - its NVIDIA_REQUIRE_CUDA or CUDA_VERSION environment variable,
+//   - images, mapping image name prefixes to CUDA versions; the longest
+//     matching prefix wins,
+//   - the tag of nvidia/cuda images, e.g. 12.4.1-runtime-ubuntu22.04.
+//
+// Containers without a known requirement are skipped, as are devices that
+// publish neither a cudaDriverVersion nor a driverVersion attribute. Only the
+// CUDA major and minor versions are compared.
+func CUDAIncompatibilities(pods []types.DevicePod, devices []types.DeviceInfo, images map[string]string) []types.CUDAIncompatibility {
+	claimDevices := make(map[string][]types.DeviceInfo)
+	for _, dev := range devices {
+		if dev.Claim != "" {
+			claimDevices[dev.Claim] = append(claimDevices[dev.Claim], dev)
+		}
+	}
+
+	var result []types.CUDAIncompatibility
+	for _, pod := range pods {
+		var podDevices []types.DeviceInfo
+		for _, claim := range pod.Claims {
+			podDevices = append(podDevices, claimDevices[pod.Namespace+"/"+claim]...)
+		}
+		if len(podDevices) == 0 {
+			continue
+		}
+
+		for _, container := range pod.Containers {
+			required, source := cudaRequirement(pod, container, images)
+			if required == nil {
+				continue
+			}
+			var incompatible *types.CUDAIncompatibility
+			var oldest *version.Version
+			for _, dev := range podDevices {
+				supported := deviceCUDAVersion(dev)
+				if supported == nil || !supported.LessThan(required) {
+					continue
+				}
+				if incompatible == nil {
+					incompatible = &types.CUDAIncompatibility{
+						Namespace:    pod.Namespace,
+						Pod:          pod.Name,
+						Container:    container.Name,
+						Image:        container.Image,
+						NodeName:     pod.NodeName,
+						RequiredCUDA: required.String(),
+						Source:       source,
+					}
+				}
+				incompatible.Devices = append(incompatible.Devices, dev.Driver+"/"+dev.Pool+"/"+dev.Name)
+				if oldest == nil || supported.LessThan(oldest) {
+					oldest = supported
+					incompatible.DriverVersion = dev.Attributes["driverVersion"]
+					incompatible.SupportedCUDA = supported.String()
+				}
+			}
+			if incompatible != nil {
+				result = append(result, *incompatible)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+		return a.Container < b.Container
+	})
+	return result
+}
+
+// cudaRequirement returns the CUDA major and minor version the container
+// needs and where it was found, or nil if it is unknown.
+func cudaRequirement(pod types.DevicePod, container types.ContainerInfo, images map[string]string) (*version.Version, string) {
+	if v := majorMinor(pod.Annotations[CUDAVersionAnnotation]); v != nil {
+		return v, "annotation " + CUDAVersionAnnotation
+	}
+	if m := requireCUDA.FindStringSubmatch(container.Env["NVIDIA_REQUIRE_CUDA"]); m != nil {
+		if v := majorMinor(m[1]); v != nil {
+			return v, "env NVIDIA_REQUIRE_CUDA"
+		}
+	}
+	if v := majorMinor(container.Env["CUDA_VERSION"]); v != nil {
+		return v, "env CUDA_VERSION"
+	}
+
+	var longest string
+	for prefix := range images {
+		if strings.HasPrefix(container.Image, prefix) && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	if longest != "" {
+		if v := majorMinor(images[longest]); v != nil {
+			return v, fmt.Sprintf("image mapping %s", longest)
+		}
+	}
+
+	// nvidia/cuda tags start with the CUDA version, e.g. 12.4.1-base-ubuntu22.04
+	image, _, _ := strings.Cut(container.Image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repository, tag := image[:i], image[i+1:]
+		if repository == "nvidia/cuda" || strings.HasSuffix(repository, "/nvidia/cuda") {
+			tagVersion, _, _ := strings.Cut(tag, "-")
+			if v := majorMinor(tagVersion); v != nil {
+				return v, "image tag"
+			}
+		}
+	}
+	return nil, ""
+}
+
+// deviceCUDAVersion returns the newest CUDA version the driver of the device
+// supports, or nil if the device does not publish its driver version.
+func deviceCUDAVersion(dev types.DeviceInfo) *version.Version {
+	if v := majorMinor(dev.Attributes["cudaDriverVersion"]); v != nil {
+		return v
+	}
+	driver, err := version.ParseGeneric(dev.Attributes["driverVersion"])
+	if err != nil {
+		return nil
+	}
+	for _, min := range minDriverVersions {
+		if driver.AtLeast(version.MustParseGeneric(min.driver)) {
+			return version.MustParseGeneric(min.cuda)
+		}
+	}
+	// older than any CUDA release still in use
+	return version.MajorMinor(0, 0)
+}
+
+// majorMinor parses the major and minor version of value, e.g. 12.4 of
+// 12.4.1, or returns nil if value is not a version.
+func majorMinor(value string) *version.Version {
+	v, err := version.ParseGeneric(value)
+	if err != nil {
+		return nil
+	}
+	return version.MajorMinor(v.Major(), v.Minor())
+}
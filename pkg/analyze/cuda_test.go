@@ -0,0 +1,76 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCUDAIncompatibilities(t *testing.T) {
+	gpu := func(nodeName, name, claim string, attributes map[string]string) types.DeviceInfo {
+		return types.DeviceInfo{NodeName: nodeName, Driver: "gpu.nvidia.com", Pool: nodeName, Name: name, Claim: claim, Attributes: attributes}
+	}
+	oldDriver := map[string]string{"driverVersion": "535.104.5"}
+	devices := []types.DeviceInfo{
+		gpu("node-1", "gpu-0", "team-a/train", oldDriver),
+		gpu("node-1", "gpu-1", "team-a/train", oldDriver),
+		gpu("node-2", "gpu-0", "team-a/infer", map[string]string{"driverVersion": "550.54.15", "cudaDriverVersion": "12.4.0"}),
+		gpu("node-3", "gpu-0", "team-b/legacy", map[string]string{}),
+		gpu("node-1", "gpu-2", "", oldDriver),
+	}
+	pods := []types.DevicePod{
+		{
+			Namespace: "team-a", Name: "train-0", NodeName: "node-1", Claims: []string{"train"},
+			Containers: []types.ContainerInfo{
+				{Name: "trainer", Image: "nvcr.io/nvidia/cuda:12.4.1-runtime-ubuntu22.04"},
+				{Name: "sidecar", Image: "busybox:1.36"},
+				{Name: "exporter", Image: "registry.example.com/exporter:v1", Env: map[string]string{"NVIDIA_REQUIRE_CUDA": "cuda>=11.8 brand=tesla,driver>=470"}},
+			},
+		},
+		{
+			Namespace: "team-a", Name: "infer-0", NodeName: "node-2", Claims: []string{"infer"},
+			Annotations: map[string]string{CUDAVersionAnnotation: "12.6"},
+			Containers:  []types.ContainerInfo{{Name: "server", Image: "nvcr.io/nvidia/tritonserver:24.08-py3"}},
+		},
+		{
+			Namespace: "team-a", Name: "notebook", NodeName: "node-2", Claims: []string{"infer"},
+			Containers: []types.ContainerInfo{{Name: "jupyter", Image: "nvcr.io/nvidia/pytorch:24.03-py3", Env: map[string]string{"CUDA_VERSION": "12.4.0"}}},
+		},
+		{
+			Namespace: "team-b", Name: "legacy-0", NodeName: "node-3", Claims: []string{"legacy"},
+			Containers: []types.ContainerInfo{{Name: "app", Image: "nvidia/cuda:12.8.0-base-ubuntu24.04"}},
+		},
+		{
+			Namespace: "team-a", Name: "train-1", NodeName: "node-1", Claims: []string{"train"},
+			Containers: []types.ContainerInfo{{Name: "app", Image: "nvcr.io/nvidia/pytorch:24.03-py3"}},
+		},
+	}
+	images := map[string]string{
+		"nvcr.io/nvidia/pytorch":       "12.1",
+		"nvcr.io/nvidia/pytorch:24.03": "12.4",
+	}
+
+	got := CUDAIncompatibilities(pods, devices, images)
+
+	want := []types.CUDAIncompatibility{
+		{
+			Namespace: "team-a", Pod: "infer-0", Container: "server", Image: "nvcr.io/nvidia/tritonserver:24.08-py3", NodeName: "node-2",
+			RequiredCUDA: "12.6", Source: "annotation " + CUDAVersionAnnotation,
+			Devices: []string{"gpu.nvidia.com/node-2/gpu-0"}, DriverVersion: "550.54.15", SupportedCUDA: "12.4",
+		},
+		{
+			Namespace: "team-a", Pod: "train-0", Container: "trainer", Image: "nvcr.io/nvidia/cuda:12.4.1-runtime-ubuntu22.04", NodeName: "node-1",
+			RequiredCUDA: "12.4", Source: "image tag",
+			Devices: []string{"gpu.nvidia.com/node-1/gpu-0", "gpu.nvidia.com/node-1/gpu-1"}, DriverVersion: "535.104.5", SupportedCUDA: "12.2",
+		},
+		{
+			Namespace: "team-a", Pod: "train-1", Container: "app", Image: "nvcr.io/nvidia/pytorch:24.03-py3", NodeName: "node-1",
+			RequiredCUDA: "12.4", Source: "image mapping nvcr.io/nvidia/pytorch:24.03",
+			Devices: []string{"gpu.nvidia.com/node-1/gpu-0", "gpu.nvidia.com/node-1/gpu-1"}, DriverVersion: "535.104.5", SupportedCUDA: "12.2",
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
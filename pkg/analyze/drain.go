@@ -0,0 +1,45 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// DrainCandidates ranks nodes with devices by how disruptive draining them
+// would be. Nodes whose devices are entirely free come first, followed by the
+// nodes whose draining evicts the fewest device-consuming pods.
+func DrainCandidates(nodes []*types.NodeInfo) []types.DrainCandidate {
+	var candidates []types.DrainCandidate
+	for _, node := range nodes {
+		if len(node.Devices) == 0 {
+			continue
+		}
+
+		candidate := types.DrainCandidate{
+			NodeName:     node.NodeName,
+			ConsumerPods: len(node.DeviceConsumers),
+		}
+		for _, dev := range node.Devices {
+			candidate.TotalDevices += dev.TotalCount
+			candidate.AllocatedDevices += dev.TotalCount - dev.AvailableCount
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Free() != b.Free() {
+			return a.Free()
+		}
+		if a.ConsumerPods != b.ConsumerPods {
+			return a.ConsumerPods < b.ConsumerPods
+		}
+		if a.AllocatedDevices != b.AllocatedDevices {
+			return a.AllocatedDevices < b.AllocatedDevices
+		}
+		return a.NodeName < b.NodeName
+	})
+
+	return candidates
+}
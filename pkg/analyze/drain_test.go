@@ -0,0 +1,40 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDrainCandidates(t *testing.T) {
+	nodes := []*types.NodeInfo{
+		{
+			NodeName:        "busy",
+			Devices:         []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 0}},
+			DeviceConsumers: []string{"default/a", "default/b"},
+		},
+		{
+			NodeName: "cpu-only",
+		},
+		{
+			NodeName: "free",
+			Devices:  []types.Device{{ProductName: "gpu", TotalCount: 2, AvailableCount: 2}},
+		},
+		{
+			NodeName:        "light",
+			Devices:         []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 3}},
+			DeviceConsumers: []string{"default/c"},
+		},
+	}
+
+	expected := []types.DrainCandidate{
+		{NodeName: "free", TotalDevices: 2},
+		{NodeName: "light", TotalDevices: 4, AllocatedDevices: 1, ConsumerPods: 1},
+		{NodeName: "busy", TotalDevices: 4, AllocatedDevices: 4, ConsumerPods: 2},
+	}
+
+	if diff := cmp.Diff(DrainCandidates(nodes), expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
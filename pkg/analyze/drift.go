@@ -0,0 +1,72 @@
+package analyze
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// ClassDrift compares the DeviceClasses of several clusters, keyed by context
+// name, and returns the classes that are missing from some of them or whose
+// selectors or configuration differ. Selectors and configuration are compared
+// as unordered sets. Classes defined identically everywhere are omitted.
+func ClassDrift(classes map[string][]types.DeviceClassInfo) []types.ClassDrift {
+	contexts := make([]string, 0, len(classes))
+	for name := range classes {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+
+	// class name -> context -> definition
+	byName := make(map[string]map[string]types.DeviceClassInfo)
+	for _, context := range contexts {
+		for _, class := range classes[context] {
+			if byName[class.Name] == nil {
+				byName[class.Name] = make(map[string]types.DeviceClassInfo)
+			}
+			byName[class.Name][context] = normalizeClass(class)
+		}
+	}
+
+	var drifts []types.ClassDrift
+	for name, defs := range byName {
+		drift := types.ClassDrift{Name: name}
+		for _, context := range contexts {
+			def, ok := defs[context]
+			if !ok {
+				drift.Missing = append(drift.Missing, context)
+				continue
+			}
+			found := false
+			for i := range drift.Variants {
+				if reflect.DeepEqual(drift.Variants[i].Class, def) {
+					drift.Variants[i].Contexts = append(drift.Variants[i].Contexts, context)
+					found = true
+					break
+				}
+			}
+			if !found {
+				drift.Variants = append(drift.Variants, types.ClassVariant{Contexts: []string{context}, Class: def})
+			}
+		}
+		if len(drift.Missing) > 0 || len(drift.Variants) > 1 {
+			drifts = append(drifts, drift)
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Name < drifts[j].Name
+	})
+	return drifts
+}
+
+// normalizeClass sorts the selectors and configuration of a class so their
+// order does not count as a difference.
+func normalizeClass(class types.DeviceClassInfo) types.DeviceClassInfo {
+	class.Selectors = append([]string(nil), class.Selectors...)
+	class.Config = append([]string(nil), class.Config...)
+	sort.Strings(class.Selectors)
+	sort.Strings(class.Config)
+	return class
+}
@@ -0,0 +1,49 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClassDrift(t *testing.T) {
+	gpu := types.DeviceClassInfo{
+		Name:      "gpu",
+		Selectors: []string{`device.driver == "gpu.nvidia.com"`, `device.attributes["gpu.nvidia.com"].type == "gpu"`},
+	}
+	gpuReordered := types.DeviceClassInfo{
+		Name:      "gpu",
+		Selectors: []string{`device.attributes["gpu.nvidia.com"].type == "gpu"`, `device.driver == "gpu.nvidia.com"`},
+	}
+	mig := types.DeviceClassInfo{Name: "mig", Selectors: []string{`device.attributes["gpu.nvidia.com"].type == "mig"`}}
+	migProd := types.DeviceClassInfo{
+		Name:      "mig",
+		Selectors: []string{`device.attributes["gpu.nvidia.com"].type == "mig"`},
+		Config:    []string{`gpu.nvidia.com: {"sharing":"timeSlicing"}`},
+	}
+	nic := types.DeviceClassInfo{Name: "nic", Selectors: []string{`device.driver == "net.example.com"`}}
+
+	got := ClassDrift(map[string][]types.DeviceClassInfo{
+		"staging": {gpu, mig, nic},
+		"prod":    {gpuReordered, migProd},
+	})
+
+	expected := []types.ClassDrift{
+		{
+			Name: "mig",
+			Variants: []types.ClassVariant{
+				{Contexts: []string{"prod"}, Class: migProd},
+				{Contexts: []string{"staging"}, Class: mig},
+			},
+		},
+		{
+			Name:     "nic",
+			Missing:  []string{"prod"},
+			Variants: []types.ClassVariant{{Contexts: []string{"staging"}, Class: nic}},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,74 @@
+package analyze
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// nfdLabelPrefix is the prefix of the labels Node Feature Discovery puts on
+// nodes.
+const nfdLabelPrefix = "feature.node.kubernetes.io/"
+
+// versionFeatures are the software versions read from Node Feature Discovery
+// and GPU Feature Discovery labels. Each is taken from the first label set
+// on the node; a label ending in ".major" is joined with its ".minor" and
+// ".rev" siblings, as older GPU Feature Discovery releases split versions
+// that way.
+var versionFeatures = []struct {
+	name   string
+	labels []string
+}{
+	{"GPU driver", []string{"nvidia.com/cuda.driver-version.full", "nvidia.com/cuda.driver.major"}},
+	{"CUDA", []string{"nvidia.com/cuda.runtime-version.full", "nvidia.com/cuda.runtime.major"}},
+	{"Compute capability", []string{"nvidia.com/gpu.compute.major"}},
+	{"GPU family", []string{"nvidia.com/gpu.family"}},
+	{"MIG strategy", []string{"nvidia.com/mig.strategy"}},
+	{"Kernel", []string{nfdLabelPrefix + "kernel-version.full"}},
+	{"OS", []string{nfdLabelPrefix + "system-os_release.ID"}},
+	{"OS version", []string{nfdLabelPrefix + "system-os_release.VERSION_ID"}},
+}
+
+// NodeFeatures returns the software versions and PCI devices a node reports
+// through Node Feature Discovery and GPU Feature Discovery labels, so that
+// compatibility questions such as which driver and CUDA versions run next to
+// a node's devices can be answered from the node view. PCI devices come from
+// the pci-<class>_<vendor>.present labels, e.g. 0302_10de for NVIDIA 3D
+// controllers, sorted. Nodes without these labels have no features.
+func NodeFeatures(labels map[string]string) []types.NodeFeature {
+	var features []types.NodeFeature
+	for _, feature := range versionFeatures {
+		for _, label := range feature.labels {
+			value, ok := labels[label]
+			if !ok {
+				continue
+			}
+			if base, found := strings.CutSuffix(label, ".major"); found {
+				for _, part := range []string{".minor", ".rev"} {
+					if next, ok := labels[base+part]; ok {
+						value += "." + next
+					}
+				}
+			}
+			features = append(features, types.NodeFeature{Name: feature.name, Value: value, Label: label})
+			break
+		}
+	}
+
+	var pciDevices []string
+	for label, value := range labels {
+		id, ok := strings.CutPrefix(label, nfdLabelPrefix+"pci-")
+		if !ok || value != "true" {
+			continue
+		}
+		if id, ok = strings.CutSuffix(id, ".present"); ok {
+			pciDevices = append(pciDevices, id)
+		}
+	}
+	sort.Strings(pciDevices)
+	for _, id := range pciDevices {
+		features = append(features, types.NodeFeature{Name: "PCI device", Value: id, Label: nfdLabelPrefix + "pci-" + id + ".present"})
+	}
+	return features
+}
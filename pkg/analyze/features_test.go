@@ -0,0 +1,46 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNodeFeatures(t *testing.T) {
+	labels := map[string]string{
+		"kubernetes.io/hostname":                                    "node-1",
+		"nvidia.com/cuda.driver-version.full":                       "550.54.15",
+		"nvidia.com/cuda.driver.major":                              "550",
+		"nvidia.com/cuda.runtime.major":                             "12",
+		"nvidia.com/cuda.runtime.minor":                             "4",
+		"nvidia.com/gpu.compute.major":                              "8",
+		"nvidia.com/gpu.compute.minor":                              "0",
+		"feature.node.kubernetes.io/kernel-version.full":            "5.15.0-1040-aws",
+		"feature.node.kubernetes.io/system-os_release.ID":           "ubuntu",
+		"feature.node.kubernetes.io/pci-10de.present":               "true",
+		"feature.node.kubernetes.io/pci-0200_15b3.present":          "true",
+		"feature.node.kubernetes.io/pci-0302_10de.sriov.capable":    "true",
+		"feature.node.kubernetes.io/cpu-cpuid.AVX512F":              "true",
+		"feature.node.kubernetes.io/system-os_release.VERSION_ID.x": "22",
+	}
+
+	got := NodeFeatures(labels)
+
+	want := []types.NodeFeature{
+		{Name: "GPU driver", Value: "550.54.15", Label: "nvidia.com/cuda.driver-version.full"},
+		{Name: "CUDA", Value: "12.4", Label: "nvidia.com/cuda.runtime.major"},
+		{Name: "Compute capability", Value: "8.0", Label: "nvidia.com/gpu.compute.major"},
+		{Name: "Kernel", Value: "5.15.0-1040-aws", Label: "feature.node.kubernetes.io/kernel-version.full"},
+		{Name: "OS", Value: "ubuntu", Label: "feature.node.kubernetes.io/system-os_release.ID"},
+		{Name: "PCI device", Value: "0200_15b3", Label: "feature.node.kubernetes.io/pci-0200_15b3.present"},
+		{Name: "PCI device", Value: "10de", Label: "feature.node.kubernetes.io/pci-10de.present"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if got := NodeFeatures(map[string]string{"kubernetes.io/hostname": "node-2"}); len(got) != 0 {
+		t.Errorf("expected no features without discovery labels, got %v", got)
+	}
+}
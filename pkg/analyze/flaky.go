@@ -0,0 +1,132 @@
+package analyze
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// FlakyThresholds decide which devices FlakyDevices flags.
+type FlakyThresholds struct {
+	// Since is the start of the analyzed period.
+	Since time.Time
+	// ChurnFactor flags devices allocated at least this many times as often
+	// as the median device of their product.
+	ChurnFactor float64
+	// MinAllocations is the number of allocations below which churn is not
+	// flagged, so that a quiet cluster does not flag every device used twice.
+	MinAllocations int
+	// MinHealthFlaps flags devices that became unhealthy at least this many
+	// times.
+	MinHealthFlaps int
+}
+
+// FlakyDevices returns the devices whose recorded history since
+// thresholds.Since shows unusually frequent allocation churn or repeated
+// health flaps, the most flapping first. A device that keeps being allocated
+// to new claims often fails its workloads early; one that repeatedly turns
+// unhealthy and back often has a hardware or driver fault. devices are the
+// current devices of the cluster, which give the product and current health;
+// devices that are no longer published are compared with the others of their
+// driver.
+func FlakyDevices(histories []types.DeviceHistory, devices []types.DeviceInfo, thresholds FlakyThresholds) []types.FlakyDevice {
+	current := make(map[string]types.DeviceInfo, len(devices))
+	for _, dev := range devices {
+		current[dev.Driver+"/"+dev.Pool+"/"+dev.Name] = dev
+	}
+
+	candidates := make([]types.FlakyDevice, 0, len(histories))
+	groups := make(map[string][]int)
+	groupOf := make([]string, 0, len(histories))
+	for _, history := range histories {
+		if len(history.States) == 0 {
+			continue
+		}
+		flaky := types.FlakyDevice{
+			Driver:   history.Driver,
+			Pool:     history.Pool,
+			Device:   history.Device,
+			NodeName: history.States[len(history.States)-1].NodeName,
+		}
+		group := history.Driver
+		if dev, ok := current[history.Driver+"/"+history.Pool+"/"+history.Device]; ok {
+			flaky.ProductName, flaky.Unhealthy = dev.ProductName, dev.Unhealthy
+			group = dev.ProductName
+		}
+
+		// the first state may have begun before the period and only tells
+		// what the device changed from
+		for i, state := range history.States {
+			if state.Since.Before(thresholds.Since) {
+				continue
+			}
+			var prev types.DeviceState
+			if i > 0 {
+				prev = history.States[i-1]
+			}
+			if state.Claim != "" && state.Claim != prev.Claim {
+				flaky.Allocations++
+			}
+			if state.Unhealthy && !prev.Unhealthy {
+				flaky.HealthFlaps++
+			}
+		}
+		groups[group] = append(groups[group], flaky.Allocations)
+		groupOf = append(groupOf, group)
+		candidates = append(candidates, flaky)
+	}
+
+	medians := make(map[string]float64, len(groups))
+	for group, allocations := range groups {
+		medians[group] = median(allocations)
+	}
+
+	result := make([]types.FlakyDevice, 0, len(candidates))
+	for i, flaky := range candidates {
+		flaky.MedianAllocations = medians[groupOf[i]]
+		if flaky.Allocations >= thresholds.MinAllocations && float64(flaky.Allocations) >= thresholds.ChurnFactor*flaky.MedianAllocations {
+			flaky.Reasons = append(flaky.Reasons, fmt.Sprintf("allocated %d times, the median is %s", flaky.Allocations, formatMedian(flaky.MedianAllocations)))
+		}
+		if flaky.HealthFlaps >= thresholds.MinHealthFlaps {
+			flaky.Reasons = append(flaky.Reasons, fmt.Sprintf("became unhealthy %d times", flaky.HealthFlaps))
+		}
+		if len(flaky.Reasons) > 0 {
+			result = append(result, flaky)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].HealthFlaps != result[j].HealthFlaps {
+			return result[i].HealthFlaps > result[j].HealthFlaps
+		}
+		if result[i].Allocations != result[j].Allocations {
+			return result[i].Allocations > result[j].Allocations
+		}
+		if result[i].Driver != result[j].Driver {
+			return result[i].Driver < result[j].Driver
+		}
+		if result[i].Pool != result[j].Pool {
+			return result[i].Pool < result[j].Pool
+		}
+		return result[i].Device < result[j].Device
+	})
+	return result
+}
+
+func median(values []int) float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return float64(sorted[n/2])
+	}
+	return float64(sorted[n/2-1]+sorted[n/2]) / 2
+}
+
+func formatMedian(v float64) string {
+	if v == float64(int(v)) {
+		return fmt.Sprintf("%d", int(v))
+	}
+	return fmt.Sprintf("%.1f", v)
+}
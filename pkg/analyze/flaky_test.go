@@ -0,0 +1,87 @@
+package analyze
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFlakyDevices(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+	// history gives a device one state per hour, holding the given claims
+	// ("" while available, "!" while unhealthy)
+	history := func(pool, device string, claims ...string) types.DeviceHistory {
+		h := types.DeviceHistory{Driver: "gpu.example.com", Pool: pool, Device: device}
+		for i, claim := range claims {
+			state := types.DeviceState{Since: at(i), NodeName: pool, Claim: claim}
+			if claim == "!" {
+				state.Claim, state.Unhealthy = "", true
+			}
+			if i < len(claims)-1 {
+				state.Until = at(i + 1)
+			}
+			h.States = append(h.States, state)
+		}
+		return h
+	}
+	histories := []types.DeviceHistory{
+		// allocated before the period, which does not count
+		history("node-1", "gpu-0", "ml/a", "ml/a", "ml/b"),
+		history("node-1", "gpu-1", "", "ml/c", ""),
+		history("node-1", "gpu-2", "ml/d", "ml/e", "ml/f", "ml/g", "ml/h", "ml/i"),
+		history("node-2", "gpu-0", "", "!", "", "!", "ml/j", "!"),
+		// no longer published
+		history("node-3", "gpu-0", "", "!", "", "!"),
+	}
+	devices := []types.DeviceInfo{
+		{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", ProductName: "A100"},
+		{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-1", ProductName: "A100"},
+		{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-2", ProductName: "A100"},
+		{NodeName: "node-2", Driver: "gpu.example.com", Pool: "node-2", Name: "gpu-0", ProductName: "A100", Unhealthy: true},
+	}
+
+	got := FlakyDevices(histories, devices, FlakyThresholds{Since: at(1), ChurnFactor: 3, MinAllocations: 3, MinHealthFlaps: 2})
+
+	expected := []types.FlakyDevice{
+		{
+			Driver: "gpu.example.com", Pool: "node-2", Device: "gpu-0", NodeName: "node-2", ProductName: "A100",
+			Allocations: 1, MedianAllocations: 1, HealthFlaps: 3, Unhealthy: true,
+			Reasons: []string{"became unhealthy 3 times"},
+		},
+		{
+			Driver: "gpu.example.com", Pool: "node-3", Device: "gpu-0", NodeName: "node-3",
+			HealthFlaps: 2,
+			Reasons:     []string{"became unhealthy 2 times"},
+		},
+		{
+			Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-2", NodeName: "node-1", ProductName: "A100",
+			Allocations: 5, MedianAllocations: 1,
+			Reasons: []string{"allocated 5 times, the median is 1"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values   []int
+		expected float64
+	}{
+		{[]int{3}, 3},
+		{[]int{5, 1, 3}, 3},
+		{[]int{4, 1, 2, 8}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.values), func(t *testing.T) {
+			if got := median(tt.values); got != tt.expected {
+				t.Errorf("median() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package analyze
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NoLabelValue groups the nodes, or claims, that do not carry the grouping
+// label.
+const NoLabelValue = "<none>"
+
+// GroupByLabel sums the capacity and devices of the nodes sharing a value of
+// the given label, e.g. topology.kubernetes.io/zone. Groups are sorted by
+// label value, with nodes lacking the label last. With excludeUnschedulable,
+// devices on cordoned or NotReady nodes count as unavailable.
+func GroupByLabel(nodes []*types.NodeInfo, label string, excludeUnschedulable bool) []types.NodeGroup {
+	return groupBy(nodes, func(node *types.NodeInfo) (string, bool) {
+		value, ok := node.Labels[label]
+		return value, ok
+	}, excludeUnschedulable)
+}
+
+// GroupByNodePool is like GroupByLabel for the managed node pool of the
+// nodes, whichever cloud provider label it comes from.
+func GroupByNodePool(nodes []*types.NodeInfo, excludeUnschedulable bool) []types.NodeGroup {
+	return groupBy(nodes, func(node *types.NodeInfo) (string, bool) {
+		return node.NodePool, node.NodePool != ""
+	}, excludeUnschedulable)
+}
+
+// GroupByInstanceType is like GroupByLabel for the cloud instance type of the
+// nodes.
+func GroupByInstanceType(nodes []*types.NodeInfo, excludeUnschedulable bool) []types.NodeGroup {
+	return groupBy(nodes, func(node *types.NodeInfo) (string, bool) {
+		return node.InstanceType, node.InstanceType != ""
+	}, excludeUnschedulable)
+}
+
+// GroupByShape is like GroupByLabel for the shape of the nodes: their role,
+// instance type and device products and counts. Large clusters mostly consist
+// of a few shapes, so the groups summarize thousands of nodes in a few rows.
+func GroupByShape(nodes []*types.NodeInfo, excludeUnschedulable bool) []types.NodeGroup {
+	return groupBy(nodes, func(node *types.NodeInfo) (string, bool) {
+		return nodeShape(node), true
+	}, excludeUnschedulable)
+}
+
+// nodeShape describes the role, instance type and devices of a node, e.g.
+// "gpu, p5.48xlarge, 8x NVIDIA H100".
+func nodeShape(node *types.NodeInfo) string {
+	role, instanceType := node.NodeRole, node.InstanceType
+	if role == "" {
+		role = NoLabelValue
+	}
+	if instanceType == "" {
+		instanceType = NoLabelValue
+	}
+	devices := make([]string, 0, len(node.Devices))
+	for _, dev := range node.Devices {
+		devices = append(devices, fmt.Sprintf("%dx %s", dev.TotalCount, dev.ProductName))
+	}
+	sort.Strings(devices)
+	if len(devices) == 0 {
+		devices = append(devices, "no devices")
+	}
+	return role + ", " + instanceType + ", " + strings.Join(devices, " + ")
+}
+
+// groupBy groups the nodes by the value returned by key; nodes without a
+// value are grouped under NoLabelValue.
+func groupBy(nodes []*types.NodeInfo, key func(*types.NodeInfo) (string, bool), excludeUnschedulable bool) []types.NodeGroup {
+	groups := make(map[string]*types.NodeGroup)
+	devices := make(map[string]map[string]*types.Device) // value -> product -> device
+	for _, node := range nodes {
+		value, ok := key(node)
+		if !ok {
+			value = NoLabelValue
+		}
+		group, ok := groups[value]
+		if !ok {
+			group = &types.NodeGroup{Value: value}
+			groups[value] = group
+			devices[value] = make(map[string]*types.Device)
+		}
+
+		group.Nodes++
+		capacity := &group.NodeCapacity
+		capacity.TotalCPU.Add(node.NodeCapacity.TotalCPU)
+		addAvailable(&capacity.AvailableCPU, node.NodeCapacity.AvailableCPU)
+		capacity.TotalMemory.Add(node.NodeCapacity.TotalMemory)
+		addAvailable(&capacity.AvailableMemory, node.NodeCapacity.AvailableMemory)
+		capacity.TotalStorage.Add(node.NodeCapacity.TotalStorage)
+		addAvailable(&capacity.AvailableStorage, node.NodeCapacity.AvailableStorage)
+
+		excluded := excludeUnschedulable && !node.Schedulable()
+		for _, dev := range node.Devices {
+			sum, ok := devices[value][dev.ProductName]
+			if !ok {
+				sum = &types.Device{ProductName: dev.ProductName, Memory: dev.Memory, Capacity: dev.Capacity}
+				devices[value][dev.ProductName] = sum
+			}
+			sum.TotalCount += dev.TotalCount
+			sum.UnhealthyCount += dev.UnhealthyCount
+			if !excluded {
+				sum.AvailableCount += dev.AvailableCount
+			}
+		}
+	}
+
+	result := make([]types.NodeGroup, 0, len(groups))
+	for value, group := range groups {
+		group.Devices = []types.Device{}
+		for _, dev := range devices[value] {
+			group.Devices = append(group.Devices, *dev)
+		}
+		sort.Slice(group.Devices, func(i, j int) bool {
+			return group.Devices[i].ProductName < group.Devices[j].ProductName
+		})
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Value == NoLabelValue) != (result[j].Value == NoLabelValue) {
+			return result[j].Value == NoLabelValue
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
+
+// addAvailable adds the available quantity of a node to sum. Overcommitted
+// nodes have negative availability, which must not hide the capacity left on
+// the other nodes of the group.
+func addAvailable(sum *resource.Quantity, available resource.Quantity) {
+	if available.Sign() > 0 {
+		sum.Add(available)
+	}
+}
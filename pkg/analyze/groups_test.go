@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGroupByLabel(t *testing.T) {
+	const zone = "topology.kubernetes.io/zone"
+	node := func(name, zoneName, availableCPU string, unschedulable bool, devices ...types.Device) *types.NodeInfo {
+		n := &types.NodeInfo{
+			NodeName:      name,
+			Unschedulable: unschedulable,
+			NodeCapacity: types.NodeCapacity{
+				TotalCPU:     resource.MustParse("8"),
+				AvailableCPU: resource.MustParse(availableCPU),
+			},
+			Devices: devices,
+		}
+		if zoneName != "" {
+			n.Labels = map[string]string{zone: zoneName}
+		}
+		return n
+	}
+	nodes := []*types.NodeInfo{
+		node("node-1", "us-east-1b", "4", false, types.Device{ProductName: "L4", TotalCount: 4, AvailableCount: 1}),
+		node("node-2", "us-east-1a", "-2", false, types.Device{ProductName: "H100", TotalCount: 8, AvailableCount: 8}),
+		node("node-3", "us-east-1b", "6", true, types.Device{ProductName: "L4", TotalCount: 4, AvailableCount: 4, UnhealthyCount: 1}),
+		node("node-4", "", "8", false),
+	}
+
+	got := GroupByLabel(nodes, zone, true)
+
+	expected := []types.NodeGroup{
+		{
+			Value:        "us-east-1a",
+			Nodes:        1,
+			NodeCapacity: types.NodeCapacity{TotalCPU: resource.MustParse("8")},
+			Devices:      []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 8}},
+		},
+		{
+			Value:        "us-east-1b",
+			Nodes:        2,
+			NodeCapacity: types.NodeCapacity{TotalCPU: resource.MustParse("16"), AvailableCPU: resource.MustParse("10")},
+			Devices:      []types.Device{{ProductName: "L4", TotalCount: 8, AvailableCount: 1, UnhealthyCount: 1}},
+		},
+		{
+			Value:        NoLabelValue,
+			Nodes:        1,
+			NodeCapacity: types.NodeCapacity{TotalCPU: resource.MustParse("8"), AvailableCPU: resource.MustParse("8")},
+			Devices:      []types.Device{},
+		},
+	}
+	quantityComparer := cmp.Comparer(func(x, y resource.Quantity) bool { return x.Equal(y) })
+	if diff := cmp.Diff(got, expected, quantityComparer); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGroupByShape(t *testing.T) {
+	node := func(name, role, instanceType string, devices ...types.Device) *types.NodeInfo {
+		return &types.NodeInfo{NodeName: name, NodeRole: role, InstanceType: instanceType, Devices: devices}
+	}
+	nodes := []*types.NodeInfo{
+		node("gpu-1", "worker", "p5.48xlarge", types.Device{ProductName: "H100", TotalCount: 8, AvailableCount: 2}),
+		node("gpu-2", "worker", "p5.48xlarge", types.Device{ProductName: "H100", TotalCount: 8, AvailableCount: 8}),
+		// a GPU missing from the slices makes a different shape
+		node("gpu-3", "worker", "p5.48xlarge", types.Device{ProductName: "H100", TotalCount: 7, AvailableCount: 7}),
+		node("cpu-1", "worker", "m5.large"),
+		node("cpu-2", "worker", "m5.large"),
+		node("control-plane", "control-plane", ""),
+	}
+
+	var got []string
+	for _, group := range GroupByShape(nodes, false) {
+		got = append(got, fmt.Sprintf("%d %s", group.Nodes, group.Value))
+	}
+
+	expected := []string{
+		"1 control-plane, <none>, no devices",
+		"2 worker, m5.large, no devices",
+		"1 worker, p5.48xlarge, 7x H100",
+		"2 worker, p5.48xlarge, 8x H100",
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,48 @@
+package analyze
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// LongHeld returns the allocated claims created more than minAge ago, grouped
+// by groupOf, the groups holding the most devices first and the oldest
+// claims first within a group. Claims record no allocation time, so the
+// creation time is used; claims allocated long after their creation count as
+// held for longer than they were.
+func LongHeld(claims []*types.ClaimInfo, now time.Time, minAge time.Duration, groupOf func(*types.ClaimInfo) string) []types.HeldClaimGroup {
+	groups := make(map[string]*types.HeldClaimGroup)
+	for _, claim := range claims {
+		if !claim.Allocated || claim.Created.IsZero() || now.Sub(claim.Created) < minAge {
+			continue
+		}
+		name := groupOf(claim)
+		if name == "" {
+			name = NoLabelValue
+		}
+		group, ok := groups[name]
+		if !ok {
+			group = &types.HeldClaimGroup{Group: name}
+			groups[name] = group
+		}
+		group.Claims = append(group.Claims, claim)
+		group.Devices += len(claim.Devices)
+	}
+
+	result := make([]types.HeldClaimGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.SliceStable(group.Claims, func(i, j int) bool {
+			return group.Claims[i].Created.Before(group.Claims[j].Created)
+		})
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Devices != result[j].Devices {
+			return result[i].Devices > result[j].Devices
+		}
+		return result[i].Group < result[j].Group
+	})
+	return result
+}
@@ -0,0 +1,41 @@
+package analyze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLongHeld(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	claim := func(namespace, name string, age time.Duration, devices ...string) *types.ClaimInfo {
+		return &types.ClaimInfo{
+			Namespace: namespace,
+			Name:      name,
+			Allocated: len(devices) > 0,
+			Devices:   devices,
+			Created:   now.Add(-age),
+		}
+	}
+	recent := claim("ml", "recent", time.Hour, "gpu.nvidia.com/node-1/gpu-0")
+	old := claim("ml", "old", 72*time.Hour, "gpu.nvidia.com/node-1/gpu-1")
+	older := claim("ml", "older", 96*time.Hour, "gpu.nvidia.com/node-1/gpu-2")
+	pending := claim("ml", "pending", 96*time.Hour)
+	big := claim("vision", "big", 48*time.Hour, "gpu.nvidia.com/node-2/gpu-0", "gpu.nvidia.com/node-2/gpu-1", "gpu.nvidia.com/node-2/gpu-2")
+	unlabelled := claim("scratch", "debug", 30*time.Hour, "gpu.nvidia.com/node-3/gpu-0")
+	teams := map[string]string{"ml": "research", "vision": "research"}
+
+	got := LongHeld([]*types.ClaimInfo{recent, old, older, pending, big, unlabelled}, now, 24*time.Hour, func(c *types.ClaimInfo) string {
+		return teams[c.Namespace]
+	})
+
+	expected := []types.HeldClaimGroup{
+		{Group: "research", Claims: []*types.ClaimInfo{older, old, big}, Devices: 5},
+		{Group: NoLabelValue, Claims: []*types.ClaimInfo{unlabelled}, Devices: 1},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,64 @@
+package analyze
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// NodeImpact previews what draining the named node would disrupt: the claims
+// holding its devices, the device-consuming pods that would be evicted, and
+// per product how much capacity the cluster loses and whether the displaced
+// allocations could be placed on other schedulable nodes.
+func NodeImpact(nodes []*types.NodeInfo, nodeName string) (*types.NodeImpact, error) {
+	var target *types.NodeInfo
+	for _, node := range nodes {
+		if node.NodeName == nodeName {
+			target = node
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+
+	impact := &types.NodeImpact{
+		NodeName: target.NodeName,
+		Claims:   target.AllocatedClaims,
+		Pods:     target.DeviceConsumers,
+	}
+
+	products := make(map[string]*types.ProductImpact)
+	for _, dev := range target.Devices {
+		p, ok := products[dev.ProductName]
+		if !ok {
+			p = &types.ProductImpact{ProductName: dev.ProductName}
+			products[dev.ProductName] = p
+		}
+		p.LostDevices += dev.TotalCount
+		p.DisplacedDevices += dev.TotalCount - dev.AvailableCount
+	}
+
+	for _, node := range nodes {
+		for _, dev := range node.Devices {
+			p, ok := products[dev.ProductName]
+			if !ok {
+				continue
+			}
+			p.ClusterTotal += dev.TotalCount
+			if node != target && node.Schedulable() {
+				p.AvailableElsewhere += dev.AvailableCount
+			}
+		}
+	}
+
+	for _, p := range products {
+		impact.Products = append(impact.Products, *p)
+	}
+	sort.Slice(impact.Products, func(i, j int) bool {
+		return impact.Products[i].ProductName < impact.Products[j].ProductName
+	})
+
+	return impact, nil
+}
@@ -0,0 +1,52 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNodeImpact(t *testing.T) {
+	nodes := []*types.NodeInfo{
+		{
+			NodeName:        "node-1",
+			Devices:         []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 1}},
+			DeviceConsumers: []string{"default/train"},
+			AllocatedClaims: []string{"default/train-gpu"},
+		},
+		{
+			NodeName: "node-2",
+			Devices:  []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 2}},
+		},
+		{
+			NodeName:      "node-3",
+			Unschedulable: true,
+			Devices:       []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 4}},
+		},
+	}
+
+	got, err := NodeImpact(nodes, "node-1")
+	if err != nil {
+		t.Fatalf("NodeImpact() error = %v", err)
+	}
+
+	expected := &types.NodeImpact{
+		NodeName: "node-1",
+		Claims:   []string{"default/train-gpu"},
+		Pods:     []string{"default/train"},
+		Products: []types.ProductImpact{
+			{ProductName: "gpu", LostDevices: 4, DisplacedDevices: 3, ClusterTotal: 12, AvailableElsewhere: 2},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	if got.Products[0].Fits() {
+		t.Errorf("expected displaced devices not to fit elsewhere")
+	}
+
+	if _, err := NodeImpact(nodes, "missing"); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+}
@@ -0,0 +1,53 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// ProductInventory groups devices by driver and product. Products with the
+// fewest available devices come first, so scarce SKUs top the list.
+func ProductInventory(devices []types.DeviceInfo) []types.ProductInventory {
+	type key struct{ driver, product string }
+	byProduct := make(map[key]*types.ProductInventory)
+	nodes := make(map[key]map[string]bool)
+	for _, dev := range devices {
+		k := key{dev.Driver, dev.ProductName}
+		inv, ok := byProduct[k]
+		if !ok {
+			inv = &types.ProductInventory{Driver: dev.Driver, ProductName: dev.ProductName}
+			byProduct[k] = inv
+			nodes[k] = make(map[string]bool)
+		}
+		inv.TotalCount++
+		switch {
+		case dev.Unhealthy:
+			inv.UnhealthyCount++
+		case dev.Claim != "":
+			inv.AllocatedCount++
+		default:
+			inv.AvailableCount++
+		}
+		if dev.NodeName != "" {
+			nodes[k][dev.NodeName] = true
+		}
+	}
+
+	inventory := make([]types.ProductInventory, 0, len(byProduct))
+	for k, inv := range byProduct {
+		inv.Nodes = len(nodes[k])
+		inventory = append(inventory, *inv)
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.AvailableCount != b.AvailableCount {
+			return a.AvailableCount < b.AvailableCount
+		}
+		if a.Driver != b.Driver {
+			return a.Driver < b.Driver
+		}
+		return a.ProductName < b.ProductName
+	})
+	return inventory
+}
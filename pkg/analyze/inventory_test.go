@@ -0,0 +1,31 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProductInventory(t *testing.T) {
+	devices := []types.DeviceInfo{
+		{NodeName: "node-1", Driver: "gpu.nvidia.com", Name: "gpu-0", ProductName: "L4"},
+		{NodeName: "node-1", Driver: "gpu.nvidia.com", Name: "gpu-1", ProductName: "L4"},
+		{NodeName: "node-2", Driver: "gpu.nvidia.com", Name: "gpu-0", ProductName: "L4", Claim: "default/a"},
+		{NodeName: "node-3", Driver: "gpu.nvidia.com", Name: "gpu-0", ProductName: "H100", Claim: "default/b"},
+		{NodeName: "node-3", Driver: "gpu.nvidia.com", Name: "gpu-1", ProductName: "H100", Unhealthy: true},
+		{NodeName: "node-3", Driver: "gpu.nvidia.com", Name: "gpu-2", ProductName: "H100"},
+		{NodeName: "node-1", Driver: "net.example.com", Name: "nic-0", ProductName: "net.example.com"},
+	}
+
+	got := ProductInventory(devices)
+
+	expected := []types.ProductInventory{
+		{Driver: "gpu.nvidia.com", ProductName: "H100", Nodes: 1, TotalCount: 3, AllocatedCount: 1, UnhealthyCount: 1, AvailableCount: 1},
+		{Driver: "net.example.com", ProductName: "net.example.com", Nodes: 1, TotalCount: 1, AvailableCount: 1},
+		{Driver: "gpu.nvidia.com", ProductName: "L4", Nodes: 2, TotalCount: 3, AllocatedCount: 1, AvailableCount: 2},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
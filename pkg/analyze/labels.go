@@ -0,0 +1,100 @@
+package analyze
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Labels NVIDIA GPU Feature Discovery puts on GPU nodes.
+const (
+	GPUProductLabel = "nvidia.com/gpu.product"
+	GPUCountLabel   = "nvidia.com/gpu.count"
+)
+
+// gpuLabelDriver is the DRA driver publishing the GPUs described by the GPU
+// Feature Discovery labels.
+const gpuLabelDriver = "gpu.nvidia.com"
+
+// GPULabelMismatches cross-checks the GPU labels of each node against the
+// GPUs the driver publishes in ResourceSlices, and returns one mismatch per
+// disagreeing label sorted by node and label. Labels disagreeing with the
+// slices usually mean a mislabeled node, a stale label after a hardware
+// change, or a driver that failed to publish some of the GPUs. Nodes without
+// GPU labels are skipped, as GPU Feature Discovery need not be deployed; MIG
+// devices are not counted, as the labels describe full GPUs.
+func GPULabelMismatches(nodes []*types.NodeInfo, devices []types.DeviceInfo) []types.LabelMismatch {
+	published := make(map[string][]types.DeviceInfo)
+	for _, dev := range devices {
+		if dev.Driver != gpuLabelDriver || dev.NodeName == "" {
+			continue
+		}
+		if deviceType, ok := dev.Attributes["type"]; ok && deviceType != "gpu" {
+			continue
+		}
+		published[dev.NodeName] = append(published[dev.NodeName], dev)
+	}
+
+	var mismatches []types.LabelMismatch
+	for _, node := range nodes {
+		gpus := published[node.NodeName]
+		if count, ok := node.Labels[GPUCountLabel]; ok {
+			if n, err := strconv.Atoi(count); err != nil || n != len(gpus) {
+				mismatches = append(mismatches, types.LabelMismatch{
+					NodeName:  node.NodeName,
+					Label:     GPUCountLabel,
+					Value:     count,
+					Published: strconv.Itoa(len(gpus)),
+				})
+			}
+		}
+		if product, ok := node.Labels[GPUProductLabel]; ok {
+			products := gpuProducts(gpus)
+			if !labelMatchesProduct(product, products) {
+				mismatches = append(mismatches, types.LabelMismatch{
+					NodeName:  node.NodeName,
+					Label:     GPUProductLabel,
+					Value:     product,
+					Published: strings.Join(products, ", "),
+				})
+			}
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].NodeName != mismatches[j].NodeName {
+			return mismatches[i].NodeName < mismatches[j].NodeName
+		}
+		return mismatches[i].Label < mismatches[j].Label
+	})
+	return mismatches
+}
+
+// gpuProducts returns the distinct product names of the GPUs, sorted.
+func gpuProducts(gpus []types.DeviceInfo) []string {
+	seen := make(map[string]bool)
+	var products []string
+	for _, gpu := range gpus {
+		if gpu.ProductName != "" && !seen[gpu.ProductName] {
+			seen[gpu.ProductName] = true
+			products = append(products, gpu.ProductName)
+		}
+	}
+	sort.Strings(products)
+	return products
+}
+
+// labelMatchesProduct reports whether the product label names one of the
+// published products. GPU Feature Discovery replaces spaces with dashes and
+// may append suffixes such as -SHARED for time-sliced GPUs or the MIG
+// profile, so the label only has to start with the product.
+func labelMatchesProduct(label string, products []string) bool {
+	for _, product := range products {
+		if strings.HasPrefix(label, strings.ReplaceAll(product, " ", "-")) {
+			return true
+		}
+	}
+	return false
+}
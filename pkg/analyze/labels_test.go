@@ -0,0 +1,69 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGPULabelMismatches(t *testing.T) {
+	gpu := func(nodeName, name, product string) types.DeviceInfo {
+		return types.DeviceInfo{
+			NodeName:    nodeName,
+			Driver:      "gpu.nvidia.com",
+			Pool:        nodeName,
+			Name:        name,
+			ProductName: product,
+			Attributes:  map[string]string{"type": "gpu"},
+		}
+	}
+	mig := types.DeviceInfo{
+		NodeName:    "node-1",
+		Driver:      "gpu.nvidia.com",
+		Pool:        "node-1",
+		Name:        "gpu-0-mig-1g-10gb-0",
+		ProductName: "NVIDIA A100-SXM4-80GB",
+		Attributes:  map[string]string{"type": "mig"},
+	}
+	nic := types.DeviceInfo{NodeName: "node-2", Driver: "net.example.com", Pool: "node-2", Name: "nic-0"}
+	devices := []types.DeviceInfo{
+		gpu("node-1", "gpu-0", "NVIDIA A100-SXM4-80GB"),
+		gpu("node-1", "gpu-1", "NVIDIA A100-SXM4-80GB"),
+		mig,
+		gpu("node-2", "gpu-0", "NVIDIA H100 80GB HBM3"),
+		nic,
+		gpu("node-4", "gpu-0", "NVIDIA L4"),
+	}
+	nodes := []*types.NodeInfo{
+		// matches, time-sliced
+		{NodeName: "node-1", Labels: map[string]string{
+			GPUProductLabel: "NVIDIA-A100-SXM4-80GB-SHARED",
+			GPUCountLabel:   "2",
+		}},
+		// relabeled after a hardware swap
+		{NodeName: "node-2", Labels: map[string]string{
+			GPUProductLabel: "NVIDIA-A100-SXM4-80GB",
+			GPUCountLabel:   "8",
+		}},
+		// labeled, but the driver publishes nothing
+		{NodeName: "node-3", Labels: map[string]string{
+			GPUProductLabel: "NVIDIA-L4",
+			GPUCountLabel:   "1",
+		}},
+		// no GPU Feature Discovery
+		{NodeName: "node-4"},
+	}
+
+	got := GPULabelMismatches(nodes, devices)
+
+	want := []types.LabelMismatch{
+		{NodeName: "node-2", Label: GPUCountLabel, Value: "8", Published: "1"},
+		{NodeName: "node-2", Label: GPUProductLabel, Value: "NVIDIA-A100-SXM4-80GB", Published: "NVIDIA H100 80GB HBM3"},
+		{NodeName: "node-3", Label: GPUCountLabel, Value: "1", Published: "0"},
+		{NodeName: "node-3", Label: GPUProductLabel, Value: "NVIDIA-L4", Published: ""},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,39 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// ZoneLabel is the well-known label holding a node's availability zone.
+const ZoneLabel = "topology.kubernetes.io/zone"
+
+// AvailabilityMatrix pivots the devices of the nodes into a table with one
+// row per product and one column per value of the label, e.g. ZoneLabel.
+// Nodes without the label form a NoLabelValue column.
+func AvailabilityMatrix(nodes []*types.NodeInfo, label string, excludeUnschedulable bool) types.AvailabilityMatrix {
+	groups := GroupByLabel(nodes, label, excludeUnschedulable)
+
+	matrix := types.AvailabilityMatrix{Label: label}
+	rows := make(map[string]*types.MatrixRow)
+	for i, group := range groups {
+		matrix.Columns = append(matrix.Columns, group.Value)
+		for _, dev := range group.Devices {
+			row, ok := rows[dev.ProductName]
+			if !ok {
+				row = &types.MatrixRow{ProductName: dev.ProductName, Cells: make([]types.DeviceCount, len(groups))}
+				rows[dev.ProductName] = row
+			}
+			row.Cells[i] = types.DeviceCount{TotalCount: dev.TotalCount, AvailableCount: dev.AvailableCount}
+		}
+	}
+
+	for _, row := range rows {
+		matrix.Rows = append(matrix.Rows, *row)
+	}
+	sort.Slice(matrix.Rows, func(i, j int) bool {
+		return matrix.Rows[i].ProductName < matrix.Rows[j].ProductName
+	})
+	return matrix
+}
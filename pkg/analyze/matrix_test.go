@@ -0,0 +1,42 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAvailabilityMatrix(t *testing.T) {
+	nodes := []*types.NodeInfo{
+		{
+			NodeName: "node-1",
+			Labels:   map[string]string{ZoneLabel: "us-east-1a"},
+			Devices:  []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 2}},
+		},
+		{
+			NodeName: "node-2",
+			Labels:   map[string]string{ZoneLabel: "us-east-1a"},
+			Devices:  []types.Device{{ProductName: "L4", TotalCount: 4, AvailableCount: 4}},
+		},
+		{
+			NodeName: "node-3",
+			Labels:   map[string]string{ZoneLabel: "us-east-1b"},
+			Devices:  []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 0}},
+		},
+	}
+
+	got := AvailabilityMatrix(nodes, ZoneLabel, false)
+
+	expected := types.AvailabilityMatrix{
+		Label:   ZoneLabel,
+		Columns: []string{"us-east-1a", "us-east-1b"},
+		Rows: []types.MatrixRow{
+			{ProductName: "H100", Cells: []types.DeviceCount{{TotalCount: 8, AvailableCount: 2}, {TotalCount: 8}}},
+			{ProductName: "L4", Cells: []types.DeviceCount{{TotalCount: 4, AvailableCount: 4}, {}}},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,27 @@
+package analyze
+
+import "github.com/dharmjit/k8s-dra-resources/pkg/types"
+
+// FilterOS returns the nodes running the operating system os, e.g. linux.
+// Nodes whose operating system is unknown are kept.
+func FilterOS(nodes []*types.NodeInfo, os string) []*types.NodeInfo {
+	var result []*types.NodeInfo
+	for _, node := range nodes {
+		if node.OS == "" || node.OS == os {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// FilterArch returns the nodes with the CPU architecture arch, e.g. arm64.
+// Nodes whose architecture is unknown are kept.
+func FilterArch(nodes []*types.NodeInfo, arch string) []*types.NodeInfo {
+	var result []*types.NodeInfo
+	for _, node := range nodes {
+		if node.Arch == "" || node.Arch == arch {
+			result = append(result, node)
+		}
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterOS(t *testing.T) {
+	linux := &types.NodeInfo{NodeName: "node-1", OS: "linux"}
+	windows := &types.NodeInfo{NodeName: "node-2", OS: "windows"}
+	unknown := &types.NodeInfo{NodeName: "node-3"}
+
+	got := FilterOS([]*types.NodeInfo{linux, windows, unknown}, "linux")
+
+	if diff := cmp.Diff(got, []*types.NodeInfo{linux, unknown}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestFilterArch(t *testing.T) {
+	amd64 := &types.NodeInfo{NodeName: "node-1", Arch: "amd64"}
+	arm64 := &types.NodeInfo{NodeName: "node-2", Arch: "arm64"}
+	unknown := &types.NodeInfo{NodeName: "node-3"}
+
+	got := FilterArch([]*types.NodeInfo{amd64, arm64, unknown}, "arm64")
+
+	if diff := cmp.Diff(got, []*types.NodeInfo{arm64, unknown}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,87 @@
+package analyze
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// PluginPrefix is the executable name prefix of external analyzer plugins.
+// A plugin named dra-resources-analyze-foo is run by "analyze foo".
+const PluginPrefix = "dra-resources-analyze-"
+
+// Plugin is an external analyzer found on PATH.
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// FindPlugins returns the analyzer plugins on PATH. When several directories
+// contain a plugin of the same name, the first one wins, as in shell lookup.
+func FindPlugins() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), PluginPrefix)
+			if !ok || name == "" || seen[name] || entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.Mode()&0o111 == 0 {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, entry.Name())})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// FindPlugin looks up a single analyzer plugin by name.
+func FindPlugin(name string) (Plugin, bool) {
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return Plugin{}, false
+	}
+	return Plugin{Name: name, Path: path}, true
+}
+
+// Run executes the plugin with the snapshot as JSON on stdin and decodes the
+// report it prints on stdout. Extra arguments are passed through unchanged.
+func (p Plugin) Run(ctx context.Context, snapshot any, args []string) (*types.Report, error) {
+	input, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("analyzer plugin %s failed: %w", p.Name, err)
+	}
+
+	var report types.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report of analyzer plugin %s: %w", p.Name, err)
+	}
+	return &report, nil
+}
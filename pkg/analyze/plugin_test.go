@@ -0,0 +1,54 @@
+package analyze
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+nodes=$(grep -o '"nodeName"' | wc -l | tr -d ' ')
+echo "{\"sections\":[{\"title\":\"count\",\"text\":\"$nodes nodes, args $*\"}]}"
+`
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"count"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"not-executable"), []byte(script), 0o644); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	plugins := FindPlugins()
+	if len(plugins) == 0 || plugins[0].Name != "count" || plugins[0].Path != filepath.Join(dir, PluginPrefix+"count") {
+		t.Fatalf("expected the count plugin first, got %v", plugins)
+	}
+	for _, plugin := range plugins {
+		if plugin.Name == "not-executable" {
+			t.Errorf("expected non-executable files to be skipped")
+		}
+	}
+
+	plugin, ok := FindPlugin("count")
+	if !ok {
+		t.Fatalf("FindPlugin() did not find the count plugin")
+	}
+
+	snapshot := []*types.NodeInfo{{NodeName: "node-1"}, {NodeName: "node-2"}}
+	got, err := plugin.Run(context.Background(), snapshot, []string{"--strict"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	expected := &types.Report{
+		Sections: []types.ReportSection{{Title: "count", Text: "2 nodes, args --strict"}},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,60 @@
+package analyze
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// States of a reservation.
+const (
+	ReservationExpired  = "expired"
+	ReservationExpiring = "expiring"
+	ReservationActive   = "active"
+	ReservationInvalid  = "invalid"
+)
+
+// Reservations returns the reservations that expired while their claim still
+// holds devices, that expire within the given window, or whose expiry is
+// invalid, soonest expiry first. With all set, expired reservations without
+// devices and those expiring later, or never, are included too.
+func Reservations(claims []*types.ClaimInfo, now time.Time, within time.Duration, all bool) []types.ReservationStatus {
+	var result []types.ReservationStatus
+	for _, claim := range claims {
+		if claim.Reservation == nil {
+			continue
+		}
+		r := claim.Reservation
+		state := ReservationActive
+		switch {
+		case r.InvalidExpiry != "":
+			state = ReservationInvalid
+		case r.Expires.IsZero():
+		case !r.Expires.After(now):
+			state = ReservationExpired
+		case r.Expires.Sub(now) <= within:
+			state = ReservationExpiring
+		}
+		if !all && (state == ReservationActive || state == ReservationExpired && len(claim.Devices) == 0) {
+			continue
+		}
+		result = append(result, types.ReservationStatus{
+			Namespace:   claim.Namespace,
+			Name:        claim.Name,
+			Reservation: *r,
+			State:       state,
+			Devices:     claim.Devices,
+		})
+	}
+
+	// reservations without a valid expiry go last
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].Reservation.Expires, result[j].Reservation.Expires
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return result
+}
@@ -0,0 +1,59 @@
+package analyze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReservations(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	reserved := func(name string, expires time.Time, devices ...string) *types.ClaimInfo {
+		return &types.ClaimInfo{
+			Namespace:   "ml",
+			Name:        name,
+			Allocated:   len(devices) > 0,
+			Devices:     devices,
+			Reservation: &types.Reservation{Team: "research", Expires: expires},
+		}
+	}
+	claims := []*types.ClaimInfo{
+		{Namespace: "ml", Name: "unreserved", Devices: []string{"gpu.nvidia.com/node-1/gpu-0"}},
+		reserved("later", now.Add(30*24*time.Hour), "gpu.nvidia.com/node-1/gpu-1"),
+		reserved("soon", now.Add(48*time.Hour), "gpu.nvidia.com/node-1/gpu-2"),
+		reserved("overdue", now.Add(-time.Hour), "gpu.nvidia.com/node-1/gpu-3"),
+		reserved("released", now.Add(-time.Hour)),
+		reserved("open-ended", time.Time{}),
+		{Namespace: "ml", Name: "typo", Reservation: &types.Reservation{InvalidExpiry: "next friday"}},
+	}
+
+	tests := []struct {
+		name     string
+		all      bool
+		expected []string
+	}{
+		{
+			name:     "attention needed",
+			expected: []string{"overdue/expired", "soon/expiring", "typo/invalid"},
+		},
+		{
+			name:     "all",
+			all:      true,
+			expected: []string{"overdue/expired", "released/expired", "soon/expiring", "later/active", "open-ended/active", "typo/invalid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range Reservations(claims, now, 7*24*time.Hour, tt.all) {
+				got = append(got, r.Name+"/"+r.State)
+			}
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package analyze
+
+import "github.com/dharmjit/k8s-dra-resources/pkg/types"
+
+// ExcludeSpot returns the nodes that are not on spot or preemptible capacity.
+func ExcludeSpot(nodes []*types.NodeInfo) []*types.NodeInfo {
+	var result []*types.NodeInfo
+	for _, node := range nodes {
+		if !node.Spot {
+			result = append(result, node)
+		}
+	}
+	return result
+}
@@ -0,0 +1,19 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExcludeSpot(t *testing.T) {
+	onDemand := &types.NodeInfo{NodeName: "node-1"}
+	spot := &types.NodeInfo{NodeName: "node-2", Spot: true}
+
+	got := ExcludeSpot([]*types.NodeInfo{onDemand, spot})
+
+	if diff := cmp.Diff(got, []*types.NodeInfo{onDemand}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
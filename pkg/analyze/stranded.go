@@ -0,0 +1,158 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// strandedFraction is the share of a node's allocatable CPU or memory below
+// which no pod of a typical size fits anymore.
+const strandedFraction = 0.05
+
+// StrandedDevices returns the number of free devices on a schedulable node
+// whose CPU or memory is effectively exhausted, so no pod can be placed to
+// use them. Such devices are paid for but wasted, typically because CPU-only
+// pods filled up a GPU node.
+func StrandedDevices(node *types.NodeInfo) int {
+	if !node.Schedulable() {
+		return 0
+	}
+	capacity := node.NodeCapacity
+	if !exhausted(capacity.TotalCPU, capacity.AvailableCPU) && !exhausted(capacity.TotalMemory, capacity.AvailableMemory) {
+		return 0
+	}
+	stranded := 0
+	for _, dev := range node.Devices {
+		stranded += dev.AvailableCount
+	}
+	return stranded
+}
+
+// StrandedSummary returns how many nodes have stranded devices, and how many
+// devices are stranded in total.
+func StrandedSummary(nodes []*types.NodeInfo) (strandedNodes, strandedDevices int) {
+	for _, node := range nodes {
+		if n := StrandedDevices(node); n > 0 {
+			strandedNodes++
+			strandedDevices += n
+		}
+	}
+	return strandedNodes, strandedDevices
+}
+
+func exhausted(total, available resource.Quantity) bool {
+	if total.Sign() <= 0 {
+		return false
+	}
+	return available.AsApproximateFloat64() < strandedFraction*total.AsApproximateFloat64()
+}
+
+// PodShape is the reference pod Stranded tries to place on nodes with free
+// devices.
+type PodShape struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+	// Devices is the number of devices of one product the pod claims.
+	Devices int
+	// Products restricts the devices to these products, each a product name
+	// or a prefix of it; any product if empty.
+	Products []string
+}
+
+// claims reports whether the pod can claim devices of the product.
+func (s PodShape) claims(product string) bool {
+	if len(s.Products) == 0 {
+		return true
+	}
+	for _, prefix := range s.Products {
+		if strings.HasPrefix(product, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stranded returns, per schedulable node and product, the free devices that
+// pods of the reference shape cannot use because fewer of them fit into the
+// node's available CPU and memory than the free devices would serve. They are
+// sorted by the number of stranded devices, most first, then by node and
+// product.
+func Stranded(nodes []*types.NodeInfo, shape PodShape) []types.StrandedDevices {
+	var result []types.StrandedDevices
+	for _, node := range nodes {
+		if !node.Schedulable() {
+			continue
+		}
+		capacity := node.NodeCapacity
+		fits, limitedBy := math.MaxInt, ""
+		if n := podsFitting(capacity.AvailableCPU, shape.CPU); n < fits {
+			fits, limitedBy = n, "cpu"
+		}
+		if n := podsFitting(capacity.AvailableMemory, shape.Memory); n < fits {
+			fits, limitedBy = n, "memory"
+		}
+		for _, dev := range node.Devices {
+			if !shape.claims(dev.ProductName) {
+				continue
+			}
+			usable := dev.AvailableCount
+			if fits < usable {
+				usable = min(usable, fits*max(shape.Devices, 1))
+			}
+			if usable == dev.AvailableCount {
+				continue
+			}
+			result = append(result, types.StrandedDevices{
+				NodeName:        node.NodeName,
+				ProductName:     dev.ProductName,
+				FreeDevices:     dev.AvailableCount,
+				StrandedDevices: dev.AvailableCount - usable,
+				FittingPods:     fits,
+				LimitedBy:       limitedBy,
+				AvailableCPU:    capacity.AvailableCPU,
+				AvailableMemory: capacity.AvailableMemory,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.StrandedDevices != b.StrandedDevices {
+			return a.StrandedDevices > b.StrandedDevices
+		}
+		if a.NodeName != b.NodeName {
+			return a.NodeName < b.NodeName
+		}
+		return a.ProductName < b.ProductName
+	})
+	return result
+}
+
+// podsFitting returns how many requests of request fit into available, or
+// math.MaxInt if nothing is requested.
+func podsFitting(available, request resource.Quantity) int {
+	if request.Sign() <= 0 {
+		return math.MaxInt
+	}
+	if available.Sign() <= 0 {
+		return 0
+	}
+	return int(available.MilliValue() / request.MilliValue())
+}
+
+// String describes the shape, e.g. "4 CPU, 16Gi memory, 1 device".
+func (s PodShape) String() string {
+	devices := "devices"
+	if s.Devices == 1 {
+		devices = "device"
+	}
+	shape := fmt.Sprintf("%s CPU, %s memory, %d %s", s.CPU.String(), s.Memory.String(), s.Devices, devices)
+	if len(s.Products) > 0 {
+		shape += " of " + strings.Join(s.Products, " or ")
+	}
+	return shape
+}
@@ -0,0 +1,101 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestStrandedSummary(t *testing.T) {
+	node := func(availableCPU, availableMemory string, unschedulable bool, available int) *types.NodeInfo {
+		return &types.NodeInfo{
+			Unschedulable: unschedulable,
+			NodeCapacity: types.NodeCapacity{
+				TotalCPU:        resource.MustParse("64"),
+				AvailableCPU:    resource.MustParse(availableCPU),
+				TotalMemory:     resource.MustParse("512Gi"),
+				AvailableMemory: resource.MustParse(availableMemory),
+			},
+			Devices: []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: available}},
+		}
+	}
+	nodes := []*types.NodeInfo{
+		// out of CPU with free GPUs
+		node("500m", "200Gi", false, 4),
+		// out of memory, overcommitted
+		node("32", "-2Gi", false, 2),
+		// plenty left
+		node("32", "200Gi", false, 4),
+		// out of CPU, but every GPU is allocated
+		node("0", "200Gi", false, 0),
+		// cordoned nodes are unusable for other reasons
+		node("0", "0", true, 8),
+	}
+
+	strandedNodes, strandedDevices := StrandedSummary(nodes)
+	if strandedNodes != 2 || strandedDevices != 6 {
+		t.Errorf("StrandedSummary() = %d nodes, %d devices, want 2 nodes, 6 devices", strandedNodes, strandedDevices)
+	}
+}
+
+func TestStranded(t *testing.T) {
+	node := func(name, availableCPU, availableMemory string, devices ...types.Device) *types.NodeInfo {
+		return &types.NodeInfo{
+			NodeName: name,
+			NodeCapacity: types.NodeCapacity{
+				TotalCPU:        resource.MustParse("64"),
+				AvailableCPU:    resource.MustParse(availableCPU),
+				TotalMemory:     resource.MustParse("512Gi"),
+				AvailableMemory: resource.MustParse(availableMemory),
+			},
+			Devices: devices,
+		}
+	}
+	nodes := []*types.NodeInfo{
+		// fits one pod of two GPUs, leaving two GPUs stranded
+		node("node-1", "10", "400Gi", types.Device{ProductName: "H100", TotalCount: 8, AvailableCount: 4}),
+		// memory is the limit, fitting no pod
+		node("node-2", "60", "24Gi", types.Device{ProductName: "L4", TotalCount: 4, AvailableCount: 4}),
+		// enough room for every free device
+		node("node-3", "64", "512Gi", types.Device{ProductName: "H100", TotalCount: 8, AvailableCount: 8}),
+	}
+	shape := PodShape{CPU: resource.MustParse("8"), Memory: resource.MustParse("32Gi"), Devices: 2}
+
+	got := Stranded(nodes, shape)
+
+	expected := []types.StrandedDevices{
+		{NodeName: "node-2", ProductName: "L4", FreeDevices: 4, StrandedDevices: 4, LimitedBy: "memory", AvailableCPU: resource.MustParse("60"), AvailableMemory: resource.MustParse("24Gi")},
+		{NodeName: "node-1", ProductName: "H100", FreeDevices: 4, StrandedDevices: 2, FittingPods: 1, LimitedBy: "cpu", AvailableCPU: resource.MustParse("10"), AvailableMemory: resource.MustParse("400Gi")},
+	}
+	quantityComparer := cmp.Comparer(func(x, y resource.Quantity) bool { return x.Equal(y) })
+	if diff := cmp.Diff(got, expected, quantityComparer); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestStrandedProducts(t *testing.T) {
+	nodes := []*types.NodeInfo{{
+		NodeName: "node-1",
+		NodeCapacity: types.NodeCapacity{
+			TotalCPU:        resource.MustParse("64"),
+			AvailableCPU:    resource.MustParse("1"),
+			TotalMemory:     resource.MustParse("512Gi"),
+			AvailableMemory: resource.MustParse("512Gi"),
+		},
+		Devices: []types.Device{
+			{ProductName: "NVIDIA H100 80GB HBM3", TotalCount: 8, AvailableCount: 4},
+			{ProductName: "net.example.com", TotalCount: 2, AvailableCount: 2},
+		},
+	}}
+	shape := PodShape{CPU: resource.MustParse("8"), Devices: 1, Products: []string{"NVIDIA H100"}}
+
+	var got []string
+	for _, s := range Stranded(nodes, shape) {
+		got = append(got, s.ProductName)
+	}
+	if diff := cmp.Diff(got, []string{"NVIDIA H100 80GB HBM3"}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
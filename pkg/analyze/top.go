@@ -0,0 +1,59 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Orders of TopNodes.
+const (
+	SortByAllocation  = "allocation"
+	SortByUtilization = "utilization"
+)
+
+// TopNodes returns the busiest nodes with devices, at most limit of them when
+// limit is positive. Nodes are ranked by the percentage of their devices
+// allocated, or with SortByUtilization by the utilization of their devices,
+// nodes without utilization metrics last. utilization is keyed by node name
+// and may be nil.
+func TopNodes(nodes []*types.NodeInfo, utilization map[string]float64, sortBy string, limit int) []types.NodeUsage {
+	var usage []types.NodeUsage
+	for _, node := range nodes {
+		u := types.NodeUsage{NodeName: node.NodeName}
+		for _, dev := range node.Devices {
+			u.TotalDevices += dev.TotalCount
+			u.AllocatedDevices += dev.TotalCount - dev.AvailableCount
+		}
+		if u.TotalDevices == 0 {
+			continue
+		}
+		u.AllocatedPercent = 100 * float64(u.AllocatedDevices) / float64(u.TotalDevices)
+		if value, ok := utilization[node.NodeName]; ok {
+			u.Utilization = &value
+		}
+		usage = append(usage, u)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if sortBy == SortByUtilization && (a.Utilization == nil) != (b.Utilization == nil) {
+			return a.Utilization != nil
+		}
+		if sortBy == SortByUtilization && a.Utilization != nil && *a.Utilization != *b.Utilization {
+			return *a.Utilization > *b.Utilization
+		}
+		if a.AllocatedPercent != b.AllocatedPercent {
+			return a.AllocatedPercent > b.AllocatedPercent
+		}
+		if a.AllocatedDevices != b.AllocatedDevices {
+			return a.AllocatedDevices > b.AllocatedDevices
+		}
+		return a.NodeName < b.NodeName
+	})
+
+	if limit > 0 && len(usage) > limit {
+		usage = usage[:limit]
+	}
+	return usage
+}
@@ -0,0 +1,66 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTopNodes(t *testing.T) {
+	nodes := []*types.NodeInfo{
+		{NodeName: "cpu-only"},
+		{NodeName: "full", Devices: []types.Device{{ProductName: "gpu", TotalCount: 2, AvailableCount: 0}}},
+		{NodeName: "half", Devices: []types.Device{{ProductName: "gpu", TotalCount: 8, AvailableCount: 4}}},
+		{NodeName: "idle", Devices: []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 4}}},
+		{NodeName: "quarter", Devices: []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 3}}},
+	}
+	utilization := map[string]float64{"half": 95, "quarter": 20, "full": 10}
+	util := func(v float64) *float64 { return &v }
+
+	testCases := []struct {
+		name     string
+		sortBy   string
+		limit    int
+		expected []types.NodeUsage
+	}{
+		{
+			name:   "by allocation",
+			sortBy: SortByAllocation,
+			expected: []types.NodeUsage{
+				{NodeName: "full", TotalDevices: 2, AllocatedDevices: 2, AllocatedPercent: 100, Utilization: util(10)},
+				{NodeName: "half", TotalDevices: 8, AllocatedDevices: 4, AllocatedPercent: 50, Utilization: util(95)},
+				{NodeName: "quarter", TotalDevices: 4, AllocatedDevices: 1, AllocatedPercent: 25, Utilization: util(20)},
+				{NodeName: "idle", TotalDevices: 4},
+			},
+		},
+		{
+			name:   "by utilization with limit",
+			sortBy: SortByUtilization,
+			limit:  2,
+			expected: []types.NodeUsage{
+				{NodeName: "half", TotalDevices: 8, AllocatedDevices: 4, AllocatedPercent: 50, Utilization: util(95)},
+				{NodeName: "quarter", TotalDevices: 4, AllocatedDevices: 1, AllocatedPercent: 25, Utilization: util(20)},
+			},
+		},
+		{
+			name:   "without metrics last",
+			sortBy: SortByUtilization,
+			expected: []types.NodeUsage{
+				{NodeName: "half", TotalDevices: 8, AllocatedDevices: 4, AllocatedPercent: 50, Utilization: util(95)},
+				{NodeName: "quarter", TotalDevices: 4, AllocatedDevices: 1, AllocatedPercent: 25, Utilization: util(20)},
+				{NodeName: "full", TotalDevices: 2, AllocatedDevices: 2, AllocatedPercent: 100, Utilization: util(10)},
+				{NodeName: "idle", TotalDevices: 4},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := TopNodes(nodes, utilization, tc.sortBy, tc.limit)
+			if diff := cmp.Diff(got, tc.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package audit records the changes the tool makes to the cluster, and the
+// changes it would make in dry runs, as JSON lines in a configurable sink.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is the audit record of one mutation.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// User is the local user running the tool, and KubeUser the kubeconfig
+	// user of the current context, if known.
+	User     string `json:"user,omitempty"`
+	KubeUser string `json:"kubeUser,omitempty"`
+	// Command is the subcommand making the change, e.g. "claims cleanup".
+	Command string `json:"command"`
+	// Verb is the API verb, e.g. delete or create.
+	Verb      string `json:"verb"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Details describe the change, e.g. the reason of an Event.
+	Details map[string]string `json:"details,omitempty"`
+	DryRun  bool              `json:"dryRun"`
+	// Error is set if the change failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Logger writes audit entries to a sink. A nil Logger discards entries.
+type Logger struct {
+	// User and KubeUser are recorded in every entry.
+	User     string
+	KubeUser string
+
+	mu    sync.Mutex
+	write func(ctx context.Context, line []byte) error
+	close func() error
+	now   func() time.Time
+}
+
+// Open returns a logger writing to sink: "-" for stderr, an http:// or
+// https:// URL receiving each entry as a POST request, or else the path of a
+// file entries are appended to.
+func Open(sink string) (*Logger, error) {
+	l := &Logger{now: time.Now, close: func() error { return nil }}
+	switch {
+	case sink == "-":
+		l.write = writerSink(os.Stderr)
+	case strings.HasPrefix(sink, "http://") || strings.HasPrefix(sink, "https://"):
+		l.write = httpSink(http.DefaultClient, sink)
+	default:
+		f, err := os.OpenFile(sink, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		l.write, l.close = writerSink(f), f.Close
+	}
+	return l, nil
+}
+
+// Log records an entry, filling in its time and users.
+func (l *Logger) Log(ctx context.Context, e Entry) error {
+	if l == nil {
+		return nil
+	}
+	e.Time = l.now().UTC()
+	e.User, e.KubeUser = l.User, l.KubeUser
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.write(ctx, append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the sink.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.close()
+}
+
+func writerSink(w io.Writer) func(context.Context, []byte) error {
+	return func(_ context.Context, line []byte) error {
+		_, err := w.Write(line)
+		return err
+	}
+}
+
+func httpSink(client *http.Client, url string) func(context.Context, []byte) error {
+	return func(ctx context.Context, line []byte) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(line))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("audit sink returned HTTP %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testEntries = []Entry{
+	{Command: "claims cleanup", Verb: "delete", Resource: "resourceclaims", Namespace: "team-a", Name: "claim-1", DryRun: true},
+	{Command: "operator", Verb: "create", Resource: "events", Namespace: "default", Details: map[string]string{"node": "node-1"}, Error: "forbidden"},
+}
+
+// logAll logs testEntries at a fixed time and returns the expected lines.
+func logAll(t *testing.T, l *Logger) []string {
+	t.Helper()
+	l.User, l.KubeUser = "alice", "admin"
+	l.now = func() time.Time { return time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC) }
+	for _, e := range testEntries {
+		if err := l.Log(context.Background(), e); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	return []string{
+		`{"time":"2025-06-02T07:00:00Z","user":"alice","kubeUser":"admin","command":"claims cleanup","verb":"delete","resource":"resourceclaims","namespace":"team-a","name":"claim-1","dryRun":true}`,
+		`{"time":"2025-06-02T07:00:00Z","user":"alice","kubeUser":"admin","command":"operator","verb":"create","resource":"events","namespace":"default","details":{"node":"node-1"},"dryRun":false,"error":"forbidden"}`,
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// entries are appended to existing ones
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	expected := append([]string{"{}"}, logAll(t, l)...)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if !json.Valid(data) {
+			t.Errorf("invalid entry %q", data)
+		}
+		got = append(got, strings.TrimSpace(string(data)))
+	}))
+	defer server.Close()
+
+	l, err := Open(server.URL)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	expected := logAll(t, l)
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	var l *Logger
+	if err := l.Log(context.Background(), testEntries[0]); err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
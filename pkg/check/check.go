@@ -0,0 +1,169 @@
+// Package check evaluates health checks over the cluster's DRA state, for use
+// in CI and cron jobs. Every check reports the objects violating it.
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Snapshot is the cluster state checks are evaluated against.
+type Snapshot struct {
+	Nodes  []*types.NodeInfo
+	Claims []*types.ClaimInfo
+	// Devices is only listed when one of the checks sets NeedsDevices, as
+	// listing every device is costly on large clusters.
+	Devices []types.DeviceInfo
+	// Duplicates lists the device names published more than once on a node,
+	// which Devices only holds once.
+	Duplicates []types.DuplicateDevice
+	// KubeletMismatches lists the claim requests for which the kubelet
+	// reports other devices than the claim's allocation.
+	KubeletMismatches []types.KubeletDeviceMismatch
+}
+
+// Check is a named rule over the state of a cluster.
+type Check struct {
+	Name        string
+	Description string
+	// NeedsDevices is set for checks reading Snapshot.Devices.
+	NeedsDevices bool
+	// Evaluate returns one message per violation.
+	Evaluate func(s *Snapshot) []string
+}
+
+// NeedDevices reports whether any of the checks reads Snapshot.Devices.
+func NeedDevices(checks []Check) bool {
+	for _, check := range checks {
+		if check.NeedsDevices {
+			return true
+		}
+	}
+	return false
+}
+
+// Builtin lists the checks run by default.
+var Builtin = []Check{
+	{
+		Name:        "pools-complete",
+		Description: "every pool has all of its ResourceSlices published",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, node := range s.Nodes {
+				for _, pool := range node.Pools {
+					if !pool.Complete() {
+						failures = append(failures, fmt.Sprintf("pool %s of %s on node %s has %d of %d slices",
+							pool.Name, pool.Driver, node.NodeName, pool.ObservedSliceCount, pool.ResourceSliceCount))
+					}
+				}
+			}
+			return failures
+		},
+	},
+	{
+		Name:        "devices-healthy",
+		Description: "no device is reported unhealthy",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, node := range s.Nodes {
+				for _, dev := range node.Devices {
+					if dev.UnhealthyCount > 0 {
+						failures = append(failures, fmt.Sprintf("node %s has %d unhealthy %s devices", node.NodeName, dev.UnhealthyCount, dev.ProductName))
+					}
+				}
+			}
+			return failures
+		},
+	},
+	{
+		Name:        "unique-device-names",
+		Description: "no driver publishes a device name twice on a node",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, dup := range s.Duplicates {
+				failures = append(failures, fmt.Sprintf("node %s has %d %s devices named %s in pools %s",
+					dup.NodeName, len(dup.Pools), dup.Driver, dup.Device, strings.Join(dup.Pools, ", ")))
+			}
+			return failures
+		},
+	},
+	{
+		Name:        "device-nodes-ready",
+		Description: "every node publishing devices is Ready",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, node := range s.Nodes {
+				if node.NotReady && len(node.Devices) > 0 {
+					failures = append(failures, fmt.Sprintf("node %s publishes devices but is NotReady", node.NodeName))
+				}
+			}
+			return failures
+		},
+	},
+	{
+		Name:        "claims-allocated",
+		Description: "every ResourceClaim is allocated",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, claim := range s.Claims {
+				if !claim.Allocated {
+					failures = append(failures, fmt.Sprintf("claim %s/%s is pending", claim.Namespace, claim.Name))
+				}
+			}
+			return failures
+		},
+	},
+	{
+		Name:        "claims-consumers-available",
+		Description: "no ResourceClaim is reserved for the maximum number of consumers",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, claim := range s.Claims {
+				if claim.ConsumersFull {
+					failures = append(failures, fmt.Sprintf("claim %s/%s is reserved for %d consumers, the maximum; further pods referencing it cannot be scheduled",
+						claim.Namespace, claim.Name, len(claim.Consumers)))
+				}
+			}
+			return failures
+		},
+	},
+	{
+		Name:        "claims-match-kubelet",
+		Description: "the kubelet reports the devices allocated to every ResourceClaim",
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, m := range s.KubeletMismatches {
+				claim := m.Claim
+				if m.Request != "" {
+					claim += "/" + m.Request
+				}
+				failures = append(failures, fmt.Sprintf("container %s of pod %s/%s on node %s: claim %s is allocated %s, the kubelet reports %s",
+					m.Container, m.Namespace, m.Pod, m.NodeName, claim, listOrNone(m.Allocated), listOrNone(m.Reported)))
+			}
+			return failures
+		},
+	},
+}
+
+// listOrNone joins the values for a message, or returns "no devices".
+func listOrNone(values []string) string {
+	if len(values) == 0 {
+		return "no devices"
+	}
+	return strings.Join(values, ", ")
+}
+
+// Run evaluates the checks in order.
+func Run(checks []Check, s *Snapshot) []types.CheckResult {
+	results := make([]types.CheckResult, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, types.CheckResult{
+			Name:        check.Name,
+			Description: check.Description,
+			Failures:    check.Evaluate(s),
+		})
+	}
+	return results
+}
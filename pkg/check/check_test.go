@@ -0,0 +1,221 @@
+package check
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun(t *testing.T) {
+	nodes := []*types.NodeInfo{
+		{
+			NodeName: "node-1",
+			Devices:  []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 2, UnhealthyCount: 1}},
+			Pools:    []types.Pool{{Driver: "gpu.nvidia.com", Name: "node-1", ResourceSliceCount: 2, ObservedSliceCount: 1}},
+		},
+		{
+			NodeName: "node-2",
+			NotReady: true,
+		},
+	}
+	claims := []*types.ClaimInfo{
+		{Namespace: "default", Name: "allocated", Allocated: true},
+		{Namespace: "team-a", Name: "pending"},
+		{Namespace: "team-b", Name: "shared", Allocated: true, Consumers: make([]string, 256), ConsumersFull: true},
+	}
+
+	duplicates := []types.DuplicateDevice{
+		{NodeName: "node-1", Driver: "gpu.nvidia.com", Device: "gpu-0", Pools: []string{"node-1", "node-1-mig"}},
+	}
+
+	kubeletMismatches := []types.KubeletDeviceMismatch{{
+		Namespace: "default", Pod: "trainer", Container: "main", NodeName: "node-1",
+		Claim: "allocated", Request: "gpu", Allocated: []string{"gpu.nvidia.com/node-1/gpu-0"},
+	}}
+
+	got := Run(Builtin, &Snapshot{Nodes: nodes, Claims: claims, Duplicates: duplicates, KubeletMismatches: kubeletMismatches})
+
+	expected := []types.CheckResult{
+		{
+			Name:        "pools-complete",
+			Description: "every pool has all of its ResourceSlices published",
+			Failures:    []string{"pool node-1 of gpu.nvidia.com on node node-1 has 1 of 2 slices"},
+		},
+		{
+			Name:        "devices-healthy",
+			Description: "no device is reported unhealthy",
+			Failures:    []string{"node node-1 has 1 unhealthy H100 devices"},
+		},
+		{
+			Name:        "unique-device-names",
+			Description: "no driver publishes a device name twice on a node",
+			Failures:    []string{"node node-1 has 2 gpu.nvidia.com devices named gpu-0 in pools node-1, node-1-mig"},
+		},
+		{
+			Name:        "device-nodes-ready",
+			Description: "every node publishing devices is Ready",
+		},
+		{
+			Name:        "claims-allocated",
+			Description: "every ResourceClaim is allocated",
+			Failures:    []string{"claim team-a/pending is pending"},
+		},
+		{
+			Name:        "claims-consumers-available",
+			Description: "no ResourceClaim is reserved for the maximum number of consumers",
+			Failures:    []string{"claim team-b/shared is reserved for 256 consumers, the maximum; further pods referencing it cannot be scheduled"},
+		},
+		{
+			Name:        "claims-match-kubelet",
+			Description: "the kubelet reports the devices allocated to every ResourceClaim",
+			Failures:    []string{"container main of pod default/trainer on node node-1: claim allocated/gpu is allocated gpu.nvidia.com/node-1/gpu-0, the kubelet reports no devices"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []types.CheckResult{
+		{Name: "pools-complete", Description: "every pool has all of its ResourceSlices published"},
+		{Name: "claims-allocated", Description: "every ResourceClaim is allocated", Failures: []string{"claim a/b is pending", "claim a/c is pending"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "prod", results); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="prod" tests="2" failures="1">
+  <testsuite name="prod" tests="2" failures="1">
+    <testcase name="pools-complete" classname="prod"></testcase>
+    <testcase name="claims-allocated" classname="prod">
+      <failure message="every ResourceClaim is allocated: 2 violation(s)">claim a/b is pending&#xA;claim a/c is pending</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "gpu.yaml"), `
+- name: gpu-saturation
+  description: no node has more than 90% of its GPUs allocated
+  scope: node
+  rule: node.devices.all(d, (d.totalCount - d.availableCount) * 10 <= d.totalCount * 9)
+  message: "'node ' + node.nodeName + ' has its GPUs saturated'"
+- name: gpu-driver
+  description: GPUs run driver 550 or newer
+  scope: device
+  rule: versionAtLeast(device.attributes.driverVersion, '550.0')
+`)
+	writeFile(t, filepath.Join(dir, "claims.yml"), `
+- name: claims-labelled
+  scope: claim
+  rule: claim.namespace != 'default'
+`)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a policy")
+
+	checks, err := LoadPolicies(dir)
+	if err != nil {
+		t.Fatalf("LoadPolicies() error = %v", err)
+	}
+	if !NeedDevices(checks) {
+		t.Errorf("NeedDevices() = false, want true")
+	}
+
+	snapshot := &Snapshot{
+		Nodes: []*types.NodeInfo{
+			{NodeName: "node-1", Devices: []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 0}}},
+			{NodeName: "node-2", Devices: []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 1}}},
+		},
+		Claims: []*types.ClaimInfo{
+			{Namespace: "default", Name: "a"},
+			{Namespace: "team-a", Name: "b"},
+		},
+		Devices: []types.DeviceInfo{
+			{Driver: "gpu.nvidia.com", Pool: "node-1", Name: "gpu-0", Attributes: map[string]string{"driverVersion": "550.54.15"}},
+			{Driver: "gpu.nvidia.com", Pool: "node-2", Name: "gpu-0", Attributes: map[string]string{"driverVersion": "535.104.5"}},
+			{Driver: "gpu.nvidia.com", Pool: "node-3", Name: "gpu-0", Attributes: map[string]string{"productName": "H100"}},
+		},
+	}
+
+	got := Run(checks, snapshot)
+
+	expected := []types.CheckResult{
+		{
+			Name:        "claims-labelled",
+			Description: "claim.namespace != 'default'",
+			Failures:    []string{"claim default/a violates the policy"},
+		},
+		{
+			Name:        "gpu-saturation",
+			Description: "no node has more than 90% of its GPUs allocated",
+			Failures:    []string{"node node-1 has its GPUs saturated"},
+		},
+		{
+			Name:        "gpu-driver",
+			Description: "GPUs run driver 550 or newer",
+			Failures: []string{
+				"device gpu.nvidia.com/node-2/gpu-0 violates the policy",
+				"device gpu.nvidia.com/node-3/gpu-0: failed to evaluate rule: no such key: driverVersion",
+			},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestCompilePolicyErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		expected string
+	}{
+		{
+			name:     "no name",
+			policy:   Policy{Scope: ScopeNode, Rule: "true"},
+			expected: "policy has no name",
+		},
+		{
+			name:     "unknown scope",
+			policy:   Policy{Name: "p", Scope: "pod", Rule: "true"},
+			expected: `policy p has unknown scope "pod", expected node, device or claim`,
+		},
+		{
+			name:     "non-boolean rule",
+			policy:   Policy{Name: "p", Scope: ScopeNode, Rule: "'yes'"},
+			expected: "policy p has an invalid rule: expression returns string, expected bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompilePolicy(tt.policy)
+			if err == nil {
+				t.Fatalf("CompilePolicy() error = nil, want %q", tt.expected)
+			}
+			if diff := cmp.Diff(err.Error(), tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,66 @@
+package check
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report with one test case per
+// check, as ingested by CI systems. suite names the test suite, e.g. after
+// the cluster.
+func WriteJUnit(w io.Writer, suite string, results []types.CheckResult) error {
+	ts := junitTestSuite{Name: suite, Tests: len(results)}
+	for _, result := range results {
+		tc := junitTestCase{Name: result.Name, ClassName: suite}
+		if !result.Passed() {
+			ts.Failures++
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s: %d violation(s)", result.Description, len(result.Failures)),
+				Text:    strings.Join(result.Failures, "\n"),
+			}
+		}
+		ts.TestCases = append(ts.TestCases, tc)
+	}
+	report := junitTestSuites{Name: suite, Tests: ts.Tests, Failures: ts.Failures, Suites: []junitTestSuite{ts}}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,251 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// Scopes of a policy: the rule is evaluated once per node, device or claim.
+const (
+	ScopeNode   = "node"
+	ScopeDevice = "device"
+	ScopeClaim  = "claim"
+)
+
+// Policy is a user-defined check written in CEL. Rule is a boolean
+// expression that must hold for every object of the scope, which is bound to
+// a variable named after the scope (node, device or claim) with the fields of
+// its JSON output. Message is an optional string expression describing a
+// violation.
+//
+// Rules only see the current state of the cluster; conditions over time,
+// e.g. a node being saturated for an hour, need the check to run
+// periodically and alert on repeated failures.
+type Policy struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Scope       string `json:"scope"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message,omitempty"`
+}
+
+// LoadPolicies reads the policies of every .yaml and .yml file in dir, each
+// holding a list of policies, and compiles them into checks.
+func LoadPolicies(dir string) ([]Check, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list policy files: %w", err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no policy files found in %s", dir)
+	}
+	sort.Strings(files)
+
+	var checks []Check
+	names := make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy file: %w", err)
+		}
+		var policies []Policy
+		if err := yaml.UnmarshalStrict(data, &policies); err != nil {
+			return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+		}
+		for _, policy := range policies {
+			if other, ok := names[policy.Name]; ok {
+				return nil, fmt.Errorf("policy %q in %s is already defined in %s", policy.Name, file, other)
+			}
+			names[policy.Name] = file
+			check, err := CompilePolicy(policy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile policy file %s: %w", file, err)
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+// CompilePolicy compiles a policy into a check. Errors evaluating the rule
+// against an object, e.g. a missing attribute, are reported as violations.
+func CompilePolicy(p Policy) (Check, error) {
+	if p.Name == "" {
+		return Check{}, fmt.Errorf("policy has no name")
+	}
+	if p.Scope != ScopeNode && p.Scope != ScopeDevice && p.Scope != ScopeClaim {
+		return Check{}, fmt.Errorf("policy %s has unknown scope %q, expected node, device or claim", p.Name, p.Scope)
+	}
+	if p.Rule == "" {
+		return Check{}, fmt.Errorf("policy %s has no rule", p.Name)
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable(p.Scope, cel.DynType),
+		cel.CrossTypeNumericComparisons(true),
+		versionAtLeast,
+	)
+	if err != nil {
+		return Check{}, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	rule, err := compile(env, p.Rule, cel.BoolType)
+	if err != nil {
+		return Check{}, fmt.Errorf("policy %s has an invalid rule: %w", p.Name, err)
+	}
+	var message cel.Program
+	if p.Message != "" {
+		if message, err = compile(env, p.Message, cel.StringType); err != nil {
+			return Check{}, fmt.Errorf("policy %s has an invalid message: %w", p.Name, err)
+		}
+	}
+
+	description := p.Description
+	if description == "" {
+		description = p.Rule
+	}
+	return Check{
+		Name:         p.Name,
+		Description:  description,
+		NeedsDevices: p.Scope == ScopeDevice,
+		Evaluate: func(s *Snapshot) []string {
+			var failures []string
+			for _, obj := range policyObjects(p.Scope, s) {
+				value, err := toCELValue(obj.value)
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("%s: %v", obj.name, err))
+					continue
+				}
+				vars := map[string]any{p.Scope: value}
+				out, _, err := rule.Eval(vars)
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("%s: failed to evaluate rule: %v", obj.name, err))
+					continue
+				}
+				if out == celtypes.True {
+					continue
+				}
+				failures = append(failures, policyMessage(message, vars, obj.name))
+			}
+			return failures
+		},
+	}, nil
+}
+
+func compile(env *cel.Env, expr string, outputType *cel.Type) (cel.Program, error) {
+	ast, issues := env.Compile(expr)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !ast.OutputType().IsExactType(outputType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression returns %s, expected %s", ast.OutputType(), outputType)
+	}
+	return env.Program(ast)
+}
+
+// policyMessage describes a violation with the policy's message expression,
+// falling back to the object's name.
+func policyMessage(message cel.Program, vars map[string]any, name string) string {
+	if message == nil {
+		return name + " violates the policy"
+	}
+	out, _, err := message.Eval(vars)
+	if err != nil {
+		return fmt.Sprintf("%s violates the policy (failed to evaluate message: %v)", name, err)
+	}
+	if s, ok := out.Value().(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%s violates the policy", name)
+}
+
+// policyObject is an object a policy is evaluated against.
+type policyObject struct {
+	name  string
+	value any
+}
+
+func policyObjects(scope string, s *Snapshot) []policyObject {
+	var objects []policyObject
+	switch scope {
+	case ScopeNode:
+		for _, node := range s.Nodes {
+			objects = append(objects, policyObject{"node " + node.NodeName, node})
+		}
+	case ScopeDevice:
+		for i := range s.Devices {
+			dev := &s.Devices[i]
+			objects = append(objects, policyObject{fmt.Sprintf("device %s/%s/%s", dev.Driver, dev.Pool, dev.Name), dev})
+		}
+	case ScopeClaim:
+		for _, claim := range s.Claims {
+			objects = append(objects, policyObject{fmt.Sprintf("claim %s/%s", claim.Namespace, claim.Name), claim})
+		}
+	}
+	return objects
+}
+
+// toCELValue converts an object to the maps, lists and scalars of its JSON
+// form. Integral numbers become ints so rules can do integer arithmetic on
+// device counts.
+func toCELValue(obj any) (any, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal object: %w", err)
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal object: %w", err)
+	}
+	return integralToInt(value), nil
+}
+
+func integralToInt(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, elem := range v {
+			v[key] = integralToInt(elem)
+		}
+	case []any:
+		for i, elem := range v {
+			v[i] = integralToInt(elem)
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return value
+}
+
+// versionAtLeast adds versionAtLeast(version, minimum), comparing dotted
+// versions such as driver versions numerically.
+var versionAtLeast = cel.Function("versionAtLeast",
+	cel.Overload("versionAtLeast_string_string",
+		[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+		cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+			v, err := version.ParseGeneric(fmt.Sprint(lhs.Value()))
+			if err != nil {
+				return celtypes.NewErr("invalid version %q", lhs.Value())
+			}
+			minimum, err := version.ParseGeneric(fmt.Sprint(rhs.Value()))
+			if err != nil {
+				return celtypes.NewErr("invalid version %q", rhs.Value())
+			}
+			return celtypes.Bool(v.AtLeast(minimum))
+		}),
+	),
+)
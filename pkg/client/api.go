@@ -0,0 +1,314 @@
+package client
+
+import (
+	"context"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	resourcev1 "k8s.io/api/resource/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// resourceAPIVersions are the versions of resource.k8s.io the client reads,
+// newest first.
+var resourceAPIVersions = []string{"v1", "v1beta2", "v1beta1"}
+
+// resourceAPIVersion returns the newest version of resource.k8s.io served by
+// the cluster that the client reads, determined once through discovery.
+// Clusters serving none of them, or failing discovery, are read with
+// v1beta1 so the requests fail with a meaningful error.
+func (c *resourceClient) resourceAPIVersion() string {
+	c.versionOnce.Do(func() {
+		if c.apiVersion != "" {
+			return
+		}
+		c.apiVersion = "v1beta1"
+		for _, version := range resourceAPIVersions {
+			if _, err := c.typedClient.Discovery().ServerResourcesForGroupVersion("resource.k8s.io/" + version); err == nil {
+				c.apiVersion = version
+				return
+			}
+		}
+	})
+	return c.apiVersion
+}
+
+func (c *resourceClient) listResourceSlices(ctx context.Context, opts metav1.ListOptions) ([]model.ResourceSlice, error) {
+	switch c.resourceAPIVersion() {
+	case "v1":
+		list, err := c.typedClient.ResourceV1().ResourceSlices().List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return model.FromV1ResourceSlices(list.Items), nil
+	case "v1beta2":
+		list, err := c.typedClient.ResourceV1beta2().ResourceSlices().List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return model.FromV1beta2ResourceSlices(list.Items), nil
+	}
+	list, err := c.typedClient.ResourceV1beta1().ResourceSlices().List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return model.FromV1beta1ResourceSlices(list.Items), nil
+}
+
+// listResourceSlicePage lists a page of ResourceSlices, returning the
+// continue token of the next page, empty after the last one, and the number
+// of slices remaining if the server reports it.
+func (c *resourceClient) listResourceSlicePage(ctx context.Context, opts metav1.ListOptions) ([]model.ResourceSlice, string, *int64, error) {
+	switch c.resourceAPIVersion() {
+	case "v1":
+		list, err := c.typedClient.ResourceV1().ResourceSlices().List(ctx, opts)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return model.FromV1ResourceSlices(list.Items), list.Continue, list.RemainingItemCount, nil
+	case "v1beta2":
+		list, err := c.typedClient.ResourceV1beta2().ResourceSlices().List(ctx, opts)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return model.FromV1beta2ResourceSlices(list.Items), list.Continue, list.RemainingItemCount, nil
+	}
+	list, err := c.typedClient.ResourceV1beta1().ResourceSlices().List(ctx, opts)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return model.FromV1beta1ResourceSlices(list.Items), list.Continue, list.RemainingItemCount, nil
+}
+
+func (c *resourceClient) listResourceClaims(ctx context.Context, namespace string, opts metav1.ListOptions) ([]model.ResourceClaim, error) {
+	switch c.resourceAPIVersion() {
+	case "v1":
+		list, err := c.typedClient.ResourceV1().ResourceClaims(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return model.FromV1ResourceClaims(list.Items), nil
+	case "v1beta2":
+		list, err := c.typedClient.ResourceV1beta2().ResourceClaims(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return model.FromV1beta2ResourceClaims(list.Items), nil
+	}
+	list, err := c.typedClient.ResourceV1beta1().ResourceClaims(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return model.FromV1beta1ResourceClaims(list.Items), nil
+}
+
+func (c *resourceClient) getResourceClaim(ctx context.Context, namespace, name string) (*model.ResourceClaim, error) {
+	var claim model.ResourceClaim
+	switch c.resourceAPIVersion() {
+	case "v1":
+		rc, err := c.typedClient.ResourceV1().ResourceClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		claim = model.FromV1ResourceClaim(rc)
+	case "v1beta2":
+		rc, err := c.typedClient.ResourceV1beta2().ResourceClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		claim = model.FromV1beta2ResourceClaim(rc)
+	default:
+		rc, err := c.typedClient.ResourceV1beta1().ResourceClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		claim = model.FromV1beta1ResourceClaim(rc)
+	}
+	return &claim, nil
+}
+
+func (c *resourceClient) deleteResourceClaim(ctx context.Context, namespace, name string, preconditions metav1.Preconditions) error {
+	opts := metav1.DeleteOptions{Preconditions: &preconditions}
+	switch c.resourceAPIVersion() {
+	case "v1":
+		return c.typedClient.ResourceV1().ResourceClaims(namespace).Delete(ctx, name, opts)
+	case "v1beta2":
+		return c.typedClient.ResourceV1beta2().ResourceClaims(namespace).Delete(ctx, name, opts)
+	}
+	return c.typedClient.ResourceV1beta1().ResourceClaims(namespace).Delete(ctx, name, opts)
+}
+
+func (c *resourceClient) listDeviceClasses(ctx context.Context) ([]model.DeviceClass, error) {
+	switch c.resourceAPIVersion() {
+	case "v1":
+		list, err := c.typedClient.ResourceV1().DeviceClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return convertAll(list.Items, model.FromV1DeviceClass), nil
+	case "v1beta2":
+		list, err := c.typedClient.ResourceV1beta2().DeviceClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return convertAll(list.Items, model.FromV1beta2DeviceClass), nil
+	}
+	list, err := c.typedClient.ResourceV1beta1().DeviceClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return convertAll(list.Items, model.FromV1beta1DeviceClass), nil
+}
+
+func (c *resourceClient) getDeviceClass(ctx context.Context, name string) (*model.DeviceClass, error) {
+	var class model.DeviceClass
+	switch c.resourceAPIVersion() {
+	case "v1":
+		dc, err := c.typedClient.ResourceV1().DeviceClasses().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		class = model.FromV1DeviceClass(dc)
+	case "v1beta2":
+		dc, err := c.typedClient.ResourceV1beta2().DeviceClasses().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		class = model.FromV1beta2DeviceClass(dc)
+	default:
+		dc, err := c.typedClient.ResourceV1beta1().DeviceClasses().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		class = model.FromV1beta1DeviceClass(dc)
+	}
+	return &class, nil
+}
+
+func (c *resourceClient) listResourceClaimTemplates(ctx context.Context, namespace string) ([]model.ResourceClaimTemplate, error) {
+	switch c.resourceAPIVersion() {
+	case "v1":
+		list, err := c.typedClient.ResourceV1().ResourceClaimTemplates(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return convertAll(list.Items, model.FromV1ResourceClaimTemplate), nil
+	case "v1beta2":
+		list, err := c.typedClient.ResourceV1beta2().ResourceClaimTemplates(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return convertAll(list.Items, model.FromV1beta2ResourceClaimTemplate), nil
+	}
+	list, err := c.typedClient.ResourceV1beta1().ResourceClaimTemplates(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return convertAll(list.Items, model.FromV1beta1ResourceClaimTemplate), nil
+}
+
+// resourceSlicesWatched describes watching ResourceSlices in the served
+// version.
+func (c *resourceClient) resourceSlicesWatched() watchedResource {
+	single := metav1.ListOptions{Limit: 1}
+	r := watchedResource{name: "ResourceSlices"}
+	switch c.resourceAPIVersion() {
+	case "v1":
+		slices := c.typedClient.ResourceV1().ResourceSlices()
+		r.resourceVersion = func(ctx context.Context) (string, error) {
+			list, err := slices.List(ctx, single)
+			if err != nil {
+				return "", err
+			}
+			return list.ResourceVersion, nil
+		}
+		r.watch = slices.Watch
+		return r
+	case "v1beta2":
+		slices := c.typedClient.ResourceV1beta2().ResourceSlices()
+		r.resourceVersion = func(ctx context.Context) (string, error) {
+			list, err := slices.List(ctx, single)
+			if err != nil {
+				return "", err
+			}
+			return list.ResourceVersion, nil
+		}
+		r.watch = slices.Watch
+		return r
+	}
+	slices := c.typedClient.ResourceV1beta1().ResourceSlices()
+	r.resourceVersion = func(ctx context.Context) (string, error) {
+		list, err := slices.List(ctx, single)
+		if err != nil {
+			return "", err
+		}
+		return list.ResourceVersion, nil
+	}
+	r.watch = slices.Watch
+	return r
+}
+
+// resourceClaimsWatched describes watching the ResourceClaims of all
+// namespaces in the served version.
+func (c *resourceClient) resourceClaimsWatched() watchedResource {
+	single := metav1.ListOptions{Limit: 1}
+	r := watchedResource{name: "ResourceClaims"}
+	switch c.resourceAPIVersion() {
+	case "v1":
+		claims := c.typedClient.ResourceV1().ResourceClaims(metav1.NamespaceAll)
+		r.resourceVersion = func(ctx context.Context) (string, error) {
+			list, err := claims.List(ctx, single)
+			if err != nil {
+				return "", err
+			}
+			return list.ResourceVersion, nil
+		}
+		r.watch = claims.Watch
+		return r
+	case "v1beta2":
+		claims := c.typedClient.ResourceV1beta2().ResourceClaims(metav1.NamespaceAll)
+		r.resourceVersion = func(ctx context.Context) (string, error) {
+			list, err := claims.List(ctx, single)
+			if err != nil {
+				return "", err
+			}
+			return list.ResourceVersion, nil
+		}
+		r.watch = claims.Watch
+		return r
+	}
+	claims := c.typedClient.ResourceV1beta1().ResourceClaims(metav1.NamespaceAll)
+	r.resourceVersion = func(ctx context.Context) (string, error) {
+		list, err := claims.List(ctx, single)
+		if err != nil {
+			return "", err
+		}
+		return list.ResourceVersion, nil
+	}
+	r.watch = claims.Watch
+	return r
+}
+
+// sliceDriver returns the driver of the ResourceSlice of a watch event.
+func sliceDriver(event watch.Event) (string, bool) {
+	switch rs := event.Object.(type) {
+	case *resourcev1.ResourceSlice:
+		return rs.Spec.Driver, true
+	case *resourcev1beta1.ResourceSlice:
+		return rs.Spec.Driver, true
+	case *resourcev1beta2.ResourceSlice:
+		return rs.Spec.Driver, true
+	}
+	return "", false
+}
+
+// convertAll converts the items of a list.
+func convertAll[In, Out any](items []In, convert func(*In) Out) []Out {
+	out := make([]Out, len(items))
+	for i := range items {
+		out[i] = convert(&items[i])
+	}
+	return out
+}
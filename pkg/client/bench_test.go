@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/synthetic"
+)
+
+func BenchmarkAggregate(b *testing.B) {
+	cluster := synthetic.Generate(synthetic.Options{Nodes: 1000, DevicesPerNode: 8, Claims: 6000})
+	resourceSlices := model.FromV1beta1ResourceSlices(cluster.ResourceSlices)
+	resourceClaims := model.FromV1beta1ResourceClaims(cluster.ResourceClaims)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Aggregate(context.Background(), cluster.Nodes, resourceSlices, resourceClaims, cluster.Pods); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// cachedSnapshot is the on-disk format of the snapshot cache.
+type cachedSnapshot struct {
+	FetchedAt time.Time         `json:"fetchedAt"`
+	Nodes     []*types.NodeInfo `json:"nodes"`
+}
+
+// cachingClient serves GetK8sResources of the whole cluster from a snapshot
+// on disk while it is younger than the TTL, so consecutive invocations don't
+// re-list it. Filtered and all other calls go to the wrapped client.
+type cachingClient struct {
+	ResourceClient
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewCachingClient wraps a client with a snapshot cache stored in dir. The
+// key identifies the cluster, e.g. kubeconfig path and context name, so that
+// snapshots of different contexts are kept apart.
+func NewCachingClient(inner ResourceClient, dir, key string, ttl time.Duration) ResourceClient {
+	sum := sha256.Sum256([]byte(key))
+	return &cachingClient{
+		ResourceClient: inner,
+		path:           filepath.Join(dir, hex.EncodeToString(sum[:])+".json"),
+		ttl:            ttl,
+		now:            time.Now,
+	}
+}
+
+// DefaultCacheDir returns the per-user directory used for snapshot caches.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "k8s-dra-resources"), nil
+}
+
+// CurrentContext returns the name of the current context of a kubeconfig.
+func CurrentContext(kubeconfigPath string) (string, error) {
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return rawConfig.CurrentContext, nil
+}
+
+// CurrentUser returns the user of the current context of a kubeconfig, or
+// nothing if it has none.
+func CurrentUser(kubeconfigPath string) (string, error) {
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if context, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok {
+		return context.AuthInfo, nil
+	}
+	return "", nil
+}
+
+func (c *cachingClient) GetK8sResources(ctx context.Context, opts ListOptions) ([]*types.NodeInfo, error) {
+	if opts != (ListOptions{}) {
+		return c.ResourceClient.GetK8sResources(ctx, opts)
+	}
+	if data, err := os.ReadFile(c.path); err == nil {
+		var snapshot cachedSnapshot
+		if json.Unmarshal(data, &snapshot) == nil && c.now().Sub(snapshot.FetchedAt) < c.ttl {
+			return snapshot.Nodes, nil
+		}
+	}
+
+	nodeInfoList, err := c.ResourceClient.GetK8sResources(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cache is best effort; failing to write it must not fail the command
+	_ = c.write(cachedSnapshot{FetchedAt: c.now(), Nodes: nodeInfoList})
+	return nodeInfoList, nil
+}
+
+// DeleteResourceClaim invalidates the snapshot, since it changes allocations.
+func (c *cachingClient) DeleteResourceClaim(ctx context.Context, claim *types.ClaimInfo) error {
+	defer os.Remove(c.path)
+	return c.ResourceClient.DeleteResourceClaim(ctx, claim)
+}
+
+// Watch invalidates the snapshot on every change so watchers never render a
+// stale one.
+func (c *cachingClient) Watch(ctx context.Context, onChange func()) error {
+	return c.ResourceClient.Watch(ctx, func() {
+		os.Remove(c.path)
+		onChange()
+	})
+}
+
+func (c *cachingClient) write(snapshot cachedSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// GetResourceClaims returns the claims of the namespace of opts, or of all
+// namespaces when it is empty. Only namespace-scoped read access is needed
+// for a single namespace.
+func (c *resourceClient) GetResourceClaims(ctx context.Context, opts ListOptions) ([]*types.ClaimInfo, error) {
+	resourceClaims, err := c.getResourceClaims(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make([]*types.ClaimInfo, 0, len(resourceClaims))
+	for i := range resourceClaims {
+		claims = append(claims, newClaimInfo(&resourceClaims[i]))
+	}
+	sortClaims(claims)
+	return claims, nil
+}
+
+// GetProductAvailability returns cluster-wide device availability per product
+// without listing nodes or pods. It still needs list access to ResourceSlices
+// and to ResourceClaims of all namespaces, which users limited to their own
+// namespace lack; serve offers the result to them. The result does not reveal
+// which nodes the devices are on.
+func (c *resourceClient) GetProductAvailability(ctx context.Context) ([]types.ProductAvailability, error) {
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	allocatedDevices := aggregate.AllocatedDevices(resourceClaims)
+	poolGenerations := aggregate.LatestPoolGenerations(resourceSlices)
+	sliceDecorations, err := aggregate.DecorateSlices(ctx, resourceSlices)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[string]*types.ProductAvailability)
+	seenDevices := make(map[aggregate.DeviceKey]bool)
+	for si, rs := range resourceSlices {
+		pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		if rs.Spec.Pool.Generation < poolGenerations[pool] {
+			continue
+		}
+		decorations := sliceDecorations[si]
+
+		for i, dev := range rs.Spec.Devices {
+			if seenDevices[pool.Device(dev.Name)] {
+				continue
+			}
+			seenDevices[pool.Device(dev.Name)] = true
+
+			productName := decorations[i].ProductName
+			product, ok := products[productName]
+			if !ok {
+				product = &types.ProductAvailability{ProductName: productName}
+				products[productName] = product
+			}
+			product.TotalCount++
+			if _, ok := allocatedDevices[pool.Device(dev.Name)]; !ok {
+				product.AvailableCount++
+			}
+		}
+	}
+
+	availability := make([]types.ProductAvailability, 0, len(products))
+	for _, product := range products {
+		availability = append(availability, *product)
+	}
+	sort.Slice(availability, func(i, j int) bool {
+		return availability[i].ProductName < availability[j].ProductName
+	})
+	return availability, nil
+}
+
+// GetOrphanedResourceClaims returns allocated claims whose consumers no longer
+// exist. Such claims keep their devices allocated and block reuse. A claim is
+// orphaned when it is owned by a pod that is gone, or when every pod listed in
+// its reservedFor has been deleted. Allocated claims without any consumer are
+// not reported, since a pod may be about to reserve them.
+func (c *resourceClient) GetOrphanedResourceClaims(ctx context.Context) ([]*types.ClaimInfo, error) {
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := c.getPods(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podUIDs := make(map[k8stypes.UID]bool)
+	for _, pod := range pods {
+		podUIDs[pod.UID] = true
+	}
+
+	var orphans []*types.ClaimInfo
+	for _, rc := range resourceClaims {
+		if rc.Status.Allocation == nil {
+			continue
+		}
+		if isOrphaned(&rc, podUIDs) {
+			orphans = append(orphans, newClaimInfo(&rc))
+		}
+	}
+
+	sortClaims(orphans)
+	return orphans, nil
+}
+
+// ErrChangedSinceListing is returned when deleting a claim that was modified,
+// deleted or recreated under the same name since it was listed.
+var ErrChangedSinceListing = errors.New("changed since listing")
+
+// DeleteResourceClaim deletes a listed ResourceClaim, provided it is still
+// the revision that was listed, so a decision made on the listed state, e.g.
+// that the claim is orphaned, does not delete a claim that changed since.
+func (c *resourceClient) DeleteResourceClaim(ctx context.Context, claim *types.ClaimInfo) error {
+	var preconditions metav1.Preconditions
+	if claim.UID != "" {
+		preconditions.UID = (*k8stypes.UID)(&claim.UID)
+	}
+	if claim.ResourceVersion != "" {
+		preconditions.ResourceVersion = &claim.ResourceVersion
+	}
+	err := c.deleteResourceClaim(ctx, claim.Namespace, claim.Name, preconditions)
+	if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+		return fmt.Errorf("resourceclaim %s/%s %w", claim.Namespace, claim.Name, ErrChangedSinceListing)
+	}
+	if err != nil {
+		return apiError(err, "delete ResourceClaim %s/%s", claim.Namespace, claim.Name)
+	}
+	return nil
+}
+
+func isOrphaned(rc *model.ResourceClaim, podUIDs map[k8stypes.UID]bool) bool {
+	for _, owner := range rc.OwnerReferences {
+		if owner.Kind == "Pod" && !podUIDs[owner.UID] {
+			return true
+		}
+	}
+
+	if len(rc.Status.ReservedFor) == 0 {
+		return false
+	}
+	for _, consumer := range rc.Status.ReservedFor {
+		// consumers other than pods cannot be checked and are assumed alive
+		if consumer.Resource != "pods" || podUIDs[consumer.UID] {
+			return false
+		}
+	}
+	return true
+}
+
+func newClaimInfo(rc *model.ResourceClaim) *types.ClaimInfo {
+	info := &types.ClaimInfo{
+		Namespace:       rc.Namespace,
+		Name:            rc.Name,
+		UID:             string(rc.UID),
+		ResourceVersion: rc.ResourceVersion,
+		Allocated:       rc.Status.Allocation != nil,
+		Requests:        requestSummaries(&rc.Spec),
+		Created:         rc.CreationTimestamp.Time,
+		Reservation:     reservation(rc.Annotations),
+	}
+	if rc.Status.Allocation != nil {
+		for _, result := range rc.Status.Allocation.Devices.Results {
+			info.Devices = append(info.Devices, fmt.Sprintf("%s/%s/%s", result.Driver, result.Pool, result.Device))
+		}
+	}
+	info.Consumers = consumerNames(rc)
+	info.ConsumersFull = len(rc.Status.ReservedFor) >= model.ResourceClaimReservedForMaxSize
+	return info
+}
+
+// consumerNames returns the consumers the claim is reserved for, pods by
+// their name and other resources as resource/name.
+func consumerNames(rc *model.ResourceClaim) []string {
+	var names []string
+	for _, consumer := range rc.Status.ReservedFor {
+		if consumer.Resource == "pods" {
+			names = append(names, consumer.Name)
+		} else {
+			names = append(names, consumer.Resource+"/"+consumer.Name)
+		}
+	}
+	return names
+}
+
+// GetClaimDescription returns a ResourceClaim with the device allocated for
+// each request and the driver configuration of its allocation result.
+func (c *resourceClient) GetClaimDescription(ctx context.Context, namespace, name string) (*types.ClaimDescription, error) {
+	rc, err := c.getResourceClaim(ctx, namespace, name)
+	if err != nil {
+		return nil, apiError(err, "get ResourceClaim %s/%s", namespace, name)
+	}
+	description := &types.ClaimDescription{ClaimInfo: *newClaimInfo(rc)}
+	if rc.Status.Allocation == nil {
+		return description, nil
+	}
+	for _, result := range rc.Status.Allocation.Devices.Results {
+		description.Results = append(description.Results, types.AllocatedDevice{
+			Request: result.Request,
+			Device:  fmt.Sprintf("%s/%s/%s", result.Driver, result.Pool, result.Device),
+		})
+	}
+	for _, config := range rc.Status.Allocation.Devices.Config {
+		if config.Opaque == nil {
+			continue
+		}
+		ac := types.AllocationConfig{
+			Source:     string(config.Source),
+			Requests:   config.Requests,
+			Driver:     config.Opaque.Driver,
+			Parameters: string(config.Opaque.Parameters.Raw),
+		}
+		// Parameters a decoder does not understand are shown raw rather
+		// than failing the description.
+		fields, err := decorator.DecodeConfig(config.Opaque.Driver, config.Opaque.Parameters.Raw)
+		if err == nil {
+			for _, f := range fields {
+				ac.Fields = append(ac.Fields, types.ConfigField{Name: f.Name, Value: f.Value})
+			}
+		}
+		description.AllocationConfigs = append(description.AllocationConfigs, ac)
+	}
+	return description, nil
+}
+
+func sortClaims(claims []*types.ClaimInfo) {
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Namespace != claims[j].Namespace {
+			return claims[i].Namespace < claims[j].Namespace
+		}
+		return claims[i].Name < claims[j].Name
+	})
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetDeviceClasses returns the selectors and configuration of every
+// DeviceClass, sorted by name.
+func (c *resourceClient) GetDeviceClasses(ctx context.Context) ([]types.DeviceClassInfo, error) {
+	deviceClasses, err := c.listDeviceClasses(ctx)
+	if err != nil {
+		return nil, apiError(err, "list DeviceClasses")
+	}
+
+	classes := make([]types.DeviceClassInfo, 0, len(deviceClasses))
+	for i := range deviceClasses {
+		classes = append(classes, newDeviceClassInfo(&deviceClasses[i]))
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].Name < classes[j].Name
+	})
+	return classes, nil
+}
+
+// GetClassConsumers returns the claims and ResourceClaimTemplates with a
+// request, or subrequest, for the DeviceClass, with the devices allocated for
+// those requests and the pods and workloads consuming the claims.
+func (c *resourceClient) GetClassConsumers(ctx context.Context, className string) (*types.ClassConsumers, error) {
+	dc, err := c.getDeviceClass(ctx, className)
+	if err != nil {
+		return nil, apiError(err, "get DeviceClass %s", className)
+	}
+	result := &types.ClassConsumers{Class: newDeviceClassInfo(dc), Claims: []types.ClassConsumer{}}
+
+	templates, err := c.listResourceClaimTemplates(ctx, metav1.NamespaceAll)
+	if err != nil {
+		return nil, apiError(err, "list ResourceClaimTemplates")
+	}
+	for _, tmpl := range templates {
+		if len(classRequests(&tmpl.Spec.Spec, className)) > 0 {
+			result.Templates = append(result.Templates, tmpl.Namespace+"/"+tmpl.Name)
+		}
+	}
+	sort.Strings(result.Templates)
+
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	podClaims := make(map[string][]int) // namespace/pod -> indexes into result.Claims
+	for _, rc := range resourceClaims {
+		requests := classRequests(&rc.Spec, className)
+		if len(requests) == 0 {
+			continue
+		}
+		consumer := types.ClassConsumer{
+			Namespace: rc.Namespace,
+			Name:      rc.Name,
+			Allocated: rc.Status.Allocation != nil,
+		}
+		if rc.Status.Allocation != nil {
+			for _, allocated := range rc.Status.Allocation.Devices.Results {
+				if requests[allocated.Request] {
+					consumer.Devices++
+				}
+			}
+		}
+		for _, ref := range rc.Status.ReservedFor {
+			if ref.Resource == "pods" {
+				consumer.Consumers = append(consumer.Consumers, ref.Name)
+				podClaims[rc.Namespace+"/"+ref.Name] = append(podClaims[rc.Namespace+"/"+ref.Name], len(result.Claims))
+			} else {
+				consumer.Consumers = append(consumer.Consumers, ref.Resource+"/"+ref.Name)
+			}
+		}
+		result.Claims = append(result.Claims, consumer)
+	}
+
+	if len(podClaims) > 0 {
+		pods, err := c.getPods(ctx, ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			owner := podOwner(&pod)
+			if owner == "" {
+				continue
+			}
+			for _, i := range podClaims[pod.Namespace+"/"+pod.Name] {
+				result.Claims[i].Owners = appendUnique(result.Claims[i].Owners, owner)
+			}
+		}
+	}
+
+	for i := range result.Claims {
+		sort.Strings(result.Claims[i].Owners)
+	}
+	sort.Slice(result.Claims, func(i, j int) bool {
+		if result.Claims[i].Namespace != result.Claims[j].Namespace {
+			return result.Claims[i].Namespace < result.Claims[j].Namespace
+		}
+		return result.Claims[i].Name < result.Claims[j].Name
+	})
+	return result, nil
+}
+
+// classRequests returns the names of the requests of the claim spec for the
+// DeviceClass, as they appear in allocation results: subrequests are named
+// request/subrequest.
+func classRequests(spec *model.ResourceClaimSpec, className string) map[string]bool {
+	requests := make(map[string]bool)
+	for _, req := range spec.Devices.Requests {
+		if req.DeviceClassName == className {
+			requests[req.Name] = true
+		}
+		for _, sub := range req.FirstAvailable {
+			if sub.DeviceClassName == className {
+				requests[req.Name+"/"+sub.Name] = true
+			}
+		}
+	}
+	return requests
+}
+
+func newDeviceClassInfo(dc *model.DeviceClass) types.DeviceClassInfo {
+	info := types.DeviceClassInfo{Name: dc.Name}
+	for _, selector := range dc.Spec.Selectors {
+		if selector.CEL != nil {
+			info.Selectors = append(info.Selectors, selector.CEL.Expression)
+		}
+	}
+	for _, config := range dc.Spec.Config {
+		if config.Opaque == nil {
+			continue
+		}
+		// compact the parameters so formatting differences are not
+		// reported as drift
+		params := config.Opaque.Parameters.Raw
+		var buf bytes.Buffer
+		if json.Compact(&buf, params) == nil {
+			params = buf.Bytes()
+		}
+		info.Config = append(info.Config, config.Opaque.Driver+": "+string(params))
+	}
+	return info
+}
@@ -3,31 +3,86 @@ package client
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
+	"sync"
 
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
 	"github.com/dharmjit/k8s-dra-resources/pkg/types"
 	corev1 "k8s.io/api/core/v1"
-	resourcev1beta1 "k8s.io/api/resource/v1beta1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 type ResourceClient interface {
-	getResourceSlices(ctx context.Context) ([]resourcev1beta1.ResourceSlice, error)
-	getResourceClaims(ctx context.Context) ([]resourcev1beta1.ResourceClaim, error)
-	getNodes(ctx context.Context) ([]corev1.Node, error)
-	getPods(ctx context.Context) ([]corev1.Pod, error)
-	GetK8sResources(ctx context.Context) ([]*types.NodeInfo, error)
+	getResourceSlices(ctx context.Context, opts ListOptions) ([]model.ResourceSlice, error)
+	getResourceClaims(ctx context.Context, opts ListOptions) ([]model.ResourceClaim, error)
+	getNodes(ctx context.Context, opts ListOptions) ([]corev1.Node, error)
+	getPods(ctx context.Context, opts ListOptions) ([]corev1.Pod, error)
+	GetK8sResources(ctx context.Context, opts ListOptions) ([]*types.NodeInfo, error)
+	GetResourceClaims(ctx context.Context, opts ListOptions) ([]*types.ClaimInfo, error)
+	GetProductAvailability(ctx context.Context) ([]types.ProductAvailability, error)
+	GetOrphanedResourceClaims(ctx context.Context) ([]*types.ClaimInfo, error)
+	GetClaimTemplateStats(ctx context.Context) ([]types.ClaimTemplateStats, error)
+	GetClaimMutations(ctx context.Context, namespace, name string) (*types.ClaimMutations, error)
+	GetClaimDescription(ctx context.Context, namespace, name string) (*types.ClaimDescription, error)
+	GetDeviceClasses(ctx context.Context) ([]types.DeviceClassInfo, error)
+	GetClassConsumers(ctx context.Context, className string) (*types.ClassConsumers, error)
+	SimulateClassChange(ctx context.Context, class *model.DeviceClass) (*types.ClassChange, error)
+	GetAttributeInventory(ctx context.Context, attributes []string, perProduct bool) ([]types.AttributeInventory, error)
+	GetDevices(ctx context.Context, opts ListOptions) ([]types.DeviceInfo, error)
+	ForEachDevice(ctx context.Context, opts ListOptions, fn func(types.DeviceInfo) error) error
+	GetNamespaceLabels(ctx context.Context) (map[string]map[string]string, error)
+	GetClaimPods(ctx context.Context, opts ListOptions) ([]types.PodInfo, error)
+	GetPodGroups(ctx context.Context, opts ListOptions) ([]types.PodGroupInfo, error)
+	GetWorkloads(ctx context.Context, opts ListOptions) ([]types.WorkloadUsage, error)
+	GetQueueDemand(ctx context.Context, quotaResources map[string]string) ([]types.QueueDemand, error)
+	GetSpotRisk(ctx context.Context) ([]types.SpotRisk, error)
+	GetDevicePods(ctx context.Context, opts ListOptions) ([]types.DevicePod, error)
+	GetCounterOvercommit(ctx context.Context) ([]types.CounterOvercommit, error)
+	GetKubeletDeviceMismatches(ctx context.Context) ([]types.KubeletDeviceMismatch, error)
+	GetDuplicateDevices(ctx context.Context) ([]types.DuplicateDevice, error)
+	GetDriverLogs(ctx context.Context, driver, nodeName string, tailLines int64) (*types.DriverLogs, error)
+	DeleteResourceClaim(ctx context.Context, claim *types.ClaimInfo) error
+	Watch(ctx context.Context, onChange func()) error
+	Mirror(ctx context.Context, opts ListOptions, onChange func()) (*Mirror, error)
+	WatchSliceUpdates(ctx context.Context, onUpdate func(driver string)) error
+	EmitNodeEvent(ctx context.Context, nodeName, eventType, reason, message string) error
+	RunLeaderElected(ctx context.Context, config LeaderElectionConfig, run func(ctx context.Context) error) error
+	// Namespace returns the namespace of the kubeconfig's current context.
+	Namespace() string
 }
 
 type resourceClient struct {
 	typedClient kubernetes.Interface
+	// dynamicClient reads custom resources such as Kueue's; it is nil for
+	// clients created from a clientset, which then see no custom resources.
+	dynamicClient dynamic.Interface
+	namespace     string
+
+	// apiVersion is the version of resource.k8s.io read, see
+	// resourceAPIVersion.
+	versionOnce sync.Once
+	apiVersion  string
 }
 
 func NewResourceClient(kubeconfigPath string) (ResourceClient, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	return NewResourceClientForContext(kubeconfigPath, "")
+}
+
+// NewResourceClientForContext returns a client for the named context of the
+// kubeconfig, or for its current context if contextName is empty.
+func NewResourceClientForContext(kubeconfigPath, contextName string) (ResourceClient, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	)
+
+	config, err := clientConfig.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes config: %w", err)
 	}
@@ -37,203 +92,131 @@ func NewResourceClient(kubeconfigPath string) (ResourceClient, error) {
 		return nil, fmt.Errorf("failed to create typed client: %w", err)
 	}
 
-	return &resourceClient{typedClient: typedClient}, nil
-}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
 
-func (c *resourceClient) getResourceSlices(ctx context.Context) ([]resourcev1beta1.ResourceSlice, error) {
-	list, err := c.typedClient.ResourceV1beta1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	namespace, _, err := clientConfig.Namespace()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list ResourceSlices: %w", err)
+		namespace = metav1.NamespaceDefault
 	}
-	return list.Items, nil
+
+	return &resourceClient{typedClient: typedClient, dynamicClient: dynamicClient, namespace: namespace}, nil
+}
+
+// NewResourceClientForClientset returns a client using the given clientset,
+// e.g. a fake clientset seeded with synthetic objects. namespace is used as
+// the current namespace.
+func NewResourceClientForClientset(clientset kubernetes.Interface, namespace string) ResourceClient {
+	return &resourceClient{typedClient: clientset, namespace: namespace}
+}
+
+func (c *resourceClient) Namespace() string {
+	return c.namespace
 }
 
-func (c *resourceClient) getResourceClaims(ctx context.Context) ([]resourcev1beta1.ResourceClaim, error) {
-	list, err := c.typedClient.ResourceV1beta1().ResourceClaims("").List(ctx, metav1.ListOptions{}) // "" for all namespaces
+func (c *resourceClient) getResourceSlices(ctx context.Context, opts ListOptions) ([]model.ResourceSlice, error) {
+	reportProgress(ctx, Progress{Resource: "resourceslices"})
+	items, err := c.listResourceSlices(ctx, opts.resourceSlices())
 	if err != nil {
-		return nil, fmt.Errorf("failed to list ResourceClaims: %w", err)
+		return nil, apiError(err, "list ResourceSlices")
 	}
-	return list.Items, nil
+	reportProgress(ctx, Progress{Resource: "resourceslices", Listed: len(items), Total: len(items), Done: true})
+	return slices.DeleteFunc(items, func(rs model.ResourceSlice) bool {
+		return !opts.selectsSlice(&rs)
+	}), nil
 }
 
-func (c *resourceClient) getNodes(ctx context.Context) ([]corev1.Node, error) {
-	list, err := c.typedClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+func (c *resourceClient) getResourceClaims(ctx context.Context, opts ListOptions) ([]model.ResourceClaim, error) {
+	reportProgress(ctx, Progress{Resource: "resourceclaims"})
+	items, err := c.listResourceClaims(ctx, opts.Namespace, metav1.ListOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %w", err)
+		return nil, apiError(err, "list ResourceClaims")
 	}
-	return list.Items, nil
+	reportProgress(ctx, Progress{Resource: "resourceclaims", Listed: len(items), Total: len(items), Done: true})
+	return items, nil
 }
 
-func (c *resourceClient) getPods(ctx context.Context) ([]corev1.Pod, error) {
-	list, err := c.typedClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+// getNodes lists the nodes selected by opts. A single node is fetched by
+// name, so a missing one is reported as not found.
+func (c *resourceClient) getNodes(ctx context.Context, opts ListOptions) ([]corev1.Node, error) {
+	if opts.NodeName != "" {
+		node, err := c.typedClient.CoreV1().Nodes().Get(ctx, opts.NodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("node %q not found", opts.NodeName)
+		}
+		if err != nil {
+			return nil, apiError(err, "get node")
+		}
+		selector, err := labels.Parse(opts.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector %q: %w", opts.NodeSelector, err)
+		}
+		if !selector.Matches(labels.Set(node.Labels)) {
+			return nil, nil
+		}
+		return []corev1.Node{*node}, nil
+	}
+
+	reportProgress(ctx, Progress{Resource: "nodes"})
+	list, err := c.typedClient.CoreV1().Nodes().List(ctx, opts.nodes())
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
+		return nil, apiError(err, "list nodes")
 	}
+	reportProgress(ctx, Progress{Resource: "nodes", Listed: len(list.Items), Total: len(list.Items), Done: true})
 	return list.Items, nil
 }
 
-func (c *resourceClient) GetK8sResources(ctx context.Context) ([]*types.NodeInfo, error) {
-	nodes, err := c.getNodes(ctx)
+func (c *resourceClient) getPods(ctx context.Context, opts ListOptions) ([]corev1.Pod, error) {
+	list, err := c.typedClient.CoreV1().Pods(opts.Namespace).List(ctx, opts.pods())
 	if err != nil {
-		return nil, err
+		return nil, apiError(err, "list pods")
 	}
+	return list.Items, nil
+}
 
-	resourceSlices, err := c.getResourceSlices(ctx)
+// GetK8sResources returns the resources of the nodes selected by opts, sorted
+// by name, with the devices of the driver of opts if set. Only the selected
+// nodes, their ResourceSlices and, for a single node, its pods are fetched,
+// so looking at one node stays fast on large clusters. Claims are still
+// listed in all namespaces because allocations cannot be filtered by node on
+// the server; opts.Namespace is ignored, as the claims and pods of every
+// namespace count towards a node.
+func (c *resourceClient) GetK8sResources(ctx context.Context, opts ListOptions) ([]*types.NodeInfo, error) {
+	opts.Namespace = metav1.NamespaceAll
+
+	nodes, err := c.getNodes(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	resourceClaims, err := c.getResourceClaims(ctx)
+	resourceSlices, err := c.getResourceSlices(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	pods, err := c.getPods(ctx)
+	resourceClaims, err := c.getResourceClaims(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// calculate total requested resources per node
-	requestedResources := make(map[string]corev1.ResourceList)
-	for _, pod := range pods {
-		if pod.Spec.NodeName == "" {
-			continue
-		}
-		if _, ok := requestedResources[pod.Spec.NodeName]; !ok {
-			requestedResources[pod.Spec.NodeName] = make(corev1.ResourceList)
-		}
-		for _, container := range pod.Spec.Containers {
-			for resName, resQuant := range container.Resources.Requests {
-				if _, ok := requestedResources[pod.Spec.NodeName][resName]; !ok {
-					requestedResources[pod.Spec.NodeName][resName] = resQuant.DeepCopy()
-				} else {
-					existingQuant := requestedResources[pod.Spec.NodeName][resName]
-					existingQuant.Add(resQuant)
-					requestedResources[pod.Spec.NodeName][resName] = existingQuant
-				}
-			}
-		}
-	}
-
-	allocatedDevices := make(map[string]map[string]bool)
-	for _, rc := range resourceClaims {
-		if rc.Status.Allocation != nil && len(rc.Status.Allocation.Devices.Results) > 0 {
-			for _, ads := range rc.Status.Allocation.Devices.Results {
-				sliceIdentifier := fmt.Sprintf("%s-%s", ads.Driver, ads.Pool)
-				if _, ok := allocatedDevices[sliceIdentifier]; !ok {
-					allocatedDevices[sliceIdentifier] = make(map[string]bool)
-				}
-				allocatedDevices[sliceIdentifier][ads.Device] = true
-			}
-		}
-	}
-
-	// Map to hold all info per node
-	nodeMap := make(map[string]*types.NodeInfo)
-	for _, node := range nodes {
-		role := "<none>"
-		for k := range node.Labels {
-			if strings.HasPrefix(k, "node-role.kubernetes.io/") {
-				role = strings.TrimPrefix(k, "node-role.kubernetes.io/")
-				break
-			}
-		}
-
-		// Calculate available resources
-		availableCPU := node.Status.Allocatable[corev1.ResourceCPU].DeepCopy()
-		availableMemory := node.Status.Allocatable[corev1.ResourceMemory].DeepCopy()
-		availableStorage := node.Status.Allocatable[corev1.ResourceStorage].DeepCopy()
-
-		if reqs, ok := requestedResources[node.Name]; ok {
-			if cpuReq, ok := reqs[corev1.ResourceCPU]; ok {
-				availableCPU.Sub(cpuReq)
-			}
-			if memReq, ok := reqs[corev1.ResourceMemory]; ok {
-				availableMemory.Sub(memReq)
-			}
-			if storageReq, ok := reqs[corev1.ResourceStorage]; ok {
-				availableStorage.Sub(storageReq)
-			}
-		}
-
-		nodeMap[node.Name] = &types.NodeInfo{
-			NodeName: node.Name,
-			NodeRole: role,
-			NodeCapacity: types.NodeCapacity{
-				TotalCPU:         node.Status.Capacity[corev1.ResourceCPU],
-				AvailableCPU:     availableCPU,
-				TotalMemory:      node.Status.Capacity[corev1.ResourceMemory],
-				AvailableMemory:  availableMemory,
-				TotalStorage:     node.Status.Capacity[corev1.ResourceStorage],
-				AvailableStorage: availableStorage,
-			},
-			Devices: []types.Device{},
-		}
+	// calculate total requested resources per node, streaming the pods so
+	// memory stays flat on clusters with many of them
+	pods := aggregate.NewPodUsage()
+	if err := c.forEachPod(ctx, opts, pods.Add); err != nil {
+		return nil, err
 	}
 
-	// Populate devices for each node
-	for _, rs := range resourceSlices {
-		nodeInfo, ok := nodeMap[rs.Spec.NodeName]
-		if !ok {
-			continue
-		}
-
-		deviceMap := make(map[string]types.Device) // key is productName
-
-		sliceIdentifier := fmt.Sprintf("%s-%s", rs.Spec.Driver, rs.Spec.Pool.Name)
-		for _, dev := range rs.Spec.Devices {
-
-			var productName string
-			productName = rs.Spec.Driver
-			if productName == "gpu.nvidia.com" {
-				if dev.Basic != nil {
-					if attrProductName, ok := dev.Basic.Attributes["productName"]; ok {
-						productName = *attrProductName.StringValue
-					}
-				}
-			}
-
-			var memory resource.Quantity
-			if dev.Basic != nil {
-				if mem, ok := dev.Basic.Capacity["memory"]; ok {
-					memory = mem.Value
-				}
-			}
-
-			// if productName is not in deviceMap, initialize it otherwise increment the TotalCount and AvailableCount by 1
-			if _, ok := deviceMap[productName]; !ok {
-				deviceMap[productName] = types.Device{
-					ProductName:    productName,
-					TotalCount:     1,
-					AvailableCount: 1,
-					Memory:         memory,
-				}
-			} else {
-				dev := deviceMap[productName]
-				dev.TotalCount++
-				dev.AvailableCount++
-				deviceMap[productName] = dev
-			}
-
-			// if the device is allocated, reduce the available count by 1
-			if allocatedDevices[sliceIdentifier][dev.Name] {
-				dev := deviceMap[productName]
-				if dev.AvailableCount > 0 {
-					dev.AvailableCount--
-				}
-				deviceMap[productName] = dev
-			}
-		}
-		// Iterate over the deviceMap to populate nodeInfo.Devices
-		for _, dev := range deviceMap {
-			nodeInfo.Devices = append(nodeInfo.Devices, dev)
-		}
-	}
+	return aggregate.Nodes(ctx, nodes, resourceSlices, resourceClaims, pods)
+}
 
-	var nodeInfoList []*types.NodeInfo
-	for _, nodeInfo := range nodeMap {
-		nodeInfoList = append(nodeInfoList, nodeInfo)
+// Aggregate computes the per-node summaries from already fetched objects, the
+// same way GetK8sResources does after listing them.
+func Aggregate(ctx context.Context, nodes []corev1.Node, resourceSlices []model.ResourceSlice, resourceClaims []model.ResourceClaim, pods []corev1.Pod) ([]*types.NodeInfo, error) {
+	acc := aggregate.NewPodUsage()
+	for i := range pods {
+		acc.Add(&pods[i])
 	}
-
-	return nodeInfoList, nil
+	return aggregate.Nodes(ctx, nodes, resourceSlices, resourceClaims, acc)
 }
@@ -2,16 +2,31 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
 	"github.com/dharmjit/k8s-dra-resources/pkg/types"
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	resourcev1 "k8s.io/api/resource/v1"
 	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestGetK8sResources(t *testing.T) {
@@ -158,7 +173,7 @@ func TestGetK8sResources(t *testing.T) {
 			},
 			resourceClaims: []resourcev1beta1.ResourceClaim{
 				{
-					ObjectMeta: metav1.ObjectMeta{Name: "claim-1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
 					Status: resourcev1beta1.ResourceClaimStatus{
 						Allocation: &resourcev1beta1.AllocationResult{
 							Devices: resourcev1beta1.DeviceAllocationResult{
@@ -178,6 +193,7 @@ func TestGetK8sResources(t *testing.T) {
 				{
 					NodeName: "node-1",
 					NodeRole: "worker",
+					Labels:   map[string]string{"node-role.kubernetes.io/worker": ""},
 					NodeCapacity: types.NodeCapacity{
 						TotalCPU:         resource.MustParse("4"),
 						AvailableCPU:     resource.MustParse("2"),
@@ -188,16 +204,31 @@ func TestGetK8sResources(t *testing.T) {
 					},
 					Devices: []types.Device{
 						{
+							Driver:         "gpu.nvidia.com",
 							ProductName:    "NVIDIA GeForce RTX 5090",
 							TotalCount:     2,
 							AvailableCount: 1,
 							Memory:         resource.MustParse("8Gi"),
+							Capacity: map[string]resource.Quantity{
+								"memory": resource.MustParse("8Gi"),
+							},
+						},
+					},
+					Pools: []types.Pool{
+						{
+							Driver:             "gpu.nvidia.com",
+							Name:               "pool-a",
+							ObservedSliceCount: 1,
+							TotalCount:         2,
+							AvailableCount:     1,
 						},
 					},
+					AllocatedClaims: []string{"default/claim-1"},
 				},
 				{
 					NodeName: "node-2",
 					NodeRole: "worker",
+					Labels:   map[string]string{"node-role.kubernetes.io/worker": ""},
 					NodeCapacity: types.NodeCapacity{
 						TotalCPU:         resource.MustParse("4"),
 						AvailableCPU:     resource.MustParse("3"),
@@ -208,14 +239,350 @@ func TestGetK8sResources(t *testing.T) {
 					},
 					Devices: []types.Device{
 						{
+							Driver:         "gpu.nvidia.com",
 							ProductName:    "NVIDIA GeForce RTX 5090",
 							TotalCount:     2,
 							AvailableCount: 2,
 							Memory:         resource.MustParse("8Gi"),
+							Capacity: map[string]resource.Quantity{
+								"memory": resource.MustParse("8Gi"),
+							},
+						},
+					},
+					Pools: []types.Pool{
+						{
+							Driver:             "gpu.nvidia.com",
+							Name:               "pool-b",
+							ObservedSliceCount: 1,
+							TotalCount:         2,
+							AvailableCount:     2,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should resolve driver-qualified attribute and capacity names",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+					Status: corev1.NodeStatus{
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+			resourceSlices: []resourcev1beta1.ResourceSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+					Spec: resourcev1beta1.ResourceSliceSpec{
+						NodeName: "node-1",
+						Driver:   "gpu.nvidia.com",
+						Pool: resourcev1beta1.ResourcePool{
+							Name: "pool-a",
+						},
+						Devices: []resourcev1beta1.Device{
+							{
+								Name: "gpu-0",
+								Basic: &resourcev1beta1.BasicDevice{
+									Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+										"gpu.nvidia.com/productName": {StringValue: stringPtr("NVIDIA A100")},
+									},
+									Capacity: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceCapacity{
+										"gpu.nvidia.com/memory":       {Value: resource.MustParse("40Gi")},
+										"example.com/multiprocessors": {Value: resource.MustParse("108")},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: []*types.NodeInfo{
+				{
+					NodeName: "node-1",
+					NodeRole: "<none>",
+					NodeCapacity: types.NodeCapacity{
+						TotalCPU:        resource.MustParse("4"),
+						AvailableCPU:    resource.MustParse("4"),
+						TotalMemory:     resource.MustParse("16Gi"),
+						AvailableMemory: resource.MustParse("16Gi"),
+					},
+					Devices: []types.Device{
+						{
+							Driver:         "gpu.nvidia.com",
+							ProductName:    "NVIDIA A100",
+							TotalCount:     1,
+							AvailableCount: 1,
+							Memory:         resource.MustParse("40Gi"),
+							Capacity: map[string]resource.Quantity{
+								"memory":                      resource.MustParse("40Gi"),
+								"example.com/multiprocessors": resource.MustParse("108"),
+							},
+						},
+					},
+					Pools: []types.Pool{
+						{
+							Driver:             "gpu.nvidia.com",
+							Name:               "pool-a",
+							ObservedSliceCount: 1,
+							TotalCount:         1,
+							AvailableCount:     1,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should only count the highest generation of a pool",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				},
+			},
+			resourceSlices: []resourcev1beta1.ResourceSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "slice-old"},
+					Spec: resourcev1beta1.ResourceSliceSpec{
+						NodeName: "node-1",
+						Driver:   "gpu.example.com",
+						Pool: resourcev1beta1.ResourcePool{
+							Name:       "pool-a",
+							Generation: 1,
+						},
+						Devices: []resourcev1beta1.Device{
+							{Name: "gpu-0"},
+							{Name: "gpu-1"},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "slice-new"},
+					Spec: resourcev1beta1.ResourceSliceSpec{
+						NodeName: "node-1",
+						Driver:   "gpu.example.com",
+						Pool: resourcev1beta1.ResourcePool{
+							Name:       "pool-a",
+							Generation: 2,
+						},
+						Devices: []resourcev1beta1.Device{
+							{Name: "gpu-0"},
+							{Name: "gpu-1"},
+						},
+					},
+				},
+			},
+			expected: []*types.NodeInfo{
+				{
+					NodeName: "node-1",
+					NodeRole: "<none>",
+					Devices: []types.Device{
+						{
+							Driver:         "gpu.example.com",
+							ProductName:    "gpu.example.com",
+							TotalCount:     2,
+							AvailableCount: 2,
+							Capacity:       map[string]resource.Quantity{},
+						},
+					},
+					Pools: []types.Pool{
+						{
+							Driver:             "gpu.example.com",
+							Name:               "pool-a",
+							Generation:         2,
+							ObservedSliceCount: 1,
+							TotalCount:         2,
+							AvailableCount:     2,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should not attribute allocations across colliding driver and pool names",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				},
+			},
+			resourceSlices: []resourcev1beta1.ResourceSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+					Spec: resourcev1beta1.ResourceSliceSpec{
+						NodeName: "node-1",
+						Driver:   "gpu.example.com",
+						Pool: resourcev1beta1.ResourcePool{
+							Name: "a-b",
+						},
+						Devices: []resourcev1beta1.Device{
+							{Name: "gpu-0"},
+						},
+					},
+				},
+			},
+			resourceClaims: []resourcev1beta1.ResourceClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
+					Status: resourcev1beta1.ResourceClaimStatus{
+						Allocation: &resourcev1beta1.AllocationResult{
+							Devices: resourcev1beta1.DeviceAllocationResult{
+								Results: []resourcev1beta1.DeviceRequestAllocationResult{
+									{
+										Request: "gpu",
+										Driver:  "gpu.example.com-a",
+										Pool:    "b",
+										Device:  "gpu-0",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: []*types.NodeInfo{
+				{
+					NodeName: "node-1",
+					NodeRole: "<none>",
+					Devices: []types.Device{
+						{
+							Driver:         "gpu.example.com",
+							ProductName:    "gpu.example.com",
+							TotalCount:     1,
+							AvailableCount: 1,
+							Capacity:       map[string]resource.Quantity{},
+						},
+					},
+					Pools: []types.Pool{
+						{
+							Driver:             "gpu.example.com",
+							Name:               "a-b",
+							ObservedSliceCount: 1,
+							TotalCount:         1,
+							AvailableCount:     1,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should merge devices of a pool split across several slices",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				},
+			},
+			resourceSlices: []resourcev1beta1.ResourceSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+					Spec: resourcev1beta1.ResourceSliceSpec{
+						NodeName: "node-1",
+						Driver:   "gpu.example.com",
+						Pool: resourcev1beta1.ResourcePool{
+							Name:               "pool-a",
+							ResourceSliceCount: 2,
+						},
+						Devices: []resourcev1beta1.Device{
+							{Name: "gpu-0"},
+							{Name: "gpu-1"},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "slice-2"},
+					Spec: resourcev1beta1.ResourceSliceSpec{
+						NodeName: "node-1",
+						Driver:   "gpu.example.com",
+						Pool: resourcev1beta1.ResourcePool{
+							Name:               "pool-a",
+							ResourceSliceCount: 2,
+						},
+						Devices: []resourcev1beta1.Device{
+							{Name: "gpu-2"},
+						},
+					},
+				},
+			},
+			resourceClaims: []resourcev1beta1.ResourceClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
+					Status: resourcev1beta1.ResourceClaimStatus{
+						Allocation: &resourcev1beta1.AllocationResult{
+							Devices: resourcev1beta1.DeviceAllocationResult{
+								Results: []resourcev1beta1.DeviceRequestAllocationResult{
+									{
+										Driver: "gpu.example.com",
+										Pool:   "pool-a",
+										Device: "gpu-2",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: []*types.NodeInfo{
+				{
+					NodeName: "node-1",
+					NodeRole: "<none>",
+					Devices: []types.Device{
+						{
+							Driver:         "gpu.example.com",
+							ProductName:    "gpu.example.com",
+							TotalCount:     3,
+							AvailableCount: 2,
+							Capacity:       map[string]resource.Quantity{},
+						},
+					},
+					Pools: []types.Pool{
+						{
+							Driver:             "gpu.example.com",
+							Name:               "pool-a",
+							ResourceSliceCount: 2,
+							ObservedSliceCount: 2,
+							TotalCount:         3,
+							AvailableCount:     2,
+						},
+					},
+					AllocatedClaims: []string{"default/claim-1"},
+				},
+			},
+		},
+		{
+			name: "should report cordoned and not ready nodes",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+					Spec:       corev1.NodeSpec{Unschedulable: true},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+					Status: corev1.NodeStatus{
+						Conditions: []corev1.NodeCondition{
+							{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
 						},
 					},
 				},
 			},
+			expected: []*types.NodeInfo{
+				{
+					NodeName:      "node-1",
+					NodeRole:      "<none>",
+					Unschedulable: true,
+					Devices:       []types.Device{},
+				},
+				{
+					NodeName: "node-2",
+					NodeRole: "<none>",
+					NotReady: true,
+					Devices:  []types.Device{},
+				},
+			},
 		},
 	}
 
@@ -252,14 +619,14 @@ func TestGetK8sResources(t *testing.T) {
 				}
 			}
 			for i := range tc.resourceClaims {
-				_, err := client.ResourceV1beta1().ResourceClaims("").Create(context.Background(), &tc.resourceClaims[i], metav1.CreateOptions{})
+				_, err := client.ResourceV1beta1().ResourceClaims(tc.resourceClaims[i].Namespace).Create(context.Background(), &tc.resourceClaims[i], metav1.CreateOptions{})
 				if err != nil {
 					t.Fatalf("failed to create resource claim: %v", err)
 				}
 			}
 
 			rc := &resourceClient{typedClient: client}
-			got, err := rc.GetK8sResources(context.Background())
+			got, err := rc.GetK8sResources(context.Background(), ListOptions{})
 			if (err != nil) != tc.expectErr {
 				t.Fatalf("GetK8sResources() error = %v, expectErr %v", err, tc.expectErr)
 			}
@@ -281,3 +648,2537 @@ func TestGetK8sResources(t *testing.T) {
 		})
 	}
 }
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestGetOrphanedResourceClaims(t *testing.T) {
+	allocation := &resourcev1beta1.AllocationResult{
+		Devices: resourcev1beta1.DeviceAllocationResult{
+			Results: []resourcev1beta1.DeviceRequestAllocationResult{
+				{Driver: "gpu.example.com", Pool: "pool-a", Device: "gpu-0"},
+			},
+		},
+	}
+
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "alive", Namespace: "default", UID: "uid-alive"}},
+	}
+
+	claims := []resourcev1beta1.ResourceClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "in-use", Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: allocation,
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{
+					{Resource: "pods", Name: "alive", UID: "uid-alive"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "consumer-gone", Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: allocation,
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{
+					{Resource: "pods", Name: "gone", UID: "uid-gone"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "owner-gone",
+				Namespace: "team-a",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "v1", Kind: "Pod", Name: "gone", UID: "uid-gone"},
+				},
+			},
+			Status: resourcev1beta1.ResourceClaimStatus{Allocation: allocation},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-yet-reserved", Namespace: "default"},
+			Status:     resourcev1beta1.ResourceClaimStatus{Allocation: allocation},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "unallocated", Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{
+					{Resource: "pods", Name: "gone", UID: "uid-gone"},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset()
+	for i := range pods {
+		if _, err := client.CoreV1().Pods(pods[i].Namespace).Create(context.Background(), &pods[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create pod: %v", err)
+		}
+	}
+	for i := range claims {
+		if _, err := client.ResourceV1beta1().ResourceClaims(claims[i].Namespace).Create(context.Background(), &claims[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create resource claim: %v", err)
+		}
+	}
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetOrphanedResourceClaims(context.Background())
+	if err != nil {
+		t.Fatalf("GetOrphanedResourceClaims() error = %v", err)
+	}
+
+	expected := []*types.ClaimInfo{
+		{
+			Namespace: "default",
+			Name:      "consumer-gone",
+			Allocated: true,
+			Devices:   []string{"gpu.example.com/pool-a/gpu-0"},
+			Consumers: []string{"gone"},
+		},
+		{
+			Namespace: "team-a",
+			Name:      "owner-gone",
+			Allocated: true,
+			Devices:   []string{"gpu.example.com/pool-a/gpu-0"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestDeleteResourceClaimPreconditions(t *testing.T) {
+	ctx := context.Background()
+	claim := func(uid k8stypes.UID) *resourcev1beta1.ResourceClaim {
+		return &resourcev1beta1.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "train-gpu", UID: uid, ResourceVersion: "1"}}
+	}
+	client := fake.NewSimpleClientset(claim("uid-listed"))
+	// the object tracker ignores preconditions, so check them like the API
+	// server does
+	gvr := resourcev1beta1.SchemeGroupVersion.WithResource("resourceclaims")
+	client.PrependReactor("delete", "resourceclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(k8stesting.DeleteActionImpl)
+		obj, err := client.Tracker().Get(gvr, deleteAction.Namespace, deleteAction.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		current := obj.(*resourcev1beta1.ResourceClaim)
+		preconditions := deleteAction.DeleteOptions.Preconditions
+		if preconditions == nil {
+			return false, nil, nil
+		}
+		if preconditions.UID == nil || preconditions.ResourceVersion == nil {
+			t.Errorf("delete without UID and resourceVersion preconditions: %+v", preconditions)
+		}
+		if preconditions.UID != nil && *preconditions.UID != current.UID ||
+			preconditions.ResourceVersion != nil && *preconditions.ResourceVersion != current.ResourceVersion {
+			return true, nil, apierrors.NewConflict(gvr.GroupResource(), deleteAction.Name, errors.New("precondition failed"))
+		}
+		return false, nil, nil
+	})
+	rc := &resourceClient{typedClient: client}
+
+	listed, err := rc.GetResourceClaims(ctx, ListOptions{Namespace: "default"})
+	if err != nil {
+		t.Fatalf("GetResourceClaims() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].UID != "uid-listed" || listed[0].ResourceVersion != "1" {
+		t.Fatalf("listed claims = %+v, want train-gpu with its UID and resourceVersion", listed)
+	}
+
+	// a job resubmitted after listing creates a claim of the same name
+	if err := client.ResourceV1beta1().ResourceClaims("default").Delete(ctx, "train-gpu", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ResourceV1beta1().ResourceClaims("default").Create(ctx, claim("uid-recreated"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.DeleteResourceClaim(ctx, listed[0]); !errors.Is(err, ErrChangedSinceListing) {
+		t.Fatalf("DeleteResourceClaim() error = %v, want ErrChangedSinceListing", err)
+	}
+	if _, err := client.ResourceV1beta1().ResourceClaims("default").Get(ctx, "train-gpu", metav1.GetOptions{}); err != nil {
+		t.Errorf("recreated claim was deleted: %v", err)
+	}
+
+	listed, err = rc.GetResourceClaims(ctx, ListOptions{Namespace: "default"})
+	if err != nil {
+		t.Fatalf("GetResourceClaims() error = %v", err)
+	}
+	if err := rc.DeleteResourceClaim(ctx, listed[0]); err != nil {
+		t.Fatalf("DeleteResourceClaim() error = %v", err)
+	}
+	if _, err := client.ResourceV1beta1().ResourceClaims("default").Get(ctx, "train-gpu", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("unchanged claim was not deleted: %v", err)
+	}
+}
+
+func TestGetProductAvailability(t *testing.T) {
+	slices := []resourcev1beta1.ResourceSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1"},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}, {Name: "gpu-1"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-2"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-2",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-2"},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}},
+			},
+		},
+	}
+	claims := []resourcev1beta1.ResourceClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "team-b"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Driver: "gpu.example.com", Pool: "node-2", Device: "gpu-0"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset()
+	for i := range slices {
+		if _, err := client.ResourceV1beta1().ResourceSlices().Create(context.Background(), &slices[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create resource slice: %v", err)
+		}
+	}
+	for i := range claims {
+		if _, err := client.ResourceV1beta1().ResourceClaims(claims[i].Namespace).Create(context.Background(), &claims[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create resource claim: %v", err)
+		}
+	}
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetProductAvailability(context.Background())
+	if err != nil {
+		t.Fatalf("GetProductAvailability() error = %v", err)
+	}
+
+	expected := []types.ProductAvailability{
+		{ProductName: "gpu.example.com", TotalCount: 3, AvailableCount: 2},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestCachingClient(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cached := NewCachingClient(&resourceClient{typedClient: client}, t.TempDir(), "kubeconfig/context", 30*time.Second).(*cachingClient)
+	cached.now = func() time.Time { return now }
+
+	nodeNames := func() []string {
+		t.Helper()
+		nodeInfoList, err := cached.GetK8sResources(context.Background(), ListOptions{})
+		if err != nil {
+			t.Fatalf("GetK8sResources() error = %v", err)
+		}
+		var names []string
+		for _, nodeInfo := range nodeInfoList {
+			names = append(names, nodeInfo.NodeName)
+		}
+		return names
+	}
+
+	if diff := cmp.Diff(nodeNames(), []string{"node-1"}); diff != "" {
+		t.Fatalf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := client.CoreV1().Nodes().Create(context.Background(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if diff := cmp.Diff(nodeNames(), []string{"node-1"}); diff != "" {
+		t.Errorf("expected the cached snapshot within the TTL (-got +want):\n%s", diff)
+	}
+
+	now = now.Add(30 * time.Second)
+	if diff := cmp.Diff(nodeNames(), []string{"node-1", "node-2"}); diff != "" {
+		t.Errorf("expected a fresh snapshot after the TTL (-got +want):\n%s", diff)
+	}
+}
+
+func TestMirror(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "trainer", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Containers: []corev1.Container{{
+					Name:      "main",
+					Image:     "trainer:latest",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+				}},
+			},
+		},
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1"},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}},
+			},
+		},
+	)
+	rc := &resourceClient{typedClient: client}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	mirror, err := rc.Mirror(ctx, ListOptions{}, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("Mirror() error = %v", err)
+	}
+	listed := len(client.Actions())
+
+	got, err := mirror.Nodes(ctx)
+	if err != nil {
+		t.Fatalf("Nodes() error = %v", err)
+	}
+	expected, err := rc.GetK8sResources(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mirrored nodes differ from listed ones (-got +want):\n%s", diff)
+	}
+	client.ClearActions()
+
+	if _, err := client.CoreV1().Nodes().Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no change reported for the new node")
+	}
+	got, err = mirror.Nodes(ctx)
+	if err != nil {
+		t.Fatalf("Nodes() error = %v", err)
+	}
+	var names []string
+	for _, nodeInfo := range got {
+		names = append(names, nodeInfo.NodeName)
+	}
+	if diff := cmp.Diff(names, []string{"node-1", "node-2"}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	devices, err := mirror.Devices(ctx)
+	if err != nil {
+		t.Fatalf("Devices() error = %v", err)
+	}
+	if len(devices) != 1 || devices[0].Name != "gpu-0" {
+		t.Errorf("Devices() = %v, want gpu-0", devices)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" {
+			t.Errorf("aggregating from the mirror listed %s", action.GetResource().Resource)
+		}
+	}
+	if listed == 0 {
+		t.Error("Mirror() did not list the cluster")
+	}
+}
+
+func TestMirrorSelectsNodes(t *testing.T) {
+	slice := func(nodeName string) *resourcev1beta1.ResourceSlice {
+		return &resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName + "-gpus"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: nodeName,
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: nodeName},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "gpu-node", Labels: map[string]string{"gpu": "true"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cpu-node"}},
+		slice("gpu-node"),
+		slice("cpu-node"),
+	)
+	rc := &resourceClient{typedClient: client}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := ListOptions{NodeSelector: "gpu=true"}
+	mirror, err := rc.Mirror(ctx, opts, func() {})
+	if err != nil {
+		t.Fatalf("Mirror() error = %v", err)
+	}
+	var nodeSelectors []string
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "nodes" {
+			nodeSelectors = append(nodeSelectors, action.(k8stesting.ListAction).GetListRestrictions().Labels.String())
+		}
+	}
+	if diff := cmp.Diff(nodeSelectors, []string{"gpu=true"}); diff != "" {
+		t.Errorf("expected nodes to be listed with the node selector (-got +want):\n%s", diff)
+	}
+
+	got, err := mirror.Nodes(ctx)
+	if err != nil {
+		t.Fatalf("Nodes() error = %v", err)
+	}
+	expected, err := rc.GetK8sResources(ctx, opts)
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mirrored nodes differ from listed ones (-got +want):\n%s", diff)
+	}
+	if len(got) != 1 || got[0].NodeName != "gpu-node" {
+		t.Errorf("Nodes() = %v, want only gpu-node", got)
+	}
+
+	if _, err := rc.Mirror(ctx, ListOptions{NodeSelector: "gpu in"}, func() {}); err == nil {
+		t.Error("expected an error for an invalid node selector")
+	}
+}
+
+func TestForEachPodPaginates(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	pages := map[string]*corev1.PodList{
+		"": {
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items:    []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}}},
+		},
+		"page-2": {
+			Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pod-2"}}},
+		},
+	}
+	var calls []metav1.ListOptions
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).GetListOptions()
+		calls = append(calls, opts)
+		if len(calls) > len(pages) {
+			return true, nil, errors.New("listed more pages than there are")
+		}
+		return true, pages[opts.Continue], nil
+	})
+
+	rc := &resourceClient{typedClient: client}
+	var names []string
+	var progress []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		progress = append(progress, p)
+	})
+	err := rc.forEachPod(ctx, ListOptions{NodeName: "node-1"}, func(pod *corev1.Pod) {
+		names = append(names, pod.Name)
+	})
+	if err != nil {
+		t.Fatalf("forEachPod() error = %v", err)
+	}
+	if diff := cmp.Diff(names, []string{"pod-1", "pod-2"}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	expectedCalls := []metav1.ListOptions{
+		{FieldSelector: "spec.nodeName=node-1", Limit: 500},
+		{FieldSelector: "spec.nodeName=node-1", Limit: 500, Continue: "page-2"},
+	}
+	if diff := cmp.Diff(calls, expectedCalls); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	expectedProgress := []Progress{
+		{Resource: "pods"},
+		{Resource: "pods", Listed: 1},
+		{Resource: "pods", Listed: 2, Done: true},
+	}
+	if diff := cmp.Diff(progress, expectedProgress); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetK8sResourcesWithOptions(t *testing.T) {
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"gpu": "true"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+	}
+	slices := []resourcev1beta1.ResourceSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1"},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}, {Name: "gpu-1"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-2"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-2",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-2"},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}},
+			},
+		},
+	}
+	claim := &resourcev1beta1.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "team-b"},
+		Status: resourcev1beta1.ResourceClaimStatus{
+			Allocation: &resourcev1beta1.AllocationResult{
+				Devices: resourcev1beta1.DeviceAllocationResult{
+					Results: []resourcev1beta1.DeviceRequestAllocationResult{
+						{Driver: "gpu.example.com", Pool: "node-2", Device: "gpu-0"},
+					},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(claim)
+	for i := range nodes {
+		if _, err := client.CoreV1().Nodes().Create(context.Background(), &nodes[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+	}
+	for i := range slices {
+		if _, err := client.ResourceV1beta1().ResourceSlices().Create(context.Background(), &slices[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create resource slice: %v", err)
+		}
+	}
+
+	var sliceSelectors []string
+	client.PrependReactor("list", "resourceslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sliceSelectors = append(sliceSelectors, action.(k8stesting.ListAction).GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+
+	rc := &resourceClient{typedClient: client}
+	nodeInfoList, err := rc.GetK8sResources(context.Background(), ListOptions{NodeName: "node-2", Namespace: "team-a"})
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	if len(nodeInfoList) != 1 || nodeInfoList[0].NodeName != "node-2" {
+		t.Fatalf("expected only node-2, got %v", nodeInfoList)
+	}
+	got := nodeInfoList[0]
+	expectedDevices := []types.Device{
+		{Driver: "gpu.example.com", ProductName: "gpu.example.com", TotalCount: 1, AvailableCount: 0, Capacity: map[string]resource.Quantity{}},
+	}
+	if diff := cmp.Diff(got.Devices, expectedDevices, cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Equal(y)
+	})); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(got.AllocatedClaims, []string{"team-b/claim-1"}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(sliceSelectors, []string{"spec.nodeName=node-2"}); diff != "" {
+		t.Errorf("expected a spec.nodeName field selector (-got +want):\n%s", diff)
+	}
+
+	if _, err := rc.GetK8sResources(context.Background(), ListOptions{NodeName: "node-3"}); err == nil {
+		t.Error("expected an error for an unknown node")
+	}
+
+	nodeInfoList, err = rc.GetK8sResources(context.Background(), ListOptions{NodeSelector: "gpu=true"})
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	if len(nodeInfoList) != 1 || nodeInfoList[0].NodeName != "node-1" {
+		t.Errorf("expected only the labeled node-1, got %v", nodeInfoList)
+	}
+	nodeInfoList, err = rc.GetK8sResources(context.Background(), ListOptions{NodeName: "node-2", NodeSelector: "gpu=true"})
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	if len(nodeInfoList) != 0 {
+		t.Errorf("expected no node matching both name and selector, got %v", nodeInfoList)
+	}
+
+	sliceSelectors = nil
+	devices, err := rc.GetDevices(context.Background(), ListOptions{Driver: "gpu.example.com", NodeSelector: "gpu=true"})
+	if err != nil {
+		t.Fatalf("GetDevices() error = %v", err)
+	}
+	var names []string
+	for _, dev := range devices {
+		names = append(names, dev.NodeName+"/"+dev.Name)
+	}
+	if diff := cmp.Diff(names, []string{"node-1/gpu-0", "node-1/gpu-1"}); diff != "" {
+		t.Errorf("expected the devices of the selected nodes (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(sliceSelectors, []string{"spec.driver=gpu.example.com"}); diff != "" {
+		t.Errorf("expected a spec.driver field selector (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetClaimTemplateStats(t *testing.T) {
+	objects := []runtime.Object{
+		&resourcev1beta1.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "gpu.example.com"}},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: "team-a"},
+			Spec: resourcev1beta1.ResourceClaimTemplateSpec{
+				Spec: resourcev1beta1.ResourceClaimSpec{
+					Devices: resourcev1beta1.DeviceClaim{
+						Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: "gpu.example.com"}},
+					},
+				},
+			},
+		},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "typo", Namespace: "team-a"},
+			Spec: resourcev1beta1.ResourceClaimTemplateSpec{
+				Spec: resourcev1beta1.ResourceClaimSpec{
+					Devices: resourcev1beta1.DeviceClaim{
+						Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: "gpu.exmaple.com"}},
+					},
+				},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-1-gpu-abcde", Namespace: "team-a"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-2-gpu-fghij", Namespace: "team-a"},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-3-gpu-klmno", Namespace: "team-a"},
+		},
+	}
+	for _, p := range []struct{ pod, template, claim string }{
+		{"pod-1", "gpu", "pod-1-gpu-abcde"},
+		{"pod-2", "gpu", "pod-2-gpu-fghij"},
+		{"pod-3", "typo", "pod-3-gpu-klmno"},
+	} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: p.pod, Namespace: "team-a"},
+			Spec: corev1.PodSpec{
+				ResourceClaims: []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: stringPtr(p.template)}},
+			},
+			Status: corev1.PodStatus{
+				ResourceClaimStatuses: []corev1.PodResourceClaimStatus{{Name: "gpu", ResourceClaimName: stringPtr(p.claim)}},
+			},
+		})
+	}
+
+	rc := &resourceClient{typedClient: fake.NewSimpleClientset(objects...)}
+	got, err := rc.GetClaimTemplateStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetClaimTemplateStats() error = %v", err)
+	}
+
+	expected := []types.ClaimTemplateStats{
+		{Namespace: "team-a", Name: "gpu", Claims: 2, Allocated: 1, Pending: 1},
+		{Namespace: "team-a", Name: "typo", Claims: 1, Pending: 1, MissingDeviceClasses: []string{"gpu.exmaple.com"}},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetClaimMutations(t *testing.T) {
+	templateSpec := resourcev1beta1.ResourceClaimSpec{
+		Devices: resourcev1beta1.DeviceClaim{
+			Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: "gpu.example.com"}},
+		},
+	}
+	// the stored claim was defaulted by the API server and got a selector
+	// from a mutating webhook
+	storedSpec := resourcev1beta1.ResourceClaimSpec{
+		Devices: resourcev1beta1.DeviceClaim{
+			Requests: []resourcev1beta1.DeviceRequest{{
+				Name:            "gpu",
+				DeviceClassName: "gpu.example.com",
+				AllocationMode:  resourcev1beta1.DeviceAllocationModeExactCount,
+				Count:           1,
+				Selectors: []resourcev1beta1.DeviceSelector{
+					{CEL: &resourcev1beta1.CELDeviceSelector{Expression: `device.attributes["gpu.example.com"].zone == "a"`}},
+				},
+			}},
+		},
+	}
+	objects := []runtime.Object{
+		&resourcev1beta1.DeviceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu.example.com"},
+			Spec: resourcev1beta1.DeviceClassSpec{
+				Config: []resourcev1beta1.DeviceClassConfiguration{{
+					DeviceConfiguration: resourcev1beta1.DeviceConfiguration{
+						Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{
+							Driver:     "gpu.example.com",
+							Parameters: runtime.RawExtension{Raw: []byte(`{"sharing":"timeSlicing"}`)},
+						},
+					},
+				}},
+			},
+		},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: "team-a"},
+			Spec:       resourcev1beta1.ResourceClaimTemplateSpec{Spec: templateSpec},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "team-a"},
+			Spec: corev1.PodSpec{
+				ResourceClaims: []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: stringPtr("gpu")}},
+			},
+			Status: corev1.PodStatus{
+				ResourceClaimStatuses: []corev1.PodResourceClaimStatus{{Name: "gpu", ResourceClaimName: stringPtr("pod-1-gpu-abcde")}},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "pod-1-gpu-abcde",
+				Namespace:       "team-a",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Pod", Name: "pod-1"}},
+			},
+			Spec: storedSpec,
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "applied",
+				Namespace:   "team-a",
+				Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: `{"apiVersion":"resource.k8s.io/v1beta1","kind":"ResourceClaim","spec":{"devices":{"requests":[{"name":"gpu","deviceClassName":"gpu.example.com","count":2}]}}}`},
+			},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{
+					Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: "gpu.example.com", AllocationMode: resourcev1beta1.DeviceAllocationModeExactCount, Count: 2}},
+				},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "unknown", Namespace: "team-a"},
+			Spec:       storedSpec,
+		},
+	}
+	classConfigs := []types.ClassConfig{{Request: "gpu", DeviceClass: "gpu.example.com", Driver: "gpu.example.com", Parameters: `{"sharing":"timeSlicing"}`}}
+
+	tests := []struct {
+		name     string
+		claim    string
+		expected *types.ClaimMutations
+	}{
+		{
+			name:  "from template",
+			claim: "pod-1-gpu-abcde",
+			expected: &types.ClaimMutations{
+				Namespace: "team-a",
+				Name:      "pod-1-gpu-abcde",
+				Source:    "ResourceClaimTemplate team-a/gpu of pod pod-1",
+				Changes: []types.FieldChange{
+					{Path: "devices.requests[0].allocationMode", To: `"ExactCount"`, Reason: ChangeDefaulted},
+					{Path: "devices.requests[0].count", To: "1", Reason: ChangeDefaulted},
+					{Path: "devices.requests[0].selectors[0].cel.expression", To: `"device.attributes[\"gpu.example.com\"].zone == \"a\""`, Reason: ChangeAdded},
+				},
+				ClassConfigs: classConfigs,
+			},
+		},
+		{
+			name:  "from last applied configuration",
+			claim: "applied",
+			expected: &types.ClaimMutations{
+				Namespace: "team-a",
+				Name:      "applied",
+				Source:    "last applied configuration",
+				Changes: []types.FieldChange{
+					{Path: "devices.requests[0].allocationMode", To: `"ExactCount"`, Reason: ChangeDefaulted},
+				},
+				ClassConfigs: classConfigs,
+			},
+		},
+		{
+			name:     "unknown source",
+			claim:    "unknown",
+			expected: &types.ClaimMutations{Namespace: "team-a", Name: "unknown", ClassConfigs: classConfigs},
+		},
+	}
+	rc := &resourceClient{typedClient: fake.NewSimpleClientset(objects...)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rc.GetClaimMutations(context.Background(), "team-a", tt.claim)
+			if err != nil {
+				t.Fatalf("GetClaimMutations() error = %v", err)
+			}
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetDeviceClasses(t *testing.T) {
+	client := fake.NewSimpleClientset(&resourcev1beta1.DeviceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+		Spec: resourcev1beta1.DeviceClassSpec{
+			Selectors: []resourcev1beta1.DeviceSelector{
+				{CEL: &resourcev1beta1.CELDeviceSelector{Expression: `device.driver == "gpu.nvidia.com"`}},
+			},
+			Config: []resourcev1beta1.DeviceClassConfiguration{{
+				DeviceConfiguration: resourcev1beta1.DeviceConfiguration{
+					Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{
+						Driver:     "gpu.nvidia.com",
+						Parameters: runtime.RawExtension{Raw: []byte("{\n  \"sharing\": \"timeSlicing\"\n}")},
+					},
+				},
+			}},
+		},
+	})
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetDeviceClasses(context.Background())
+	if err != nil {
+		t.Fatalf("GetDeviceClasses() error = %v", err)
+	}
+
+	expected := []types.DeviceClassInfo{{
+		Name:      "gpu",
+		Selectors: []string{`device.driver == "gpu.nvidia.com"`},
+		Config:    []string{`gpu.nvidia.com: {"sharing":"timeSlicing"}`},
+	}}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetClassConsumers(t *testing.T) {
+	controller := true
+	client := fake.NewSimpleClientset(
+		&resourcev1beta1.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "trainer", Namespace: "team-a"},
+			Spec: resourcev1beta1.ResourceClaimTemplateSpec{Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: "gpu"}}},
+			}},
+		},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "nic", Namespace: "team-a"},
+			Spec: resourcev1beta1.ResourceClaimTemplateSpec{Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{{Name: "nic", DeviceClassName: "nic"}}},
+			}},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "train", Namespace: "team-a"},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{
+					{Name: "gpu", DeviceClassName: "gpu"},
+					{Name: "nic", DeviceClassName: "nic"},
+				}},
+			},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+							{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1"},
+							{Request: "nic", Driver: "nic.example.com", Pool: "node-1", Device: "nic-0"},
+						},
+					},
+				},
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{{Resource: "pods", Name: "train-0"}},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: "default"},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{{
+					Name: "accel",
+					FirstAvailable: []resourcev1beta1.DeviceSubRequest{
+						{Name: "big", DeviceClassName: "big-gpu"},
+						{Name: "small", DeviceClassName: "gpu"},
+					},
+				}}},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "network", Namespace: "default"},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{{Name: "nic", DeviceClassName: "nic"}}},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "train-0",
+			Namespace:       "team-a",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "train", Controller: &controller}},
+		}},
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetClassConsumers(context.Background(), "gpu")
+	if err != nil {
+		t.Fatalf("GetClassConsumers() error = %v", err)
+	}
+
+	expected := &types.ClassConsumers{
+		Class: types.DeviceClassInfo{Name: "gpu"},
+		Claims: []types.ClassConsumer{
+			{Namespace: "default", Name: "fallback"},
+			{Namespace: "team-a", Name: "train", Allocated: true, Devices: 2, Consumers: []string{"train-0"}, Owners: []string{"StatefulSet/train"}},
+		},
+		Templates: []string{"team-a/trainer"},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := rc.GetClassConsumers(context.Background(), "missing"); err == nil {
+		t.Errorf("GetClassConsumers() of a missing class error = nil, want an error")
+	}
+}
+
+func TestSimulateClassChange(t *testing.T) {
+	class := func(expression string) *resourcev1beta1.DeviceClass {
+		return &resourcev1beta1.DeviceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+			Spec: resourcev1beta1.DeviceClassSpec{
+				Selectors: []resourcev1beta1.DeviceSelector{{CEL: &resourcev1beta1.CELDeviceSelector{Expression: expression}}},
+			},
+		}
+	}
+	slice := func(nodeName, productName string) *resourcev1beta1.ResourceSlice {
+		return &resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: nodeName,
+				Driver:   "gpu.nvidia.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: nodeName, ResourceSliceCount: 1},
+				Devices: []resourcev1beta1.Device{{
+					Name: "gpu-0",
+					Basic: &resourcev1beta1.BasicDevice{
+						Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+							"productName": {StringValue: &productName},
+						},
+					},
+				}},
+			},
+		}
+	}
+	claim := func(name string, count int64, allocated ...resourcev1beta1.DeviceRequestAllocationResult) *resourcev1beta1.ResourceClaim {
+		rc := &resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{
+					{Name: "gpu", DeviceClassName: "gpu", AllocationMode: resourcev1beta1.DeviceAllocationModeExactCount, Count: count},
+				}},
+			},
+		}
+		if len(allocated) > 0 {
+			rc.Status.Allocation = &resourcev1beta1.AllocationResult{
+				Devices: resourcev1beta1.DeviceAllocationResult{Results: allocated},
+			}
+		}
+		return rc
+	}
+	client := fake.NewSimpleClientset(
+		class(`device.attributes["gpu.nvidia.com"].productName == "A100"`),
+		slice("node-1", "A100"),
+		slice("node-2", "H100"),
+		claim("running", 1, resourcev1beta1.DeviceRequestAllocationResult{Request: "gpu", Driver: "gpu.nvidia.com", Pool: "node-1", Device: "gpu-0"}),
+		claim("pending", 1),
+		claim("too-large", 2),
+	)
+
+	rc := &resourceClient{typedClient: client}
+	proposed := model.FromV1beta1DeviceClass(class(`device.attributes["gpu.nvidia.com"].productName == "H100"`))
+	got, err := rc.SimulateClassChange(context.Background(), &proposed)
+	if err != nil {
+		t.Fatalf("SimulateClassChange() error = %v", err)
+	}
+
+	expected := &types.ClassChange{
+		Class: "gpu",
+		Unmatched: []types.DeviceInfo{
+			{NodeName: "node-1", Driver: "gpu.nvidia.com", Pool: "node-1", Name: "gpu-0", ProductName: "A100", Claim: "default/running", Request: "gpu"},
+		},
+		Matched: []types.DeviceInfo{
+			{NodeName: "node-2", Driver: "gpu.nvidia.com", Pool: "node-2", Name: "gpu-0", ProductName: "H100"},
+		},
+		Satisfiable: []string{"default/pending"},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	invalid := model.FromV1beta1DeviceClass(class(`device.attributes[`))
+	if _, err := rc.SimulateClassChange(context.Background(), &invalid); err == nil {
+		t.Errorf("SimulateClassChange() with an invalid selector error = nil, want an error")
+	}
+}
+
+func TestAttributeInventory(t *testing.T) {
+	device := func(name, driverVersion string) resourcev1beta1.Device {
+		return resourcev1beta1.Device{
+			Name: name,
+			Basic: &resourcev1beta1.BasicDevice{
+				Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+					"gpu.example.com/driverVersion": {VersionValue: stringPtr(driverVersion)},
+				},
+			},
+		}
+	}
+	slices := []resourcev1beta1.ResourceSlice{
+		{Spec: resourcev1beta1.ResourceSliceSpec{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: resourcev1beta1.ResourcePool{Name: "node-1"},
+			Devices: []resourcev1beta1.Device{device("gpu-0", "550.54.15"), device("gpu-1", "550.54.15")},
+		}},
+		{Spec: resourcev1beta1.ResourceSliceSpec{
+			NodeName: "node-2", Driver: "gpu.example.com", Pool: resourcev1beta1.ResourcePool{Name: "node-2"},
+			Devices: []resourcev1beta1.Device{device("gpu-0", "535.104.5")},
+		}},
+		{Spec: resourcev1beta1.ResourceSliceSpec{
+			NodeName: "node-3", Driver: "gpu.example.com", Pool: resourcev1beta1.ResourcePool{Name: "node-3"},
+			Devices: []resourcev1beta1.Device{device("gpu-0", "550.54.15"), {Name: "gpu-1"}},
+		}},
+	}
+
+	got, err := attributeInventory(context.Background(), model.FromV1beta1ResourceSlices(slices), []string{"driverVersion", "cudaDriverVersion"}, false)
+	if err != nil {
+		t.Fatalf("attributeInventory() error = %v", err)
+	}
+
+	expected := []types.AttributeInventory{{
+		Driver:    "gpu.example.com",
+		Attribute: "driverVersion",
+		Values: []types.AttributeValue{
+			{Value: "550.54.15", Devices: 3, Nodes: []string{"node-1", "node-3"}},
+			{Value: "535.104.5", Devices: 1, Nodes: []string{"node-2"}, Outdated: true},
+		},
+	}}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetDevices(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"karpenter.sh/capacity-type": "spot"},
+		}},
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1"},
+				Devices: []resourcev1beta1.Device{
+					{Name: "gpu-1"},
+					{Name: "gpu-0", Basic: &resourcev1beta1.BasicDevice{
+						Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+							"gpu.example.com/vbiosVersion": {StringValue: stringPtr("92.00.36.00.01")},
+						},
+					}},
+				},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1"},
+						},
+					},
+				},
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{
+					{Resource: "pods", Name: "inference-0"},
+					{Resource: "pods", Name: "inference-1"},
+				},
+			},
+		},
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetDevices(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetDevices() error = %v", err)
+	}
+
+	expected := []types.DeviceInfo{
+		{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", ProductName: "gpu.example.com", Spot: true,
+			Attributes: map[string]string{"vbiosVersion": "92.00.36.00.01"},
+		},
+		{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-1", ProductName: "gpu.example.com", Spot: true,
+			Claim: "default/claim-1", Consumers: []string{"inference-0", "inference-1"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetDevicesV1beta2(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&resourcev1beta2.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+			Spec: resourcev1beta2.ResourceSliceSpec{
+				NodeName: stringPtr("node-1"),
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta2.ResourcePool{Name: "node-1"},
+				Devices: []resourcev1beta2.Device{
+					{Name: "gpu-0", Attributes: map[resourcev1beta2.QualifiedName]resourcev1beta2.DeviceAttribute{
+						"gpu.example.com/vbiosVersion": {StringValue: stringPtr("92.00.36.00.01")},
+					}},
+					{Name: "gpu-1"},
+				},
+			},
+		},
+		&resourcev1beta2.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
+			Status: resourcev1beta2.ResourceClaimStatus{
+				Allocation: &resourcev1beta2.AllocationResult{
+					Devices: resourcev1beta2.DeviceAllocationResult{
+						Results: []resourcev1beta2.DeviceRequestAllocationResult{
+							{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1"},
+						},
+					},
+				},
+			},
+		},
+	)
+	client.Resources = []*metav1.APIResourceList{{GroupVersion: "resource.k8s.io/v1beta2"}}
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetDevices(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetDevices() error = %v", err)
+	}
+	if rc.resourceAPIVersion() != "v1beta2" {
+		t.Errorf("resourceAPIVersion() = %s, want v1beta2", rc.resourceAPIVersion())
+	}
+
+	expected := []types.DeviceInfo{
+		{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", ProductName: "gpu.example.com",
+			Attributes: map[string]string{"vbiosVersion": "92.00.36.00.01"},
+		},
+		{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-1", ProductName: "gpu.example.com",
+			Claim: "default/claim-1",
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetDevicesV1(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&resourcev1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice-1"},
+			Spec: resourcev1.ResourceSliceSpec{
+				NodeName: stringPtr("node-1"),
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1.ResourcePool{Name: "node-1"},
+				Devices: []resourcev1.Device{
+					{Name: "gpu-0", Attributes: map[resourcev1.QualifiedName]resourcev1.DeviceAttribute{
+						"gpu.example.com/vbiosVersion": {StringValue: stringPtr("92.00.36.00.01")},
+					}},
+					{Name: "gpu-1"},
+				},
+			},
+		},
+		&resourcev1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
+			Status: resourcev1.ResourceClaimStatus{
+				Allocation: &resourcev1.AllocationResult{
+					Devices: resourcev1.DeviceAllocationResult{
+						Results: []resourcev1.DeviceRequestAllocationResult{
+							{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1"},
+						},
+					},
+				},
+			},
+		},
+	)
+	client.Resources = []*metav1.APIResourceList{{GroupVersion: "resource.k8s.io/v1"}}
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetDevices(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetDevices() error = %v", err)
+	}
+	if rc.resourceAPIVersion() != "v1" {
+		t.Errorf("resourceAPIVersion() = %s, want v1", rc.resourceAPIVersion())
+	}
+
+	expected := []types.DeviceInfo{
+		{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", ProductName: "gpu.example.com",
+			Attributes: map[string]string{"vbiosVersion": "92.00.36.00.01"},
+		},
+		{
+			NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-1", ProductName: "gpu.example.com",
+			Claim: "default/claim-1",
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestForEachDevice(t *testing.T) {
+	slice := func(name, node string, generation int64, devices ...string) resourcev1beta1.ResourceSlice {
+		rs := resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: node,
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: node, Generation: generation},
+			},
+		}
+		for _, dev := range devices {
+			rs.Spec.Devices = append(rs.Spec.Devices, resourcev1beta1.Device{Name: dev})
+		}
+		return rs
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-1", Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Request: "gpu", Driver: "gpu.example.com", Pool: "node-2", Device: "gpu-0"},
+						},
+					},
+				},
+			},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-2", Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Request: "gpu", Driver: "gpu.example.com", Pool: "node-2", Device: "gpu-0"},
+						},
+					},
+				},
+			},
+		},
+	)
+	pages := []*resourcev1beta1.ResourceSliceList{
+		{
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items:    []resourcev1beta1.ResourceSlice{slice("node-1-new", "node-1", 2, "gpu-0", "gpu-1")},
+		},
+		{
+			Items: []resourcev1beta1.ResourceSlice{
+				slice("node-1-old", "node-1", 1, "gpu-0", "gpu-2"),
+				slice("node-2", "node-2", 1, "gpu-0"),
+			},
+		},
+	}
+	client.PrependReactor("list", "resourceslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[0]
+		pages = pages[1:]
+		return true, page, nil
+	})
+
+	rc := &resourceClient{typedClient: client}
+	var got []string
+	var progress []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		if p.Resource == "resourceslices" {
+			progress = append(progress, p)
+		}
+	})
+	err := rc.ForEachDevice(ctx, ListOptions{}, func(dev types.DeviceInfo) error {
+		got = append(got, strings.Join(append([]string{dev.Pool + "/" + dev.Name, dev.Claim, dev.Request}, dev.SharedClaims...), " "))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachDevice() error = %v", err)
+	}
+	if diff := cmp.Diff(got, []string{"node-1/gpu-0  ", "node-1/gpu-1  ", "node-2/gpu-0 default/claim-1 gpu default/claim-2"}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	expectedProgress := []Progress{
+		{Resource: "resourceslices"},
+		{Resource: "resourceslices", Listed: 1},
+		{Resource: "resourceslices", Listed: 3, Done: true},
+	}
+	if diff := cmp.Diff(progress, expectedProgress); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	stop := errors.New("stop")
+	pages = []*resourcev1beta1.ResourceSliceList{{Items: []resourcev1beta1.ResourceSlice{slice("node-1", "node-1", 1, "gpu-0", "gpu-1")}}}
+	calls := 0
+	err = rc.ForEachDevice(context.Background(), ListOptions{}, func(types.DeviceInfo) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ForEachDevice() error = %v after %d calls, want the error of fn after 1 call", err, calls)
+	}
+}
+
+func TestDeviceListerForgetsCompletePools(t *testing.T) {
+	slice := func(generation int64, devices ...string) *model.ResourceSlice {
+		rs := &model.ResourceSlice{Spec: model.ResourceSliceSpec{
+			Driver: "gpu.example.com",
+			Pool:   model.ResourcePool{Name: "node-1", Generation: generation, ResourceSliceCount: 2},
+		}}
+		for _, dev := range devices {
+			rs.Spec.Devices = append(rs.Spec.Devices, model.Device{Name: dev})
+		}
+		return rs
+	}
+	l := newDeviceListerFor(nil, nil, false)
+	var got []string
+	list := func(rs *model.ResourceSlice) {
+		err := l.devices(rs, make([]decorator.Decoration, len(rs.Spec.Devices)), func(dev types.DeviceInfo) error {
+			got = append(got, dev.Name)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("devices() error = %v", err)
+		}
+	}
+
+	list(slice(1, "gpu-0", "gpu-1"))
+	// the next generation starts over counting slices, but keeps the devices
+	list(slice(2, "gpu-0"))
+	if len(l.pools) != 1 {
+		t.Errorf("expected the incomplete pool to be kept, got %d pools", len(l.pools))
+	}
+	list(slice(2, "gpu-1", "gpu-2"))
+	if len(l.pools) != 0 {
+		t.Errorf("expected the complete pool to be forgotten, got %d pools", len(l.pools))
+	}
+	if diff := cmp.Diff(got, []string{"gpu-0", "gpu-1", "gpu-2"}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestEmitNodeEvent(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	rc := &resourceClient{typedClient: client}
+	if err := rc.EmitNodeEvent(context.Background(), "node-1", corev1.EventTypeWarning, "DRADevicesExhausted", "All devices are allocated"); err != nil {
+		t.Fatalf("EmitNodeEvent() error = %v", err)
+	}
+
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events.Items))
+	}
+	event := events.Items[0]
+	if event.InvolvedObject.Kind != "Node" || event.InvolvedObject.Name != "node-1" || event.Reason != "DRADevicesExhausted" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestRunLeaderElected(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	rc := &resourceClient{typedClient: client}
+	config := LeaderElectionConfig{LeaseName: "dra-resources", LeaseNamespace: "default", Identity: "replica-1"}
+
+	boom := errors.New("boom")
+	err := rc.RunLeaderElected(context.Background(), config, func(ctx context.Context) error {
+		lease, err := client.CoordinationV1().Leases("default").Get(ctx, "dra-resources", metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("failed to get lease: %v", err)
+		} else if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "replica-1" {
+			t.Errorf("expected replica-1 to hold the lease, got %v", lease.Spec.HolderIdentity)
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected run's error, got %v", err)
+	}
+}
+
+func TestReservation(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		want        *types.Reservation
+	}{
+		{
+			annotations: map[string]string{
+				ReservationTeamAnnotation:    "research",
+				ReservationPurposeAnnotation: "llm pretraining",
+				ReservationExpiresAnnotation: "2025-07-01T18:00:00+02:00",
+			},
+			want: &types.Reservation{Team: "research", Purpose: "llm pretraining", Expires: time.Date(2025, 7, 1, 16, 0, 0, 0, time.UTC)},
+		},
+		{
+			annotations: map[string]string{ReservationExpiresAnnotation: "2025-07-01"},
+			want:        &types.Reservation{Expires: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			annotations: map[string]string{ReservationTeamAnnotation: "research", ReservationExpiresAnnotation: "next friday"},
+			want:        &types.Reservation{Team: "research", InvalidExpiry: "next friday"},
+		},
+		{
+			annotations: map[string]string{"other": "value"},
+		},
+	}
+	for _, tt := range tests {
+		got := reservation(tt.annotations)
+		if diff := cmp.Diff(got, tt.want, cmp.Comparer(time.Time.Equal)); diff != "" {
+			t.Errorf("reservation(%v) mismatch (-got +want):\n%s", tt.annotations, diff)
+		}
+	}
+}
+
+func TestGetNamespaceLabels(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ml", Labels: map[string]string{"team": "research"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetNamespaceLabels(context.Background())
+	if err != nil {
+		t.Fatalf("GetNamespaceLabels() error = %v", err)
+	}
+
+	expected := map[string]map[string]string{
+		"ml":      {"team": "research"},
+		"default": nil,
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetClaimPods(t *testing.T) {
+	claim := func(name string, count int64, allocated bool) *resourcev1beta1.ResourceClaim {
+		rc := &resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{
+					{Name: "gpu", DeviceClassName: "gpu", AllocationMode: resourcev1beta1.DeviceAllocationModeExactCount, Count: count},
+				}},
+			},
+		}
+		if allocated {
+			rc.Status.Allocation = &resourcev1beta1.AllocationResult{
+				Devices: resourcev1beta1.DeviceAllocationResult{Results: []resourcev1beta1.DeviceRequestAllocationResult{
+					{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+				}},
+			}
+		}
+		return rc
+	}
+	pod := func(name, nodeName string, claims ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		}
+		for _, claim := range claims {
+			p.Spec.ResourceClaims = append(p.Spec.ResourceClaims, corev1.PodResourceClaim{Name: claim, ResourceClaimName: &claim})
+		}
+		return p
+	}
+	gated := pod("gated", "", "fits")
+	gated.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: "example.com/quota"}}
+	templated := pod("templated", "")
+	templated.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: stringPtr("gpu-template")}}
+	templated.UID = "templated-uid"
+	broken := pod("broken", "")
+	broken.UID = "broken-uid"
+	broken.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: stringPtr("deleted-template")}}
+	unneeded := pod("unneeded", "")
+	unneeded.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: stringPtr("gpu-template")}}
+	unneeded.Status.ResourceClaimStatuses = []corev1.PodResourceClaimStatus{{Name: "gpu"}}
+	failedCreation := func(name string, uid k8stypes.UID, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: name, UID: uid},
+			Reason:         claimCreationFailedReason,
+			Message:        message,
+			Type:           corev1.EventTypeWarning,
+		}
+	}
+	running := pod("running", "node-1", "running")
+	running.Status.Phase = corev1.PodRunning
+	sidecar := pod("sidecar", "node-1", "running")
+	sidecar.Status.Phase = corev1.PodRunning
+	shared := claim("running", 1, true)
+	shared.Status.ReservedFor = []resourcev1beta1.ResourceClaimConsumerReference{
+		{Resource: "pods", Name: "running"},
+		{Resource: "pods", Name: "sidecar"},
+	}
+	done := pod("done", "node-1", "running")
+	done.Status.Phase = corev1.PodSucceeded
+
+	client := fake.NewSimpleClientset(
+		&resourcev1beta1.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}},
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1", ResourceSliceCount: 1},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}, {Name: "gpu-1"}},
+			},
+		},
+		shared,
+		claim("fits", 1, false),
+		claim("too-large", 2, false),
+		gated,
+		templated,
+		broken,
+		unneeded,
+		// recorded for an earlier pod of the same name
+		failedCreation("templated", "earlier-uid", "PodResourceClaim gpu: template not found"),
+		failedCreation("broken", "broken-uid", `PodResourceClaim gpu: resourceclaimtemplate.resource.k8s.io "deleted-template" not found`),
+		pod("missing", "", "deleted"),
+		pod("waiting", "", "fits"),
+		pod("starved", "", "too-large"),
+		running,
+		sidecar,
+		done,
+		pod("no-claims", ""),
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetClaimPods(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetClaimPods() error = %v", err)
+	}
+
+	expected := []types.PodInfo{
+		{
+			Namespace: "default", Name: "broken", Phase: "Pending", Reason: types.PodClaimGenerationFailed,
+			Details: []string{`PodResourceClaim gpu: resourceclaimtemplate.resource.k8s.io "deleted-template" not found`},
+		},
+		{Namespace: "default", Name: "gated", Phase: "Pending", Claims: []string{"fits"}, Reason: types.PodSchedulingGated, Details: []string{"example.com/quota"}},
+		{Namespace: "default", Name: "missing", Phase: "Pending", Claims: []string{"deleted"}, Reason: types.PodClaimMissing, Details: []string{"deleted"}},
+		{Namespace: "default", Name: "running", NodeName: "node-1", Phase: "Running", Claims: []string{"running"}, SharedWith: []string{"sidecar"}},
+		{Namespace: "default", Name: "sidecar", NodeName: "node-1", Phase: "Running", Claims: []string{"running"}, SharedWith: []string{"running"}},
+		{Namespace: "default", Name: "starved", Phase: "Pending", Claims: []string{"too-large"}, Reason: types.PodInsufficientDevices, Details: []string{"too-large"}},
+		{Namespace: "default", Name: "templated", Phase: "Pending", Reason: types.PodClaimMissing, Details: []string{"gpu (not generated yet)"}},
+		{Namespace: "default", Name: "unneeded", Phase: "Pending"},
+		{Namespace: "default", Name: "waiting", Phase: "Pending", Claims: []string{"fits"}, Reason: types.PodClaimUnallocated, Details: []string{"fits"}},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetQueueDemand(t *testing.T) {
+	quota := func(values ...int64) map[string]any {
+		var flavors []any
+		for i, v := range values {
+			flavors = append(flavors, map[string]any{
+				"name":      fmt.Sprintf("flavor-%d", i),
+				"resources": []any{map[string]any{"name": "example.com/gpu", "nominalQuota": fmt.Sprint(v)}},
+			})
+		}
+		return map[string]any{"resourceGroups": []any{map[string]any{"flavors": flavors}}}
+	}
+	object := func(kind, namespace, name string, spec, status map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "kueue.x-k8s.io/v1beta1",
+			"kind":       kind,
+			"metadata":   map[string]any{"name": name, "namespace": namespace},
+			"spec":       spec,
+		}}
+		if status != nil {
+			obj.Object["status"] = status
+		}
+		return obj
+	}
+	workload := func(name, queue string, count int64, claims []any, status map[string]any) *unstructured.Unstructured {
+		return object("Workload", "team-a", name, map[string]any{
+			"queueName": queue,
+			"podSets": []any{map[string]any{
+				"name":     "main",
+				"count":    count,
+				"template": map[string]any{"spec": map[string]any{"containers": []any{}, "resourceClaims": claims}},
+			}},
+		}, status)
+	}
+	condition := func(condType string) map[string]any {
+		return map[string]any{"type": condType, "status": "True", "reason": condType, "message": "", "lastTransitionTime": "2025-01-01T00:00:00Z"}
+	}
+	template := map[string]any{"name": "gpu", "resourceClaimTemplateName": "gpu-template"}
+	shared := map[string]any{"name": "shared", "resourceClaimName": "shared"}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		clusterQueueResource: "ClusterQueueList",
+		localQueueResource:   "LocalQueueList",
+		workloadResource:     "WorkloadList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		object("ClusterQueue", "", "idle", quota(8), nil),
+		object("ClusterQueue", "", "research", quota(4, 2), nil),
+		object("LocalQueue", "team-a", "lq", map[string]any{"clusterQueue": "research"}, nil),
+		workload("admitted", "lq", 2, []any{template}, map[string]any{
+			"admission":  map[string]any{"clusterQueue": "research"},
+			"conditions": []any{condition("Admitted")},
+		}),
+		workload("pending", "lq", 3, []any{template, shared}, nil),
+		workload("finished", "lq", 5, []any{template}, map[string]any{"conditions": []any{condition("Finished")}}),
+		workload("unknown-queue", "missing", 1, []any{template}, nil),
+	)
+
+	request := resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{
+		{Name: "gpu", DeviceClassName: "gpu", AllocationMode: resourcev1beta1.DeviceAllocationModeExactCount, Count: 1},
+	}}
+	typedClient := fake.NewSimpleClientset(
+		&resourcev1beta1.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}},
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1", ResourceSliceCount: 1},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}, {Name: "gpu-1"}, {Name: "gpu-2"}},
+			},
+		},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-template", Namespace: "team-a"},
+			Spec:       resourcev1beta1.ResourceClaimTemplateSpec{Spec: resourcev1beta1.ResourceClaimSpec{Devices: request}},
+		},
+		&resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "team-a"},
+			Spec:       resourcev1beta1.ResourceClaimSpec{Devices: request},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{Results: []resourcev1beta1.DeviceRequestAllocationResult{
+						{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+					}},
+				},
+			},
+		},
+	)
+
+	rc := &resourceClient{typedClient: typedClient, dynamicClient: dynamicClient}
+	got, err := rc.GetQueueDemand(context.Background(), map[string]string{"gpu": "example.com/gpu"})
+	if err != nil {
+		t.Fatalf("GetQueueDemand() error = %v", err)
+	}
+
+	idleQuota, researchQuota := int64(8), int64(6)
+	expected := []types.QueueDemand{
+		{ClusterQueue: "idle", DeviceClass: "gpu", Quota: &idleQuota, ClusterTotal: 3, ClusterAvailable: 2},
+		{ClusterQueue: "research", DeviceClass: "gpu", Quota: &researchQuota, Admitted: 2, Pending: 4, PendingWorkloads: 1, ClusterTotal: 3, ClusterAvailable: 2},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	notInstalled := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	notInstalled.PrependReactor("list", "clusterqueues", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(clusterQueueResource.GroupResource(), "")
+	})
+	rc = &resourceClient{typedClient: typedClient, dynamicClient: notInstalled}
+	if _, err := rc.GetQueueDemand(context.Background(), nil); !errors.Is(err, ErrKueueNotInstalled) {
+		t.Errorf("GetQueueDemand() error = %v, want ErrKueueNotInstalled", err)
+	}
+}
+
+func TestGetPodGroups(t *testing.T) {
+	podGroup := func(name string, minMember int64, phase string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "scheduling.volcano.sh/v1beta1",
+			"kind":       "PodGroup",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+			"spec":       map[string]any{"minMember": minMember},
+			"status":     map[string]any{"phase": phase},
+		}}
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podGroupResource: "PodGroupList"},
+		podGroup("train", 3, "Inqueue"),
+		podGroup("idle", 2, "Pending"),
+	)
+
+	claim := func(name string, count int64, device string) *resourcev1beta1.ResourceClaim {
+		rc := &resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{Requests: []resourcev1beta1.DeviceRequest{
+					{Name: "gpu", DeviceClassName: "gpu", AllocationMode: resourcev1beta1.DeviceAllocationModeExactCount, Count: count},
+				}},
+			},
+		}
+		if device != "" {
+			rc.Status.Allocation = &resourcev1beta1.AllocationResult{
+				Devices: resourcev1beta1.DeviceAllocationResult{Results: []resourcev1beta1.DeviceRequestAllocationResult{
+					{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: device},
+				}},
+			}
+		}
+		return rc
+	}
+	pod := func(name, nodeName, group string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: map[string]string{PodGroupAnnotation: group}},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				ResourceClaims: []corev1.PodResourceClaim{
+					{Name: "gpu", ResourceClaimTemplateName: stringPtr("gpu-template")},
+					{Name: "dataset", ResourceClaimName: stringPtr("dataset")},
+				},
+			},
+		}
+	}
+	running := pod("train-0", "node-1", "train")
+	running.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimName: stringPtr("running")}}
+
+	typedClient := fake.NewSimpleClientset(
+		&resourcev1beta1.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}},
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1", ResourceSliceCount: 1},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}, {Name: "gpu-1"}, {Name: "gpu-2"}, {Name: "gpu-3"}},
+			},
+		},
+		&resourcev1beta1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-template", Namespace: "default"},
+			Spec:       resourcev1beta1.ResourceClaimTemplateSpec{Spec: claim("", 2, "").Spec},
+		},
+		claim("running", 1, "gpu-0"),
+		claim("dataset", 1, ""),
+		running,
+		pod("train-1", "", "train"),
+		pod("train-2", "", "train"),
+		pod("solo", "", ""),
+	)
+
+	rc := &resourceClient{typedClient: typedClient, dynamicClient: dynamicClient}
+	got, err := rc.GetPodGroups(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetPodGroups() error = %v", err)
+	}
+
+	expected := []types.PodGroupInfo{
+		{Namespace: "default", Name: "train", Phase: "Inqueue", MinMember: 3, Members: 3, Waiting: []string{"train-1", "train-2"}, Devices: 5, Available: 3},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	pods, err := rc.GetClaimPods(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetClaimPods() error = %v", err)
+	}
+	groups := make(map[string]string)
+	for _, p := range pods {
+		groups[p.Name] = p.PodGroup
+	}
+	expectedGroups := map[string]string{"solo": "", "train-0": "train", "train-1": "train", "train-2": "train"}
+	if diff := cmp.Diff(groups, expectedGroups); diff != "" {
+		t.Errorf("pod groups mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetWorkloads(t *testing.T) {
+	controller := true
+	claim := func(name string, devices ...string) *resourcev1beta1.ResourceClaim {
+		rc := &resourcev1beta1.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ml"}}
+		if len(devices) > 0 {
+			rc.Status.Allocation = &resourcev1beta1.AllocationResult{}
+			for _, device := range devices {
+				rc.Status.Allocation.Devices.Results = append(rc.Status.Allocation.Devices.Results,
+					resourcev1beta1.DeviceRequestAllocationResult{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: device})
+			}
+		}
+		return rc
+	}
+	pod := func(name, nodeName, ownerKind, ownerName string, claims ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ml"},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+		if ownerKind != "" {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &controller}}
+		}
+		for _, claim := range claims {
+			p.Spec.ResourceClaims = append(p.Spec.ResourceClaims, corev1.PodResourceClaim{Name: claim, ResourceClaimName: stringPtr(claim)})
+		}
+		return p
+	}
+	worker0 := pod("train-workers-0-0", "node-1", "Job", "train-workers-0", "worker-0", "dataset")
+	worker0.Labels = map[string]string{jobSetNameLabel: "train"}
+	worker1 := pod("train-workers-1-0", "node-2", "Job", "train-workers-1", "worker-1", "dataset")
+	worker1.Labels = map[string]string{jobSetNameLabel: "train"}
+	finished := pod("bert-worker-1", "node-1", "PyTorchJob", "bert", "bert-1")
+	finished.Status.Phase = corev1.PodSucceeded
+	step := func(name, nodeName, node, claim string) *corev1.Pod {
+		p := pod(name, nodeName, "Workflow", "etl", claim)
+		p.Labels = map[string]string{argoWorkflowLabel: "etl"}
+		p.Annotations = map[string]string{argoNodeNameAnnotation: "etl" + node}
+		return p
+	}
+
+	client := fake.NewSimpleClientset(
+		claim("worker-0", "gpu-0", "gpu-1"),
+		claim("worker-1", "gpu-2", "gpu-3"),
+		claim("dataset", "gpu-4"),
+		claim("bert-0"),
+		claim("bert-1", "gpu-5"),
+		claim("debug", "gpu-6"),
+		worker0,
+		worker1,
+		pod("bert-master-0", "", "PyTorchJob", "bert", "bert-0"),
+		finished,
+		pod("debug", "node-1", "", "", "debug"),
+		pod("no-claims", "node-1", "Job", "cleanup"),
+		claim("prepare", "gpu-7"),
+		claim("train", "gpu-8", "gpu-9"),
+		claim("evaluate"),
+		step("etl-prepare", "node-2", "[0].prepare", "prepare"),
+		step("etl-train", "node-2", "[1].train", "train"),
+		step("etl-evaluate", "", "[2].evaluate", "evaluate"),
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetWorkloads(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetWorkloads() error = %v", err)
+	}
+
+	expected := []types.WorkloadUsage{
+		{Namespace: "ml", Workload: "JobSet/train", DeviceUsage: types.DeviceUsage{Pods: 2, Claims: 3, Devices: 5, Nodes: []string{"node-1", "node-2"}}},
+		{
+			Namespace:   "ml",
+			Workload:    "Workflow/etl",
+			DeviceUsage: types.DeviceUsage{Pods: 3, PendingPods: 1, Claims: 3, PendingClaims: 1, Devices: 3, Nodes: []string{"node-2"}},
+			Steps: []types.WorkloadStep{
+				{Name: "[1].train", DeviceUsage: types.DeviceUsage{Pods: 1, Claims: 1, Devices: 2, Nodes: []string{"node-2"}}},
+				{Name: "[0].prepare", DeviceUsage: types.DeviceUsage{Pods: 1, Claims: 1, Devices: 1, Nodes: []string{"node-2"}}},
+				{Name: "[2].evaluate", DeviceUsage: types.DeviceUsage{Pods: 1, PendingPods: 1, Claims: 1, PendingClaims: 1}},
+			},
+		},
+		{Namespace: "ml", Workload: "Pod/debug", DeviceUsage: types.DeviceUsage{Pods: 1, Claims: 1, Devices: 1, Nodes: []string{"node-1"}}},
+		{Namespace: "ml", Workload: "PyTorchJob/bert", DeviceUsage: types.DeviceUsage{Pods: 1, PendingPods: 1, Claims: 1, PendingClaims: 1}},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetSpotRisk(t *testing.T) {
+	controller := true
+	slice := func(nodeName string) *resourcev1beta1.ResourceSlice {
+		return &resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: nodeName,
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: nodeName},
+				Devices:  []resourcev1beta1.Device{{Name: "gpu-0"}},
+			},
+		}
+	}
+	claim := func(name, pool, pod string) *resourcev1beta1.ResourceClaim {
+		return &resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Driver: "gpu.example.com", Pool: pool, Device: "gpu-0"},
+						},
+					},
+				},
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{{Resource: "pods", Name: pod}},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "spot-1", Labels: map[string]string{"cloud.google.com/gke-spot": "true"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "on-demand-1"}},
+		slice("spot-1"),
+		slice("on-demand-1"),
+		claim("train", "spot-1", "train-7d9f8-abcde"),
+		claim("serve", "on-demand-1", "serve-0"),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "train-7d9f8-abcde",
+			Namespace:       "default",
+			Labels:          map[string]string{"pod-template-hash": "7d9f8"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "train-7d9f8", Controller: &controller}},
+		}},
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetSpotRisk(context.Background())
+	if err != nil {
+		t.Fatalf("GetSpotRisk() error = %v", err)
+	}
+
+	expected := []types.SpotRisk{
+		{
+			Namespace: "default",
+			Name:      "train",
+			Nodes:     []string{"spot-1"},
+			Devices:   []string{"gpu.example.com/spot-1/gpu-0"},
+			Pods:      []string{"train-7d9f8-abcde"},
+			Owners:    []string{"Deployment/train"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetDriverLogs(t *testing.T) {
+	daemonSet := []metav1.OwnerReference{{Kind: "DaemonSet", Name: "gpu-driver"}}
+	pluginPod := func(name, nodeName, hostPath string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dra-system", Name: name, OwnerReferences: owners},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Volumes: []corev1.Volume{{
+					Name:         "plugin",
+					VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: hostPath}},
+				}},
+				Containers: []corev1.Container{
+					{Name: "init-config"},
+					{Name: "plugin", VolumeMounts: []corev1.VolumeMount{{Name: "plugin", MountPath: hostPath}}},
+				},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		pluginPod("other-driver-abc", "node-1", "/var/lib/kubelet/plugins/net.example.com", daemonSet),
+		pluginPod("standalone", "node-1", "/var/lib/kubelet/plugins/gpu.example.com", nil),
+		pluginPod("gpu-driver-xyz", "node-2", "/var/lib/kubelet/plugins/gpu.example.com/", daemonSet),
+		pluginPod("gpu-driver-abc", "node-1", "/var/lib/kubelet/plugins/gpu.example.com/", daemonSet),
+	)
+	rc := &resourceClient{typedClient: client}
+
+	got, err := rc.GetDriverLogs(context.Background(), "gpu.example.com", "node-1", 20)
+	if err != nil {
+		t.Fatalf("GetDriverLogs() error = %v", err)
+	}
+	// the fake clientset serves the same log for every pod
+	want := &types.DriverLogs{Namespace: "dra-system", Pod: "gpu-driver-abc", Container: "plugin", Lines: []string{"fake logs"}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := rc.GetDriverLogs(context.Background(), "gpu.example.com", "node-3", 20); err == nil {
+		t.Error("expected an error for a node without driver pod")
+	}
+}
+
+func TestGetDevicePods(t *testing.T) {
+	claimName, generatedName := "shared-gpu", "train-0-scratch-x7k2p"
+	pod := func(name, nodeName string, phase corev1.PodPhase, claims ...corev1.PodResourceClaim) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name, Annotations: map[string]string{"team": "a"}},
+			Spec: corev1.PodSpec{
+				NodeName:       nodeName,
+				ResourceClaims: claims,
+				Containers: []corev1.Container{{
+					Name:  "app",
+					Image: "nvidia/cuda:12.4.1-base-ubuntu22.04",
+					Env: []corev1.EnvVar{
+						{Name: "CUDA_VERSION", Value: "12.4.1"},
+						{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "token"}}},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	running := pod("train-0", "node-1", corev1.PodRunning,
+		corev1.PodResourceClaim{Name: "gpu", ResourceClaimName: &claimName},
+		corev1.PodResourceClaim{Name: "scratch", ResourceClaimTemplateName: &claimName},
+	)
+	running.Status.ResourceClaimStatuses = []corev1.PodResourceClaimStatus{{Name: "scratch", ResourceClaimName: &generatedName}}
+	client := fake.NewSimpleClientset(
+		running,
+		pod("pending", "", corev1.PodPending, corev1.PodResourceClaim{Name: "gpu", ResourceClaimName: &claimName}),
+		pod("done", "node-1", corev1.PodSucceeded, corev1.PodResourceClaim{Name: "gpu", ResourceClaimName: &claimName}),
+		pod("cpu-only", "node-1", corev1.PodRunning),
+	)
+	rc := &resourceClient{typedClient: client}
+
+	got, err := rc.GetDevicePods(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("GetDevicePods() error = %v", err)
+	}
+	want := []types.DevicePod{{
+		Namespace:   "team-a",
+		Name:        "train-0",
+		NodeName:    "node-1",
+		Claims:      []string{"shared-gpu", "train-0-scratch-x7k2p"},
+		Annotations: map[string]string{"team": "a"},
+		Containers: []types.ContainerInfo{{
+			Name:  "app",
+			Image: "nvidia/cuda:12.4.1-base-ubuntu22.04",
+			Env:   map[string]string{"CUDA_VERSION": "12.4.1"},
+		}},
+	}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestAggregateWindowsNode(t *testing.T) {
+	nodes := []corev1.Node{{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-win"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("16"),
+				corev1.ResourceMemory:           resource.MustParse("64Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("256Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("16"),
+				corev1.ResourceMemory:           resource.MustParse("62Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("240Gi"),
+			},
+			NodeInfo: corev1.NodeSystemInfo{OperatingSystem: "windows", Architecture: "amd64"},
+		},
+	}}
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "iis"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-win",
+			Containers: []corev1.Container{{
+				Name: "iis",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("40Gi"),
+				}},
+			}},
+		},
+	}}
+
+	nodeInfoList, err := Aggregate(context.Background(), nodes, nil, nil, pods)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	got := nodeInfoList[0]
+	if got.OS != "windows" {
+		t.Errorf("expected OS windows from the node status, got %q", got.OS)
+	}
+	if got.Arch != "amd64" {
+		t.Errorf("expected architecture amd64 from the node status, got %q", got.Arch)
+	}
+	if len(got.Devices) != 0 {
+		t.Errorf("expected no devices, got %v", got.Devices)
+	}
+	if _, ok := got.NodeCapacity.Resources[string(corev1.ResourceEphemeralStorage)]; !ok {
+		t.Errorf("expected ephemeral-storage among the node resources, got %v", got.NodeCapacity.Resources)
+	}
+	if got.NodeCapacity.TotalStorage.Cmp(resource.MustParse("256Gi")) != 0 || got.NodeCapacity.AvailableStorage.Cmp(resource.MustParse("200Gi")) != 0 {
+		t.Errorf("expected ephemeral storage 256Gi/200Gi, got %s/%s", got.NodeCapacity.TotalStorage.String(), got.NodeCapacity.AvailableStorage.String())
+	}
+}
+
+func TestAggregateOtherResources(t *testing.T) {
+	nodes := []corev1.Node{{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:                   resource.MustParse("8"),
+				corev1.ResourceMemory:                resource.MustParse("64Gi"),
+				corev1.ResourceStorage:               resource.MustParse("100Gi"),
+				corev1.ResourceName("hugepages-2Mi"): resource.MustParse("8Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:                   resource.MustParse("8"),
+				corev1.ResourceMemory:                resource.MustParse("60Gi"),
+				corev1.ResourceStorage:               resource.MustParse("90Gi"),
+				corev1.ResourceName("hugepages-2Mi"): resource.MustParse("8Gi"),
+			},
+		},
+	}}
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dpdk"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{{
+				Name: "dpdk",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceName("hugepages-2Mi"): resource.MustParse("2Gi"),
+				}},
+			}},
+		},
+	}}
+
+	nodeInfoList, err := Aggregate(context.Background(), nodes, nil, nil, pods)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	want := map[string]types.ResourceCapacity{
+		"hugepages-2Mi": {Total: resource.MustParse("8Gi"), Available: resource.MustParse("6Gi")},
+	}
+	if diff := cmp.Diff(nodeInfoList[0].NodeCapacity.Resources, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetCounterOvercommit(t *testing.T) {
+	partition := func(name, counterSet, memory string) resourcev1beta1.Device {
+		return resourcev1beta1.Device{
+			Name: name,
+			Basic: &resourcev1beta1.BasicDevice{
+				ConsumesCounters: []resourcev1beta1.DeviceCounterConsumption{{
+					CounterSet: counterSet,
+					Counters:   map[string]resourcev1beta1.Counter{"memory": {Value: resource.MustParse(memory)}},
+				}},
+			},
+		}
+	}
+	claim := func(name string, devices ...string) *resourcev1beta1.ResourceClaim {
+		rc := &resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Status:     resourcev1beta1.ResourceClaimStatus{Allocation: &resourcev1beta1.AllocationResult{}},
+		}
+		for _, dev := range devices {
+			rc.Status.Allocation.Devices.Results = append(rc.Status.Allocation.Devices.Results, resourcev1beta1.DeviceRequestAllocationResult{
+				Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: dev,
+			})
+		}
+		return rc
+	}
+	client := fake.NewSimpleClientset(
+		// the counter sets and the partitions are published in separate
+		// slices of the pool
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1-counters"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1", ResourceSliceCount: 2},
+				SharedCounters: []resourcev1beta1.CounterSet{
+					{Name: "gpu-0", Counters: map[string]resourcev1beta1.Counter{"memory": {Value: resource.MustParse("40Gi")}}},
+					{Name: "gpu-1", Counters: map[string]resourcev1beta1.Counter{"memory": {Value: resource.MustParse("40Gi")}}},
+				},
+			},
+		},
+		&resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1-partitions"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: "node-1",
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: "node-1", ResourceSliceCount: 2},
+				Devices: []resourcev1beta1.Device{
+					partition("gpu-0-full", "gpu-0", "40Gi"),
+					partition("gpu-0-half-0", "gpu-0", "20Gi"),
+					partition("gpu-0-half-1", "gpu-0", "20Gi"),
+					partition("gpu-1-half-0", "gpu-1", "20Gi"),
+					partition("gpu-1-half-1", "gpu-1", "20Gi"),
+				},
+			},
+		},
+		// gpu-0 is allocated both whole and as a half; gpu-1 is split
+		// within its memory
+		claim("train", "gpu-0-full"),
+		claim("notebook", "gpu-0-half-1"),
+		claim("infer", "gpu-1-half-0", "gpu-1-half-1"),
+	)
+	rc := &resourceClient{typedClient: client}
+
+	got, err := rc.GetCounterOvercommit(context.Background())
+	if err != nil {
+		t.Fatalf("GetCounterOvercommit() error = %v", err)
+	}
+	want := []types.CounterOvercommit{{
+		NodeName:   "node-1",
+		Driver:     "gpu.example.com",
+		Pool:       "node-1",
+		CounterSet: "gpu-0",
+		Counter:    "memory",
+		Capacity:   resource.MustParse("40Gi"),
+		Allocated:  resource.MustParse("60Gi"),
+		Devices:    []string{"gpu-0-full", "gpu-0-half-1"},
+		Claims:     []string{"default/notebook", "default/train"},
+	}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetKubeletDeviceMismatches(t *testing.T) {
+	claim := func(name string, devices ...string) *resourcev1beta1.ResourceClaim {
+		rc := &resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Status:     resourcev1beta1.ResourceClaimStatus{Allocation: &resourcev1beta1.AllocationResult{}},
+		}
+		for _, dev := range devices {
+			rc.Status.Allocation.Devices.Results = append(rc.Status.Allocation.Devices.Results, resourcev1beta1.DeviceRequestAllocationResult{
+				Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: dev,
+			})
+		}
+		return rc
+	}
+	// pod returns a pod whose container the kubelet reports the devices of
+	// the claim's gpu request for.
+	pod := func(name, claimName string, reported ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.PodSpec{
+				NodeName:       "node-1",
+				ResourceClaims: []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimName: &claimName}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		status := corev1.ResourceStatus{Name: "claim:gpu/gpu"}
+		for _, id := range reported {
+			status.Resources = append(status.Resources, corev1.ResourceHealth{ResourceID: corev1.ResourceID(id), Health: corev1.ResourceHealthStatusHealthy})
+		}
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "main", AllocatedResourcesStatus: []corev1.ResourceStatus{status}}}
+		return p
+	}
+	unreported := pod("unreported", "training")
+	unreported.Status.ContainerStatuses = nil
+	generated := pod("generated", "")
+	generated.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: stringPtr("gpu")}}
+	generated.Status.ResourceClaimStatuses = []corev1.PodResourceClaimStatus{{Name: "gpu", ResourceClaimName: stringPtr("generated-gpu-abcde")}}
+	generated.Status.ContainerStatuses[0].AllocatedResourcesStatus[0].Resources = []corev1.ResourceHealth{{ResourceID: "gpu.example.com/node-1/gpu-3"}}
+
+	client := fake.NewSimpleClientset(
+		claim("training", "gpu-0"),
+		claim("inference", "gpu-1"),
+		claim("generated-gpu-abcde", "gpu-2"),
+		pod("trainer", "training", "gpu.example.com/node-1/gpu-0"),
+		pod("server", "inference"),
+		unreported,
+		generated,
+	)
+
+	rc := &resourceClient{typedClient: client}
+	got, err := rc.GetKubeletDeviceMismatches(context.Background())
+	if err != nil {
+		t.Fatalf("GetKubeletDeviceMismatches() error = %v", err)
+	}
+
+	expected := []types.KubeletDeviceMismatch{
+		{
+			Namespace: "default", Pod: "generated", Container: "main", NodeName: "node-1", Claim: "generated-gpu-abcde", Request: "gpu",
+			Allocated: []string{"gpu.example.com/node-1/gpu-2"}, Reported: []string{"gpu.example.com/node-1/gpu-3"},
+		},
+		{
+			Namespace: "default", Pod: "server", Container: "main", NodeName: "node-1", Claim: "inference", Request: "gpu",
+			Allocated: []string{"gpu.example.com/node-1/gpu-1"},
+		},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetDuplicateDevices(t *testing.T) {
+	slice := func(name, nodeName, pool string, generation int64, devices ...string) *resourcev1beta1.ResourceSlice {
+		rs := &resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: nodeName,
+				Driver:   "gpu.example.com",
+				Pool:     resourcev1beta1.ResourcePool{Name: pool, Generation: generation, ResourceSliceCount: 1},
+			},
+		}
+		for _, dev := range devices {
+			rs.Spec.Devices = append(rs.Spec.Devices, resourcev1beta1.Device{Name: dev})
+		}
+		return rs
+	}
+	client := fake.NewSimpleClientset(
+		// gpu-0 is published twice within a pool, gpu-1 in two pools
+		slice("node-1-a", "node-1", "node-1-a", 0, "gpu-0", "gpu-0", "gpu-1"),
+		slice("node-1-b", "node-1", "node-1-b", 0, "gpu-1", "gpu-2"),
+		// the same names on another node do not collide
+		slice("node-2", "node-2", "node-2", 0, "gpu-0", "gpu-2"),
+		// an old generation republished under a new one is not a duplicate
+		slice("node-3-old", "node-3", "node-3", 1, "gpu-0"),
+		slice("node-3-new", "node-3", "node-3", 2, "gpu-0"),
+	)
+	rc := &resourceClient{typedClient: client}
+
+	got, err := rc.GetDuplicateDevices(context.Background())
+	if err != nil {
+		t.Fatalf("GetDuplicateDevices() error = %v", err)
+	}
+	want := []types.DuplicateDevice{
+		{NodeName: "node-1", Driver: "gpu.example.com", Device: "gpu-0", Pools: []string{"node-1-a", "node-1-a"}},
+		{NodeName: "node-1", Driver: "gpu.example.com", Device: "gpu-1", Pools: []string{"node-1-a", "node-1-b"}},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestRequestPlanner(t *testing.T) {
+	node := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Node",
+			"metadata":   map[string]any{"name": name},
+		}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "nodes"}:                                         "NodeList",
+		{Version: "v1", Resource: "pods"}:                                          "PodList",
+		{Group: "resource.k8s.io", Version: "v1beta1", Resource: "resourceslices"}: "ResourceSliceList",
+		{Group: "resource.k8s.io", Version: "v1beta1", Resource: "resourceclaims"}: "ResourceClaimList",
+	}
+	target := &resourceClient{
+		typedClient:   fake.NewSimpleClientset(),
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, node("node-1"), node("node-2")),
+		namespace:     "default",
+	}
+	planner := NewRequestPlanner(target)
+
+	if _, err := planner.GetK8sResources(context.Background(), ListOptions{}); err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	// the planner answers like an empty cluster
+	if _, err := planner.GetClaimMutations(context.Background(), "default", "train"); !apierrors.IsNotFound(err) {
+		t.Fatalf("GetClaimMutations() error = %v, want not found", err)
+	}
+
+	got, err := planner.Requests(context.Background())
+	if err != nil {
+		t.Fatalf("Requests() error = %v", err)
+	}
+	want := []types.PlannedRequest{
+		{Verb: "list", Resource: "nodes", Objects: 2, Estimated: true},
+		{Verb: "list", Resource: "resourceslices.resource.k8s.io", Estimated: true},
+		{Verb: "list", Resource: "resourceclaims.resource.k8s.io", Estimated: true},
+		{Verb: "list", Resource: "pods", Limit: podPageSize, Estimated: true},
+		{Verb: "get", Resource: "resourceclaims.resource.k8s.io", Namespace: "default", Name: "train", Objects: 1, Estimated: true},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestRequestSummaries(t *testing.T) {
+	cel := func(expression string) []resourcev1beta1.DeviceSelector {
+		return []resourcev1beta1.DeviceSelector{{CEL: &resourcev1beta1.CELDeviceSelector{Expression: expression}}}
+	}
+	spec := &resourcev1beta1.ResourceClaimSpec{
+		Devices: resourcev1beta1.DeviceClaim{
+			Requests: []resourcev1beta1.DeviceRequest{
+				{
+					Name:            "gpu",
+					DeviceClassName: "gpu.example.com",
+					Count:           2,
+					Selectors:       cel(`device.capacity["gpu.example.com"].memory.compareTo(quantity("40Gi")) >= 0`),
+				},
+				{
+					Name:            "nic",
+					DeviceClassName: "net.example.com",
+					AllocationMode:  resourcev1beta1.DeviceAllocationModeAll,
+					Selectors: append(cel(`device.attributes["net.example.com"].model == "cx7"`),
+						cel(`device.driver == "net.example.com"`)...),
+				},
+				{
+					Name: "accelerator",
+					FirstAvailable: []resourcev1beta1.DeviceSubRequest{
+						{Name: "h100", DeviceClassName: "h100.example.com"},
+						{Name: "a100", DeviceClassName: "a100.example.com", Count: 2,
+							Selectors: cel("device.attributes[\"gpu.example.com\"].index < 4 &&\n  device.attributes[\"gpu.example.com\"].mig == false")},
+					},
+				},
+			},
+		},
+	}
+
+	converted := model.FromV1beta1ResourceClaimSpec(spec)
+	got := requestSummaries(&converted)
+
+	want := []string{
+		"2x gpu.example.com[memory>=40Gi]",
+		`all net.example.com[model=="cx7",driver==net.example.com]`,
+		`1x h100.example.com|2x a100.example.com[device.attributes["gpu.example.com"].index < 4 && device.attributes["gpu.example.com"].mig == false]`,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestGetClaimDescription(t *testing.T) {
+	client := fake.NewSimpleClientset(&resourcev1beta1.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "train"},
+		Spec: resourcev1beta1.ResourceClaimSpec{
+			Devices: resourcev1beta1.DeviceClaim{
+				Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: "gpu.example.com", Count: 2}},
+			},
+		},
+		Status: resourcev1beta1.ResourceClaimStatus{
+			Allocation: &resourcev1beta1.AllocationResult{
+				Devices: resourcev1beta1.DeviceAllocationResult{
+					Results: []resourcev1beta1.DeviceRequestAllocationResult{
+						{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+						{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1"},
+					},
+					Config: []resourcev1beta1.DeviceAllocationConfiguration{
+						{
+							Source: resourcev1beta1.AllocationConfigSourceClass,
+							DeviceConfiguration: resourcev1beta1.DeviceConfiguration{Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{
+								Driver:     "gpu.example.com",
+								Parameters: runtime.RawExtension{Raw: []byte(`{"sharing":{"strategy":"TimeSlicing"}}`)},
+							}},
+						},
+						{
+							Source:   resourcev1beta1.AllocationConfigSourceClaim,
+							Requests: []string{"gpu"},
+							DeviceConfiguration: resourcev1beta1.DeviceConfiguration{Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{
+								Driver:     "gpu.example.com",
+								Parameters: runtime.RawExtension{Raw: []byte(`{"mps":true}`)},
+							}},
+						},
+						{
+							Source: resourcev1beta1.AllocationConfigSourceClaim,
+							DeviceConfiguration: resourcev1beta1.DeviceConfiguration{Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{
+								Driver:     "gpu.nvidia.com",
+								Parameters: runtime.RawExtension{Raw: []byte(`{"kind":"GpuConfig","sharing":{"strategy":"TimeSlicing"}}`)},
+							}},
+						},
+					},
+				},
+			},
+		},
+	})
+	rc := &resourceClient{typedClient: client}
+
+	got, err := rc.GetClaimDescription(context.Background(), "team-a", "train")
+	if err != nil {
+		t.Fatalf("GetClaimDescription() error = %v", err)
+	}
+	want := &types.ClaimDescription{
+		ClaimInfo: types.ClaimInfo{
+			Namespace: "team-a",
+			Name:      "train",
+			Allocated: true,
+			Requests:  []string{"2x gpu.example.com"},
+			Devices:   []string{"gpu.example.com/node-1/gpu-0", "gpu.example.com/node-1/gpu-1"},
+		},
+		Results: []types.AllocatedDevice{
+			{Request: "gpu", Device: "gpu.example.com/node-1/gpu-0"},
+			{Request: "gpu", Device: "gpu.example.com/node-1/gpu-1"},
+		},
+		AllocationConfigs: []types.AllocationConfig{
+			{Source: "FromClass", Driver: "gpu.example.com", Parameters: `{"sharing":{"strategy":"TimeSlicing"}}`},
+			{Source: "FromClaim", Requests: []string{"gpu"}, Driver: "gpu.example.com", Parameters: `{"mps":true}`},
+			{
+				Source:     "FromClaim",
+				Driver:     "gpu.nvidia.com",
+				Parameters: `{"kind":"GpuConfig","sharing":{"strategy":"TimeSlicing"}}`,
+				Fields:     []types.ConfigField{{Name: "kind", Value: "GpuConfig"}, {Name: "sharing", Value: "TimeSlicing"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := rc.GetClaimDescription(context.Background(), "team-a", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("GetClaimDescription() of a missing claim error = %v, want not found", err)
+	}
+}
+
+func TestClaimConsumersFull(t *testing.T) {
+	rc := &model.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared"}}
+	for i := 0; i < model.ResourceClaimReservedForMaxSize-1; i++ {
+		rc.Status.ReservedFor = append(rc.Status.ReservedFor, model.ResourceClaimConsumerReference{Resource: "pods", Name: fmt.Sprintf("pod-%d", i)})
+	}
+	if newClaimInfo(rc).ConsumersFull {
+		t.Errorf("ConsumersFull set for %d consumers", len(rc.Status.ReservedFor))
+	}
+	rc.Status.ReservedFor = append(rc.Status.ReservedFor, model.ResourceClaimConsumerReference{Resource: "pods", Name: "pod-last"})
+	if !newClaimInfo(rc).ConsumersFull {
+		t.Errorf("ConsumersFull not set for %d consumers", len(rc.Status.ReservedFor))
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	gr := schema.GroupResource{Group: "resource.k8s.io", Resource: "resourceslices"}
+	testCases := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{name: "forbidden", err: apierrors.NewForbidden(gr, "", errors.New("no RBAC")), want: []error{ErrForbidden}},
+		{name: "unauthorized", err: apierrors.NewUnauthorized("expired token"), want: []error{ErrForbidden}},
+		{name: "unavailable", err: apierrors.NewServiceUnavailable("etcd down"), want: []error{ErrAPIUnavailable}},
+		{name: "throttled", err: apierrors.NewTooManyRequests("slow down", 1), want: []error{ErrAPIUnavailable}},
+		{name: "invalid", err: apierrors.NewBadRequest("bad selector")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("list", "resourceslices", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tc.err
+			})
+			rc := &resourceClient{typedClient: client}
+
+			_, err := rc.GetK8sResources(context.Background(), ListOptions{})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.Op != "list ResourceSlices" {
+				t.Fatalf("GetK8sResources() error = %v, want an APIError listing ResourceSlices", err)
+			}
+			for _, target := range []error{ErrForbidden, ErrAPIUnavailable, ErrPartialData} {
+				want := false
+				for _, w := range tc.want {
+					want = want || w == target
+				}
+				if got := errors.Is(err, target); got != want {
+					t.Errorf("errors.Is(%v, %v) = %v, want %v", err, target, got, want)
+				}
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected the API server's error to be wrapped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestForEachPodPartialData(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	first := true
+	client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if first {
+			first = false
+			return true, &corev1.PodList{
+				ListMeta: metav1.ListMeta{Continue: "page-2"},
+				Items:    []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}}},
+			}, nil
+		}
+		return true, nil, apierrors.NewResourceExpired("continue token expired")
+	})
+
+	rc := &resourceClient{typedClient: client}
+	err := rc.forEachPod(context.Background(), ListOptions{}, func(*corev1.Pod) {})
+	if !errors.Is(err, ErrPartialData) {
+		t.Errorf("forEachPod() error = %v, want partial data", err)
+	}
+	if errors.Is(err, ErrForbidden) || errors.Is(err, ErrAPIUnavailable) {
+		t.Errorf("forEachPod() error = %v matches an unrelated failure mode", err)
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetDevicePods returns the pods bound to a node that consume ResourceClaims
+// and have not finished, selected by the namespace and node of opts, with
+// their annotations and containers, sorted by namespace and name. Only
+// environment variables with literal values are returned, as values from
+// ConfigMaps or Secrets are not read.
+func (c *resourceClient) GetDevicePods(ctx context.Context, opts ListOptions) ([]types.DevicePod, error) {
+	var pods []types.DevicePod
+	err := c.forEachPod(ctx, opts, func(pod *corev1.Pod) {
+		if pod.Spec.NodeName == "" || len(pod.Spec.ResourceClaims) == 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return
+		}
+		info := types.DevicePod{
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			NodeName:    pod.Spec.NodeName,
+			Annotations: pod.Annotations,
+		}
+		for _, entry := range pod.Spec.ResourceClaims {
+			if name, _ := podClaimName(pod, entry); name != "" {
+				info.Claims = append(info.Claims, name)
+			}
+		}
+		for _, container := range pod.Spec.Containers {
+			ci := types.ContainerInfo{Name: container.Name, Image: container.Image}
+			for _, env := range container.Env {
+				if env.ValueFrom != nil {
+					continue
+				}
+				if ci.Env == nil {
+					ci.Env = make(map[string]string)
+				}
+				ci.Env[env.Name] = env.Value
+			}
+			info.Containers = append(info.Containers, ci)
+		}
+		pods = append(pods, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// slicePageSize is the number of ResourceSlices requested per list call when
+// streaming devices.
+const slicePageSize = 100
+
+// GetDevices returns every device of the latest generation of each pool, with
+// its product, health, allocation and attributes, sorted by node, driver,
+// pool and name. The node and driver of opts are passed on when listing
+// ResourceSlices; with a node selector, only devices of the selected nodes
+// are returned.
+func (c *resourceClient) GetDevices(ctx context.Context, opts ListOptions) ([]types.DeviceInfo, error) {
+	resourceSlices, err := c.getResourceSlices(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	lister, err := c.newDeviceLister(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return devicesOf(ctx, resourceSlices, lister)
+}
+
+// devicesOf returns the devices of the latest generation of each pool of the
+// slices, sorted by node, driver, pool and name.
+func devicesOf(ctx context.Context, resourceSlices []model.ResourceSlice, lister *deviceLister) ([]types.DeviceInfo, error) {
+	var devices []types.DeviceInfo
+	poolGenerations := aggregate.LatestPoolGenerations(resourceSlices)
+	decorations, err := aggregate.DecorateSlices(ctx, resourceSlices)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resourceSlices {
+		rs := &resourceSlices[i]
+		if rs.Spec.Pool.Generation < poolGenerations[aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}] {
+			continue
+		}
+		err := lister.devices(rs, decorations[i], func(info types.DeviceInfo) error {
+			devices = append(devices, info)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		a, b := devices[i], devices[j]
+		if a.NodeName != b.NodeName {
+			return a.NodeName < b.NodeName
+		}
+		if a.Driver != b.Driver {
+			return a.Driver < b.Driver
+		}
+		if a.Pool != b.Pool {
+			return a.Pool < b.Pool
+		}
+		return a.Name < b.Name
+	})
+	return devices, nil
+}
+
+// ForEachDevice calls fn with the devices GetDevices returns, but lists the
+// ResourceSlices in pages and hands their devices on as they arrive, so the
+// slices are never held all at once. The nodes and the claims of all
+// namespaces are still listed up front to resolve spot nodes and
+// allocations, and the names of devices already passed on are kept per pool
+// until all slices of the pool were seen, so memory grows with nodes, claims
+// and pools rather than with devices. Devices come in the order the API
+// server returns their slices in rather than sorted.
+// Slices of a pool older than a generation already seen are skipped; while a
+// driver republishes a pool, devices of the previous generation listed
+// before the new slices are still passed on. The iteration stops at the
+// first error returned by fn, which is returned.
+func (c *resourceClient) ForEachDevice(ctx context.Context, opts ListOptions, fn func(types.DeviceInfo) error) error {
+	lister, err := c.newDeviceLister(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	listOpts := opts.resourceSlices()
+	listOpts.Limit = slicePageSize
+	poolGenerations := make(map[aggregate.PoolKey]int64)
+
+	reportProgress(ctx, Progress{Resource: "resourceslices"})
+	listed := 0
+	for {
+		page, next, remaining, err := c.listResourceSlicePage(ctx, listOpts)
+		if err != nil {
+			return &APIError{Op: "list ResourceSlices", Partial: listed > 0, Err: err}
+		}
+		// the devices of a page are decorated together, one decorator
+		// call per driver
+		var selected []model.ResourceSlice
+		for i := range page {
+			rs := &page[i]
+			if !opts.selectsSlice(rs) {
+				continue
+			}
+			pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+			if generation, ok := poolGenerations[pool]; ok && rs.Spec.Pool.Generation < generation {
+				continue
+			}
+			poolGenerations[pool] = rs.Spec.Pool.Generation
+			selected = append(selected, *rs)
+		}
+		decorations, err := aggregate.DecorateSlices(ctx, selected)
+		if err != nil {
+			return err
+		}
+		for i := range selected {
+			if decorations[i] == nil {
+				// older than a later slice of the page
+				continue
+			}
+			if err := lister.devices(&selected[i], decorations[i], fn); err != nil {
+				return err
+			}
+		}
+		listed += len(page)
+
+		progress := Progress{Resource: "resourceslices", Listed: listed, Done: next == ""}
+		if remaining != nil {
+			progress.Total = listed + int(*remaining)
+		}
+		reportProgress(ctx, progress)
+
+		if next == "" {
+			return nil
+		}
+		listOpts.Continue = next
+	}
+}
+
+// deviceLister turns the devices of ResourceSlices into DeviceInfos, each
+// device once.
+type deviceLister struct {
+	// spotNodes holds every listed node, true for spot nodes.
+	spotNodes map[string]bool
+	// selectNodes skips slices of nodes that were not listed.
+	selectNodes      bool
+	allocatedDevices map[aggregate.DeviceKey][]aggregate.Allocation
+	// consumers holds the consumers of each claim by namespace/name.
+	consumers map[string][]string
+	// pools holds the pools with slices still to come.
+	pools map[aggregate.PoolKey]*seenPool
+}
+
+// seenPool is a pool whose slices are being listed: the devices passed on,
+// and how many slices of the latest generation were seen.
+type seenPool struct {
+	devices    map[string]bool
+	generation int64
+	slices     int64
+}
+
+// newDeviceLister lists the nodes of opts and the claims of all namespaces,
+// whose allocations all count.
+func (c *resourceClient) newDeviceLister(ctx context.Context, opts ListOptions) (*deviceLister, error) {
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := c.getNodes(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newDeviceListerFor(nodes, resourceClaims, opts.NodeSelector != ""), nil
+}
+
+// newDeviceListerFor returns a lister for the listed nodes and claims. With
+// selectNodes, slices of nodes not listed are skipped.
+func newDeviceListerFor(nodes []corev1.Node, resourceClaims []model.ResourceClaim, selectNodes bool) *deviceLister {
+	l := &deviceLister{
+		spotNodes:        make(map[string]bool),
+		selectNodes:      selectNodes,
+		allocatedDevices: aggregate.AllocatedDevices(resourceClaims),
+		consumers:        make(map[string][]string),
+		pools:            make(map[aggregate.PoolKey]*seenPool),
+	}
+	for i := range resourceClaims {
+		rc := &resourceClaims[i]
+		l.consumers[rc.Namespace+"/"+rc.Name] = consumerNames(rc)
+	}
+	for _, node := range nodes {
+		l.spotNodes[node.Name] = aggregate.IsSpot(node.Labels)
+	}
+	return l
+}
+
+// devices calls fn with the devices of the slice not seen before, given the
+// decorations of the slice. A pool is forgotten once as many slices of its
+// generation as it consists of were seen.
+func (l *deviceLister) devices(rs *model.ResourceSlice, decorations []decorator.Decoration, fn func(types.DeviceInfo) error) error {
+	if _, ok := l.spotNodes[rs.Spec.NodeName]; l.selectNodes && !ok {
+		return nil
+	}
+
+	pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+	seen, ok := l.pools[pool]
+	if !ok {
+		seen = &seenPool{devices: make(map[string]bool), generation: rs.Spec.Pool.Generation}
+		l.pools[pool] = seen
+	}
+	if rs.Spec.Pool.Generation != seen.generation {
+		seen.generation, seen.slices = rs.Spec.Pool.Generation, 0
+	}
+	if seen.slices++; seen.slices >= rs.Spec.Pool.ResourceSliceCount {
+		delete(l.pools, pool)
+	}
+
+	for i, dev := range rs.Spec.Devices {
+		if seen.devices[dev.Name] {
+			continue
+		}
+		seen.devices[dev.Name] = true
+
+		info := types.DeviceInfo{
+			NodeName:    rs.Spec.NodeName,
+			Driver:      rs.Spec.Driver,
+			Pool:        rs.Spec.Pool.Name,
+			Name:        dev.Name,
+			ProductName: decorations[i].ProductName,
+			Unhealthy:   decorations[i].Health == decorator.Unhealthy,
+			Spot:        l.spotNodes[rs.Spec.NodeName],
+		}
+		for i, alloc := range l.allocatedDevices[pool.Device(dev.Name)] {
+			if i == 0 {
+				info.Claim, info.Request = alloc.Claim(), alloc.Request
+			} else {
+				info.SharedClaims = append(info.SharedClaims, alloc.Claim())
+			}
+			info.Consumers = append(info.Consumers, l.consumers[alloc.Claim()]...)
+		}
+		if len(dev.Attributes) > 0 {
+			info.Attributes = make(map[string]string)
+			for name, attr := range dev.Attributes {
+				info.Attributes[aggregate.NormalizeName(rs.Spec.Driver, name)] = aggregate.AttributeString(attr)
+			}
+		}
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// kubeletPluginDir is where DRA drivers create their kubelet plugin socket,
+// in a directory named after the driver.
+const kubeletPluginDir = "/var/lib/kubelet/plugins"
+
+// GetDriverLogs returns the last tailLines log lines of the driver's
+// DaemonSet pod on a node. The pod is recognized by the kubelet plugin
+// directory of the driver it mounts; the logs are those of the container
+// mounting it.
+func (c *resourceClient) GetDriverLogs(ctx context.Context, driver, nodeName string, tailLines int64) (*types.DriverLogs, error) {
+	var logs *types.DriverLogs
+	err := c.forEachPod(ctx, ListOptions{NodeName: nodeName}, func(pod *corev1.Pod) {
+		if logs != nil || pod.Spec.NodeName != nodeName || !ownedByDaemonSet(pod) {
+			return
+		}
+		if container := driverPluginContainer(pod, driver); container != "" {
+			logs = &types.DriverLogs{Namespace: pod.Namespace, Pod: pod.Name, Container: container}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		return nil, fmt.Errorf("no pod of driver %s found on node %s", driver, nodeName)
+	}
+
+	opts := &corev1.PodLogOptions{Container: logs.Container, TailLines: &tailLines}
+	raw, err := c.typedClient.CoreV1().Pods(logs.Namespace).GetLogs(logs.Pod, opts).DoRaw(ctx)
+	if err != nil {
+		return nil, apiError(err, "get logs of pod %s/%s", logs.Namespace, logs.Pod)
+	}
+	if text := strings.TrimRight(string(raw), "\n"); text != "" {
+		logs.Lines = strings.Split(text, "\n")
+	}
+	return logs, nil
+}
+
+func ownedByDaemonSet(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// driverPluginContainer returns the container of the pod mounting the
+// kubelet plugin directory of the driver, or an empty string if there is
+// none.
+func driverPluginContainer(pod *corev1.Pod, driver string) string {
+	pluginDir := path.Join(kubeletPluginDir, driver)
+	volumes := make(map[string]bool)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath != nil && path.Clean(volume.HostPath.Path) == pluginDir {
+			volumes[volume.Name] = true
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if volumes[mount.Name] {
+				return container.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// GetDuplicateDevices returns the device names a driver publishes more than
+// once on the same node, within a pool or across its pools, sorted by node,
+// driver and device. Allocations only name the driver, pool and device, so
+// the scheduler and kubelet cannot tell such devices apart; it usually
+// indicates a driver bug.
+func (c *resourceClient) GetDuplicateDevices(ctx context.Context) ([]types.DuplicateDevice, error) {
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return duplicateDevices(resourceSlices), nil
+}
+
+func duplicateDevices(resourceSlices []model.ResourceSlice) []types.DuplicateDevice {
+	type nodeDeviceKey struct {
+		NodeName string
+		Driver   string
+		Device   string
+	}
+
+	poolGenerations := aggregate.LatestPoolGenerations(resourceSlices)
+	pools := make(map[nodeDeviceKey][]string)
+	for _, rs := range resourceSlices {
+		pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		// slices shared by several nodes have no node to collide on
+		if rs.Spec.NodeName == "" || rs.Spec.Pool.Generation < poolGenerations[pool] {
+			continue
+		}
+		for _, dev := range rs.Spec.Devices {
+			key := nodeDeviceKey{NodeName: rs.Spec.NodeName, Driver: rs.Spec.Driver, Device: dev.Name}
+			pools[key] = append(pools[key], rs.Spec.Pool.Name)
+		}
+	}
+
+	var duplicates []types.DuplicateDevice
+	for key, names := range pools {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		duplicates = append(duplicates, types.DuplicateDevice{
+			NodeName: key.NodeName,
+			Driver:   key.Driver,
+			Device:   key.Device,
+			Pools:    names,
+		})
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		a, b := duplicates[i], duplicates[j]
+		if a.NodeName != b.NodeName {
+			return a.NodeName < b.NodeName
+		}
+		if a.Driver != b.Driver {
+			return a.Driver < b.Driver
+		}
+		return a.Device < b.Device
+	})
+	return duplicates
+}
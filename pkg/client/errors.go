@@ -0,0 +1,76 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// Failure modes of requests to the API server, for use with errors.Is. The
+// errors returned by the client wrap the API server's error in an APIError,
+// which matches the ones that apply.
+var (
+	// ErrForbidden matches requests rejected for lack of permissions or
+	// credentials.
+	ErrForbidden = errors.New("forbidden")
+	// ErrAPIUnavailable matches requests that failed because the API server
+	// could not be reached, timed out or was overloaded. Retrying may help.
+	ErrAPIUnavailable = errors.New("API server unavailable")
+	// ErrPartialData matches requests that failed after part of the data had
+	// been received, e.g. when a paginated list expired.
+	ErrPartialData = errors.New("partial data")
+)
+
+// APIError is a failed request to the API server.
+type APIError struct {
+	// Op describes the request, e.g. "list ResourceSlices".
+	Op string
+	// Partial is set when part of the data had been received before the
+	// request failed.
+	Partial bool
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrForbidden, ErrAPIUnavailable or
+// ErrPartialData.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrForbidden:
+		return apierrors.IsForbidden(e.Err) || apierrors.IsUnauthorized(e.Err)
+	case ErrAPIUnavailable:
+		return isUnavailable(e.Err)
+	case ErrPartialData:
+		return e.Partial
+	}
+	return false
+}
+
+// isUnavailable reports whether err means the API server could not serve the
+// request rather than rejecting it.
+func isUnavailable(err error) bool {
+	if apierrors.IsServiceUnavailable(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+	if utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// apiError wraps the error of a request to the API server described by op.
+func apiError(err error, op string, args ...any) error {
+	return &APIError{Op: fmt.Sprintf(op, args...), Err: err}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// EventSource is the component name events are reported by.
+const EventSource = "dra-resources"
+
+// claimCreationFailedReason is the reason of the events the resourceclaim
+// controller records on a pod when it fails to generate one of its claims.
+const claimCreationFailedReason = "FailedResourceClaimCreation"
+
+// EmitNodeEvent records an Event on a node, in the default namespace where
+// the kubelet records node events, so it shows up in `kubectl describe node`.
+func (c *resourceClient) EmitNodeEvent(ctx context.Context, nodeName, eventType, reason, message string) error {
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", nodeName, now.UnixNano()),
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+			// the kubelet uses the node name as UID for node events
+			UID: k8stypes.UID(nodeName),
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: EventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := c.typedClient.CoreV1().Events(metav1.NamespaceDefault).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		return apiError(err, "create event on node %s", nodeName)
+	}
+	return nil
+}
+
+// claimGenerationFailures returns the message of the latest event reporting
+// a failed claim generation for each pod of the namespace, all namespaces if
+// empty, by pod UID so that events of deleted pods of the same name are
+// ignored.
+func (c *resourceClient) claimGenerationFailures(ctx context.Context, namespace string) (map[k8stypes.UID]string, error) {
+	selector := fields.AndSelectors(
+		fields.OneTermEqualSelector("involvedObject.kind", "Pod"),
+		fields.OneTermEqualSelector("reason", claimCreationFailedReason),
+	)
+	events, err := c.typedClient.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, apiError(err, "list events")
+	}
+
+	failures := make(map[k8stypes.UID]string)
+	latest := make(map[k8stypes.UID]time.Time)
+	for _, event := range events.Items {
+		// fake clientsets, e.g. of -demo, ignore field selectors
+		if event.InvolvedObject.Kind != "Pod" || event.Reason != claimCreationFailedReason {
+			continue
+		}
+		uid := event.InvolvedObject.UID
+		if t, ok := latest[uid]; ok && event.LastTimestamp.Time.Before(t) {
+			continue
+		}
+		latest[uid] = event.LastTimestamp.Time
+		failures[uid] = event.Message
+	}
+	return failures, nil
+}
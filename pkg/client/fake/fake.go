@@ -0,0 +1,96 @@
+// Package fake provides a client.ResourceClient serving in-memory objects,
+// for unit tests of programs using the client package. Clusters are built
+// from the objects of the fixtures package:
+//
+//	c := fake.NewCluster().
+//		Node("node-1").
+//		ResourceSlice("node-1", "gpu.example.com", fixtures.NewDevice("gpu-0", "NVIDIA A100")).
+//		ResourceClaim("default", "training", "gpu.example.com", fixtures.Allocated("gpu.example.com", "node-1", "gpu-0")).
+//		Pod("default", "trainer", "node-1", "training").
+//		Client()
+//
+// Custom resources, such as Kueue's, are not served.
+package fake
+
+import (
+	"github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/dratest/fixtures"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Cluster builds the objects and failures a fake client serves.
+type Cluster struct {
+	namespace string
+	objects   []runtime.Object
+	failures  []failure
+}
+
+// failure makes requests with a verb for a resource fail.
+type failure struct {
+	verb, resource string
+	err            error
+}
+
+// NewCluster returns an empty cluster whose current namespace is default.
+func NewCluster() *Cluster {
+	return &Cluster{namespace: metav1.NamespaceDefault}
+}
+
+// Namespace sets the namespace the client uses as its current namespace.
+func (c *Cluster) Namespace(namespace string) *Cluster {
+	c.namespace = namespace
+	return c
+}
+
+// Node adds a ready node, see fixtures.NewNode.
+func (c *Cluster) Node(name string) *Cluster {
+	return c.Objects(fixtures.NewNode(name))
+}
+
+// ResourceSlice adds a slice publishing the devices of the node's pool, see
+// fixtures.NewResourceSlice.
+func (c *Cluster) ResourceSlice(nodeName, driver string, devices ...resourcev1beta1.Device) *Cluster {
+	return c.Objects(fixtures.NewResourceSlice(nodeName, driver, devices...))
+}
+
+// ResourceClaim adds a claim for a device of the class, allocated to the
+// given devices if any are passed, see fixtures.NewResourceClaim.
+func (c *Cluster) ResourceClaim(namespace, name, deviceClassName string, allocated ...resourcev1beta1.DeviceRequestAllocationResult) *Cluster {
+	return c.Objects(fixtures.NewResourceClaim(namespace, name, deviceClassName, allocated...))
+}
+
+// Pod adds a pod consuming the named claims, see fixtures.NewPod.
+func (c *Cluster) Pod(namespace, name, nodeName string, claimNames ...string) *Cluster {
+	return c.Objects(fixtures.NewPod(namespace, name, nodeName, claimNames...))
+}
+
+// Objects adds arbitrary objects, e.g. DeviceClasses or fixtures modified
+// after building them.
+func (c *Cluster) Objects(objects ...runtime.Object) *Cluster {
+	c.objects = append(c.objects, objects...)
+	return c
+}
+
+// Fail makes requests with the verb, e.g. list, for the resource, e.g.
+// resourceslices, fail with err. Use the errors of k8s.io/apimachinery, such
+// as apierrors.NewForbidden, to exercise the client's typed errors.
+func (c *Cluster) Fail(verb, resource string, err error) *Cluster {
+	c.failures = append(c.failures, failure{verb: verb, resource: resource, err: err})
+	return c
+}
+
+// Client returns a client serving the cluster's objects. Changes made
+// through the client, e.g. deleted claims, are visible to later calls.
+func (c *Cluster) Client() client.ResourceClient {
+	clientset := kubefake.NewSimpleClientset(c.objects...)
+	for _, f := range c.failures {
+		clientset.PrependReactor(f.verb, f.resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, f.err
+		})
+	}
+	return client.NewResourceClientForClientset(clientset, c.namespace)
+}
@@ -0,0 +1,57 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/client/fake"
+	"github.com/dharmjit/k8s-dra-resources/pkg/dratest/fixtures"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCluster(t *testing.T) {
+	c := fake.NewCluster().
+		Node("node-1").
+		ResourceSlice("node-1", "gpu.nvidia.com",
+			fixtures.NewDevice("gpu-0", "NVIDIA A100"),
+			fixtures.NewDevice("gpu-1", "NVIDIA A100"),
+		).
+		ResourceClaim("team-a", "claim-1", "gpu.nvidia.com", fixtures.Allocated("gpu.nvidia.com", "node-1", "gpu-0")).
+		Pod("team-a", "trainer", "node-1", "claim-1").
+		Client()
+
+	nodeInfoList, err := c.GetK8sResources(context.Background(), client.ListOptions{})
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+	if len(nodeInfoList) != 1 || len(nodeInfoList[0].Devices) != 1 {
+		t.Fatalf("expected one node with one product, got %+v", nodeInfoList)
+	}
+	if dev := nodeInfoList[0].Devices[0]; dev.ProductName != "NVIDIA A100" || dev.TotalCount != 2 || dev.AvailableCount != 1 {
+		t.Errorf("expected 1 of 2 NVIDIA A100 available, got %+v", dev)
+	}
+
+	pods, err := c.GetClaimPods(context.Background(), client.ListOptions{})
+	if err != nil {
+		t.Fatalf("GetClaimPods() error = %v", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "trainer" {
+		t.Errorf("expected pod trainer, got %+v", pods)
+	}
+	if c.Namespace() != "default" {
+		t.Errorf("Namespace() = %s, want default", c.Namespace())
+	}
+}
+
+func TestClusterFail(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "resource.k8s.io", Resource: "resourceslices"}, "", errors.New("no access"))
+	c := fake.NewCluster().Node("node-1").Fail("list", "resourceslices", forbidden).Client()
+
+	_, err := c.GetK8sResources(context.Background(), client.ListOptions{})
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("GetK8sResources() error = %v, want ErrForbidden", err)
+	}
+}
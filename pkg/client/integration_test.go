@@ -0,0 +1,41 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/dratest"
+	"github.com/dharmjit/k8s-dra-resources/pkg/dratest/fixtures"
+)
+
+func TestGetK8sResourcesIntegration(t *testing.T) {
+	env := dratest.Start(t)
+	env.Create(t,
+		fixtures.NewNode("node-1"),
+		fixtures.NewResourceSlice("node-1", "gpu.nvidia.com",
+			fixtures.NewDevice("gpu-0", "NVIDIA A100"),
+			fixtures.NewDevice("gpu-1", "NVIDIA A100"),
+		),
+		fixtures.NewResourceClaim("team-a", "claim-1", "gpu.nvidia.com",
+			fixtures.Allocated("gpu.nvidia.com", "node-1", "gpu-0"),
+		),
+	)
+
+	c, err := client.NewResourceClient(env.KubeconfigPath)
+	if err != nil {
+		t.Fatalf("NewResourceClient() error = %v", err)
+	}
+	nodeInfoList, err := c.GetK8sResources(context.Background(), client.ListOptions{})
+	if err != nil {
+		t.Fatalf("GetK8sResources() error = %v", err)
+	}
+
+	if len(nodeInfoList) != 1 || len(nodeInfoList[0].Devices) != 1 {
+		t.Fatalf("expected one node with one product, got %+v", nodeInfoList)
+	}
+	dev := nodeInfoList[0].Devices[0]
+	if dev.ProductName != "NVIDIA A100" || dev.TotalCount != 2 || dev.AvailableCount != 1 {
+		t.Errorf("expected 1 of 2 NVIDIA A100 available, got %+v", dev)
+	}
+}
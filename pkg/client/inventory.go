@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/decorator"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// DefaultVersionAttributes are the driver version attributes inventoried when
+// no others are asked for.
+var DefaultVersionAttributes = []string{"driverVersion", "cudaDriverVersion"}
+
+// FirmwareAttributes are the attributes drivers commonly use to publish the
+// firmware and VBIOS versions of a device.
+var FirmwareAttributes = []string{"firmwareVersion", "vbiosVersion"}
+
+// GetAttributeInventory returns, per driver and attribute, which values the
+// devices publish and on which nodes. With perProduct, the inventory is also
+// broken down by product. Attribute names may be given with or without the
+// driver's domain. Values that parse as versions and are older than the
+// newest value of the same group are marked outdated.
+func (c *resourceClient) GetAttributeInventory(ctx context.Context, attributes []string, perProduct bool) ([]types.AttributeInventory, error) {
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return attributeInventory(ctx, resourceSlices, attributes, perProduct)
+}
+
+func attributeInventory(ctx context.Context, resourceSlices []model.ResourceSlice, attributes []string, perProduct bool) ([]types.AttributeInventory, error) {
+	type inventoryKey struct {
+		Driver      string
+		ProductName string
+		Attribute   string
+	}
+	type valueStats struct {
+		devices int
+		nodes   map[string]bool
+	}
+
+	poolGenerations := aggregate.LatestPoolGenerations(resourceSlices)
+	var sliceDecorations [][]decorator.Decoration
+	if perProduct {
+		var err error
+		if sliceDecorations, err = aggregate.DecorateSlices(ctx, resourceSlices); err != nil {
+			return nil, err
+		}
+	}
+	values := make(map[inventoryKey]map[string]*valueStats)
+	seenDevices := make(map[aggregate.DeviceKey]bool)
+	for si, rs := range resourceSlices {
+		pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		if rs.Spec.Pool.Generation < poolGenerations[pool] {
+			continue
+		}
+		// devices not bound to a node are reported under their pool
+		location := rs.Spec.NodeName
+		if location == "" {
+			location = rs.Spec.Pool.Name
+		}
+
+		var decorations []decorator.Decoration
+		if perProduct {
+			decorations = sliceDecorations[si]
+		}
+
+		for i, dev := range rs.Spec.Devices {
+			if seenDevices[pool.Device(dev.Name)] {
+				continue
+			}
+			seenDevices[pool.Device(dev.Name)] = true
+
+			for _, name := range attributes {
+				attr, ok := aggregate.LookupAttribute(dev.Attributes, rs.Spec.Driver, name)
+				if !ok {
+					continue
+				}
+				key := inventoryKey{Driver: rs.Spec.Driver, Attribute: name}
+				if perProduct {
+					key.ProductName = decorations[i].ProductName
+				}
+				if values[key] == nil {
+					values[key] = make(map[string]*valueStats)
+				}
+				value := aggregate.AttributeString(attr)
+				stats, ok := values[key][value]
+				if !ok {
+					stats = &valueStats{nodes: make(map[string]bool)}
+					values[key][value] = stats
+				}
+				stats.devices++
+				stats.nodes[location] = true
+			}
+		}
+	}
+
+	inventory := make([]types.AttributeInventory, 0, len(values))
+	for key, byValue := range values {
+		inv := types.AttributeInventory{Driver: key.Driver, ProductName: key.ProductName, Attribute: key.Attribute}
+		for value, stats := range byValue {
+			v := types.AttributeValue{Value: value, Devices: stats.devices}
+			for node := range stats.nodes {
+				v.Nodes = append(v.Nodes, node)
+			}
+			sort.Strings(v.Nodes)
+			inv.Values = append(inv.Values, v)
+		}
+		markOutdated(inv.Values)
+		inventory = append(inventory, inv)
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		if inventory[i].Driver != inventory[j].Driver {
+			return inventory[i].Driver < inventory[j].Driver
+		}
+		if inventory[i].ProductName != inventory[j].ProductName {
+			return inventory[i].ProductName < inventory[j].ProductName
+		}
+		return inventory[i].Attribute < inventory[j].Attribute
+	})
+	return inventory, nil
+}
+
+// markOutdated sorts the values newest first and marks every version older
+// than the newest as outdated. Values that are not versions are sorted last
+// and never marked.
+func markOutdated(values []types.AttributeValue) {
+	parsed := make(map[string]*version.Version)
+	for _, v := range values {
+		if ver, err := version.ParseGeneric(v.Value); err == nil {
+			parsed[v.Value] = ver
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		vi, vj := parsed[values[i].Value], parsed[values[j].Value]
+		switch {
+		case vi != nil && vj != nil && !vi.EqualTo(vj):
+			return vj.LessThan(vi)
+		case (vi == nil) != (vj == nil):
+			return vi != nil
+		default:
+			return values[i].Value < values[j].Value
+		}
+	})
+
+	newest := parsed[values[0].Value]
+	if newest == nil {
+		return
+	}
+	for i := range values {
+		if ver := parsed[values[i].Value]; ver != nil && ver.LessThan(newest) {
+			values[i].Outdated = true
+		}
+	}
+}
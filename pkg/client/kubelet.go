@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// kubeletClaimPrefix starts the names of the allocatedResourcesStatus entries
+// the kubelet reports for DRA claims, claim:<entry> or
+// claim:<entry>/<request>, where entry names the claim in the pod's spec.
+const kubeletClaimPrefix = "claim:"
+
+// GetKubeletDeviceMismatches compares the devices the kubelet reports in the
+// allocatedResourcesStatus of each container with the allocation of the
+// claims they belong to, and returns the requests whose devices differ,
+// sorted by namespace, pod, container, claim and request. The kubelet only
+// reports devices with the ResourceHealthStatus feature gate, so containers
+// without DRA entries in their status are skipped rather than flagged.
+func (c *resourceClient) GetKubeletDeviceMismatches(ctx context.Context) ([]types.KubeletDeviceMismatch, error) {
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	claims := make(map[string]*model.ResourceClaim, len(resourceClaims))
+	for i := range resourceClaims {
+		claims[resourceClaims[i].Namespace+"/"+resourceClaims[i].Name] = &resourceClaims[i]
+	}
+
+	var mismatches []types.KubeletDeviceMismatch
+	err = c.forEachPod(ctx, ListOptions{}, func(pod *corev1.Pod) {
+		if pod.Spec.NodeName == "" || len(pod.Spec.ResourceClaims) == 0 {
+			return
+		}
+		statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+		for _, status := range statuses {
+			for _, resourceStatus := range status.AllocatedResourcesStatus {
+				entry, found := strings.CutPrefix(string(resourceStatus.Name), kubeletClaimPrefix)
+				if !found {
+					continue
+				}
+				entry, request, _ := strings.Cut(entry, "/")
+				name := kubeletClaimName(pod, entry)
+				if name == "" {
+					continue
+				}
+
+				var allocated []string
+				if rc, ok := claims[pod.Namespace+"/"+name]; ok && rc.Status.Allocation != nil {
+					for _, result := range rc.Status.Allocation.Devices.Results {
+						if request == "" || result.Request == request || strings.HasPrefix(result.Request, request+"/") {
+							allocated = append(allocated, result.Driver+"/"+result.Pool+"/"+result.Device)
+						}
+					}
+				}
+				var reported []string
+				for _, health := range resourceStatus.Resources {
+					reported = append(reported, string(health.ResourceID))
+				}
+				sort.Strings(allocated)
+				sort.Strings(reported)
+				allocated, reported = slices.Compact(allocated), slices.Compact(reported)
+				if slices.Equal(allocated, reported) {
+					continue
+				}
+				mismatches = append(mismatches, types.KubeletDeviceMismatch{
+					Namespace: pod.Namespace,
+					Pod:       pod.Name,
+					Container: status.Name,
+					NodeName:  pod.Spec.NodeName,
+					Claim:     name,
+					Request:   request,
+					Allocated: allocated,
+					Reported:  reported,
+				})
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		a, b := mismatches[i], mismatches[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+		if a.Container != b.Container {
+			return a.Container < b.Container
+		}
+		if a.Claim != b.Claim {
+			return a.Claim < b.Claim
+		}
+		return a.Request < b.Request
+	})
+	return mismatches, nil
+}
+
+// kubeletClaimName returns the name of the claim of the pod's claim entry
+// the kubelet reports, or "" if the pod has no such entry or no claim for it.
+func kubeletClaimName(pod *corev1.Pod, entry string) string {
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		if podClaim.Name == entry {
+			name, _ := podClaimName(pod, podClaim)
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrKueueNotInstalled is returned when the cluster has no Kueue CRDs.
+var ErrKueueNotInstalled = errors.New("kueue is not installed in the cluster")
+
+// Kueue resources read with the dynamic client.
+var (
+	clusterQueueResource = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "clusterqueues"}
+	localQueueResource   = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "localqueues"}
+	workloadResource     = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "workloads"}
+)
+
+// kueueClusterQueue, kueueLocalQueue and kueueWorkload hold the fields of
+// the Kueue resources needed to compute device demand.
+type kueueClusterQueue struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ResourceGroups []struct {
+			Flavors []struct {
+				Resources []struct {
+					Name         string            `json:"name"`
+					NominalQuota resource.Quantity `json:"nominalQuota"`
+				} `json:"resources"`
+			} `json:"flavors"`
+		} `json:"resourceGroups"`
+	} `json:"spec"`
+}
+
+type kueueLocalQueue struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ClusterQueue string `json:"clusterQueue"`
+	} `json:"spec"`
+}
+
+type kueueWorkload struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		QueueName string `json:"queueName"`
+		PodSets   []struct {
+			Count    int32                  `json:"count"`
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"podSets"`
+	} `json:"spec"`
+	Status struct {
+		Admission *struct {
+			ClusterQueue string `json:"clusterQueue"`
+		} `json:"admission,omitempty"`
+		Conditions []metav1.Condition `json:"conditions,omitempty"`
+	} `json:"status"`
+}
+
+// GetQueueDemand returns, per Kueue ClusterQueue and DeviceClass, the devices
+// requested by the queue's admitted and pending workloads, the queue's quota
+// and the devices of the class in the cluster, sorted by queue and class.
+// quotaResources maps DeviceClass names to the resource names used in the
+// quotas, as in Kueue's deviceClassMappings; unmapped classes use their own
+// name.
+//
+// Workloads request devices through the ResourceClaimTemplates of their pod
+// templates, counted once per pod, and through named claims, counted once per
+// workload since their pods share them.
+func (c *resourceClient) GetQueueDemand(ctx context.Context, quotaResources map[string]string) ([]types.QueueDemand, error) {
+	if c.dynamicClient == nil {
+		return nil, ErrKueueNotInstalled
+	}
+	clusterQueues, err := listCustomResources[kueueClusterQueue](ctx, c.dynamicClient, clusterQueueResource, metav1.NamespaceAll, ErrKueueNotInstalled)
+	if err != nil {
+		return nil, err
+	}
+	localQueues, err := listCustomResources[kueueLocalQueue](ctx, c.dynamicClient, localQueueResource, metav1.NamespaceAll, ErrKueueNotInstalled)
+	if err != nil {
+		return nil, err
+	}
+	workloads, err := listCustomResources[kueueWorkload](ctx, c.dynamicClient, workloadResource, metav1.NamespaceAll, ErrKueueNotInstalled)
+	if err != nil {
+		return nil, err
+	}
+
+	queueOf := make(map[string]string) // namespace/localqueue -> clusterqueue
+	for _, lq := range localQueues {
+		queueOf[lq.Namespace+"/"+lq.Name] = lq.Spec.ClusterQueue
+	}
+
+	templates, err := c.listResourceClaimTemplates(ctx, metav1.NamespaceAll)
+	if err != nil {
+		return nil, apiError(err, "list ResourceClaimTemplates")
+	}
+	templateSpecs := make(map[string]*model.ResourceClaimSpec)
+	for i := range templates {
+		templateSpecs[templates[i].Namespace+"/"+templates[i].Name] = &templates[i].Spec.Spec
+	}
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	claimSpecs := make(map[string]*model.ResourceClaimSpec)
+	for i := range resourceClaims {
+		claimSpecs[resourceClaims[i].Namespace+"/"+resourceClaims[i].Name] = &resourceClaims[i].Spec
+	}
+
+	demand := make(map[queueClass]*types.QueueDemand)
+	entry := func(queue, class string) *types.QueueDemand {
+		key := queueClass{queue, class}
+		if demand[key] == nil {
+			demand[key] = &types.QueueDemand{ClusterQueue: queue, DeviceClass: class}
+		}
+		return demand[key]
+	}
+
+	for _, wl := range workloads {
+		if meta.IsStatusConditionTrue(wl.Status.Conditions, "Finished") {
+			continue
+		}
+		admitted := meta.IsStatusConditionTrue(wl.Status.Conditions, "Admitted")
+		queue := queueOf[wl.Namespace+"/"+wl.Spec.QueueName]
+		if wl.Status.Admission != nil {
+			queue = wl.Status.Admission.ClusterQueue
+		}
+		if queue == "" {
+			continue
+		}
+
+		requested := make(map[string]int) // class -> devices
+		for _, podSet := range wl.Spec.PodSets {
+			for _, claim := range podSet.Template.Spec.ResourceClaims {
+				switch {
+				case claim.ResourceClaimTemplateName != nil:
+					if spec := templateSpecs[wl.Namespace+"/"+*claim.ResourceClaimTemplateName]; spec != nil {
+						for class, count := range requestedDevices(spec) {
+							requested[class] += count * int(podSet.Count)
+						}
+					}
+				case claim.ResourceClaimName != nil:
+					if spec := claimSpecs[wl.Namespace+"/"+*claim.ResourceClaimName]; spec != nil {
+						for class, count := range requestedDevices(spec) {
+							requested[class] += count
+						}
+					}
+				}
+			}
+		}
+		for class, count := range requested {
+			d := entry(queue, class)
+			if admitted {
+				d.Admitted += count
+			} else {
+				d.Pending += count
+				d.PendingWorkloads++
+			}
+		}
+	}
+
+	classes := make(map[string]bool)
+	for key := range demand {
+		classes[key.class] = true
+	}
+	for class := range quotaResources {
+		classes[class] = true
+	}
+	for _, cq := range clusterQueues {
+		quotas := make(map[string]int64)
+		for _, group := range cq.Spec.ResourceGroups {
+			for _, flavor := range group.Flavors {
+				for _, res := range flavor.Resources {
+					quotas[res.Name] += res.NominalQuota.Value()
+				}
+			}
+		}
+		for class := range classes {
+			resourceName := class
+			if name, ok := quotaResources[class]; ok {
+				resourceName = name
+			}
+			if quota, ok := quotas[resourceName]; ok {
+				entry(cq.Name, class).Quota = &quota
+			}
+		}
+	}
+
+	if len(demand) > 0 {
+		counts, err := c.classDeviceCounts(ctx, classes, resourceClaims)
+		if err != nil {
+			return nil, err
+		}
+		for key, d := range demand {
+			d.ClusterTotal, d.ClusterAvailable = counts[key.class].TotalCount, counts[key.class].AvailableCount
+		}
+	}
+
+	result := make([]types.QueueDemand, 0, len(demand))
+	for _, d := range demand {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ClusterQueue != result[j].ClusterQueue {
+			return result[i].ClusterQueue < result[j].ClusterQueue
+		}
+		return result[i].DeviceClass < result[j].DeviceClass
+	})
+	return result, nil
+}
+
+// queueClass identifies the demand of a ClusterQueue for a DeviceClass.
+type queueClass struct {
+	queue string
+	class string
+}
+
+// requestedDevices returns the number of devices the claim spec requests per
+// DeviceClass. Requests for all matching devices count as one, and requests
+// with alternatives count as their first alternative.
+func requestedDevices(spec *model.ResourceClaimSpec) map[string]int {
+	requested := make(map[string]int)
+	for _, req := range spec.Devices.Requests {
+		class, mode, count := req.DeviceClassName, req.AllocationMode, req.Count
+		if len(req.FirstAvailable) > 0 {
+			sub := req.FirstAvailable[0]
+			class, mode, count = sub.DeviceClassName, sub.AllocationMode, sub.Count
+		}
+		if mode == model.DeviceAllocationModeAll {
+			count = 1
+		}
+		requested[class] += int(max(count, 1))
+	}
+	return requested
+}
+
+// listCustomResources lists the custom resources of a namespace, or of all
+// namespaces when it is empty, decoded into T. A missing CRD is reported as notInstalled.
+func listCustomResources[T any](ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace string, notInstalled error) ([]T, error) {
+	list, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, notInstalled
+	}
+	if err != nil {
+		return nil, apiError(err, "list %s", gvr.Resource)
+	}
+	items := make([]T, len(list.Items))
+	for i := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].UnstructuredContent(), &items[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode %s %s: %w", gvr.Resource, list.Items[i].GetName(), err)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// ErrLostLeadership is returned by RunLeaderElected when the lease is lost
+// before the context is canceled.
+var ErrLostLeadership = errors.New("lost leadership")
+
+// LeaderElectionConfig identifies the Lease replicas compete for and this
+// replica.
+type LeaderElectionConfig struct {
+	LeaseName      string
+	LeaseNamespace string
+	// Identity must be unique among the replicas.
+	Identity string
+}
+
+// RunLeaderElected blocks until this replica holds the Lease, then calls run
+// with a context that is canceled when leadership is lost. run should only
+// return on error or once its context is canceled. RunLeaderElected returns
+// run's error, nil when ctx is canceled, and ErrLostLeadership otherwise. The
+// lease is released when run returns.
+func (c *resourceClient) RunLeaderElected(ctx context.Context, config LeaderElectionConfig, run func(ctx context.Context) error) error {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: config.LeaseName, Namespace: config.LeaseNamespace},
+		Client:     c.typedClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: config.Identity},
+	}
+
+	electionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var leading atomic.Bool
+	var runErr error
+	done := make(chan struct{})
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				leading.Store(true)
+				defer close(done)
+				runErr = run(ctx)
+				// step down when run gives up while still leading
+				cancel()
+			},
+			OnStoppedLeading: func() {},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up leader election: %w", err)
+	}
+
+	elector.Run(electionCtx)
+	if !leading.Load() {
+		return nil
+	}
+	<-done
+	if runErr != nil {
+		return runErr
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return ErrLostLeadership
+}
@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	resourcev1 "k8s.io/api/resource/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Mirror is an in-memory copy of the nodes, pods, ResourceSlices and
+// ResourceClaims of the cluster, kept up to date by informers. Long-running
+// views aggregate from it on every change instead of listing the cluster
+// again; only the initial list and relists after the watch could not be
+// resumed reach the API server. Pods are trimmed to the fields the
+// aggregation reads.
+type Mirror struct {
+	nodes, pods, slices, claims cache.SharedIndexInformer
+	// opts selects the mirrored objects, and nodeSelector is its parsed
+	// NodeSelector.
+	opts         ListOptions
+	nodeSelector labels.Selector
+	// apiVersion is the version of resource.k8s.io of the ResourceSlices
+	// and ResourceClaims.
+	apiVersion string
+}
+
+// Mirror lists the objects selected by opts, as GetK8sResources does, and
+// returns a mirror of them, which is kept up to date until ctx is done. After
+// the initial list, onChange is called whenever an object changes; it may be
+// called concurrently.
+func (c *resourceClient) Mirror(ctx context.Context, opts ListOptions, onChange func()) (*Mirror, error) {
+	nodeSelector, err := labels.Parse(opts.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node selector %q: %w", opts.NodeSelector, err)
+	}
+	// informers of a factory share its list options, so each selection has
+	// its own factory; claims cannot be filtered by node on the server
+	factory := func(selected metav1.ListOptions) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(c.typedClient, 0,
+			informers.WithTransform(trimObject),
+			informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+				o.LabelSelector = selected.LabelSelector
+				o.FieldSelector = selected.FieldSelector
+			}))
+	}
+	nodeFactory := factory(opts.nodes())
+	podFactory := factory(opts.pods())
+	sliceFactory := factory(opts.resourceSlices())
+	claimFactory := factory(metav1.ListOptions{})
+	m := &Mirror{
+		nodes:        nodeFactory.Core().V1().Nodes().Informer(),
+		pods:         podFactory.Core().V1().Pods().Informer(),
+		opts:         opts,
+		nodeSelector: nodeSelector,
+		apiVersion:   c.resourceAPIVersion(),
+	}
+	switch m.apiVersion {
+	case "v1":
+		m.slices = sliceFactory.Resource().V1().ResourceSlices().Informer()
+		m.claims = claimFactory.Resource().V1().ResourceClaims().Informer()
+	case "v1beta2":
+		m.slices = sliceFactory.Resource().V1beta2().ResourceSlices().Informer()
+		m.claims = claimFactory.Resource().V1beta2().ResourceClaims().Informer()
+	default:
+		m.slices = sliceFactory.Resource().V1beta1().ResourceSlices().Informer()
+		m.claims = claimFactory.Resource().V1beta1().ResourceClaims().Informer()
+	}
+
+	var synced atomic.Bool
+	changed := func(any) {
+		// the objects of the initial list are part of the first aggregation
+		if synced.Load() {
+			onChange()
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    changed,
+		UpdateFunc: func(_, obj any) { changed(obj) },
+		DeleteFunc: changed,
+	}
+	listErrs := make(chan error, 4)
+	mirrored := map[string]cache.SharedIndexInformer{"nodes": m.nodes, "pods": m.pods, "ResourceSlices": m.slices, "ResourceClaims": m.claims}
+	for name, informer := range mirrored {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return nil, err
+		}
+		// Errors before the initial list completes, e.g. forbidden lists,
+		// fail Mirror; later ones are retried by the informer.
+		err := informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+			if !synced.Load() {
+				select {
+				case listErrs <- apiError(err, "list %s", name):
+				default:
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	syncCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for _, f := range []informers.SharedInformerFactory{nodeFactory, podFactory, sliceFactory, claimFactory} {
+		f.Start(ctx.Done())
+	}
+	done := make(chan bool, 1)
+	go func() {
+		done <- cache.WaitForCacheSync(syncCtx.Done(), m.nodes.HasSynced, m.pods.HasSynced, m.slices.HasSynced, m.claims.HasSynced)
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			return nil, ctx.Err()
+		}
+	case err := <-listErrs:
+		return nil, err
+	}
+	synced.Store(true)
+	return m, nil
+}
+
+// trimObject drops the managed fields of the mirrored objects, and of pods
+// everything the aggregation does not read.
+func trimObject(obj any) (any, error) {
+	if accessor, ok := obj.(metav1.ObjectMetaAccessor); ok {
+		accessor.GetObjectMeta().SetManagedFields(nil)
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	trimmed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       pod.Namespace,
+			Name:            pod.Name,
+			UID:             pod.UID,
+			ResourceVersion: pod.ResourceVersion,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:       pod.Spec.NodeName,
+			ResourceClaims: pod.Spec.ResourceClaims,
+		},
+		Status: corev1.PodStatus{Phase: pod.Status.Phase},
+	}
+	for _, container := range pod.Spec.Containers {
+		trimmed.Spec.Containers = append(trimmed.Spec.Containers, corev1.Container{
+			Name:      container.Name,
+			Resources: corev1.ResourceRequirements{Requests: container.Resources.Requests},
+		})
+	}
+	return trimmed, nil
+}
+
+// Nodes aggregates the mirrored objects into the per-node summaries
+// GetK8sResources returns.
+func (m *Mirror) Nodes(ctx context.Context) ([]*types.NodeInfo, error) {
+	pods := aggregate.NewPodUsage()
+	for _, obj := range m.pods.GetStore().List() {
+		pods.Add(obj.(*corev1.Pod))
+	}
+	return aggregate.Nodes(ctx, m.nodeList(), m.sliceList(), m.claimList(), pods)
+}
+
+// Devices returns the devices of the mirrored objects, as GetDevices does
+// for all nodes.
+func (m *Mirror) Devices(ctx context.Context) ([]types.DeviceInfo, error) {
+	claims := m.claimList()
+	return devicesOf(ctx, m.sliceList(), newDeviceListerFor(m.nodeList(), claims, false))
+}
+
+// nodeList returns the mirrored nodes sorted by name, as the API server
+// lists them. Fake clientsets, e.g. of -demo, ignore selectors on watches, so
+// mirrored nodes and ResourceSlices are checked again.
+func (m *Mirror) nodeList() []corev1.Node {
+	objs := m.nodes.GetStore().List()
+	nodes := make([]corev1.Node, 0, len(objs))
+	for _, obj := range objs {
+		node := obj.(*corev1.Node)
+		if (m.opts.NodeName != "" && node.Name != m.opts.NodeName) || !m.nodeSelector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+func (m *Mirror) sliceList() []model.ResourceSlice {
+	objs := m.slices.GetStore().List()
+	slices := make([]model.ResourceSlice, 0, len(objs))
+	for _, obj := range objs {
+		var rs model.ResourceSlice
+		switch m.apiVersion {
+		case "v1":
+			rs = model.FromV1ResourceSlice(obj.(*resourcev1.ResourceSlice))
+		case "v1beta2":
+			rs = model.FromV1beta2ResourceSlice(obj.(*resourcev1beta2.ResourceSlice))
+		default:
+			rs = model.FromV1beta1ResourceSlice(obj.(*resourcev1beta1.ResourceSlice))
+		}
+		if m.opts.selectsSlice(&rs) {
+			slices = append(slices, rs)
+		}
+	}
+	sort.Slice(slices, func(i, j int) bool { return slices[i].Name < slices[j].Name })
+	return slices
+}
+
+func (m *Mirror) claimList() []model.ResourceClaim {
+	objs := m.claims.GetStore().List()
+	claims := make([]model.ResourceClaim, 0, len(objs))
+	for _, obj := range objs {
+		switch m.apiVersion {
+		case "v1":
+			claims = append(claims, model.FromV1ResourceClaim(obj.(*resourcev1.ResourceClaim)))
+		case "v1beta2":
+			claims = append(claims, model.FromV1beta2ResourceClaim(obj.(*resourcev1beta2.ResourceClaim)))
+		default:
+			claims = append(claims, model.FromV1beta1ResourceClaim(obj.(*resourcev1beta1.ResourceClaim)))
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Namespace != claims[j].Namespace {
+			return claims[i].Namespace < claims[j].Namespace
+		}
+		return claims[i].Name < claims[j].Name
+	})
+	return claims
+}
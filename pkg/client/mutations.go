@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of field changes.
+const (
+	ChangeDefaulted = "defaulted"
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeChanged   = "changed"
+)
+
+// requestDefaults are the fields the API server defaults in device requests
+// and their subrequests, keyed by field name, with their JSON default.
+var requestDefaults = map[string]string{
+	"allocationMode": `"ExactCount"`,
+	"count":          "1",
+}
+
+// GetClaimMutations compares a claim against the ResourceClaimTemplate it
+// was generated from or, for claims created directly, against its last
+// applied configuration, and lists the configuration its DeviceClasses add.
+// Unlike the other reads it uses v1beta1 rather than the model: the fields are
+// diffed as serialized, and the last applied configuration is only meaningful
+// against the version it was applied with.
+func (c *resourceClient) GetClaimMutations(ctx context.Context, namespace, name string) (*types.ClaimMutations, error) {
+	claim, err := c.typedClient.ResourceV1beta1().ResourceClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, apiError(err, "get ResourceClaim %s/%s", namespace, name)
+	}
+	result := &types.ClaimMutations{Namespace: namespace, Name: name}
+
+	source, base, err := c.claimSource(ctx, claim)
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		result.Source = source
+		if result.Changes, err = diffClaimSpecs(base, &claim.Spec); err != nil {
+			return nil, err
+		}
+	}
+
+	classes := make(map[string]*resourcev1beta1.DeviceClass)
+	for _, request := range claim.Spec.Devices.Requests {
+		className := request.DeviceClassName
+		if className == "" {
+			continue
+		}
+		class, ok := classes[className]
+		if !ok {
+			class, err = c.typedClient.ResourceV1beta1().DeviceClasses().Get(ctx, className, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				classes[className] = nil
+				continue
+			}
+			if err != nil {
+				return nil, apiError(err, "get DeviceClass %s", className)
+			}
+			classes[className] = class
+		}
+		if class == nil {
+			continue
+		}
+		for _, config := range class.Spec.Config {
+			if config.Opaque == nil {
+				continue
+			}
+			result.ClassConfigs = append(result.ClassConfigs, types.ClassConfig{
+				Request:     request.Name,
+				DeviceClass: className,
+				Driver:      config.Opaque.Driver,
+				Parameters:  string(config.Opaque.Parameters.Raw),
+			})
+		}
+	}
+	return result, nil
+}
+
+// claimSource returns a description and the spec of what the claim was
+// created from, or a nil spec if that is unknown. A claim generated from a
+// template is owned by its pod, whose status records which of its entries
+// the claim was generated for.
+func (c *resourceClient) claimSource(ctx context.Context, claim *resourcev1beta1.ResourceClaim) (string, *resourcev1beta1.ResourceClaimSpec, error) {
+	for _, owner := range claim.OwnerReferences {
+		if owner.Kind != "Pod" {
+			continue
+		}
+		pod, err := c.typedClient.CoreV1().Pods(claim.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return "", nil, apiError(err, "get pod %s/%s", claim.Namespace, owner.Name)
+		}
+		for _, podClaim := range pod.Spec.ResourceClaims {
+			if name, _ := podClaimName(pod, podClaim); name != claim.Name || podClaim.ResourceClaimTemplateName == nil {
+				continue
+			}
+			templateName := *podClaim.ResourceClaimTemplateName
+			template, err := c.typedClient.ResourceV1beta1().ResourceClaimTemplates(claim.Namespace).Get(ctx, templateName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return "", nil, apiError(err, "get ResourceClaimTemplate %s/%s", claim.Namespace, templateName)
+			}
+			return fmt.Sprintf("ResourceClaimTemplate %s/%s of pod %s", claim.Namespace, templateName, pod.Name), &template.Spec.Spec, nil
+		}
+	}
+	if applied, ok := claim.Annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		var appliedClaim resourcev1beta1.ResourceClaim
+		if err := json.Unmarshal([]byte(applied), &appliedClaim); err == nil {
+			return "last applied configuration", &appliedClaim.Spec, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// diffClaimSpecs returns the fields that differ between two claim specs,
+// sorted by path.
+func diffClaimSpecs(from, to *resourcev1beta1.ResourceClaimSpec) ([]types.FieldChange, error) {
+	fromFields, err := flattenJSON(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flattenJSON(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	for path := range fromFields {
+		paths[path] = true
+	}
+	for path := range toFields {
+		paths[path] = true
+	}
+	var changes []types.FieldChange
+	for path := range paths {
+		fromValue, inFrom := fromFields[path]
+		toValue, inTo := toFields[path]
+		change := types.FieldChange{Path: path, From: fromValue, To: toValue}
+		switch {
+		case inFrom && inTo && fromValue == toValue:
+			continue
+		case !inFrom && isRequestDefault(path, toValue):
+			change.Reason = ChangeDefaulted
+		case !inFrom:
+			change.Reason = ChangeAdded
+		case !inTo:
+			change.Reason = ChangeRemoved
+		default:
+			change.Reason = ChangeChanged
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// isRequestDefault reports whether the field at path of a device request or
+// subrequest holds its API default.
+func isRequestDefault(path, value string) bool {
+	if !strings.HasPrefix(path, "devices.requests[") {
+		return false
+	}
+	field := path[strings.LastIndex(path, ".")+1:]
+	def, ok := requestDefaults[field]
+	return ok && def == value
+}
+
+// flattenJSON returns the leaf values of v's JSON representation, keyed by
+// their path, e.g. devices.requests[0].count.
+func flattenJSON(v any) (map[string]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claim spec: %w", err)
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode claim spec: %w", err)
+	}
+	fields := make(map[string]string)
+	var walk func(path string, node any)
+	walk = func(path string, node any) {
+		switch node := node.(type) {
+		case map[string]any:
+			if len(node) == 0 && path != "" {
+				fields[path] = "{}"
+			}
+			for key, child := range node {
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				walk(childPath, child)
+			}
+		case []any:
+			if len(node) == 0 {
+				fields[path] = "[]"
+			}
+			for i, child := range node {
+				walk(fmt.Sprintf("%s[%d]", path, i), child)
+			}
+		default:
+			leaf, _ := json.Marshal(node)
+			fields[path] = string(leaf)
+		}
+	}
+	walk("", tree)
+	return fields, nil
+}
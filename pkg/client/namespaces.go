@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetNamespaceLabels returns the labels of every namespace, keyed by
+// namespace name, e.g. to attribute claims to the team owning a namespace.
+func (c *resourceClient) GetNamespaceLabels(ctx context.Context) (map[string]map[string]string, error) {
+	list, err := c.typedClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, apiError(err, "list namespaces")
+	}
+	labels := make(map[string]map[string]string, len(list.Items))
+	for _, ns := range list.Items {
+		labels[ns.Name] = ns.Labels
+	}
+	return labels, nil
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// ListOptions narrows what a call returns. The filters are passed to the API
+// server as namespaces and field or label selectors wherever it supports
+// them, so only matching objects are transferred. The zero value selects
+// everything.
+type ListOptions struct {
+	// Namespace restricts claims and pods to one namespace.
+	Namespace string
+	// NodeName restricts nodes, ResourceSlices and pods to one node.
+	NodeName string
+	// NodeSelector is a label selector restricting nodes, e.g.
+	// "nvidia.com/gpu.present=true".
+	NodeSelector string
+	// Driver restricts ResourceSlices to one driver.
+	Driver string
+}
+
+// nodes returns the list options selecting the nodes.
+func (o ListOptions) nodes() metav1.ListOptions {
+	opts := metav1.ListOptions{LabelSelector: o.NodeSelector}
+	if o.NodeName != "" {
+		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", o.NodeName).String()
+	}
+	return opts
+}
+
+// resourceSlices returns the list options selecting the ResourceSlices.
+func (o ListOptions) resourceSlices() metav1.ListOptions {
+	var selectors []fields.Selector
+	if o.NodeName != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("spec.nodeName", o.NodeName))
+	}
+	if o.Driver != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("spec.driver", o.Driver))
+	}
+	if len(selectors) == 0 {
+		return metav1.ListOptions{}
+	}
+	return metav1.ListOptions{FieldSelector: fields.AndSelectors(selectors...).String()}
+}
+
+// selectsSlice reports whether the ResourceSlice matches the node name and
+// driver. Fake clientsets, e.g. of -demo, ignore field selectors, so listed
+// slices are checked again.
+func (o ListOptions) selectsSlice(rs *model.ResourceSlice) bool {
+	return (o.NodeName == "" || rs.Spec.NodeName == o.NodeName) && (o.Driver == "" || rs.Spec.Driver == o.Driver)
+}
+
+// pods returns the list options selecting the pods. The namespace is part of
+// the request path rather than of the options.
+func (o ListOptions) pods() metav1.ListOptions {
+	var opts metav1.ListOptions
+	if o.NodeName != "" {
+		opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", o.NodeName).String()
+	}
+	return opts
+}
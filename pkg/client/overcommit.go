@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// counterKey identifies a counter of a shared counter set. Counter set names
+// are only unique per pool.
+type counterKey struct {
+	aggregate.PoolKey
+	CounterSet string
+	Counter    string
+}
+
+// GetCounterOvercommit returns the shared counters, such as the memory of a
+// GPU partitioned into slices, of which the allocated devices consume more
+// than the counter set provides, sorted by node, driver, pool, counter set
+// and counter. The scheduler only honours counters with the
+// DRAPartitionableDevices feature enabled; without it, partitions of the same
+// physical device can be allocated beyond its capacity.
+func (c *resourceClient) GetCounterOvercommit(ctx context.Context) ([]types.CounterOvercommit, error) {
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return counterOvercommit(resourceSlices, resourceClaims), nil
+}
+
+func counterOvercommit(resourceSlices []model.ResourceSlice, resourceClaims []model.ResourceClaim) []types.CounterOvercommit {
+	allocatedDevices := aggregate.AllocatedDevices(resourceClaims)
+	poolGenerations := aggregate.LatestPoolGenerations(resourceSlices)
+
+	available := make(map[counterKey]resource.Quantity)
+	poolNodes := make(map[aggregate.PoolKey]string)
+	consumed := make(map[counterKey]*types.CounterOvercommit)
+	seenDevices := make(map[aggregate.DeviceKey]bool)
+	for _, rs := range resourceSlices {
+		pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		if rs.Spec.Pool.Generation < poolGenerations[pool] {
+			continue
+		}
+		if rs.Spec.NodeName != "" {
+			poolNodes[pool] = rs.Spec.NodeName
+		}
+		for _, set := range rs.Spec.SharedCounters {
+			for name, counter := range set.Counters {
+				available[counterKey{PoolKey: pool, CounterSet: set.Name, Counter: name}] = counter.Value
+			}
+		}
+
+		for _, dev := range rs.Spec.Devices {
+			if seenDevices[pool.Device(dev.Name)] {
+				continue
+			}
+			seenDevices[pool.Device(dev.Name)] = true
+			allocations, ok := allocatedDevices[pool.Device(dev.Name)]
+			if !ok {
+				continue
+			}
+			for _, consumption := range dev.ConsumesCounters {
+				for name, counter := range consumption.Counters {
+					key := counterKey{PoolKey: pool, CounterSet: consumption.CounterSet, Counter: name}
+					usage, ok := consumed[key]
+					if !ok {
+						usage = &types.CounterOvercommit{
+							Driver:     pool.Driver,
+							Pool:       pool.Pool,
+							CounterSet: consumption.CounterSet,
+							Counter:    name,
+						}
+						consumed[key] = usage
+					}
+					usage.Allocated.Add(counter.Value)
+					usage.Devices = append(usage.Devices, dev.Name)
+					for _, alloc := range allocations {
+						usage.Claims = append(usage.Claims, alloc.Claim())
+					}
+				}
+			}
+		}
+	}
+
+	var result []types.CounterOvercommit
+	for key, usage := range consumed {
+		capacity, ok := available[key]
+		// devices referencing a counter set no slice publishes cannot be
+		// checked
+		if !ok || usage.Allocated.Cmp(capacity) <= 0 {
+			continue
+		}
+		usage.NodeName = poolNodes[key.PoolKey]
+		usage.Capacity = capacity
+		sort.Strings(usage.Devices)
+		sort.Strings(usage.Claims)
+		usage.Claims = slices.Compact(usage.Claims)
+		result = append(result, *usage)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.NodeName != b.NodeName {
+			return a.NodeName < b.NodeName
+		}
+		if a.Driver != b.Driver {
+			return a.Driver < b.Driver
+		}
+		if a.Pool != b.Pool {
+			return a.Pool < b.Pool
+		}
+		if a.CounterSet != b.CounterSet {
+			return a.CounterSet < b.CounterSet
+		}
+		return a.Counter < b.Counter
+	})
+	return result
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// RequestPlanner is a client that records the API requests made through it
+// instead of sending them, answering like an empty cluster. Requests that
+// depend on the objects returned by earlier ones are therefore missing from
+// the plan, e.g. the pods of a node that is not found.
+type RequestPlanner struct {
+	*resourceClient
+	// target is the cluster the recorded requests are estimated against; nil
+	// if the planned client cannot list arbitrary resources.
+	target dynamic.Interface
+
+	mu       sync.Mutex
+	requests []types.PlannedRequest
+	// resources keeps the group of every recorded request, which
+	// PlannedRequest only holds as part of the resource name.
+	resources []schema.GroupVersionResource
+}
+
+// NewRequestPlanner returns a planner for the requests commands would make
+// with target.
+func NewRequestPlanner(target ResourceClient) *RequestPlanner {
+	p := &RequestPlanner{}
+	// the planned requests use the version of resource.k8s.io the target
+	// serves rather than discovering it from the fake clientset
+	apiVersion := "v1beta1"
+	if rc, ok := target.(*resourceClient); ok {
+		p.target = rc.dynamicClient
+		apiVersion = rc.resourceAPIVersion()
+	}
+
+	typedClient := fake.NewSimpleClientset()
+	typedClient.PrependReactor("*", "*", p.react)
+	typedClient.PrependWatchReactor("*", p.reactWatch)
+
+	listKinds := map[schema.GroupVersionResource]string{
+		clusterQueueResource: "ClusterQueueList",
+		localQueueResource:   "LocalQueueList",
+		workloadResource:     "WorkloadList",
+		podGroupResource:     "PodGroupList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	dynamicClient.PrependReactor("*", "*", p.react)
+	dynamicClient.PrependWatchReactor("*", p.reactWatch)
+
+	p.resourceClient = &resourceClient{typedClient: typedClient, dynamicClient: dynamicClient, namespace: target.Namespace(), apiVersion: apiVersion}
+	return p
+}
+
+func (p *RequestPlanner) react(action k8stesting.Action) (bool, runtime.Object, error) {
+	p.record(action)
+	return false, nil, nil
+}
+
+func (p *RequestPlanner) reactWatch(action k8stesting.Action) (bool, watch.Interface, error) {
+	p.record(action)
+	return false, nil, nil
+}
+
+func (p *RequestPlanner) record(action k8stesting.Action) {
+	gvr := action.GetResource()
+	request := types.PlannedRequest{
+		Verb:      action.GetVerb(),
+		Resource:  gvr.GroupResource().String(),
+		Namespace: action.GetNamespace(),
+	}
+	switch action := action.(type) {
+	case k8stesting.ListActionImpl:
+		opts := action.GetListOptions()
+		request.LabelSelector, request.FieldSelector, request.Limit = opts.LabelSelector, opts.FieldSelector, opts.Limit
+		if request.LabelSelector == "" && action.ListRestrictions.Labels != nil && !action.ListRestrictions.Labels.Empty() {
+			request.LabelSelector = action.ListRestrictions.Labels.String()
+		}
+		if request.FieldSelector == "" && action.ListRestrictions.Fields != nil && !action.ListRestrictions.Fields.Empty() {
+			request.FieldSelector = action.ListRestrictions.Fields.String()
+		}
+	case k8stesting.WatchActionImpl:
+		restrictions := action.GetWatchRestrictions()
+		if restrictions.Labels != nil && !restrictions.Labels.Empty() {
+			request.LabelSelector = restrictions.Labels.String()
+		}
+		if restrictions.Fields != nil && !restrictions.Fields.Empty() {
+			request.FieldSelector = restrictions.Fields.String()
+		}
+	case k8stesting.GetAction:
+		request.Name = action.GetName()
+	case k8stesting.DeleteAction:
+		request.Name = action.GetName()
+	case k8stesting.PatchAction:
+		request.Name = action.GetName()
+	}
+	if sub := action.GetSubresource(); sub != "" {
+		request.Resource += "/" + sub
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, request)
+	p.resources = append(p.resources, gvr)
+}
+
+// Requests returns the recorded requests in the order they were made. Lists
+// are estimated by listing a single object of the target cluster, for which
+// the API server reports how many remain.
+func (p *RequestPlanner) Requests(ctx context.Context) ([]types.PlannedRequest, error) {
+	p.mu.Lock()
+	requests := append([]types.PlannedRequest(nil), p.requests...)
+	resources := append([]schema.GroupVersionResource(nil), p.resources...)
+	p.mu.Unlock()
+
+	for i := range requests {
+		switch requests[i].Verb {
+		case "watch":
+		case "list":
+			if p.target == nil {
+				continue
+			}
+			if err := p.estimateList(ctx, &requests[i], resources[i]); err != nil {
+				return nil, err
+			}
+		default:
+			requests[i].Objects, requests[i].Estimated = 1, true
+		}
+	}
+	return requests, nil
+}
+
+func (p *RequestPlanner) estimateList(ctx context.Context, request *types.PlannedRequest, gvr schema.GroupVersionResource) error {
+	list, err := p.target.Resource(gvr).Namespace(request.Namespace).List(ctx, metav1.ListOptions{
+		Limit:         1,
+		LabelSelector: request.LabelSelector,
+		FieldSelector: request.FieldSelector,
+	})
+	if apierrors.IsNotFound(err) {
+		// the resource is not installed
+		request.Estimated = true
+		return nil
+	}
+	if err != nil {
+		return apiError(err, "estimate listing %s", request.Resource)
+	}
+	switch {
+	case list.GetContinue() == "":
+		request.Objects, request.Estimated = int64(len(list.Items)), true
+	case list.GetRemainingItemCount() != nil:
+		request.Objects, request.Estimated = int64(len(list.Items))+*list.GetRemainingItemCount(), true
+	}
+	return nil
+}
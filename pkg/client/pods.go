@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podPageSize is the number of pods requested per list call when streaming.
+const podPageSize = 500
+
+// forEachPod lists the pods selected by opts page by page and calls fn for
+// each of them, so only one page is held in memory at a time.
+func (c *resourceClient) forEachPod(ctx context.Context, opts ListOptions, fn func(pod *corev1.Pod)) error {
+	listOpts := opts.pods()
+	listOpts.Limit = podPageSize
+
+	reportProgress(ctx, Progress{Resource: "pods"})
+	listed := 0
+	for {
+		list, err := c.typedClient.CoreV1().Pods(opts.Namespace).List(ctx, listOpts)
+		if err != nil {
+			return &APIError{Op: "list pods", Partial: listed > 0, Err: err}
+		}
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+		listed += len(list.Items)
+
+		progress := Progress{Resource: "pods", Listed: listed, Done: list.Continue == ""}
+		if list.RemainingItemCount != nil {
+			progress.Total = listed + int(*list.RemainingItemCount)
+		}
+		reportProgress(ctx, progress)
+
+		if list.Continue == "" {
+			return nil
+		}
+		listOpts.Continue = list.Continue
+	}
+}
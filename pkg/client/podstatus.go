@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// GetClaimPods returns the pods with ResourceClaims that have not finished,
+// selected by the namespace and node of opts, sorted by namespace and name.
+// Pods not bound to a node yet carry the reason they are waiting: scheduling
+// gates come first, then claims that failed to be generated from their
+// template, as reported by the resourceclaim controller's events, then
+// missing claims, then unallocated claims, told apart by whether any node has
+// enough available devices for them.
+func (c *resourceClient) GetClaimPods(ctx context.Context, opts ListOptions) ([]types.PodInfo, error) {
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{Namespace: opts.Namespace})
+	if err != nil {
+		return nil, err
+	}
+	claims := make(map[string]*model.ResourceClaim, len(resourceClaims))
+	for i := range resourceClaims {
+		claims[resourceClaims[i].Namespace+"/"+resourceClaims[i].Name] = &resourceClaims[i]
+	}
+
+	var pods []types.PodInfo
+	unallocated := make(map[int][]*model.ResourceClaim) // index into pods -> claims
+	generating := make(map[int]k8stypes.UID)            // index into pods -> pod UID
+	err = c.forEachPod(ctx, opts, func(pod *corev1.Pod) {
+		if len(pod.Spec.ResourceClaims) == 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return
+		}
+		info := types.PodInfo{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			NodeName:  pod.Spec.NodeName,
+			Phase:     string(pod.Status.Phase),
+			PodGroup:  pod.Annotations[PodGroupAnnotation],
+		}
+		var missing, sharedWith []string
+		var pending []*model.ResourceClaim
+		ungenerated := false
+		for _, entry := range pod.Spec.ResourceClaims {
+			name, notGenerated := podClaimName(pod, entry)
+			if notGenerated {
+				missing = append(missing, entry.Name+" (not generated yet)")
+				ungenerated = true
+				continue
+			}
+			if name == "" {
+				continue
+			}
+			info.Claims = append(info.Claims, name)
+			rc, ok := claims[pod.Namespace+"/"+name]
+			switch {
+			case !ok:
+				missing = append(missing, name)
+			case rc.Status.Allocation == nil:
+				pending = append(pending, rc)
+			}
+			if ok && len(rc.Status.ReservedFor) > 1 {
+				sharedWith = append(sharedWith, consumerNames(rc)...)
+			}
+		}
+		info.SharedWith = otherConsumers(pod.Name, sharedWith)
+
+		if pod.Spec.NodeName == "" {
+			switch {
+			case len(pod.Spec.SchedulingGates) > 0:
+				info.Reason = types.PodSchedulingGated
+				for _, gate := range pod.Spec.SchedulingGates {
+					info.Details = append(info.Details, gate.Name)
+				}
+			case len(missing) > 0:
+				info.Reason, info.Details = types.PodClaimMissing, missing
+				if ungenerated {
+					generating[len(pods)] = pod.UID
+				}
+			case len(pending) > 0:
+				unallocated[len(pods)] = pending
+			}
+		}
+		pods = append(pods, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(generating) > 0 {
+		failures, err := c.claimGenerationFailures(ctx, opts.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i, uid := range generating {
+			if message, ok := failures[uid]; ok {
+				pods[i].Reason, pods[i].Details = types.PodClaimGenerationFailed, []string{message}
+			}
+		}
+	}
+
+	if len(unallocated) > 0 {
+		if opts.Namespace != "" {
+			// availability depends on the allocations of all namespaces
+			if resourceClaims, err = c.getResourceClaims(ctx, ListOptions{}); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.explainUnallocated(ctx, pods, unallocated, resourceClaims); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// otherConsumers returns the consumers other than the pod, sorted and each
+// once.
+func otherConsumers(podName string, consumers []string) []string {
+	var others []string
+	seen := map[string]bool{podName: true}
+	for _, consumer := range consumers {
+		if !seen[consumer] {
+			seen[consumer] = true
+			others = append(others, consumer)
+		}
+	}
+	sort.Strings(others)
+	return others
+}
+
+// explainUnallocated sets the reason of pods waiting for unallocated claims,
+// depending on whether any node has enough available devices for them.
+func (c *resourceClient) explainUnallocated(ctx context.Context, pods []types.PodInfo, unallocated map[int][]*model.ResourceClaim, resourceClaims []model.ResourceClaim) error {
+	m := newSelectorMatcher()
+	classes, err := c.compileDeviceClasses(ctx, m)
+	if err != nil {
+		return err
+	}
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return err
+	}
+	devices, err := simulatedDevices(ctx, resourceSlices, aggregate.AllocatedDevices(resourceClaims))
+	if err != nil {
+		return err
+	}
+
+	satisfiable := make(map[*model.ResourceClaim]bool)
+	for i, claims := range unallocated {
+		pods[i].Reason = types.PodClaimUnallocated
+		var insufficient []string
+		for _, rc := range claims {
+			ok, found := satisfiable[rc]
+			if !found {
+				// a claim with an invalid selector can never be allocated
+				ok, _ = m.satisfiable(ctx, &rc.Spec, classes, devices)
+				satisfiable[rc] = ok
+			}
+			if !ok {
+				insufficient = append(insufficient, rc.Name)
+			}
+			pods[i].Details = append(pods[i].Details, rc.Name)
+		}
+		if len(insufficient) > 0 {
+			pods[i].Reason, pods[i].Details = types.PodInsufficientDevices, insufficient
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package client
+
+import "context"
+
+// Progress describes how far listing a resource has come.
+type Progress struct {
+	// Resource is the plural name of the resource being listed, e.g. "pods".
+	Resource string
+	// Listed is the number of objects received so far.
+	Listed int
+	// Total is the number of objects to list, or 0 if the API server did not
+	// report it.
+	Total int
+	// Done is set once the resource has been listed completely.
+	Done bool
+}
+
+// ProgressFunc receives progress updates while the client lists resources.
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress returns a context that reports the progress of list calls made
+// with it to fn: before each list, after each page and once it is done.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func reportProgress(ctx context.Context, p Progress) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(p)
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+)
+
+var (
+	// capacitySelector matches CEL selectors comparing a capacity with a
+	// quantity, e.g. device.capacity["gpu.example.com"].memory.compareTo(quantity("40Gi")) >= 0.
+	capacitySelector = regexp.MustCompile(`^device\.capacity\["[^"]*"\]\.([\w-]+)\.compareTo\(quantity\("([^"]+)"\)\)\s*(>=|>|<=|<|==|!=)\s*0$`)
+	// attributeSelector matches CEL selectors comparing an attribute, e.g.
+	// device.attributes["gpu.example.com"].model == "A100".
+	attributeSelector = regexp.MustCompile(`^device\.attributes\["[^"]*"\]\.([\w-]+)\s*(==|!=|>=|>|<=|<)\s*(.+)$`)
+	// driverSelector matches CEL selectors on the driver, e.g.
+	// device.driver == "gpu.example.com".
+	driverSelector = regexp.MustCompile(`^device\.driver\s*==\s*"([^"]+)"$`)
+)
+
+// requestSummaries renders each request of the claim spec concisely, e.g.
+// 2x gpu.example.com[memory>=40Gi]. Requests with alternatives list them
+// in order of preference, separated by |.
+func requestSummaries(spec *model.ResourceClaimSpec) []string {
+	var summaries []string
+	for _, req := range spec.Devices.Requests {
+		if len(req.FirstAvailable) == 0 {
+			summaries = append(summaries, requestSummary(req.DeviceClassName, req.AllocationMode, req.Count, req.Selectors))
+			continue
+		}
+		alternatives := make([]string, len(req.FirstAvailable))
+		for i, sub := range req.FirstAvailable {
+			alternatives[i] = requestSummary(sub.DeviceClassName, sub.AllocationMode, sub.Count, sub.Selectors)
+		}
+		summaries = append(summaries, strings.Join(alternatives, "|"))
+	}
+	return summaries
+}
+
+func requestSummary(className string, mode model.DeviceAllocationMode, count int64, selectors []model.DeviceSelector) string {
+	amount := fmt.Sprintf("%dx", max(count, 1))
+	if mode == model.DeviceAllocationModeAll {
+		amount = "all"
+	}
+	summary := amount + " " + className
+	var conditions []string
+	for _, selector := range selectors {
+		if selector.CEL != nil {
+			conditions = append(conditions, summarizeSelector(selector.CEL.Expression))
+		}
+	}
+	if len(conditions) > 0 {
+		summary += "[" + strings.Join(conditions, ",") + "]"
+	}
+	return summary
+}
+
+// summarizeSelector shortens the common forms of CEL selectors to
+// name-operator-value, leaving other expressions as they are apart from
+// whitespace.
+func summarizeSelector(expression string) string {
+	expression = strings.Join(strings.Fields(expression), " ")
+	if m := capacitySelector.FindStringSubmatch(expression); m != nil {
+		return m[1] + m[3] + m[2]
+	}
+	if m := attributeSelector.FindStringSubmatch(expression); m != nil && !strings.ContainsAny(m[3], "&|") {
+		return m[1] + m[2] + m[3]
+	}
+	if m := driverSelector.FindStringSubmatch(expression); m != nil {
+		return "driver==" + m[1]
+	}
+	return expression
+}
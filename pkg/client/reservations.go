@@ -0,0 +1,39 @@
+package client
+
+import (
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Annotations of the reservation convention: a claim reserving devices for a
+// team records the team, the purpose and when the reservation expires, as an
+// RFC 3339 time or a date (midnight UTC).
+const (
+	ReservationTeamAnnotation    = "reservation.dra-resources.dharmjit.github.io/team"
+	ReservationPurposeAnnotation = "reservation.dra-resources.dharmjit.github.io/purpose"
+	ReservationExpiresAnnotation = "reservation.dra-resources.dharmjit.github.io/expires"
+)
+
+// reservation returns the reservation recorded in the annotations, or nil
+// if there is none.
+func reservation(annotations map[string]string) *types.Reservation {
+	team, hasTeam := annotations[ReservationTeamAnnotation]
+	purpose, hasPurpose := annotations[ReservationPurposeAnnotation]
+	expires, hasExpires := annotations[ReservationExpiresAnnotation]
+	if !hasTeam && !hasPurpose && !hasExpires {
+		return nil
+	}
+
+	r := &types.Reservation{Team: team, Purpose: purpose}
+	if hasExpires {
+		if t, err := time.Parse(time.RFC3339, expires); err == nil {
+			r.Expires = t
+		} else if t, err := time.Parse(time.DateOnly, expires); err == nil {
+			r.Expires = t
+		} else {
+			r.InvalidExpiry = expires
+		}
+	}
+	return r
+}
@@ -0,0 +1,329 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	dracel "k8s.io/dynamic-resource-allocation/cel"
+)
+
+// SimulateClassChange compares the devices selected by the DeviceClass of the
+// same name as class with those class would select, and finds the pending
+// claims that class would make allocatable. Selectors are evaluated the way
+// the scheduler does.
+//
+// A claim counts as allocatable if a single node has enough available devices
+// for each of its requests. Constraints, and requests competing for the same
+// devices, are not taken into account, so the result is an estimate.
+func (c *resourceClient) SimulateClassChange(ctx context.Context, class *model.DeviceClass) (*types.ClassChange, error) {
+	m := newSelectorMatcher()
+	current, err := c.compileDeviceClasses(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	proposed := make(map[string][]dracel.CompilationResult, len(current)+1)
+	for name, selectors := range current {
+		proposed[name] = selectors
+	}
+	if proposed[class.Name], err = m.compile(class.Spec.Selectors); err != nil {
+		return nil, fmt.Errorf("new DeviceClass %s: %w", class.Name, err)
+	}
+
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	devices, err := simulatedDevices(ctx, resourceSlices, aggregate.AllocatedDevices(resourceClaims))
+	if err != nil {
+		return nil, err
+	}
+
+	change := &types.ClassChange{
+		Class:       class.Name,
+		Unmatched:   []types.DeviceInfo{},
+		Matched:     []types.DeviceInfo{},
+		Satisfiable: []string{},
+	}
+	for _, dev := range devices {
+		before := m.matches(ctx, current[class.Name], dev)
+		after := m.matches(ctx, proposed[class.Name], dev)
+		switch {
+		case before && !after:
+			change.Unmatched = append(change.Unmatched, dev.info)
+		case !before && after:
+			change.Matched = append(change.Matched, dev.info)
+		}
+	}
+
+	for _, rc := range resourceClaims {
+		if rc.Status.Allocation != nil || len(classRequests(&rc.Spec, class.Name)) == 0 {
+			continue
+		}
+		before, err := m.satisfiable(ctx, &rc.Spec, current, devices)
+		if err != nil {
+			return nil, fmt.Errorf("ResourceClaim %s/%s: %w", rc.Namespace, rc.Name, err)
+		}
+		after, err := m.satisfiable(ctx, &rc.Spec, proposed, devices)
+		if err != nil {
+			return nil, fmt.Errorf("ResourceClaim %s/%s: %w", rc.Namespace, rc.Name, err)
+		}
+		if !before && after {
+			change.Satisfiable = append(change.Satisfiable, rc.Namespace+"/"+rc.Name)
+		}
+	}
+	sort.Strings(change.Satisfiable)
+	return change, nil
+}
+
+// compileDeviceClasses returns the compiled selectors of every DeviceClass,
+// keyed by class name.
+func (c *resourceClient) compileDeviceClasses(ctx context.Context, m *selectorMatcher) (map[string][]dracel.CompilationResult, error) {
+	deviceClasses, err := c.listDeviceClasses(ctx)
+	if err != nil {
+		return nil, apiError(err, "list DeviceClasses")
+	}
+	classes := make(map[string][]dracel.CompilationResult, len(deviceClasses))
+	for _, dc := range deviceClasses {
+		selectors, err := m.compile(dc.Spec.Selectors)
+		if err != nil {
+			return nil, fmt.Errorf("DeviceClass %s: %w", dc.Name, err)
+		}
+		classes[dc.Name] = selectors
+	}
+	return classes, nil
+}
+
+// classDeviceCounts returns the number of devices each of the classes selects
+// in the cluster, and how many of them are available. Classes that do not
+// exist select no device.
+func (c *resourceClient) classDeviceCounts(ctx context.Context, classes map[string]bool, resourceClaims []model.ResourceClaim) (map[string]types.DeviceCount, error) {
+	m := newSelectorMatcher()
+	selectors, err := c.compileDeviceClasses(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	devices, err := simulatedDevices(ctx, resourceSlices, aggregate.AllocatedDevices(resourceClaims))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]types.DeviceCount, len(classes))
+	for class := range classes {
+		var count types.DeviceCount
+		if classSelectors, ok := selectors[class]; ok {
+			for _, dev := range devices {
+				if m.matches(ctx, classSelectors, dev) {
+					count.TotalCount++
+					if dev.available {
+						count.AvailableCount++
+					}
+				}
+			}
+		}
+		counts[class] = count
+	}
+	return counts, nil
+}
+
+// simulatedDevice is a device of the latest generation of its pool, as seen
+// by CEL selectors.
+type simulatedDevice struct {
+	info      types.DeviceInfo
+	device    dracel.Device
+	available bool
+}
+
+func simulatedDevices(ctx context.Context, resourceSlices []model.ResourceSlice, allocatedDevices map[aggregate.DeviceKey][]aggregate.Allocation) ([]simulatedDevice, error) {
+	poolGenerations := aggregate.LatestPoolGenerations(resourceSlices)
+	sliceDecorations, err := aggregate.DecorateSlices(ctx, resourceSlices)
+	if err != nil {
+		return nil, err
+	}
+	var devices []simulatedDevice
+	seenDevices := make(map[aggregate.DeviceKey]bool)
+	for si, rs := range resourceSlices {
+		pool := aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}
+		if rs.Spec.Pool.Generation < poolGenerations[pool] {
+			continue
+		}
+		decorations := sliceDecorations[si]
+		for i, dev := range rs.Spec.Devices {
+			if seenDevices[pool.Device(dev.Name)] {
+				continue
+			}
+			seenDevices[pool.Device(dev.Name)] = true
+
+			sim := simulatedDevice{
+				info: types.DeviceInfo{
+					NodeName:    rs.Spec.NodeName,
+					Driver:      rs.Spec.Driver,
+					Pool:        rs.Spec.Pool.Name,
+					Name:        dev.Name,
+					ProductName: decorations[i].ProductName,
+				},
+				device: dracel.Device{
+					Driver:     rs.Spec.Driver,
+					Attributes: model.ToV1Attributes(dev.Attributes),
+					Capacity:   model.ToV1Capacity(dev.Capacity),
+				},
+				available: true,
+			}
+			for i, alloc := range allocatedDevices[pool.Device(dev.Name)] {
+				if i == 0 {
+					sim.info.Claim, sim.info.Request = alloc.Claim(), alloc.Request
+				} else {
+					sim.info.SharedClaims = append(sim.info.SharedClaims, alloc.Claim())
+				}
+				sim.available = false
+			}
+			devices = append(devices, sim)
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		a, b := devices[i].info, devices[j].info
+		if a.NodeName != b.NodeName {
+			return a.NodeName < b.NodeName
+		}
+		if a.Driver != b.Driver {
+			return a.Driver < b.Driver
+		}
+		if a.Pool != b.Pool {
+			return a.Pool < b.Pool
+		}
+		return a.Name < b.Name
+	})
+	return devices, nil
+}
+
+// selectorMatcher evaluates CEL device selectors, compiling each expression
+// once.
+type selectorMatcher struct {
+	compiled map[string]dracel.CompilationResult
+}
+
+func newSelectorMatcher() *selectorMatcher {
+	return &selectorMatcher{compiled: make(map[string]dracel.CompilationResult)}
+}
+
+func (m *selectorMatcher) compile(selectors []model.DeviceSelector) ([]dracel.CompilationResult, error) {
+	var results []dracel.CompilationResult
+	for _, selector := range selectors {
+		if selector.CEL == nil {
+			continue
+		}
+		result, ok := m.compiled[selector.CEL.Expression]
+		if !ok {
+			result = dracel.GetCompiler(dracel.Features{}).CompileCELExpression(selector.CEL.Expression, dracel.Options{})
+			m.compiled[selector.CEL.Expression] = result
+		}
+		if result.Error != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", selector.CEL.Expression, result.Error)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// matches reports whether the device satisfies all selectors. Like the
+// scheduler, a selector failing to evaluate does not match.
+func (m *selectorMatcher) matches(ctx context.Context, selectors []dracel.CompilationResult, dev simulatedDevice) bool {
+	for _, selector := range selectors {
+		matches, _, err := selector.DeviceMatches(ctx, dev.device)
+		if err != nil || !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// simulatedRequest is a request or subrequest of a claim.
+type simulatedRequest struct {
+	className string
+	selectors []model.DeviceSelector
+	mode      model.DeviceAllocationMode
+	count     int64
+}
+
+// satisfiable reports whether a single node has enough available devices for
+// every request of the claim spec, given the selectors of each DeviceClass.
+// Devices not attached to a node are usable from any node.
+func (m *selectorMatcher) satisfiable(ctx context.Context, spec *model.ResourceClaimSpec, classes map[string][]dracel.CompilationResult, devices []simulatedDevice) (bool, error) {
+	nodes := map[string]bool{"": true}
+	for _, dev := range devices {
+		nodes[dev.info.NodeName] = true
+	}
+	for node := range nodes {
+		ok, err := m.satisfiableOnNode(ctx, spec, classes, devices, node)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (m *selectorMatcher) satisfiableOnNode(ctx context.Context, spec *model.ResourceClaimSpec, classes map[string][]dracel.CompilationResult, devices []simulatedDevice, node string) (bool, error) {
+	for _, req := range spec.Devices.Requests {
+		alternatives := []simulatedRequest{{req.DeviceClassName, req.Selectors, req.AllocationMode, req.Count}}
+		if len(req.FirstAvailable) > 0 {
+			alternatives = alternatives[:0]
+			for _, sub := range req.FirstAvailable {
+				alternatives = append(alternatives, simulatedRequest{sub.DeviceClassName, sub.Selectors, sub.AllocationMode, sub.Count})
+			}
+		}
+		ok := false
+		for _, alt := range alternatives {
+			var err error
+			if ok, err = m.requestSatisfiable(ctx, alt, classes, devices, node); err != nil {
+				return false, err
+			}
+			if ok {
+				break
+			}
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *selectorMatcher) requestSatisfiable(ctx context.Context, req simulatedRequest, classes map[string][]dracel.CompilationResult, devices []simulatedDevice, node string) (bool, error) {
+	classSelectors, ok := classes[req.className]
+	if !ok {
+		return false, nil
+	}
+	selectors, err := m.compile(req.selectors)
+	if err != nil {
+		return false, err
+	}
+
+	matching, available := int64(0), int64(0)
+	for _, dev := range devices {
+		if dev.info.NodeName != "" && dev.info.NodeName != node {
+			continue
+		}
+		if !m.matches(ctx, classSelectors, dev) || !m.matches(ctx, selectors, dev) {
+			continue
+		}
+		matching++
+		if dev.available {
+			available++
+		}
+	}
+	if req.mode == model.DeviceAllocationModeAll {
+		return matching > 0 && available == matching, nil
+	}
+	return available >= max(req.count, 1), nil
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetSpotRisk returns the allocated claims holding devices on spot or
+// preemptible nodes, with the pods consuming them and the workloads owning
+// those pods, sorted by namespace and name.
+func (c *resourceClient) GetSpotRisk(ctx context.Context) ([]types.SpotRisk, error) {
+	nodes, err := c.getNodes(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	spotNodes := make(map[string]bool)
+	for _, node := range nodes {
+		if aggregate.IsSpot(node.Labels) {
+			spotNodes[node.Name] = true
+		}
+	}
+	if len(spotNodes) == 0 {
+		return nil, nil
+	}
+
+	resourceSlices, err := c.getResourceSlices(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	poolNodes := make(map[aggregate.PoolKey]string)
+	for _, rs := range resourceSlices {
+		if rs.Spec.NodeName != "" {
+			poolNodes[aggregate.PoolKey{Driver: rs.Spec.Driver, Pool: rs.Spec.Pool.Name}] = rs.Spec.NodeName
+		}
+	}
+
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	risks := make(map[string]*types.SpotRisk)       // namespace/name -> risk
+	consumers := make(map[string][]*types.SpotRisk) // namespace/pod -> risks
+	for _, rc := range resourceClaims {
+		if rc.Status.Allocation == nil {
+			continue
+		}
+		var risk *types.SpotRisk
+		for _, result := range rc.Status.Allocation.Devices.Results {
+			nodeName := poolNodes[aggregate.PoolKey{Driver: result.Driver, Pool: result.Pool}]
+			if !spotNodes[nodeName] {
+				continue
+			}
+			if risk == nil {
+				risk = &types.SpotRisk{Namespace: rc.Namespace, Name: rc.Name}
+				risks[rc.Namespace+"/"+rc.Name] = risk
+			}
+			risk.Devices = append(risk.Devices, result.Driver+"/"+result.Pool+"/"+result.Device)
+			risk.Nodes = appendUnique(risk.Nodes, nodeName)
+		}
+		if risk == nil {
+			continue
+		}
+		for _, consumer := range rc.Status.ReservedFor {
+			if consumer.Resource == "pods" {
+				risk.Pods = append(risk.Pods, consumer.Name)
+				consumers[rc.Namespace+"/"+consumer.Name] = append(consumers[rc.Namespace+"/"+consumer.Name], risk)
+			}
+		}
+	}
+	if len(risks) == 0 {
+		return nil, nil
+	}
+
+	pods, err := c.getPods(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		owner := podOwner(&pod)
+		if owner == "" {
+			continue
+		}
+		for _, risk := range consumers[pod.Namespace+"/"+pod.Name] {
+			risk.Owners = appendUnique(risk.Owners, owner)
+		}
+	}
+
+	result := make([]types.SpotRisk, 0, len(risks))
+	for _, risk := range risks {
+		sort.Strings(risk.Pods)
+		sort.Strings(risk.Owners)
+		result = append(result, *risk)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// jobSetNameLabel names the JobSet of the pods of its Jobs.
+const jobSetNameLabel = "jobset.sigs.k8s.io/jobset-name"
+
+// podOwner returns the workload controlling the pod as Kind/name. Pods of a
+// ReplicaSet are attributed to its Deployment, whose name is the ReplicaSet
+// name without the pod template hash, and pods of the Jobs of a JobSet to the
+// JobSet. Training operator jobs such as PyTorchJob and TFJob control their
+// pods directly.
+func podOwner(pod *corev1.Pod) string {
+	if name := pod.Labels[jobSetNameLabel]; name != "" {
+		return "JobSet/" + name
+	}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if hash := pod.Labels["pod-template-hash"]; ref.Kind == "ReplicaSet" && hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+			return "Deployment/" + strings.TrimSuffix(ref.Name, "-"+hash)
+		}
+		return ref.Kind + "/" + ref.Name
+	}
+	return ""
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetClaimTemplateStats returns, for every ResourceClaimTemplate, how many of
+// the claims generated from it are allocated or pending, and which of the
+// DeviceClasses it references are missing. Generated claims do not record
+// their template, so they are traced through the pods: a pod's claim entry
+// names the template and its status names the claim created for it.
+func (c *resourceClient) GetClaimTemplateStats(ctx context.Context) ([]types.ClaimTemplateStats, error) {
+	templates, err := c.listResourceClaimTemplates(ctx, metav1.NamespaceAll)
+	if err != nil {
+		return nil, apiError(err, "list ResourceClaimTemplates")
+	}
+
+	classes, err := c.listDeviceClasses(ctx)
+	if err != nil {
+		return nil, apiError(err, "list DeviceClasses")
+	}
+	classNames := make(map[string]bool)
+	for _, class := range classes {
+		classNames[class.Name] = true
+	}
+
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	allocated := make(map[string]bool) // namespace/name -> allocated
+	for _, rc := range resourceClaims {
+		allocated[rc.Namespace+"/"+rc.Name] = rc.Status.Allocation != nil
+	}
+
+	stats := make(map[string]*types.ClaimTemplateStats) // namespace/name -> stats
+	for _, tmpl := range templates {
+		stats[tmpl.Namespace+"/"+tmpl.Name] = &types.ClaimTemplateStats{
+			Namespace:            tmpl.Namespace,
+			Name:                 tmpl.Name,
+			MissingDeviceClasses: missingDeviceClasses(&tmpl.Spec.Spec, classNames),
+		}
+	}
+
+	err = c.forEachPod(ctx, ListOptions{}, func(pod *corev1.Pod) {
+		for _, claim := range pod.Spec.ResourceClaims {
+			if claim.ResourceClaimTemplateName == nil {
+				continue
+			}
+			s, ok := stats[pod.Namespace+"/"+*claim.ResourceClaimTemplateName]
+			if !ok {
+				continue
+			}
+			claimName, _ := podClaimName(pod, claim)
+			if claimName == "" {
+				continue
+			}
+			isAllocated, exists := allocated[pod.Namespace+"/"+claimName]
+			if !exists {
+				continue
+			}
+			s.Claims++
+			if isAllocated {
+				s.Allocated++
+			} else {
+				s.Pending++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.ClaimTemplateStats, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// podClaimName returns the name of the claim of the pod's claim entry: the
+// claim it names, or the claim generated from its template as recorded in
+// the pod's resourceClaimStatuses, the only reliable source since generated
+// names carry a random suffix. pending is set for template entries the
+// resourceclaim controller has not generated a claim for yet. Entries it
+// recorded without a claim need none, and their name is "".
+func podClaimName(pod *corev1.Pod, entry corev1.PodResourceClaim) (name string, pending bool) {
+	if entry.ResourceClaimName != nil {
+		return *entry.ResourceClaimName, false
+	}
+	for _, status := range pod.Status.ResourceClaimStatuses {
+		if status.Name == entry.Name {
+			if status.ResourceClaimName == nil {
+				return "", false
+			}
+			return *status.ResourceClaimName, false
+		}
+	}
+	return "", entry.ResourceClaimTemplateName != nil
+}
+
+// missingDeviceClasses returns the DeviceClasses referenced by the claim spec,
+// including by its subrequests, that are not in classNames.
+func missingDeviceClasses(spec *model.ResourceClaimSpec, classNames map[string]bool) []string {
+	missing := make(map[string]bool)
+	for _, req := range spec.Devices.Requests {
+		if req.DeviceClassName != "" && !classNames[req.DeviceClassName] {
+			missing[req.DeviceClassName] = true
+		}
+		for _, sub := range req.FirstAvailable {
+			if sub.DeviceClassName != "" && !classNames[sub.DeviceClassName] {
+				missing[sub.DeviceClassName] = true
+			}
+		}
+	}
+
+	var names []string
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
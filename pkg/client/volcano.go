@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ErrVolcanoNotInstalled is returned when the cluster has no Volcano CRDs.
+var ErrVolcanoNotInstalled = errors.New("volcano is not installed in the cluster")
+
+// PodGroupAnnotation names the Volcano PodGroup a pod belongs to.
+const PodGroupAnnotation = "scheduling.k8s.io/group-name"
+
+var podGroupResource = schema.GroupVersionResource{Group: "scheduling.volcano.sh", Version: "v1beta1", Resource: "podgroups"}
+
+// volcanoPodGroup holds the fields of a Volcano PodGroup needed to describe
+// its gang.
+type volcanoPodGroup struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		MinMember int32 `json:"minMember"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+// GetPodGroups returns the Volcano PodGroups with pods consuming
+// ResourceClaims, of the namespace of opts or of all namespaces when it is
+// empty, sorted by namespace and name.
+//
+// A gang is only scheduled once enough of its pods fit, so its waiting pods
+// are described together: the devices their unallocated claims request, and
+// how many devices of the requested classes are available in the cluster.
+// Claims shared by several pods count once; claims not generated from their
+// template yet count the devices of the template.
+func (c *resourceClient) GetPodGroups(ctx context.Context, opts ListOptions) ([]types.PodGroupInfo, error) {
+	if c.dynamicClient == nil {
+		return nil, ErrVolcanoNotInstalled
+	}
+	podGroups, err := listCustomResources[volcanoPodGroup](ctx, c.dynamicClient, podGroupResource, opts.Namespace, ErrVolcanoNotInstalled)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string]*types.PodGroupInfo)
+	for _, pg := range podGroups {
+		groups[pg.Namespace+"/"+pg.Name] = &types.PodGroupInfo{
+			Namespace: pg.Namespace,
+			Name:      pg.Name,
+			Phase:     pg.Status.Phase,
+			MinMember: pg.Spec.MinMember,
+		}
+	}
+	if len(groups) == 0 {
+		return []types.PodGroupInfo{}, nil
+	}
+
+	// availability depends on the allocations of all namespaces
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	claims := make(map[string]*model.ResourceClaim, len(resourceClaims))
+	for i := range resourceClaims {
+		claims[resourceClaims[i].Namespace+"/"+resourceClaims[i].Name] = &resourceClaims[i]
+	}
+	templates, err := c.listResourceClaimTemplates(ctx, opts.Namespace)
+	if err != nil {
+		return nil, apiError(err, "list ResourceClaimTemplates")
+	}
+	templateSpecs := make(map[string]*model.ResourceClaimSpec)
+	for i := range templates {
+		templateSpecs[templates[i].Namespace+"/"+templates[i].Name] = &templates[i].Spec.Spec
+	}
+
+	requested := make(map[string]map[string]int) // group -> class -> devices
+	counted := make(map[string]bool)             // claims already counted
+	err = c.forEachPod(ctx, ListOptions{Namespace: opts.Namespace}, func(pod *corev1.Pod) {
+		key := pod.Namespace + "/" + pod.Annotations[PodGroupAnnotation]
+		group, ok := groups[key]
+		if !ok || len(pod.Spec.ResourceClaims) == 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return
+		}
+		group.Members++
+		if pod.Spec.NodeName != "" {
+			return
+		}
+		group.Waiting = append(group.Waiting, pod.Name)
+
+		if requested[key] == nil {
+			requested[key] = make(map[string]int)
+		}
+		for _, entry := range pod.Spec.ResourceClaims {
+			var spec *model.ResourceClaimSpec
+			name, pending := podClaimName(pod, entry)
+			if rc, ok := claims[pod.Namespace+"/"+name]; ok {
+				if rc.Status.Allocation != nil || counted[pod.Namespace+"/"+name] {
+					continue
+				}
+				counted[pod.Namespace+"/"+name] = true
+				spec = &rc.Spec
+			} else if pending {
+				spec = templateSpecs[pod.Namespace+"/"+*entry.ResourceClaimTemplateName]
+			}
+			if spec == nil {
+				continue
+			}
+			for class, count := range requestedDevices(spec) {
+				requested[key][class] += count
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make(map[string]bool)
+	for _, byClass := range requested {
+		for class := range byClass {
+			classes[class] = true
+		}
+	}
+	counts, err := c.classDeviceCounts(ctx, classes, resourceClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.PodGroupInfo, 0, len(groups))
+	for key, group := range groups {
+		if group.Members == 0 {
+			continue
+		}
+		for class, count := range requested[key] {
+			group.Devices += count
+			group.Available += counts[class].AvailableCount
+		}
+		sort.Strings(group.Waiting)
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
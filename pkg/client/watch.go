@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// watchFunc adapts a typed client's Watch method to cache.WatcherWithContext.
+type watchFunc func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+
+func (f watchFunc) WatchWithContext(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+	return f(ctx, options)
+}
+
+// watchedResource describes one resource kind Watch keeps track of.
+type watchedResource struct {
+	name string
+	// resourceVersion lists a single object to obtain a current resourceVersion
+	// to start watching from.
+	resourceVersion func(ctx context.Context) (string, error)
+	watch           watchFunc
+}
+
+// Watch blocks until ctx is done, calling onChange whenever nodes, pods,
+// ResourceSlices or ResourceClaims change. Watches request bookmarks so the
+// last seen resourceVersion keeps advancing even while nothing changes, and
+// resume from it after disconnects. Only when the server no longer has that
+// version is the resource listed again. onChange may be called concurrently.
+func (c *resourceClient) Watch(ctx context.Context, onChange func()) error {
+	single := metav1.ListOptions{Limit: 1}
+	resources := []watchedResource{
+		{
+			name: "nodes",
+			resourceVersion: func(ctx context.Context) (string, error) {
+				list, err := c.typedClient.CoreV1().Nodes().List(ctx, single)
+				if err != nil {
+					return "", err
+				}
+				return list.ResourceVersion, nil
+			},
+			watch: c.typedClient.CoreV1().Nodes().Watch,
+		},
+		{
+			name: "pods",
+			resourceVersion: func(ctx context.Context) (string, error) {
+				list, err := c.typedClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, single)
+				if err != nil {
+					return "", err
+				}
+				return list.ResourceVersion, nil
+			},
+			watch: c.typedClient.CoreV1().Pods(metav1.NamespaceAll).Watch,
+		},
+		c.resourceSlicesWatched(),
+		c.resourceClaimsWatched(),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(resources))
+	onEvent := func(watch.Event) { onChange() }
+	for _, r := range resources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := watchResource(ctx, r, onEvent, onChange); err != nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// WatchSliceUpdates blocks until ctx is done, calling onUpdate with the driver
+// of every ResourceSlice added, modified or deleted. Slices changed while the
+// watch is re-established after compaction are not reported.
+func (c *resourceClient) WatchSliceUpdates(ctx context.Context, onUpdate func(driver string)) error {
+	onEvent := func(event watch.Event) {
+		if driver, ok := sliceDriver(event); ok {
+			onUpdate(driver)
+		}
+	}
+	return watchResource(ctx, c.resourceSlicesWatched(), onEvent, func() {})
+}
+
+// watchResource calls onEvent for every added, modified or deleted object of
+// r, and onResync after the watch had to be re-established from a new list.
+func watchResource(ctx context.Context, r watchedResource, onEvent func(watch.Event), onResync func()) error {
+	for {
+		resourceVersion, err := r.resourceVersion(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return apiError(err, "list %s", r.name)
+		}
+
+		// The retry watcher resumes from the last resourceVersion it saw,
+		// including bookmarks, whenever the connection drops. It stops with
+		// an error event once that version has been compacted away.
+		rw, err := watchtools.NewRetryWatcherWithContext(ctx, resourceVersion, r.watch)
+		if err != nil {
+			return apiError(err, "watch %s", r.name)
+		}
+		for event := range rw.ResultChan() {
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				onEvent(event)
+			}
+		}
+		rw.Stop()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		// changes may have been missed until the new list
+		onResync()
+	}
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Argo Workflows label the pods of a workflow with its name and annotate
+// them with the name of their node, the step or task they run.
+const (
+	argoWorkflowLabel      = "workflows.argoproj.io/workflow"
+	argoNodeNameAnnotation = "workflows.argoproj.io/node-name"
+)
+
+// GetWorkloads rolls the device usage of the pods consuming ResourceClaims up
+// to the workloads owning them, of the namespace of opts or of all namespaces
+// when it is empty. Pods of a JobSet count towards the JobSet, and pods
+// without an owner are workloads of their own. The usage of Argo Workflows is
+// also broken down by step. The result is sorted by devices held, then by
+// namespace and workload, and steps by devices held and name.
+func (c *resourceClient) GetWorkloads(ctx context.Context, opts ListOptions) ([]types.WorkloadUsage, error) {
+	resourceClaims, err := c.getResourceClaims(ctx, ListOptions{Namespace: opts.Namespace})
+	if err != nil {
+		return nil, err
+	}
+	claims := make(map[string]*model.ResourceClaim, len(resourceClaims))
+	for i := range resourceClaims {
+		claims[resourceClaims[i].Namespace+"/"+resourceClaims[i].Name] = &resourceClaims[i]
+	}
+
+	workloads := make(map[string]*usageCounter)
+	err = c.forEachPod(ctx, opts, func(pod *corev1.Pod) {
+		if len(pod.Spec.ResourceClaims) == 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return
+		}
+		owner := podOwner(pod)
+		if owner == "" {
+			owner = "Pod/" + pod.Name
+		}
+		key := pod.Namespace + "/" + owner
+		w, ok := workloads[key]
+		if !ok {
+			w = newUsageCounter()
+			w.workload = types.WorkloadUsage{Namespace: pod.Namespace, Workload: owner}
+			workloads[key] = w
+		}
+		w.add(pod, claims)
+
+		if step := argoStep(pod); step != "" {
+			s, ok := w.steps[step]
+			if !ok {
+				s = newUsageCounter()
+				w.steps[step] = s
+			}
+			s.add(pod, claims)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.WorkloadUsage, 0, len(workloads))
+	for _, w := range workloads {
+		usage := w.workload
+		usage.DeviceUsage = w.usage
+		for name, s := range w.steps {
+			usage.Steps = append(usage.Steps, types.WorkloadStep{Name: name, DeviceUsage: s.usage})
+		}
+		sort.Slice(usage.Steps, func(i, j int) bool {
+			if usage.Steps[i].Devices != usage.Steps[j].Devices {
+				return usage.Steps[i].Devices > usage.Steps[j].Devices
+			}
+			return usage.Steps[i].Name < usage.Steps[j].Name
+		})
+		result = append(result, usage)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Devices != result[j].Devices {
+			return result[i].Devices > result[j].Devices
+		}
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Workload < result[j].Workload
+	})
+	return result, nil
+}
+
+// usageCounter accumulates the device usage of the pods of a workload or
+// step, counting claims shared by several pods once.
+type usageCounter struct {
+	workload types.WorkloadUsage
+	usage    types.DeviceUsage
+	counted  map[string]bool
+	steps    map[string]*usageCounter
+}
+
+func newUsageCounter() *usageCounter {
+	return &usageCounter{counted: make(map[string]bool), steps: make(map[string]*usageCounter)}
+}
+
+func (u *usageCounter) add(pod *corev1.Pod, claims map[string]*model.ResourceClaim) {
+	u.usage.Pods++
+	if pod.Spec.NodeName == "" {
+		u.usage.PendingPods++
+	} else if !u.counted["node/"+pod.Spec.NodeName] {
+		u.counted["node/"+pod.Spec.NodeName] = true
+		u.usage.Nodes = append(u.usage.Nodes, pod.Spec.NodeName)
+		sort.Strings(u.usage.Nodes)
+	}
+
+	for _, entry := range pod.Spec.ResourceClaims {
+		name, _ := podClaimName(pod, entry)
+		rc, ok := claims[pod.Namespace+"/"+name]
+		if !ok || u.counted["claim/"+name] {
+			continue
+		}
+		u.counted["claim/"+name] = true
+		u.usage.Claims++
+		if rc.Status.Allocation == nil {
+			u.usage.PendingClaims++
+			continue
+		}
+		u.usage.Devices += len(rc.Status.Allocation.Devices.Results)
+	}
+}
+
+// argoStep returns the step or task of an Argo Workflow the pod runs, its node
+// name without the workflow name, e.g. "[0].train" for a step or "train" for
+// a DAG task, or "" for pods of other workloads.
+func argoStep(pod *corev1.Pod) string {
+	workflow := pod.Labels[argoWorkflowLabel]
+	node := pod.Annotations[argoNodeNameAnnotation]
+	if workflow == "" || node == "" {
+		return ""
+	}
+	if step := strings.TrimPrefix(strings.TrimPrefix(node, workflow), "."); step != "" {
+		return step
+	}
+	return node
+}
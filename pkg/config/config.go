@@ -0,0 +1,303 @@
+// Package config reads the optional configuration file of the tool, which
+// holds the settings of scheduled runs such as where to send reports. Secrets
+// are not stored in the file; it names the environment variables holding them.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultSMTPPort is the SMTP submission port, used with STARTTLS.
+const DefaultSMTPPort = 587
+
+// DefaultSMTPPasswordEnv is the environment variable holding the SMTP password
+// unless the configuration names another.
+const DefaultSMTPPasswordEnv = "DRA_SMTP_PASSWORD"
+
+// Default environment variables holding the credentials of the paging
+// services.
+const (
+	DefaultPagerDutyRoutingKeyEnv = "DRA_PAGERDUTY_ROUTING_KEY"
+	DefaultOpsgenieAPIKeyEnv      = "DRA_OPSGENIE_API_KEY"
+)
+
+// Config is the configuration file.
+type Config struct {
+	// Email configures sending reports by email.
+	Email *Email `json:"email,omitempty"`
+	// Alerts configures paging on critical conditions.
+	Alerts *Alerts `json:"alerts,omitempty"`
+	// Serve configures the HTTP API of serve mode.
+	Serve *Serve `json:"serve,omitempty"`
+	// Audit configures the audit log of changes to the cluster.
+	Audit *Audit `json:"audit,omitempty"`
+	// Record configures the retention of the history recorded by watch
+	// --record.
+	Record *Record `json:"record,omitempty"`
+	// Profiles are reference workloads by name, e.g. training-large, for
+	// analyses that place pods of a given shape.
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// Profile is the shape of a pod of a reference workload.
+type Profile struct {
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+	// Devices is the number of devices the pod claims, defaulting to 1.
+	Devices int `json:"devices,omitempty"`
+	// Products restricts the devices to these products, each a product name
+	// or a prefix of it such as "NVIDIA H100"; any product if empty.
+	Products []string `json:"products,omitempty"`
+}
+
+// Profile returns the workload profile of the given name.
+func (c *Config) Profile(name string) (*Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("no workload profile %q in the configuration file", name)
+	}
+	return profile, nil
+}
+
+// Audit configures where changes to the cluster are recorded.
+type Audit struct {
+	// Sink is "-" for stderr, an http:// or https:// URL receiving each
+	// entry as a POST request, or the path of a file entries are appended
+	// to.
+	Sink string `json:"sink"`
+}
+
+// Record bounds the history database of watch --record, which watch and
+// "record compact" compact to it.
+type Record struct {
+	// Retention is how long history is kept, forever if unset.
+	Retention metav1.Duration `json:"retention,omitempty"`
+	// DownsampleAfter is the age from which allocation samples are thinned
+	// to one snapshot per DownsampleInterval, an hour by default.
+	DownsampleAfter    metav1.Duration `json:"downsampleAfter,omitempty"`
+	DownsampleInterval metav1.Duration `json:"downsampleInterval,omitempty"`
+}
+
+// Serve configures access to the HTTP API of serve mode.
+type Serve struct {
+	// Tokens are the API keys accepted; without tokens, the API is open.
+	Tokens []Token `json:"tokens,omitempty"`
+}
+
+// Token is an API key of serve mode, stored as its SHA-256 hash, and the
+// namespaces whose claims it may read, or "*" for all.
+type Token struct {
+	Name       string   `json:"name"`
+	SHA256     string   `json:"sha256"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// Email configures an SMTP server and the recipients of emailed reports.
+type Email struct {
+	Host string `json:"host"`
+	// Port defaults to DefaultSMTPPort.
+	Port int `json:"port,omitempty"`
+	// Username authenticates with the server; without it, mail is sent
+	// unauthenticated.
+	Username string `json:"username,omitempty"`
+	// PasswordEnv names the environment variable holding the password,
+	// defaulting to DefaultSMTPPasswordEnv.
+	PasswordEnv string   `json:"passwordEnv,omitempty"`
+	From        string   `json:"from"`
+	To          []string `json:"to"`
+	// Subject of the email, defaulting to the title of the report.
+	Subject string `json:"subject,omitempty"`
+}
+
+// Alerts configures the services paged by the operator on critical
+// conditions of the cluster.
+type Alerts struct {
+	// Source names the cluster in incidents, defaulting to the current
+	// kubeconfig context.
+	Source string `json:"source,omitempty"`
+	// DriverDownNodes is the number of nodes with incomplete pools of a
+	// driver at which it is considered down.
+	DriverDownNodes int `json:"driverDownNodes,omitempty"`
+	// Cooldown is how long a condition must stay clear before its incident
+	// is resolved, so flapping conditions do not page repeatedly.
+	Cooldown metav1.Duration `json:"cooldown,omitempty"`
+	// RateLimit is the maximum number of incidents opened per channel and
+	// hour, 0 for no limit.
+	RateLimit int `json:"rateLimit,omitempty"`
+	// Routes select the channels of each kind of condition; without routes,
+	// every condition goes to every channel.
+	Routes    []Route    `json:"routes,omitempty"`
+	PagerDuty *PagerDuty `json:"pagerDuty,omitempty"`
+	Opsgenie  *Opsgenie  `json:"opsgenie,omitempty"`
+}
+
+// Channel names of the paging services in routes.
+const (
+	ChannelPagerDuty = "pagerDuty"
+	ChannelOpsgenie  = "opsgenie"
+)
+
+// Route sends conditions of the given kinds, e.g. driver-down, or of every
+// kind if none are given, to the named channels.
+type Route struct {
+	Kinds    []string `json:"kinds,omitempty"`
+	Channels []string `json:"channels"`
+}
+
+// PagerDuty configures a service of the PagerDuty Events API v2.
+type PagerDuty struct {
+	// RoutingKeyEnv names the environment variable holding the integration
+	// key, defaulting to DefaultPagerDutyRoutingKeyEnv.
+	RoutingKeyEnv string `json:"routingKeyEnv,omitempty"`
+	URL           string `json:"url,omitempty"`
+}
+
+// Opsgenie configures the Opsgenie Alert API.
+type Opsgenie struct {
+	// APIKeyEnv names the environment variable holding the API key,
+	// defaulting to DefaultOpsgenieAPIKeyEnv.
+	APIKeyEnv string `json:"apiKeyEnv,omitempty"`
+	// URL is https://api.eu.opsgenie.com for accounts in the EU.
+	URL string `json:"url,omitempty"`
+}
+
+// DefaultPath returns the path of the configuration file in the user's
+// configuration directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine configuration directory: %w", err)
+	}
+	return filepath.Join(dir, "k8s-dra-resources", "config.yaml"), nil
+}
+
+// Load reads and validates the configuration file at path. A missing file
+// yields an empty configuration.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+	if config.Email != nil {
+		if err := config.Email.validate(); err != nil {
+			return nil, fmt.Errorf("invalid email configuration in %s: %w", path, err)
+		}
+	}
+	if config.Alerts != nil {
+		if err := config.Alerts.validate(); err != nil {
+			return nil, fmt.Errorf("invalid alerts configuration in %s: %w", path, err)
+		}
+	}
+	if config.Record != nil {
+		if err := config.Record.validate(); err != nil {
+			return nil, fmt.Errorf("invalid record configuration in %s: %w", path, err)
+		}
+	}
+	for name, profile := range config.Profiles {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("invalid profile %s in %s: %w", name, path, err)
+		}
+	}
+	return &config, nil
+}
+
+func (p *Profile) validate() error {
+	if p.CPU.Sign() < 0 || p.Memory.Sign() < 0 || p.Devices < 0 {
+		return fmt.Errorf("cpu, memory and devices must not be negative")
+	}
+	if p.Devices == 0 {
+		p.Devices = 1
+	}
+	return nil
+}
+
+func (r *Record) validate() error {
+	if r.Retention.Duration < 0 || r.DownsampleAfter.Duration < 0 || r.DownsampleInterval.Duration < 0 {
+		return fmt.Errorf("retention, downsampleAfter and downsampleInterval must not be negative")
+	}
+	if r.Retention.Duration > 0 && r.DownsampleAfter.Duration >= r.Retention.Duration {
+		return fmt.Errorf("downsampleAfter must be shorter than retention")
+	}
+	return nil
+}
+
+func (e *Email) validate() error {
+	if e.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if e.From == "" {
+		return fmt.Errorf("from is required")
+	}
+	if len(e.To) == 0 {
+		return fmt.Errorf("at least one recipient is required in to")
+	}
+	if e.Port == 0 {
+		e.Port = DefaultSMTPPort
+	}
+	if e.PasswordEnv == "" {
+		e.PasswordEnv = DefaultSMTPPasswordEnv
+	}
+	return nil
+}
+
+// Password returns the SMTP password from the environment. It is an error for
+// an authenticated server to have no password set.
+func (e *Email) Password() (string, error) {
+	if e.Username == "" {
+		return "", nil
+	}
+	return secretFromEnv("SMTP password for "+e.Username, e.PasswordEnv)
+}
+
+func (a *Alerts) validate() error {
+	if a.PagerDuty == nil && a.Opsgenie == nil {
+		return fmt.Errorf("no paging service configured, expected pagerDuty or opsgenie")
+	}
+	if a.DriverDownNodes < 0 {
+		return fmt.Errorf("driverDownNodes must not be negative")
+	}
+	if a.Cooldown.Duration < 0 || a.RateLimit < 0 {
+		return fmt.Errorf("cooldown and rateLimit must not be negative")
+	}
+	if a.PagerDuty != nil && a.PagerDuty.RoutingKeyEnv == "" {
+		a.PagerDuty.RoutingKeyEnv = DefaultPagerDutyRoutingKeyEnv
+	}
+	if a.Opsgenie != nil && a.Opsgenie.APIKeyEnv == "" {
+		a.Opsgenie.APIKeyEnv = DefaultOpsgenieAPIKeyEnv
+	}
+	return nil
+}
+
+// secretFromEnv returns the value of the environment variable env, failing if
+// it is not set.
+func secretFromEnv(what, env string) (string, error) {
+	value := os.Getenv(env)
+	if value == "" {
+		return "", fmt.Errorf("%s is not set in $%s", what, env)
+	}
+	return value, nil
+}
+
+// RoutingKey returns the PagerDuty integration key from the environment.
+func (p *PagerDuty) RoutingKey() (string, error) {
+	return secretFromEnv("PagerDuty routing key", p.RoutingKeyEnv)
+}
+
+// APIKey returns the Opsgenie API key from the environment.
+func (o *Opsgenie) APIKey() (string, error) {
+	return secretFromEnv("Opsgenie API key", o.APIKeyEnv)
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *Config
+		err      string
+	}{
+		{
+			name: "email with defaults",
+			content: `
+email:
+  host: smtp.example.com
+  username: reports
+  from: dra@example.com
+  to: [gpu-capacity@example.com]
+`,
+			expected: &Config{Email: &Email{
+				Host:        "smtp.example.com",
+				Port:        DefaultSMTPPort,
+				Username:    "reports",
+				PasswordEnv: DefaultSMTPPasswordEnv,
+				From:        "dra@example.com",
+				To:          []string{"gpu-capacity@example.com"},
+			}},
+		},
+		{
+			name: "alerts with defaults",
+			content: `
+alerts:
+  cooldown: 10m
+  routes:
+  - kinds: [driver-down]
+    channels: [pagerDuty]
+  pagerDuty: {}
+  opsgenie:
+    apiKeyEnv: OPSGENIE_KEY
+    url: https://api.eu.opsgenie.com
+`,
+			expected: &Config{Alerts: &Alerts{
+				Cooldown:  metav1.Duration{Duration: 10 * time.Minute},
+				Routes:    []Route{{Kinds: []string{"driver-down"}, Channels: []string{ChannelPagerDuty}}},
+				PagerDuty: &PagerDuty{RoutingKeyEnv: DefaultPagerDutyRoutingKeyEnv},
+				Opsgenie:  &Opsgenie{APIKeyEnv: "OPSGENIE_KEY", URL: "https://api.eu.opsgenie.com"},
+			}},
+		},
+		{
+			name:    "alerts without service",
+			content: "alerts:\n  driverDownNodes: 2\n",
+			err:     "no paging service configured",
+		},
+		{
+			name: "profiles",
+			content: `
+profiles:
+  training-large:
+    cpu: 48
+    memory: 512Gi
+    devices: 8
+    products: [NVIDIA H100]
+  notebook:
+    cpu: 2
+    memory: 16Gi
+`,
+			expected: &Config{Profiles: map[string]*Profile{
+				"training-large": {CPU: resource.MustParse("48"), Memory: resource.MustParse("512Gi"), Devices: 8, Products: []string{"NVIDIA H100"}},
+				"notebook":       {CPU: resource.MustParse("2"), Memory: resource.MustParse("16Gi"), Devices: 1},
+			}},
+		},
+		{
+			name:    "negative profile",
+			content: "profiles:\n  broken:\n    cpu: -1\n",
+			err:     "invalid profile broken",
+		},
+		{
+			name:     "empty",
+			content:  "",
+			expected: &Config{},
+		},
+		{
+			name: "missing recipients",
+			content: `
+email:
+  host: smtp.example.com
+  from: dra@example.com
+`,
+			err: "at least one recipient is required",
+		},
+		{
+			name: "record",
+			content: `
+record:
+  retention: 720h
+  downsampleAfter: 168h
+`,
+			expected: &Config{Record: &Record{
+				Retention:       metav1.Duration{Duration: 720 * time.Hour},
+				DownsampleAfter: metav1.Duration{Duration: 168 * time.Hour},
+			}},
+		},
+		{
+			name: "record downsampling after retention",
+			content: `
+record:
+  retention: 24h
+  downsampleAfter: 48h
+`,
+			err: "downsampleAfter must be shorter than retention",
+		},
+		{
+			name: "unknown field",
+			content: `
+email:
+  host: smtp.example.com
+  password: hunter2
+`,
+			err: "unknown field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			quantityComparer := cmp.Comparer(func(x, y resource.Quantity) bool { return x.Equal(y) })
+			if diff := cmp.Diff(got, tt.expected, quantityComparer); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	got, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff(got, &Config{}); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestPassword(t *testing.T) {
+	email := &Email{Username: "reports", PasswordEnv: "TEST_DRA_SMTP_PASSWORD"}
+	if _, err := email.Password(); err == nil {
+		t.Errorf("Password() error = nil, want missing password")
+	}
+	t.Setenv("TEST_DRA_SMTP_PASSWORD", "secret")
+	got, err := email.Password()
+	if err != nil {
+		t.Fatalf("Password() error = %v", err)
+	}
+	if got != "secret" {
+		t.Errorf("Password() = %q, want %q", got, "secret")
+	}
+}
@@ -0,0 +1,108 @@
+package decorator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Field is one setting decoded from a driver's opaque configuration.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ConfigDecoder turns the opaque configuration parameters of one driver into
+// fields that are easier to read than the raw JSON.
+type ConfigDecoder interface {
+	DecodeConfig(driver string, parameters []byte) ([]Field, error)
+}
+
+var configDecoders = map[string]ConfigDecoder{
+	"gpu.nvidia.com":            nvidiaConfigDecoder{},
+	"compute-domain.nvidia.com": nvidiaConfigDecoder{},
+}
+
+// RegisterConfigDecoder installs the config decoder for a driver, replacing
+// any previous one.
+func RegisterConfigDecoder(driver string, d ConfigDecoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	configDecoders[driver] = d
+}
+
+// DecodeConfig decodes opaque configuration parameters with the decoder
+// registered for the driver. It returns no fields when there is no decoder,
+// in which case the parameters are best shown as they are.
+func DecodeConfig(driver string, parameters []byte) ([]Field, error) {
+	mu.RLock()
+	d, ok := configDecoders[driver]
+	mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	fields, err := d.DecodeConfig(driver, parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode configuration of driver %s: %w", driver, err)
+	}
+	return fields, nil
+}
+
+// nvidiaConfig covers the configuration kinds of the NVIDIA DRA driver:
+// GpuConfig and MigDeviceConfig with their sharing settings, and the
+// ComputeDomain channel and daemon configs.
+type nvidiaConfig struct {
+	Kind    string `json:"kind"`
+	Sharing *struct {
+		Strategy          string `json:"strategy"`
+		TimeSlicingConfig *struct {
+			Interval string `json:"interval"`
+		} `json:"timeSlicingConfig"`
+		MpsConfig *struct {
+			DefaultActiveThreadPercentage     *int              `json:"defaultActiveThreadPercentage"`
+			DefaultPinnedDeviceMemoryLimit    string            `json:"defaultPinnedDeviceMemoryLimit"`
+			DefaultPerDevicePinnedMemoryLimit map[string]string `json:"defaultPerDevicePinnedMemoryLimit"`
+		} `json:"mpsConfig"`
+	} `json:"sharing"`
+	DomainID string `json:"domainID"`
+}
+
+// nvidiaConfigDecoder decodes the configuration of the NVIDIA DRA driver.
+type nvidiaConfigDecoder struct{}
+
+func (nvidiaConfigDecoder) DecodeConfig(driver string, parameters []byte) ([]Field, error) {
+	var config nvidiaConfig
+	if err := json.Unmarshal(parameters, &config); err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, Field{Name: name, Value: value})
+		}
+	}
+	add("kind", config.Kind)
+	add("domain", config.DomainID)
+	if s := config.Sharing; s != nil {
+		add("sharing", s.Strategy)
+		if ts := s.TimeSlicingConfig; ts != nil {
+			add("interval", ts.Interval)
+		}
+		if mps := s.MpsConfig; mps != nil {
+			if mps.DefaultActiveThreadPercentage != nil {
+				add("activeThreads", fmt.Sprintf("%d%%", *mps.DefaultActiveThreadPercentage))
+			}
+			add("pinnedMemoryLimit", mps.DefaultPinnedDeviceMemoryLimit)
+			devices := make([]string, 0, len(mps.DefaultPerDevicePinnedMemoryLimit))
+			for device := range mps.DefaultPerDevicePinnedMemoryLimit {
+				devices = append(devices, device)
+			}
+			sort.Strings(devices)
+			for _, device := range devices {
+				add("pinnedMemoryLimit["+device+"]", mps.DefaultPerDevicePinnedMemoryLimit[device])
+			}
+		}
+	}
+	return fields, nil
+}
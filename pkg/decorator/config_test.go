@@ -0,0 +1,72 @@
+package decorator
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		driver     string
+		parameters string
+		want       []Field
+		wantErr    bool
+	}{
+		{
+			name:       "time slicing",
+			driver:     "gpu.nvidia.com",
+			parameters: `{"apiVersion":"resource.nvidia.com/v1beta1","kind":"GpuConfig","sharing":{"strategy":"TimeSlicing","timeSlicingConfig":{"interval":"Long"}}}`,
+			want: []Field{
+				{Name: "kind", Value: "GpuConfig"},
+				{Name: "sharing", Value: "TimeSlicing"},
+				{Name: "interval", Value: "Long"},
+			},
+		},
+		{
+			name:       "MPS",
+			driver:     "gpu.nvidia.com",
+			parameters: `{"kind":"GpuConfig","sharing":{"strategy":"MPS","mpsConfig":{"defaultActiveThreadPercentage":50,"defaultPinnedDeviceMemoryLimit":"10Gi","defaultPerDevicePinnedMemoryLimit":{"1":"5Gi","0":"20Gi"}}}}`,
+			want: []Field{
+				{Name: "kind", Value: "GpuConfig"},
+				{Name: "sharing", Value: "MPS"},
+				{Name: "activeThreads", Value: "50%"},
+				{Name: "pinnedMemoryLimit", Value: "10Gi"},
+				{Name: "pinnedMemoryLimit[0]", Value: "20Gi"},
+				{Name: "pinnedMemoryLimit[1]", Value: "5Gi"},
+			},
+		},
+		{
+			name:       "compute domain channel",
+			driver:     "compute-domain.nvidia.com",
+			parameters: `{"kind":"ComputeDomainChannelConfig","domainID":"a1b2"}`,
+			want: []Field{
+				{Name: "kind", Value: "ComputeDomainChannelConfig"},
+				{Name: "domain", Value: "a1b2"},
+			},
+		},
+		{
+			name:       "invalid",
+			driver:     "gpu.nvidia.com",
+			parameters: `{"kind":`,
+			wantErr:    true,
+		},
+		{
+			name:       "no decoder",
+			driver:     "gpu.example.com",
+			parameters: `{"mode":"shared"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeConfig(tt.driver, []byte(tt.parameters))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package decorator lets driver-specific logic control how devices are
+// presented: which product they are grouped under, how their capacity is
+// named, and whether they are healthy. Vendors can register a Decorator in Go
+// or ship an external executable speaking the JSON protocol of Exec.
+package decorator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Device is the driver-independent view of a device handed to decorators.
+// Attribute and capacity names are unqualified when they belong to the
+// driver's own domain.
+type Device struct {
+	Name       string                       `json:"name"`
+	Pool       string                       `json:"pool"`
+	Attributes map[string]string            `json:"attributes,omitempty"`
+	Capacity   map[string]resource.Quantity `json:"capacity,omitempty"`
+}
+
+// Decoration is what a decorator reports for a device. Empty fields fall
+// back to the default presentation.
+type Decoration struct {
+	// ProductName is the name devices are grouped by.
+	ProductName string `json:"productName,omitempty"`
+	// Capacity replaces the device's capacity when set, e.g. to rename
+	// vendor-specific keys to common ones.
+	Capacity map[string]resource.Quantity `json:"capacity,omitempty"`
+	// Health is "Healthy", "Unhealthy" or empty when unknown.
+	Health string `json:"health,omitempty"`
+}
+
+const (
+	Healthy   = "Healthy"
+	Unhealthy = "Unhealthy"
+)
+
+// Decorator decorates the devices of one driver. The devices of all slices
+// of the driver are passed in one batch, and the result must have one
+// Decoration per device in the same order.
+type Decorator interface {
+	Decorate(ctx context.Context, driver string, devices []Device) ([]Decoration, error)
+}
+
+var (
+	mu         sync.RWMutex
+	decorators = map[string]Decorator{
+		"gpu.nvidia.com": nvidiaDecorator{},
+	}
+)
+
+// Register installs the decorator for a driver, replacing any previous one.
+func Register(driver string, d Decorator) {
+	mu.Lock()
+	defer mu.Unlock()
+	decorators[driver] = d
+}
+
+// For returns the decorator registered for a driver, or the default one.
+func For(driver string) Decorator {
+	mu.RLock()
+	defer mu.RUnlock()
+	if d, ok := decorators[driver]; ok {
+		return d
+	}
+	return defaultDecorator{}
+}
+
+// Decorate runs the driver's decorator and fills in defaults for anything it
+// left empty: the driver name as product name and the device's own capacity.
+func Decorate(ctx context.Context, driver string, devices []Device) ([]Decoration, error) {
+	decorations, err := For(driver).Decorate(ctx, driver, devices)
+	if err != nil {
+		return nil, err
+	}
+	if len(decorations) != len(devices) {
+		return nil, fmt.Errorf("decorator for driver %s returned %d decorations for %d devices", driver, len(decorations), len(devices))
+	}
+	for i := range decorations {
+		if decorations[i].ProductName == "" {
+			decorations[i].ProductName = driver
+		}
+		if decorations[i].Capacity == nil {
+			decorations[i].Capacity = devices[i].Capacity
+		}
+	}
+	return decorations, nil
+}
+
+// defaultDecorator groups devices by driver name and keeps their capacity.
+type defaultDecorator struct{}
+
+func (defaultDecorator) Decorate(_ context.Context, driver string, devices []Device) ([]Decoration, error) {
+	return make([]Decoration, len(devices)), nil
+}
+
+// nvidiaDecorator groups NVIDIA GPUs by their productName attribute.
+type nvidiaDecorator struct{}
+
+func (nvidiaDecorator) Decorate(_ context.Context, driver string, devices []Device) ([]Decoration, error) {
+	decorations := make([]Decoration, len(devices))
+	for i, dev := range devices {
+		decorations[i].ProductName = dev.Attributes["productName"]
+	}
+	return decorations, nil
+}
@@ -0,0 +1,84 @@
+package decorator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestDecorate(t *testing.T) {
+	devices := []Device{
+		{
+			Name:       "gpu-0",
+			Attributes: map[string]string{"productName": "NVIDIA A100"},
+			Capacity:   map[string]resource.Quantity{"memory": resource.MustParse("40Gi")},
+		},
+	}
+
+	got, err := Decorate(context.Background(), "gpu.nvidia.com", devices)
+	if err != nil {
+		t.Fatalf("Decorate() error = %v", err)
+	}
+	if got[0].ProductName != "NVIDIA A100" {
+		t.Errorf("expected NVIDIA product name, got %q", got[0].ProductName)
+	}
+
+	got, err = Decorate(context.Background(), "gpu.example.com", devices)
+	if err != nil {
+		t.Fatalf("Decorate() error = %v", err)
+	}
+	if got[0].ProductName != "gpu.example.com" {
+		t.Errorf("expected driver name as product name, got %q", got[0].ProductName)
+	}
+	if diff := cmp.Diff(got[0].Capacity, devices[0].Capacity, cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Equal(y)
+	})); diff != "" {
+		t.Errorf("expected capacity to default to the device's own (-got +want):\n%s", diff)
+	}
+}
+
+func TestExec(t *testing.T) {
+	plugin := filepath.Join(t.TempDir(), "plugin")
+	script := `#!/bin/sh
+cat > /dev/null
+echo '{"decorations":[{"productName":"Example X1","capacity":{"memory":"16Gi"},"health":"Unhealthy"}]}'
+`
+	if err := os.WriteFile(plugin, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+
+	Register("gpu.example.com", &Exec{Path: plugin})
+	defer Register("gpu.example.com", defaultDecorator{})
+
+	got, err := Decorate(context.Background(), "gpu.example.com", []Device{{Name: "gpu-0"}})
+	if err != nil {
+		t.Fatalf("Decorate() error = %v", err)
+	}
+
+	expected := []Decoration{
+		{
+			ProductName: "Example X1",
+			Capacity:    map[string]resource.Quantity{"memory": resource.MustParse("16Gi")},
+			Health:      Unhealthy,
+		},
+	}
+	if diff := cmp.Diff(got, expected, cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Equal(y)
+	})); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := Decorate(context.Background(), "gpu.example.com", []Device{{Name: "gpu-0"}, {Name: "gpu-1"}}); err == nil {
+		t.Errorf("expected an error when the plugin returns too few decorations")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Decorate(ctx, "gpu.example.com", []Device{{Name: "gpu-0"}}); err == nil {
+		t.Errorf("expected an error when the context is done")
+	}
+}
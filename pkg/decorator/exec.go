@@ -0,0 +1,67 @@
+package decorator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ExecAPIVersion identifies the version of the exec plugin protocol.
+const ExecAPIVersion = "decorator.dra-resources.dharmjit.github.io/v1alpha1"
+
+// ExecRequest is written as JSON to the plugin's stdin.
+type ExecRequest struct {
+	APIVersion string   `json:"apiVersion"`
+	Driver     string   `json:"driver"`
+	Devices    []Device `json:"devices"`
+}
+
+// ExecResponse is read as JSON from the plugin's stdout. Decorations must
+// have one entry per requested device, in the same order.
+type ExecResponse struct {
+	Decorations []Decoration `json:"decorations"`
+}
+
+// Exec is a Decorator backed by an external executable. The executable is
+// run once per batch of devices, receives an ExecRequest on stdin and must
+// print an ExecResponse on stdout and exit zero. It is killed when the
+// context is done or Timeout, 10 seconds by default, has passed.
+type Exec struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e *Exec) Decorate(ctx context.Context, driver string, devices []Device) ([]Decoration, error) {
+	request, err := json.Marshal(ExecRequest{APIVersion: ExecAPIVersion, Driver: driver, Devices: devices})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	timeout := e.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("decorator plugin %s failed: %w: %s", e.Path, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var response ExecResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("failed to decode response of decorator plugin %s: %w", e.Path, err)
+	}
+	if len(response.Decorations) != len(devices) {
+		return nil, fmt.Errorf("decorator plugin %s returned %d decorations for %d devices", e.Path, len(response.Decorations), len(devices))
+	}
+	return response.Decorations, nil
+}
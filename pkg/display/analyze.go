@@ -0,0 +1,340 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// DisplayDrainCandidates prints nodes ranked by how safe they are to drain.
+func DisplayDrainCandidates(candidates []types.DrainCandidate) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NODE", "DEVICES(TOTAL/ALLOCATED)", "CONSUMER PODS", "RECOMMENDATION")
+	for _, c := range candidates {
+		recommendation := fmt.Sprintf("drain evicts %d device pod(s)", c.ConsumerPods)
+		if c.Free() {
+			recommendation = "safe to drain"
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%d\t%s\n",
+			c.NodeName,
+			c.TotalDevices, c.AllocatedDevices,
+			c.ConsumerPods,
+			recommendation,
+		)
+	}
+}
+
+// DisplayFlakyDevices prints the devices suspected to be faulty and why.
+func DisplayFlakyDevices(devices []types.FlakyDevice) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "DEVICE", "NODE", "PRODUCT", "ALLOCATIONS", "HEALTH FLAPS", "STATE", "REASONS")
+	for _, dev := range devices {
+		state := "healthy"
+		if dev.Unhealthy {
+			state = "unhealthy"
+		}
+		fmt.Fprintf(w, "%s/%s/%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			dev.Driver, dev.Pool, dev.Device,
+			valueOrNone(dev.NodeName),
+			valueOrNone(dev.ProductName),
+			formatInt(dev.Allocations),
+			formatInt(dev.HealthFlaps),
+			state,
+			strings.Join(dev.Reasons, "; "),
+		)
+	}
+}
+
+// DisplayLabelMismatches prints the node labels disagreeing with the
+// devices the node publishes.
+func DisplayLabelMismatches(mismatches []types.LabelMismatch) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NODE", "LABEL", "VALUE", "PUBLISHED")
+	for _, m := range mismatches {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.NodeName, m.Label, m.Value, valueOrNone(m.Published))
+	}
+}
+
+// DisplayCUDAIncompatibilities prints the containers needing a newer CUDA
+// version than the driver of their devices supports.
+func DisplayCUDAIncompatibilities(incompatible []types.CUDAIncompatibility) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "POD", "CONTAINER", "NODE", "REQUIRED CUDA", "SUPPORTED CUDA", "DRIVER", "DEVICES", "SOURCE")
+	for _, c := range incompatible {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Namespace,
+			c.Pod,
+			c.Container,
+			c.NodeName,
+			c.RequiredCUDA,
+			c.SupportedCUDA,
+			valueOrNone(c.DriverVersion),
+			joinOrNone(c.Devices),
+			c.Source,
+		)
+	}
+}
+
+// DisplayCounterOvercommit prints the shared counters of which the
+// allocated devices consume more than the device provides.
+func DisplayCounterOvercommit(overcommit []types.CounterOvercommit) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NODE", "DRIVER", "POOL", "COUNTER SET", "COUNTER", "CAPACITY", "ALLOCATED", "DEVICES", "CLAIMS")
+	for _, o := range overcommit {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			valueOrNone(o.NodeName),
+			o.Driver,
+			o.Pool,
+			o.CounterSet,
+			o.Counter,
+			o.Capacity.String(),
+			o.Allocated.String(),
+			joinOrNone(o.Devices),
+			joinOrNone(o.Claims),
+		)
+	}
+}
+
+// DisplayKubeletDeviceMismatches prints the claim requests whose devices, as
+// the kubelet reports them, differ from the claim's allocation.
+func DisplayKubeletDeviceMismatches(mismatches []types.KubeletDeviceMismatch) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "POD", "CONTAINER", "NODE", "CLAIM", "REQUEST", "ALLOCATED", "KUBELET")
+	for _, m := range mismatches {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.Namespace,
+			m.Pod,
+			m.Container,
+			m.NodeName,
+			m.Claim,
+			valueOrNone(m.Request),
+			joinOrNone(m.Allocated),
+			joinOrNone(m.Reported),
+		)
+	}
+}
+
+// DisplayStrandedDevices prints the free devices reference pods cannot use,
+// with the CPU and memory left on their nodes.
+func DisplayStrandedDevices(stranded []types.StrandedDevices) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NODE", "PRODUCT", "FREE", "STRANDED", "PODS FITTING", "LIMITED BY", "CPU AVAIL", "MEMORY AVAIL GiB")
+	for _, s := range stranded {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.NodeName,
+			s.ProductName,
+			formatInt(s.FreeDevices),
+			formatInt(s.StrandedDevices),
+			formatInt(s.FittingPods),
+			s.LimitedBy,
+			s.AvailableCPU.String(),
+			formatMemoryAsGiB(s.AvailableMemory),
+		)
+	}
+}
+
+// DisplaySpotRisk prints the claims holding devices on spot nodes and the
+// workloads interrupted when those nodes are reclaimed.
+func DisplaySpotRisk(risks []types.SpotRisk) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "CLAIM", "SPOT NODES", "DEVICES", "PODS", "OWNERS")
+	for _, risk := range risks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			risk.Namespace,
+			risk.Name,
+			joinOrNone(risk.Nodes),
+			joinOrNone(risk.Devices),
+			joinOrNone(risk.Pods),
+			joinOrNone(risk.Owners),
+		)
+	}
+}
+
+// DisplayNodeImpact prints what draining a node would disrupt.
+func DisplayNodeImpact(impact *types.NodeImpact) {
+	fmt.Printf("Draining node %s would disrupt:\n\n", impact.NodeName)
+	fmt.Printf("Claims (%d): %s\n", len(impact.Claims), joinOrNone(impact.Claims))
+	fmt.Printf("Pods (%d): %s\n\n", len(impact.Pods), joinOrNone(impact.Pods))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "PRODUCT", "LOST(NODE/CLUSTER)", "DISPLACED", "AVAILABLE ELSEWHERE", "FITS ELSEWHERE")
+	for _, p := range impact.Products {
+		fits := "yes"
+		if !p.Fits() {
+			fits = "no"
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%d\t%d\t%s\n",
+			p.ProductName,
+			p.LostDevices, p.ClusterTotal,
+			p.DisplacedDevices,
+			p.AvailableElsewhere,
+			fits,
+		)
+	}
+}
+
+// DisplayReport prints the sections of an analyzer report.
+func DisplayReport(report *types.Report) {
+	for i, section := range report.Sections {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("== %s ==\n", section.Title)
+		if section.Text != "" {
+			fmt.Println(section.Text)
+		}
+		if len(section.Columns) == 0 {
+			continue
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		printHeader(w, section.Columns...)
+		for _, row := range section.Rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+	}
+}
+
+// DisplayClassDrift prints the DeviceClasses that differ between clusters,
+// one row per distinct definition.
+func DisplayClassDrift(drifts []types.ClassDrift) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "CLASS", "CONTEXTS", "SELECTORS", "CONFIG")
+	for _, d := range drifts {
+		for _, v := range d.Variants {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				d.Name,
+				strings.Join(v.Contexts, ","),
+				joinOrNone(v.Class.Selectors),
+				joinOrNone(v.Class.Config),
+			)
+		}
+		if len(d.Missing) > 0 {
+			fmt.Fprintf(w, "%s\t%s\t<not defined>\t\n", d.Name, strings.Join(d.Missing, ","))
+		}
+	}
+}
+
+// DisplayAvailabilityMatrix prints available/total device counts per product
+// and label value. Cells without devices show "-".
+func DisplayAvailabilityMatrix(matrix types.AvailabilityMatrix) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := []string{"PRODUCT"}
+	for _, column := range matrix.Columns {
+		if column == "" {
+			column = `""`
+		}
+		header = append(header, column)
+	}
+	printHeader(w, header...)
+	for _, row := range matrix.Rows {
+		cells := []string{row.ProductName}
+		for _, cell := range row.Cells {
+			if cell.TotalCount == 0 {
+				cells = append(cells, "-")
+				continue
+			}
+			cells = append(cells, formatInt(cell.AvailableCount)+"/"+formatInt(cell.TotalCount))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+// DisplayCheckResults prints the outcome of each check, followed by the
+// violations of the failed ones.
+func DisplayCheckResults(results []types.CheckResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	printHeader(w, "CHECK", "RESULT", "VIOLATIONS", "DESCRIPTION")
+	var failed []types.CheckResult
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed() {
+			status = "FAIL"
+			failed = append(failed, result)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, status, formatInt(len(result.Failures)), result.Description)
+	}
+	w.Flush()
+
+	for _, result := range failed {
+		fmt.Printf("\n%s:\n  %s\n", result.Name, strings.Join(result.Failures, "\n  "))
+	}
+}
+
+// DisplayQueueDemand prints the devices requested through each Kueue
+// ClusterQueue next to its quota and the devices in the cluster.
+func DisplayQueueDemand(demand []types.QueueDemand) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "CLUSTERQUEUE", "DEVICECLASS", "QUOTA", "ADMITTED", "PENDING", "PENDING WORKLOADS", "CLUSTER(AVAILABLE/TOTAL)")
+	for _, d := range demand {
+		quota := "<none>"
+		if d.Quota != nil {
+			quota = formatInt(int(*d.Quota))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s/%s\n",
+			d.ClusterQueue,
+			d.DeviceClass,
+			quota,
+			formatInt(d.Admitted),
+			formatInt(d.Pending),
+			formatInt(d.PendingWorkloads),
+			formatInt(d.ClusterAvailable), formatInt(d.ClusterTotal),
+		)
+	}
+}
+
+// DisplayTopNodes prints the busiest nodes by device allocation, and by
+// device utilization when showUtilization is set.
+func DisplayTopNodes(usage []types.NodeUsage, showUtilization bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	columns := []string{"NODE", "DEVICES(ALLOCATED/TOTAL)", "ALLOCATED%"}
+	if showUtilization {
+		columns = append(columns, "UTILIZATION%")
+	}
+	printHeader(w, columns...)
+	for _, u := range usage {
+		cells := []string{
+			u.NodeName,
+			formatInt(u.AllocatedDevices) + "/" + formatInt(u.TotalDevices),
+			formatFloat(u.AllocatedPercent, 0) + "%",
+		}
+		if showUtilization {
+			utilization := "<none>"
+			if u.Utilization != nil {
+				utilization = formatFloat(*u.Utilization, 0) + "%"
+			}
+			cells = append(cells, utilization)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
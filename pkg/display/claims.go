@@ -0,0 +1,285 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ",")
+}
+
+// DisplayClaims prints a table of ResourceClaims.
+func DisplayClaims(claims []*types.ClaimInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "NAME", "STATE", "REQUESTS", "DEVICES", "CONSUMERS", ageHeader())
+	for _, claim := range claims {
+		state := "pending"
+		if claim.Allocated {
+			state = "allocated"
+		}
+		if claim.ConsumersFull {
+			state += " (consumers full)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			claim.Namespace,
+			claim.Name,
+			state,
+			joinOrNone(claim.Requests),
+			joinOrNone(claim.Devices),
+			joinOrNone(claim.Consumers),
+			formatAge(claim.Created),
+		)
+	}
+}
+
+// DisplayProductAvailability prints cluster-wide device availability per
+// product.
+func DisplayProductAvailability(products []types.ProductAvailability) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "PRODUCT", "TOTAL", "AVAILABLE")
+	for _, p := range products {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.ProductName, formatInt(p.TotalCount), formatInt(p.AvailableCount))
+	}
+}
+
+// DisplayClaimTemplateStats prints the usage of each ResourceClaimTemplate.
+func DisplayClaimTemplateStats(stats []types.ClaimTemplateStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "NAME", "CLAIMS", "ALLOCATED", "PENDING", "MISSING CLASSES")
+	for _, s := range stats {
+		allocated := formatInt(s.Allocated)
+		if s.Claims > 0 {
+			allocated = fmt.Sprintf("%s (%s%%)", allocated, formatFloat(s.AllocatedRatio()*100, 0))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Namespace,
+			s.Name,
+			formatInt(s.Claims),
+			allocated,
+			formatInt(s.Pending),
+			joinOrNone(s.MissingDeviceClasses),
+		)
+	}
+}
+
+// DisplayReservations prints claim reservations with their state and expiry.
+func DisplayReservations(reservations []types.ReservationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "STATE", "NAMESPACE", "CLAIM", "TEAM", "PURPOSE", "EXPIRES", "DEVICES")
+	for _, r := range reservations {
+		expires := formatExpiry(r.Reservation.Expires)
+		if r.Reservation.InvalidExpiry != "" {
+			expires = fmt.Sprintf("%q", r.Reservation.InvalidExpiry)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.State,
+			r.Namespace,
+			r.Name,
+			valueOrNone(r.Reservation.Team),
+			valueOrNone(r.Reservation.Purpose),
+			expires,
+			joinOrNone(r.Devices),
+		)
+	}
+}
+
+// DisplayHeldClaims prints the claims holding devices for a long time, one
+// section per group.
+func DisplayHeldClaims(label string, groups []types.HeldClaimGroup) {
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("== %s %s: %d claim(s) holding %s device(s) ==\n", label, group.Group, len(group.Claims), formatInt(group.Devices))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		printHeader(w, "NAMESPACE", "CLAIM", "DEVICES", "CONSUMERS", ageHeader())
+		for _, claim := range group.Claims {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				claim.Namespace,
+				claim.Name,
+				formatInt(len(claim.Devices)),
+				joinOrNone(claim.Consumers),
+				formatAge(claim.Created),
+			)
+		}
+		w.Flush()
+	}
+}
+
+// DisplayPods prints pods consuming claims, the pods sharing their claims and
+// why unscheduled ones wait.
+func DisplayPods(pods []types.PodInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "NAME", "NODE", "PHASE", "CLAIMS", "SHARED WITH", "REASON")
+	for _, pod := range pods {
+		reason := pod.Reason
+		if len(pod.Details) > 0 {
+			reason += " (" + strings.Join(pod.Details, ",") + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			pod.Namespace,
+			pod.Name,
+			valueOrNone(pod.NodeName),
+			pod.Phase,
+			joinOrNone(pod.Claims),
+			joinOrNone(pod.SharedWith),
+			valueOrNone(reason),
+		)
+	}
+}
+
+// DisplayPodGroups prints the Volcano gangs with the devices their waiting
+// pods need collectively.
+func DisplayPodGroups(groups []types.PodGroupInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "PODGROUP", "PHASE", "MIN MEMBER", "WAITING(PODS/MEMBERS)", "DEVICES NEEDED", "DEVICES AVAILABLE")
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s/%s\t%s\t%s\n",
+			group.Namespace,
+			group.Name,
+			valueOrNone(group.Phase),
+			group.MinMember,
+			formatInt(len(group.Waiting)), formatInt(group.Members),
+			formatInt(group.Devices),
+			formatInt(group.Available),
+		)
+	}
+}
+
+// DisplayWorkloads prints the device usage of each workload, followed by the
+// usage of each step of workflows.
+func DisplayWorkloads(workloads []types.WorkloadUsage) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "WORKLOAD", "PODS(PENDING/TOTAL)", "CLAIMS(PENDING/TOTAL)", "DEVICES", "NODES")
+	printUsage := func(namespace, name string, usage types.DeviceUsage) {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s/%s\t%s\t%s\n",
+			namespace,
+			name,
+			formatInt(usage.PendingPods), formatInt(usage.Pods),
+			formatInt(usage.PendingClaims), formatInt(usage.Claims),
+			formatInt(usage.Devices),
+			joinOrNone(usage.Nodes),
+		)
+	}
+	for _, wl := range workloads {
+		printUsage(wl.Namespace, wl.Workload, wl.DeviceUsage)
+		for _, step := range wl.Steps {
+			printUsage(wl.Namespace, "  step "+step.Name, step.DeviceUsage)
+		}
+	}
+}
+
+// DisplayClaimDescription prints a claim, the device allocated for each of its
+// requests and the driver configuration of its allocation.
+func DisplayClaimDescription(d *types.ClaimDescription) {
+	state := "pending"
+	if d.Allocated {
+		state = "allocated"
+	}
+	fmt.Printf("Claim:      %s/%s\n", d.Namespace, d.Name)
+	fmt.Printf("State:      %s\n", state)
+	printList("Requests:", d.Requests)
+	printList("Consumers:", d.Consumers)
+	if !d.Created.IsZero() {
+		label := "Age:"
+		if Timestamps {
+			label = "Created:"
+		}
+		fmt.Printf("%-11s %s\n", label, formatAge(d.Created))
+	}
+
+	if len(d.Results) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w)
+		printHeader(w, "REQUEST", "DEVICE")
+		for _, r := range d.Results {
+			fmt.Fprintf(w, "%s\t%s\n", r.Request, r.Device)
+		}
+		w.Flush()
+	}
+
+	if len(d.AllocationConfigs) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w)
+	if !Quiet {
+		fmt.Fprintln(w, "Configuration passed to the drivers with the allocation:")
+		fmt.Fprintln(w)
+	}
+	printHeader(w, "SOURCE", "REQUESTS", "DRIVER", "PARAMETERS")
+	for _, c := range d.AllocationConfigs {
+		requests := "<all>"
+		if len(c.Requests) > 0 {
+			requests = strings.Join(c.Requests, ",")
+		}
+		parameters := c.Parameters
+		if len(c.Fields) > 0 {
+			fields := make([]string, len(c.Fields))
+			for i, f := range c.Fields {
+				fields[i] = f.Name + "=" + f.Value
+			}
+			parameters = strings.Join(fields, " ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Source, requests, c.Driver, parameters)
+	}
+}
+
+// DisplayClaimMutations prints the fields of a claim that differ from what
+// it was created from, and the configuration its DeviceClasses add.
+func DisplayClaimMutations(m *types.ClaimMutations) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	switch {
+	case m.Source == "":
+		fmt.Fprintf(w, "No template or last applied configuration found for claim %s/%s.\n", m.Namespace, m.Name)
+	case len(m.Changes) == 0:
+		fmt.Fprintf(w, "Claim %s/%s matches its %s.\n", m.Namespace, m.Name, m.Source)
+	default:
+		if !Quiet {
+			fmt.Fprintf(w, "Claim %s/%s compared with its %s:\n\n", m.Namespace, m.Name, m.Source)
+		}
+		printHeader(w, "FIELD", "SOURCE", "STORED", "REASON")
+		for _, c := range m.Changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Path, valueOrNone(c.From), valueOrNone(c.To), c.Reason)
+		}
+	}
+
+	if len(m.ClassConfigs) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	if !Quiet {
+		fmt.Fprintln(w, "Configuration added by DeviceClasses:")
+		fmt.Fprintln(w)
+	}
+	printHeader(w, "REQUEST", "DEVICECLASS", "DRIVER", "PARAMETERS")
+	for _, c := range m.ClassConfigs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Request, c.DeviceClass, c.Driver, c.Parameters)
+	}
+}
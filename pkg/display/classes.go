@@ -0,0 +1,102 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// DisplayDeviceClass prints the selectors and configuration of a DeviceClass.
+func DisplayDeviceClass(class types.DeviceClassInfo) {
+	fmt.Printf("Name:       %s\n", class.Name)
+	printList("Selectors:", class.Selectors)
+	printList("Config:", class.Config)
+}
+
+// printList prints a labelled list, one value per line.
+func printList(label string, values []string) {
+	if len(values) == 0 {
+		fmt.Printf("%-11s <none>\n", label)
+		return
+	}
+	for i, value := range values {
+		if i == 0 {
+			fmt.Printf("%-11s %s\n", label, value)
+		} else {
+			fmt.Printf("%-11s %s\n", "", value)
+		}
+	}
+}
+
+// DisplayClassConsumers prints a DeviceClass and the claims and templates
+// referencing it.
+func DisplayClassConsumers(c *types.ClassConsumers) {
+	DisplayDeviceClass(c.Class)
+	printList("Templates:", c.Templates)
+	fmt.Println()
+
+	allocated, devices := 0, 0
+	for _, claim := range c.Claims {
+		if claim.Allocated {
+			allocated++
+		}
+		devices += claim.Devices
+	}
+	if !Quiet {
+		fmt.Printf("%d claim(s), %d allocated, holding %d device(s)\n\n", len(c.Claims), allocated, devices)
+	}
+	if len(c.Claims) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NAMESPACE", "CLAIM", "STATE", "DEVICES", "CONSUMERS", "OWNERS")
+	for _, claim := range c.Claims {
+		state := "pending"
+		if claim.Allocated {
+			state = "allocated"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			claim.Namespace,
+			claim.Name,
+			state,
+			formatInt(claim.Devices),
+			joinOrNone(claim.Consumers),
+			joinOrNone(claim.Owners),
+		)
+	}
+}
+
+// DisplayClassChange prints the devices and pending claims affected by a
+// DeviceClass change.
+func DisplayClassChange(change *types.ClassChange) {
+	fmt.Printf("Devices no longer matching %s (%d):\n", change.Class, len(change.Unmatched))
+	displayChangedDevices(change.Unmatched)
+	fmt.Printf("\nDevices newly matching %s (%d):\n", change.Class, len(change.Matched))
+	displayChangedDevices(change.Matched)
+	fmt.Printf("\nPending claims newly satisfiable (%d): %s\n", len(change.Satisfiable), joinOrNone(change.Satisfiable))
+}
+
+func displayChangedDevices(devices []types.DeviceInfo) {
+	if len(devices) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NODE", "DRIVER", "POOL", "DEVICE", "PRODUCT", "CLAIM")
+	for _, dev := range devices {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			valueOrNone(dev.NodeName),
+			dev.Driver,
+			dev.Pool,
+			dev.Name,
+			valueOrNone(dev.ProductName),
+			valueOrNone(dev.Claim),
+		)
+	}
+}
@@ -0,0 +1,79 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Column describes a column of the node table that can be selected with
+// Options.Columns.
+type Column struct {
+	// Name selects the column; matching is case-insensitive.
+	Name        string
+	Description string
+	header      string
+}
+
+// NodeColumns lists the columns of the node table, see defaultColumns for
+// the ones shown by default.
+var NodeColumns = []Column{
+	{Name: "NODE", Description: "node name, with its status when unschedulable nodes are excluded", header: "NODE"},
+	{Name: "ROLE", Description: "node role from the node-role.kubernetes.io labels", header: "ROLE"},
+	{Name: "NODEPOOL", Description: "EKS node group, GKE node pool, AKS agent pool or Karpenter NodePool", header: "NODEPOOL"},
+	{Name: "INSTANCE_TYPE", Description: "cloud instance type from node.kubernetes.io/instance-type", header: "INSTANCE-TYPE"},
+	{Name: "OS", Description: "operating system from kubernetes.io/os", header: "OS"},
+	{Name: "ARCH", Description: "CPU architecture from kubernetes.io/arch", header: "ARCH"},
+	{Name: "CPU", Description: "total and available CPU", header: "CPU(TOTAL/AVAIL)"},
+	{Name: "MEMORY", Description: "total and available memory in GiB", header: "MEMORY(TOTAL/AVAIL GiB)"},
+	{Name: "STORAGE", Description: "total and available ephemeral storage", header: "STORAGE(TOTAL/AVAIL)"},
+	{Name: "DEVICES", Description: "total and available devices per product", header: "DEVICES"},
+	{Name: "DEVICES_TOTAL", Description: "number of devices of all drivers", header: "DEVICES_TOTAL"},
+	{Name: "DEVICES_AVAIL", Description: "number of unallocated devices of all drivers", header: "DEVICES_AVAIL"},
+	{Name: "GPU_TOTAL", Description: "number of devices of gpu.* drivers", header: "GPU_TOTAL"},
+	{Name: "GPU_AVAIL", Description: "number of unallocated devices of gpu.* drivers", header: "GPU_AVAIL"},
+}
+
+// defaultColumns are shown when Options.Columns is empty.
+var defaultColumns = []string{"NODE", "ROLE", "CPU", "MEMORY", "STORAGE", "DEVICES"}
+
+// ParseColumns parses a comma-separated list of node table columns and
+// returns their canonical names.
+func ParseColumns(list string) ([]string, error) {
+	var columns []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := findColumn(name); !ok {
+			return nil, fmt.Errorf("unknown column %q, see --columns help", name)
+		}
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return columns, nil
+}
+
+func findColumn(name string) (Column, bool) {
+	for _, column := range NodeColumns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// DisplayColumns prints the selectable node table columns.
+func DisplayColumns() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "COLUMN", "DESCRIPTION")
+	for _, column := range NodeColumns {
+		fmt.Fprintf(w, "%s\t%s\n", column.Name, column.Description)
+	}
+}
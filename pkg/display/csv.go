@@ -0,0 +1,42 @@
+package display
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// DisplayCSV prints the node table as CSV with a header row, for
+// spreadsheets. The cells are those of the table, but numbers are always in
+// the plain format: -locale would put separators into them that spreadsheets
+// of other locales cannot parse.
+func DisplayCSV(nodeInfoList []*types.NodeInfo, opts Options) error {
+	return WriteCSV(os.Stdout, nodeInfoList, opts)
+}
+
+// WriteCSV writes the CSV of DisplayCSV to w.
+func WriteCSV(w io.Writer, nodeInfoList []*types.NodeInfo, opts Options) error {
+	nodeInfoList = filterNodes(nodeInfoList, opts)
+	opts.plainNumbers = true
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+
+	cw := csv.NewWriter(w)
+	if !NoHeaders {
+		cw.Write(columnHeaders(columns, opts.Resources))
+	}
+	for _, nodeInfo := range nodeInfoList {
+		row, _ := nodeRow(nodeInfo, columns, opts)
+		cw.Write(row)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// DisplayDevices prints one row per device, with the claims it is allocated
+// to and their consumers; devices allocated to several claims, or to claims
+// reserved for several consumers, are shown as shared. wideAttributes adds a
+// column for each of the given attributes, e.g. firmware versions.
+func DisplayDevices(devices []types.DeviceInfo, wideAttributes []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := []string{"NODE", "DRIVER", "POOL", "DEVICE", "PRODUCT", "STATE", "CLAIM", "CONSUMERS"}
+	for _, attr := range wideAttributes {
+		header = append(header, strings.ToUpper(attr))
+	}
+	printHeader(w, header...)
+
+	for _, dev := range devices {
+		state := "available"
+		switch {
+		case dev.Unhealthy:
+			state = "unhealthy"
+		case len(dev.SharedClaims) > 0:
+			state = fmt.Sprintf("shared (%d claims)", len(dev.SharedClaims)+1)
+		case len(dev.Consumers) > 1:
+			state = fmt.Sprintf("shared (%d consumers)", len(dev.Consumers))
+		case dev.Claim != "":
+			state = "allocated"
+		}
+		nodeName := valueOrNone(dev.NodeName)
+		if dev.Spot {
+			nodeName += spotMarker
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			nodeName,
+			dev.Driver,
+			dev.Pool,
+			dev.Name,
+			dev.ProductName,
+			state,
+			joinOrNone(deviceClaims(dev)),
+			joinOrNone(dev.Consumers),
+		)
+		for _, attr := range wideAttributes {
+			fmt.Fprintf(w, "\t%s", valueOrNone(dev.Attributes[attr]))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// deviceClaims returns the claims a device is allocated to.
+func deviceClaims(dev types.DeviceInfo) []string {
+	if dev.Claim == "" {
+		return nil
+	}
+	return append([]string{dev.Claim}, dev.SharedClaims...)
+}
+
+// DisplayProductInventory prints the cluster-wide device count of each
+// product.
+func DisplayProductInventory(inventory []types.ProductInventory) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "PRODUCT", "DRIVER", "NODES", "TOTAL", "ALLOCATED", "UNHEALTHY", "AVAILABLE")
+	for _, inv := range inventory {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			inv.ProductName,
+			inv.Driver,
+			formatInt(inv.Nodes),
+			formatInt(inv.TotalCount),
+			formatInt(inv.AllocatedCount),
+			formatInt(inv.UnhealthyCount),
+			formatInt(inv.AvailableCount),
+		)
+	}
+}
+
+// DisplayDeviceHistory prints the recorded states of a device, oldest first:
+// when each began, how long it lasted and the claim and pods holding the
+// device meanwhile.
+func DisplayDeviceHistory(history []types.DeviceState) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "SINCE", "DURATION", "NODE", "STATE", "CLAIM", "CONSUMERS")
+	for _, state := range history {
+		until, ongoing := state.Until, ""
+		if until.IsZero() {
+			until, ongoing = now(), " (current)"
+		}
+		status := "available"
+		switch {
+		case state.Unhealthy:
+			status = "unhealthy"
+		case state.Claim != "":
+			status = "allocated"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			state.Since.Local().Format(time.RFC3339),
+			duration.HumanDuration(until.Sub(state.Since))+ongoing,
+			valueOrNone(state.NodeName),
+			status,
+			valueOrNone(state.Claim),
+			joinOrNone(state.Consumers),
+		)
+	}
+}
+
+// DisplayDeviceDescription prints a device, its attributes and, if they were
+// collected, the recent log lines of its driver.
+func DisplayDeviceDescription(d types.DeviceDescription) {
+	state := "available"
+	switch {
+	case d.Unhealthy:
+		state = "unhealthy"
+	case len(d.SharedClaims) > 0:
+		state = "shared"
+	case d.Claim != "":
+		state = "allocated"
+	}
+	fmt.Printf("Device:     %s/%s/%s\n", d.Driver, d.Pool, d.Name)
+	fmt.Printf("Node:       %s\n", valueOrNone(d.NodeName))
+	fmt.Printf("Product:    %s\n", valueOrNone(d.ProductName))
+	fmt.Printf("State:      %s\n", state)
+	fmt.Printf("Claim:      %s\n", joinOrNone(deviceClaims(d.DeviceInfo)))
+	if d.Request != "" {
+		fmt.Printf("Request:    %s\n", d.Request)
+	}
+	attributes := make([]string, 0, len(d.Attributes))
+	for name, value := range d.Attributes {
+		attributes = append(attributes, name+"="+value)
+	}
+	sort.Strings(attributes)
+	printList("Attributes:", attributes)
+
+	if d.DriverLogs == nil {
+		return
+	}
+	fmt.Printf("\nDriver logs (%s/%s, container %s):\n", d.DriverLogs.Namespace, d.DriverLogs.Pod, d.DriverLogs.Container)
+	if len(d.DriverLogs.Lines) == 0 {
+		fmt.Println("  <none>")
+	}
+	for _, line := range d.DriverLogs.Lines {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
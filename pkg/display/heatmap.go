@@ -0,0 +1,108 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// heatLevels are the cells of the heatmap from idle to busy, see heatLevel.
+var heatLevels = []struct {
+	glyph string
+	color string // ANSI color code
+	label string
+}{
+	{"·", "90", "0%"},
+	{"░", "32", "<25%"},
+	{"▒", "33", "<50%"},
+	{"▓", "31", "<75%"},
+	{"█", "91", "≥75%"},
+}
+
+// noMetricGlyph marks nodes without a utilization metric.
+const noMetricGlyph = "?"
+
+// DisplayHeatmap prints one cell per node, shaded by the percentage of its
+// devices allocated or, with utilization set, by their utilization. Nodes are
+// laid out by name, width cells per row, each row labelled with its first
+// node. With color set, cells are also colored with ANSI escape codes.
+func DisplayHeatmap(usage []types.NodeUsage, utilization bool, width int, color bool) {
+	writeHeatmap(os.Stdout, usage, utilization, width, color)
+}
+
+func writeHeatmap(w io.Writer, usage []types.NodeUsage, utilization bool, width int, color bool) {
+	nodes := make([]types.NodeUsage, len(usage))
+	copy(nodes, usage)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeName < nodes[j].NodeName })
+	if width <= 0 {
+		width = len(nodes)
+	}
+
+	labelWidth := 0
+	for i := 0; i < len(nodes); i += width {
+		labelWidth = max(labelWidth, len(nodes[i].NodeName))
+	}
+	for i := 0; i < len(nodes); i += width {
+		var row strings.Builder
+		for _, node := range nodes[i:min(i+width, len(nodes))] {
+			value := &node.AllocatedPercent
+			if utilization {
+				value = node.Utilization
+			}
+			row.WriteString(heatCell(value, color))
+		}
+		fmt.Fprintf(w, "%-*s  %s\n", labelWidth, nodes[i].NodeName, row.String())
+	}
+
+	if NoHeaders {
+		return
+	}
+	var legend []string
+	for i := range heatLevels {
+		legend = append(legend, paintHeatLevel(i, color)+" "+heatLevels[i].label)
+	}
+	if utilization {
+		legend = append(legend, noMetricGlyph+" no metrics")
+	}
+	metric := "devices allocated"
+	if utilization {
+		metric = "device utilization"
+	}
+	fmt.Fprintf(w, "\n%s nodes, %s: %s\n", formatInt(len(nodes)), metric, strings.Join(legend, "  "))
+}
+
+// heatCell renders a percentage as a heatmap cell, or noMetricGlyph for nil.
+func heatCell(value *float64, color bool) string {
+	if value == nil {
+		return noMetricGlyph
+	}
+	return paintHeatLevel(heatLevel(*value), color)
+}
+
+// heatLevel returns the index into heatLevels of a percentage: idle, then
+// one level per quarter.
+func heatLevel(value float64) int {
+	switch {
+	case value <= 0:
+		return 0
+	case value < 25:
+		return 1
+	case value < 50:
+		return 2
+	case value < 75:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func paintHeatLevel(level int, color bool) string {
+	if !color {
+		return heatLevels[level].glyph
+	}
+	return "\033[" + heatLevels[level].color + "m" + heatLevels[level].glyph + "\033[0m"
+}
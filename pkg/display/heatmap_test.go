@@ -0,0 +1,49 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteHeatmap(t *testing.T) {
+	util := func(v float64) *float64 { return &v }
+	usage := []types.NodeUsage{
+		{NodeName: "node-3", AllocatedPercent: 100, Utilization: util(80)},
+		{NodeName: "node-1", AllocatedPercent: 0},
+		{NodeName: "node-2", AllocatedPercent: 12.5, Utilization: util(30)},
+		{NodeName: "node-4", AllocatedPercent: 50, Utilization: util(0)},
+		{NodeName: "node-5", AllocatedPercent: 74.9},
+	}
+
+	tests := []struct {
+		name        string
+		utilization bool
+		want        string
+	}{
+		{
+			name: "allocation",
+			want: "node-1  ·░█\nnode-4  ▓▓\n\n5 nodes, devices allocated: · 0%  ░ <25%  ▒ <50%  ▓ <75%  █ ≥75%\n",
+		},
+		{
+			name:        "utilization",
+			utilization: true,
+			want:        "node-1  ?▒█\nnode-4  ·?\n\n5 nodes, device utilization: · 0%  ░ <25%  ▒ <50%  ▓ <75%  █ ≥75%  ? no metrics\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeHeatmap(&buf, usage, tt.utilization, 3, false)
+			if diff := cmp.Diff(buf.String(), tt.want); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+
+	if got, want := heatCell(util(60), true), "\033[31m▓\033[0m"; got != want {
+		t.Errorf("heatCell(60, color) = %q, want %q", got, want)
+	}
+}
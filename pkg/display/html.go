@@ -0,0 +1,142 @@
+package display
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateText))
+
+// htmlReport is the data of the HTML report template.
+type htmlReport struct {
+	Title     string
+	Generated string
+	Summary   []htmlSummaryItem
+	Products  []htmlProduct
+	Charts    bool
+	Headers   []string
+	Rows      [][]string
+}
+
+type htmlSummaryItem struct {
+	Label string
+	Value string
+}
+
+type htmlProduct struct {
+	Name      string
+	Total     string
+	Available string
+	Allocated string
+	// Percent is the percentage of devices allocated, for the chart.
+	Percent float64
+}
+
+// DisplayHTMLReport prints a standalone HTML page with a summary of the
+// cluster's devices, their availability per product and the node table, for
+// reports sent by email. With charts set, the availability per product is
+// also drawn as bars. The page has no external resources.
+func DisplayHTMLReport(nodeInfoList []*types.NodeInfo, opts Options, title string, charts bool) error {
+	return WriteHTMLReport(os.Stdout, nodeInfoList, opts, title, charts)
+}
+
+// WriteHTMLReport writes the page of DisplayHTMLReport to w, e.g. to attach
+// it to an email.
+func WriteHTMLReport(w io.Writer, nodeInfoList []*types.NodeInfo, opts Options, title string, charts bool) error {
+	nodeInfoList = filterNodes(nodeInfoList, opts)
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+	report := htmlReport{
+		Title:     title,
+		Generated: now().UTC().Format(time.RFC1123),
+		Charts:    charts,
+		Headers:   columnHeaders(columns, opts.Resources),
+	}
+
+	var nodesWithDevices, total, available, unhealthy int
+	products := make(map[string]*types.ProductAvailability)
+	for _, nodeInfo := range nodeInfoList {
+		row, _ := nodeRow(nodeInfo, columns, opts)
+		report.Rows = append(report.Rows, row)
+
+		if len(nodeInfo.Devices) > 0 {
+			nodesWithDevices++
+		}
+		excluded := opts.ExcludeUnschedulable && !nodeInfo.Schedulable()
+		for _, dev := range nodeInfo.Devices {
+			p, ok := products[dev.ProductName]
+			if !ok {
+				p = &types.ProductAvailability{ProductName: dev.ProductName}
+				products[dev.ProductName] = p
+			}
+			p.TotalCount += dev.TotalCount
+			total += dev.TotalCount
+			unhealthy += dev.UnhealthyCount
+			if !excluded {
+				p.AvailableCount += dev.AvailableCount
+				available += dev.AvailableCount
+			}
+		}
+	}
+
+	report.Summary = []htmlSummaryItem{
+		{"Nodes", formatInt(len(nodeInfoList))},
+		{"Nodes with devices", formatInt(nodesWithDevices)},
+		{"Devices", formatInt(total)},
+		{"Available devices", formatInt(available)},
+		{"Allocated", formatPercent(total-available, total)},
+	}
+	if unhealthy > 0 {
+		report.Summary = append(report.Summary, htmlSummaryItem{"Unhealthy devices", formatInt(unhealthy)})
+	}
+	if strandedNodes, strandedDevices := analyze.StrandedSummary(nodeInfoList); strandedNodes > 0 {
+		report.Summary = append(report.Summary, htmlSummaryItem{"Stranded devices", fmt.Sprintf("%s on %s nodes out of CPU or memory", formatInt(strandedDevices), formatInt(strandedNodes))})
+	}
+
+	names := make([]string, 0, len(products))
+	for name := range products {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := products[name]
+		allocated := p.TotalCount - p.AvailableCount
+		percent := 0.0
+		if p.TotalCount > 0 {
+			percent = 100 * float64(allocated) / float64(p.TotalCount)
+		}
+		report.Products = append(report.Products, htmlProduct{
+			Name:      name,
+			Total:     formatInt(p.TotalCount),
+			Available: formatInt(p.AvailableCount),
+			Allocated: formatPercent(allocated, p.TotalCount),
+			Percent:   percent,
+		})
+	}
+
+	if err := reportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+// formatPercent formats part as a percentage of whole.
+func formatPercent(part, whole int) string {
+	if whole == 0 {
+		return "-"
+	}
+	return formatFloat(100*float64(part)/float64(whole), 0) + "%"
+}
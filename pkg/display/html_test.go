@@ -0,0 +1,79 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	now = func() time.Time { return time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	nodes := []*types.NodeInfo{
+		{
+			NodeName: "node-1",
+			NodeRole: "worker",
+			Devices:  []types.Device{{ProductName: "NVIDIA A100", TotalCount: 4, AvailableCount: 1, UnhealthyCount: 1}},
+		},
+		{
+			NodeName:      "<cordoned>",
+			NodeRole:      "worker",
+			Unschedulable: true,
+			Devices:       []types.Device{{ProductName: "NVIDIA A100", TotalCount: 4, AvailableCount: 4}},
+		},
+		{NodeName: "cpu-only", NodeRole: "worker"},
+	}
+	opts := Options{ExcludeUnschedulable: true, Columns: []string{"NODE", "DEVICES"}}
+
+	tests := []struct {
+		name    string
+		charts  bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "tables",
+			want: []string{
+				"<title>Weekly capacity</title>",
+				"Generated Mon, 02 Jun 2025 07:00:00 UTC",
+				"<tr><td>Nodes</td><td class=\"number\">3</td></tr>",
+				"<tr><td>Nodes with devices</td><td class=\"number\">2</td></tr>",
+				"<tr><td>Available devices</td><td class=\"number\">1</td></tr>",
+				"<tr><td>Allocated</td><td class=\"number\">88%</td></tr>",
+				"<tr><td>Unhealthy devices</td><td class=\"number\">1</td></tr>",
+				"<tr><td>NVIDIA A100</td><td class=\"number\">8</td><td class=\"number\">1</td><td class=\"number\">88%</td></tr>",
+				"<tr><th>NODE</th><th>DEVICES</th></tr>",
+				"<tr><td>&lt;cordoned&gt; (SchedulingDisabled)</td><td>NVIDIA A100: 4 total, 0 available</td></tr>",
+			},
+			notWant: []string{"<cordoned>", "class=\"bar\""},
+		},
+		{
+			name:   "charts",
+			charts: true,
+			want:   []string{"<div class=\"bar\"><div style=\"width: 87.5%\"></div></div>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteHTMLReport(&buf, nodes, opts, "Weekly capacity", tt.charts); err != nil {
+				t.Fatalf("WriteHTMLReport() error = %v", err)
+			}
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("report does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("report contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// DisplayAttributeInventory prints the distribution of inventoried device
+// attributes, followed by the nodes running outdated versions.
+func DisplayAttributeInventory(inventory []types.AttributeInventory) {
+	perProduct := false
+	for _, inv := range inventory {
+		perProduct = perProduct || inv.ProductName != ""
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"DRIVER", "ATTRIBUTE", "VALUE", "DEVICES", "NODES"}
+	if perProduct {
+		header = append([]string{"PRODUCT"}, header...)
+	}
+	printHeader(w, header...)
+	var outdated []string
+	for _, inv := range inventory {
+		for _, v := range inv.Values {
+			if perProduct {
+				fmt.Fprintf(w, "%s\t", inv.ProductName)
+			}
+			value := v.Value
+			if v.Outdated {
+				value += " (outdated)"
+				for _, node := range v.Nodes {
+					outdated = append(outdated, fmt.Sprintf("%s (%s %s=%s)", node, inv.Driver, inv.Attribute, v.Value))
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", inv.Driver, inv.Attribute, value, formatInt(v.Devices), formatInt(len(v.Nodes)))
+		}
+	}
+	w.Flush()
+
+	if len(outdated) > 0 {
+		fmt.Printf("\nNodes running outdated versions:\n  %s\n", strings.Join(outdated, "\n  "))
+	}
+}
@@ -0,0 +1,14 @@
+package display
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// DisplayJSON prints v as indented JSON. The structure of every value printed
+// this way is described by a schema published in pkg/schema.
+func DisplayJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,82 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// numberPrinter formats numbers in tables; nil keeps the plain, locale
+// independent format. JSON output is never affected.
+var numberPrinter *message.Printer
+
+// SetLocale enables thousands separators and the decimal mark of the given
+// BCP 47 language tag, e.g. "en-US" or "de-DE", in table output. "auto" uses
+// the locale of the environment (LC_ALL, LC_NUMERIC or LANG), and "" restores
+// the plain format.
+func SetLocale(locale string) error {
+	if locale == "auto" {
+		locale = environmentLocale()
+	}
+	if locale == "" {
+		numberPrinter = nil
+		return nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return fmt.Errorf("invalid locale %q: %w", locale, err)
+	}
+	numberPrinter = message.NewPrinter(tag)
+	return nil
+}
+
+// environmentLocale converts a POSIX locale such as "de_DE.UTF-8" into a
+// language tag. The C and POSIX locales map to the plain format.
+func environmentLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if value == "C" || value == "POSIX" || strings.HasPrefix(value, "C.") {
+			return ""
+		}
+		value, _, _ = strings.Cut(value, ".")
+		value, _, _ = strings.Cut(value, "@")
+		return strings.ReplaceAll(value, "_", "-")
+	}
+	return ""
+}
+
+// numberFormat formats numbers with printer, or in the plain format when it
+// is nil.
+type numberFormat struct {
+	printer *message.Printer
+}
+
+// formatInt formats a count for display.
+func formatInt(n int) string {
+	return numberFormat{numberPrinter}.formatInt(n)
+}
+
+// formatFloat formats v with the given number of decimals for display.
+func formatFloat(v float64, decimals int) string {
+	return numberFormat{numberPrinter}.formatFloat(v, decimals)
+}
+
+func (f numberFormat) formatInt(n int) string {
+	if f.printer == nil {
+		return fmt.Sprint(n)
+	}
+	return f.printer.Sprintf("%d", n)
+}
+
+func (f numberFormat) formatFloat(v float64, decimals int) string {
+	if f.printer == nil {
+		return fmt.Sprintf("%.*f", decimals, v)
+	}
+	return f.printer.Sprintf("%.*f", decimals, v)
+}
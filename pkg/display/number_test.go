@@ -0,0 +1,50 @@
+package display
+
+import "testing"
+
+func TestNumberFormatting(t *testing.T) {
+	t.Cleanup(func() { numberPrinter = nil })
+
+	tests := []struct {
+		locale    string
+		wantInt   string
+		wantFloat string
+	}{
+		{locale: "", wantInt: "1234567", wantFloat: "2048.50"},
+		{locale: "en-US", wantInt: "1,234,567", wantFloat: "2,048.50"},
+		{locale: "de-DE", wantInt: "1.234.567", wantFloat: "2.048,50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			if err := SetLocale(tt.locale); err != nil {
+				t.Fatalf("SetLocale(%q) failed: %v", tt.locale, err)
+			}
+			if got := formatInt(1234567); got != tt.wantInt {
+				t.Errorf("formatInt() = %q, want %q", got, tt.wantInt)
+			}
+			if got := formatFloat(2048.5, 2); got != tt.wantFloat {
+				t.Errorf("formatFloat() = %q, want %q", got, tt.wantFloat)
+			}
+		})
+	}
+}
+
+func TestEnvironmentLocale(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		want        string
+	}{
+		{lang: "de_DE.UTF-8", want: "de-DE"},
+		{lcAll: "fr_FR@euro", lang: "de_DE.UTF-8", want: "fr-FR"},
+		{lang: "C.UTF-8", want: ""},
+		{want: ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_NUMERIC", "")
+		t.Setenv("LANG", tt.lang)
+		if got := environmentLocale(); got != tt.want {
+			t.Errorf("environmentLocale() with LC_ALL=%q LANG=%q = %q, want %q", tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var (
+	// Quiet suppresses status lines such as "Fetching node and resource
+	// info...", leaving only the tables.
+	Quiet bool
+	// NoHeaders omits the header row and legends of tables so the output can
+	// be piped into awk or cut.
+	NoHeaders bool
+	// Timestamps shows creation times as RFC 3339 timestamps instead of
+	// relative ages, e.g. for audits.
+	Timestamps bool
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// printHeader writes the tab-separated header row unless NoHeaders is set.
+func printHeader(w io.Writer, columns ...string) {
+	if NoHeaders {
+		return
+	}
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+}
+
+// ageHeader is the header of creation time columns.
+func ageHeader() string {
+	if Timestamps {
+		return "CREATED"
+	}
+	return "AGE"
+}
+
+// formatAge renders a creation time as a kubectl-style relative age such as
+// "5m" or "3d2h", or as an RFC 3339 timestamp if Timestamps is set.
+func formatAge(created time.Time) string {
+	if created.IsZero() {
+		return "<unknown>"
+	}
+	if Timestamps {
+		return created.UTC().Format(time.RFC3339)
+	}
+	return duration.HumanDuration(now().Sub(created))
+}
+
+// formatExpiry renders an expiry time relative to now, e.g. "in 3d" or "5h
+// ago", or as an RFC 3339 timestamp if Timestamps is set.
+func formatExpiry(expires time.Time) string {
+	if expires.IsZero() {
+		return "<none>"
+	}
+	if Timestamps {
+		return expires.UTC().Format(time.RFC3339)
+	}
+	if d := expires.Sub(now()); d > 0 {
+		return "in " + duration.HumanDuration(d)
+	}
+	return duration.HumanDuration(now().Sub(expires)) + " ago"
+}
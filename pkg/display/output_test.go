@@ -0,0 +1,59 @@
+package display
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatAge(t *testing.T) {
+	current := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() {
+		now = time.Now
+		Timestamps = false
+	})
+
+	tests := []struct {
+		created    time.Time
+		timestamps bool
+		want       string
+	}{
+		{created: current.Add(-90 * time.Second), want: "90s"},
+		{created: current.Add(-26 * time.Hour), want: "26h"},
+		{created: current.Add(-50 * time.Hour), want: "2d2h"},
+		{created: current.Add(-50 * time.Hour), timestamps: true, want: "2025-05-30T10:00:00Z"},
+		{want: "<unknown>"},
+	}
+	for _, tt := range tests {
+		Timestamps = tt.timestamps
+		if got := formatAge(tt.created); got != tt.want {
+			t.Errorf("formatAge(%v) with Timestamps=%v = %q, want %q", tt.created, tt.timestamps, got, tt.want)
+		}
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	current := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() {
+		now = time.Now
+		Timestamps = false
+	})
+
+	tests := []struct {
+		expires    time.Time
+		timestamps bool
+		want       string
+	}{
+		{expires: current.Add(50 * time.Hour), want: "in 2d2h"},
+		{expires: current.Add(-5 * time.Hour), want: "5h ago"},
+		{expires: current.Add(50 * time.Hour), timestamps: true, want: "2025-06-03T14:00:00Z"},
+		{want: "<none>"},
+	}
+	for _, tt := range tests {
+		Timestamps = tt.timestamps
+		if got := formatExpiry(tt.expires); got != tt.want {
+			t.Errorf("formatExpiry(%v) with Timestamps=%v = %q, want %q", tt.expires, tt.timestamps, got, tt.want)
+		}
+	}
+}
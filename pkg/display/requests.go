@@ -0,0 +1,58 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// DisplayRequestPlan prints the API requests a command would make and the
+// objects each returns, followed by their total.
+func DisplayRequestPlan(requests []types.PlannedRequest) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	printHeader(w, "VERB", "RESOURCE", "NAMESPACE", "NAME", "LABEL SELECTOR", "FIELD SELECTOR", "PAGE SIZE", "OBJECTS", "CALLS")
+	var objects, calls int64
+	unknown := 0
+	for _, r := range requests {
+		namespace := r.Namespace
+		if namespace == "" && r.Name == "" {
+			namespace = "<all>"
+		}
+		pageSize, count, pages := "<none>", "?", "?"
+		if r.Limit > 0 {
+			pageSize = strconv.FormatInt(r.Limit, 10)
+		}
+		if r.Estimated {
+			count, pages = formatInt(int(r.Objects)), formatInt(int(r.Pages()))
+			objects += r.Objects
+			calls += r.Pages()
+		} else {
+			unknown++
+			calls++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Verb,
+			r.Resource,
+			valueOrNone(namespace),
+			valueOrNone(r.Name),
+			valueOrNone(r.LabelSelector),
+			valueOrNone(r.FieldSelector),
+			pageSize,
+			count,
+			pages,
+		)
+	}
+	w.Flush()
+
+	if Quiet {
+		return
+	}
+	fmt.Printf("\n%d requests in at least %s calls, returning about %s objects", len(requests), formatInt(int(calls)), formatInt(int(objects)))
+	if unknown > 0 {
+		fmt.Printf(" plus those of %d requests that could not be estimated", unknown)
+	}
+	fmt.Println(".")
+}
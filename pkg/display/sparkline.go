@@ -0,0 +1,48 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// sparkBlocks are the bars of a sparkline, from empty to full.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders fractions between 0 and 1 as a line of bars, with a
+// space for negative values, which mark missing data.
+func sparkline(values []float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		if v < 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		i := int(math.Round(min(v, 1) * float64(len(sparkBlocks)-1)))
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// DisplayAllocationTrends prints the recorded allocation of each node and
+// product as a sparkline, next to the current allocation.
+func DisplayAllocationTrends(trends []types.AllocationTrend, period time.Duration) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	printHeader(w, "NODE", "PRODUCT", "ALLOCATED", "TREND("+duration.ShortHumanDuration(period)+")")
+	for _, trend := range trends {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\n",
+			trend.NodeName,
+			trend.ProductName,
+			formatInt(trend.Allocated), formatInt(trend.Total),
+			sparkline(trend.History),
+		)
+	}
+}
@@ -0,0 +1,19 @@
+package display
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{values: []float64{0, 0.25, 0.5, 0.75, 1}, want: "▁▃▅▆█"},
+		{values: []float64{-1, -1, 0, 1.5}, want: "  ▁█"},
+		{want: ""},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
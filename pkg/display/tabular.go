@@ -3,66 +3,420 @@ package display
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/dharmjit/k8s-dra-resources/pkg/aggregate"
+	"github.com/dharmjit/k8s-dra-resources/pkg/analyze"
 	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func formatMemoryAsGiB(q resource.Quantity) string {
+	return numberFormat{numberPrinter}.formatMemoryAsGiB(q)
+}
+
+func (f numberFormat) formatMemoryAsGiB(q resource.Quantity) string {
 	val, ok := q.AsInt64()
 	if !ok {
 		// Fallback for very large values that don't fit in int64
 		return q.String()
 	}
 	gib := float64(val) / (1024 * 1024 * 1024)
-	return fmt.Sprintf("%.2fGi", gib)
+	return f.formatFloat(gib, 2) + "Gi"
 }
 
-func DisplayTabularInfo(client resourceClient.ResourceClient) error {
-	ctx := context.Background()
-	fmt.Println("Fetching node and resource info...")
+// overcommitMarker flags available values that were clamped to zero because
+// pod requests exceed the node's allocatable resources.
+const overcommitMarker = "!"
 
-	nodeInfoList, err := client.GetK8sResources(ctx)
+// clampAvailable returns q, or zero if q is negative, and whether it clamped.
+func clampAvailable(q resource.Quantity) (resource.Quantity, bool) {
+	if q.Sign() < 0 {
+		return *resource.NewQuantity(0, q.Format), true
+	}
+	return q, false
+}
+
+// spotMarker follows the names of nodes on spot or preemptible capacity.
+const spotMarker = " (spot)"
+
+// Options controls how the tabular output is rendered.
+type Options struct {
+	// CapacityKeys lists the device capacity names shown next to the product
+	// name. A single "all" entry shows every capacity the driver publishes.
+	CapacityKeys []string
+	// ShowPools prints a per-pool availability table after the node table.
+	ShowPools bool
+	// ExcludeUnschedulable counts devices on cordoned or NotReady nodes as
+	// unavailable, matching what the scheduler can actually place.
+	ExcludeUnschedulable bool
+	// ExcludeSpot hides nodes on interruptible spot or preemptible capacity.
+	ExcludeSpot bool
+	// OS only shows nodes running this operating system, e.g. linux, if set.
+	OS string
+	// Arch only shows nodes of this CPU architecture, e.g. arm64, if set.
+	Arch string
+	// Columns selects and orders the columns of the node table, see
+	// NodeColumns. Empty shows the default columns.
+	Columns []string
+	// Resources adds a total/available column for each of these node
+	// resources after Columns, e.g. hugepages-2Mi or ephemeral-storage.
+	Resources []string
+	// CompressAbove prints one row per node shape instead of one per node
+	// when more nodes than this are shown, if set.
+	CompressAbove int
+	// NodeSelector is a label selector passed to the API server so only the
+	// matching nodes are fetched, if set.
+	NodeSelector string
+	// plainNumbers ignores the locale set by SetLocale, for CSV cells.
+	plainNumbers bool
+}
+
+// numbers returns the format of the numbers in node table cells.
+func (o Options) numbers() numberFormat {
+	if o.plainNumbers {
+		return numberFormat{}
+	}
+	return numberFormat{numberPrinter}
+}
+
+// filterNodes leaves out the nodes hidden by the options.
+func filterNodes(nodeInfoList []*types.NodeInfo, opts Options) []*types.NodeInfo {
+	if opts.ExcludeSpot {
+		nodeInfoList = analyze.ExcludeSpot(nodeInfoList)
+	}
+	if opts.OS != "" {
+		nodeInfoList = analyze.FilterOS(nodeInfoList, opts.OS)
+	}
+	if opts.Arch != "" {
+		nodeInfoList = analyze.FilterArch(nodeInfoList, opts.Arch)
+	}
+	return nodeInfoList
+}
+
+// nodeStatus renders the kubectl-style status suffix of a node name.
+func nodeStatus(nodeInfo *types.NodeInfo) string {
+	var status []string
+	if nodeInfo.NotReady {
+		status = append(status, "NotReady")
+	}
+	if nodeInfo.Unschedulable {
+		status = append(status, "SchedulingDisabled")
+	}
+	if len(status) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(status, ",") + ")"
+}
+
+// formatCapacity renders the selected capacity entries of a device. Memory
+// keeps its historical "+<n>Gi" form; other entries are shown as "+name=value".
+// Keys qualified with the driver's domain, e.g. "gpu.nvidia.com/memory", are
+// normalized like the collected capacity names.
+func formatCapacity(driver string, capacity map[string]resource.Quantity, keys []string, nf numberFormat) string {
+	if len(keys) == 1 && keys[0] == "all" {
+		keys = make([]string, 0, len(capacity))
+		for name := range capacity {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+	}
+
+	var b strings.Builder
+	shown := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		name := aggregate.NormalizeName(driver, model.QualifiedName(key))
+		if shown[name] {
+			continue
+		}
+		shown[name] = true
+		q, ok := capacity[name]
+		if !ok || q.IsZero() {
+			continue
+		}
+		if name == "memory" {
+			b.WriteString("+" + nf.formatMemoryAsGiB(q))
+			continue
+		}
+		b.WriteString("+" + name + "=" + q.String())
+	}
+	return b.String()
+}
+
+// formatDevices renders the devices column. Excluded nodes show no
+// available devices.
+func formatDevices(devices []types.Device, excluded bool, opts Options) string {
+	if len(devices) == 0 {
+		return "None"
+	}
+	nf := opts.numbers()
+	var parts []string
+	for _, dev := range devices {
+		available := dev.AvailableCount
+		if excluded {
+			available = 0
+		}
+		deviceAndCapacityName := dev.ProductName + formatCapacity(dev.Driver, dev.Capacity, opts.CapacityKeys, nf)
+		part := fmt.Sprintf("%s: %s total, %s available", deviceAndCapacityName, nf.formatInt(dev.TotalCount), nf.formatInt(available))
+		if dev.UnhealthyCount > 0 {
+			part += fmt.Sprintf(", %s unhealthy", nf.formatInt(dev.UnhealthyCount))
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DisplayTabularInfo fetches the node information and prints the node table.
+func DisplayTabularInfo(client resourceClient.ResourceClient, opts Options) error {
+	return DisplayTabularInfoContext(context.Background(), client, opts)
+}
+
+// DisplayTabularInfoContext is like DisplayTabularInfo but stops fetching
+// when ctx is canceled.
+func DisplayTabularInfoContext(ctx context.Context, client resourceClient.ResourceClient, opts Options) error {
+	if !Quiet {
+		fmt.Println("Fetching node and resource info...")
+	}
+
+	nodeInfoList, err := client.GetK8sResources(ctx, resourceClient.ListOptions{NodeSelector: opts.NodeSelector})
 	if err != nil {
 		return err
 	}
 
+	DisplayNodes(nodeInfoList, opts)
+	return nil
+}
+
+// DisplayNodes prints the node table for already fetched node information.
+func DisplayNodes(nodeInfoList []*types.NodeInfo, opts Options) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
-	// Header for the new format
-	fmt.Fprintln(w, "NODE\tROLE\tCPU(TOTAL/AVAIL)\tMEMORY(TOTAL/AVAIL GiB)\tSTORAGE(TOTAL/AVAIL)\tDEVICES")
+	nodeInfoList = filterNodes(nodeInfoList, opts)
+	if opts.CompressAbove > 0 && len(nodeInfoList) > opts.CompressAbove {
+		groups := analyze.GroupByShape(nodeInfoList, opts.ExcludeUnschedulable)
+		DisplayNodeGroups("shape", groups, opts)
+		if !NoHeaders {
+			fmt.Printf("\n%s nodes compressed into %s rows of nodes with the same role, instance type and devices; -compress-above 0 lists every node.\n",
+				formatInt(len(nodeInfoList)), formatInt(len(groups)))
+			printStranded(os.Stdout, nodeInfoList)
+		}
+		return
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+	printHeader(w, columnHeaders(columns, opts.Resources)...)
 
+	var overcommitted bool
 	for _, nodeInfo := range nodeInfoList {
-		// Create a string for the devices column
-		var deviceString string
-		if len(nodeInfo.Devices) == 0 {
-			deviceString = "None"
-		} else {
-			var parts []string
-			for _, dev := range nodeInfo.Devices {
-				deviceAndMemoryName := dev.ProductName
-				if !dev.Memory.IsZero() {
-					deviceAndMemoryName += "+" + formatMemoryAsGiB(dev.Memory)
+		row, clamped := nodeRow(nodeInfo, columns, opts)
+		overcommitted = overcommitted || clamped
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	if !NoHeaders {
+		printOvercommitLegend(w, overcommitted)
+		printStranded(w, nodeInfoList)
+	}
+
+	if opts.ShowPools {
+		if !NoHeaders {
+			fmt.Fprintln(w)
+		}
+		printHeader(w, "NODE", "DRIVER", "POOL", "GENERATION", "SLICES", "DEVICES(TOTAL/AVAIL)", ageHeader())
+		for _, nodeInfo := range nodeInfoList {
+			excluded := opts.ExcludeUnschedulable && !nodeInfo.Schedulable()
+			for _, pool := range nodeInfo.Pools {
+				available := pool.AvailableCount
+				if excluded {
+					available = 0
+				}
+				slices := fmt.Sprintf("%d/%d", pool.ObservedSliceCount, pool.ResourceSliceCount)
+				if !pool.Complete() {
+					slices += " (incomplete)"
 				}
-				parts = append(parts, fmt.Sprintf("%s: %d total, %d available", deviceAndMemoryName, dev.TotalCount, dev.AvailableCount))
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s/%s\t%s\n",
+					nodeInfo.NodeName,
+					pool.Driver,
+					pool.Name,
+					pool.Generation,
+					slices,
+					formatInt(pool.TotalCount), formatInt(available),
+					formatAge(pool.Created),
+				)
 			}
-			deviceString = strings.Join(parts, "; ")
 		}
+	}
+}
 
-		// Print the main row for the node
+// printOvercommitLegend explains overcommitMarker if a row shows it.
+func printOvercommitLegend(w io.Writer, overcommitted bool) {
+	if overcommitted {
+		fmt.Fprintf(w, "\n%s requested resources exceed allocatable (overcommit or accounting mismatch)\n", overcommitMarker)
+	}
+}
+
+// printStranded prints how many free devices are stranded on nodes without
+// CPU or memory left, if any.
+func printStranded(w io.Writer, nodeInfoList []*types.NodeInfo) {
+	strandedNodes, strandedDevices := analyze.StrandedSummary(nodeInfoList)
+	if strandedNodes > 0 {
+		fmt.Fprintf(w, "\n%s free devices are stranded on %s nodes without CPU or memory left for pods to use them\n",
+			formatInt(strandedDevices), formatInt(strandedNodes))
+	}
+}
+
+// DisplayNodeFeatures prints the software versions and PCI devices a node
+// reports through feature discovery labels, following the node table.
+func DisplayNodeFeatures(features []types.NodeFeature) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if !NoHeaders {
+		fmt.Fprintln(w)
+	}
+	printHeader(w, "FEATURE", "VALUE", "LABEL")
+	for _, feature := range features {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", feature.Name, feature.Value, feature.Label)
+	}
+}
+
+// columnHeaders returns the headers of the node table columns, followed by
+// those of the node resources.
+func columnHeaders(columns []string, resources []string) []string {
+	headers := make([]string, 0, len(columns)+len(resources))
+	for _, name := range columns {
+		column, _ := findColumn(name)
+		headers = append(headers, column.header)
+	}
+	for _, name := range resources {
+		headers = append(headers, strings.ToUpper(name)+"(TOTAL/AVAIL)")
+	}
+	return headers
+}
+
+// nodeRow returns the cells of a node in the given columns, and whether any
+// of them shows a resource clamped because requests exceed allocatable.
+func nodeRow(nodeInfo *types.NodeInfo, columns []string, opts Options) ([]string, bool) {
+	nodeName := nodeInfo.NodeName
+	if nodeInfo.Spot {
+		nodeName += spotMarker
+	}
+	excluded := opts.ExcludeUnschedulable && !nodeInfo.Schedulable()
+	if opts.ExcludeUnschedulable {
+		nodeName += nodeStatus(nodeInfo)
+	}
+
+	deviceString := formatDevices(nodeInfo.Devices, excluded, opts)
+	if len(nodeInfo.Devices) == 0 && nodeInfo.OS != "" && nodeInfo.OS != "linux" {
+		// DRA drivers only run on Linux nodes
+		deviceString += " (" + nodeInfo.OS + ")"
+	}
+
+	// Requests exceeding allocatable would otherwise print as negative
+	// quantities such as "-2Gi"; show 0 with a marker instead.
+	availableCPU, cpuClamped := clampAvailable(nodeInfo.NodeCapacity.AvailableCPU)
+	availableMemory, memoryClamped := clampAvailable(nodeInfo.NodeCapacity.AvailableMemory)
+	availableStorage, storageClamped := clampAvailable(nodeInfo.NodeCapacity.AvailableStorage)
+	nf := opts.numbers()
+	cpuString := availableCPU.String()
+	memoryString := nf.formatMemoryAsGiB(availableMemory)
+	storageString := availableStorage.String()
+	if cpuClamped {
+		cpuString += overcommitMarker
+	}
+	if memoryClamped {
+		memoryString += overcommitMarker
+	}
+	if storageClamped {
+		storageString += overcommitMarker
+	}
+	clamped := map[string]bool{"CPU": cpuClamped, "MEMORY": memoryClamped, "STORAGE": storageClamped}
+
+	var devicesTotal, devicesAvailable, gpuTotal, gpuAvailable int
+	for _, pool := range nodeInfo.Pools {
+		available := pool.AvailableCount
+		if excluded {
+			available = 0
+		}
+		devicesTotal += pool.TotalCount
+		devicesAvailable += available
+		if strings.HasPrefix(pool.Driver, "gpu.") {
+			gpuTotal += pool.TotalCount
+			gpuAvailable += available
+		}
+	}
+
+	values := map[string]string{
+		"NODE":          nodeName,
+		"ROLE":          nodeInfo.NodeRole,
+		"NODEPOOL":      valueOrNone(nodeInfo.NodePool),
+		"INSTANCE_TYPE": valueOrNone(nodeInfo.InstanceType),
+		"OS":            valueOrNone(nodeInfo.OS),
+		"ARCH":          valueOrNone(nodeInfo.Arch),
+		"CPU":           nodeInfo.NodeCapacity.TotalCPU.String() + "/" + cpuString,
+		"MEMORY":        nf.formatMemoryAsGiB(nodeInfo.NodeCapacity.TotalMemory) + "/" + memoryString,
+		"STORAGE":       nodeInfo.NodeCapacity.TotalStorage.String() + "/" + storageString,
+		"DEVICES":       deviceString,
+		"DEVICES_TOTAL": nf.formatInt(devicesTotal),
+		"DEVICES_AVAIL": nf.formatInt(devicesAvailable),
+		"GPU_TOTAL":     nf.formatInt(gpuTotal),
+		"GPU_AVAIL":     nf.formatInt(gpuAvailable),
+	}
+	row := make([]string, 0, len(columns)+len(opts.Resources))
+	overcommitted := false
+	for _, name := range columns {
+		row = append(row, values[name])
+		overcommitted = overcommitted || clamped[name]
+	}
+	for _, name := range opts.Resources {
+		res, ok := nodeInfo.NodeCapacity.Resources[name]
+		if !ok {
+			row = append(row, "<none>")
+			continue
+		}
+		available, resClamped := clampAvailable(res.Available)
+		cell := res.Total.String() + "/" + available.String()
+		if resClamped {
+			cell += overcommitMarker
+		}
+		row = append(row, cell)
+		overcommitted = overcommitted || resClamped
+	}
+	return row, overcommitted
+}
+
+// DisplayNodeGroups prints the subtotals of nodes grouped by a label. The
+// first column is named after the last segment of the label, e.g. ZONE for
+// topology.kubernetes.io/zone.
+func DisplayNodeGroups(label string, groups []types.NodeGroup, opts Options) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	name := label[strings.LastIndex(label, "/")+1:]
+	printHeader(w, strings.ToUpper(name), "NODES", "CPU(TOTAL/AVAIL)", "MEMORY(TOTAL/AVAIL GiB)", "STORAGE(TOTAL/AVAIL)", "DEVICES")
+	for _, group := range groups {
+		value := group.Value
+		if value == "" {
+			value = `""`
+		}
+		capacity := group.NodeCapacity
 		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s/%s\t%s/%s\t%s\n",
-			nodeInfo.NodeName,
-			nodeInfo.NodeRole,
-			nodeInfo.NodeCapacity.TotalCPU.String(), nodeInfo.NodeCapacity.AvailableCPU.String(),
-			formatMemoryAsGiB(nodeInfo.NodeCapacity.TotalMemory), formatMemoryAsGiB(nodeInfo.NodeCapacity.AvailableMemory),
-			nodeInfo.NodeCapacity.TotalStorage.String(), nodeInfo.NodeCapacity.AvailableStorage.String(),
-			deviceString,
+			value,
+			formatInt(group.Nodes),
+			capacity.TotalCPU.String(), capacity.AvailableCPU.String(),
+			formatMemoryAsGiB(capacity.TotalMemory), formatMemoryAsGiB(capacity.AvailableMemory),
+			capacity.TotalStorage.String(), capacity.AvailableStorage.String(),
+			formatDevices(group.Devices, false, opts),
 		)
 	}
-
-	return nil
 }
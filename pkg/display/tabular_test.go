@@ -0,0 +1,77 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestClampAvailable(t *testing.T) {
+	const legend = "\n! requested resources exceed allocatable (overcommit or accounting mismatch)\n"
+
+	tests := []struct {
+		name        string
+		available   resource.Quantity
+		wantClamped bool
+		wantCPU     string
+		wantLegend  string
+	}{
+		{name: "negative", available: resource.MustParse("-2"), wantClamped: true, wantCPU: "8/0!", wantLegend: legend},
+		{name: "zero", available: resource.MustParse("0"), wantCPU: "8/0"},
+		{name: "positive", available: resource.MustParse("1500m"), wantCPU: "8/1500m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clamped := clampAvailable(tt.available)
+			if clamped != tt.wantClamped {
+				t.Errorf("clampAvailable(%s) clamped = %v, want %v", tt.available.String(), clamped, tt.wantClamped)
+			}
+			if got.Sign() < 0 {
+				t.Errorf("clampAvailable(%s) = %s, want a non-negative quantity", tt.available.String(), got.String())
+			}
+
+			node := &types.NodeInfo{NodeName: "node-1", NodeCapacity: types.NodeCapacity{TotalCPU: resource.MustParse("8"), AvailableCPU: tt.available}}
+			row, rowClamped := nodeRow(node, []string{"CPU"}, Options{})
+			if rowClamped != tt.wantClamped {
+				t.Errorf("nodeRow() clamped = %v, want %v", rowClamped, tt.wantClamped)
+			}
+			if len(row) != 1 || row[0] != tt.wantCPU {
+				t.Errorf("nodeRow() = %q, want [%q]", row, tt.wantCPU)
+			}
+
+			var buf bytes.Buffer
+			printOvercommitLegend(&buf, rowClamped)
+			if buf.String() != tt.wantLegend {
+				t.Errorf("legend = %q, want %q", buf.String(), tt.wantLegend)
+			}
+		})
+	}
+}
+
+func TestFormatCapacity(t *testing.T) {
+	capacity := map[string]resource.Quantity{
+		"memory":                      resource.MustParse("40Gi"),
+		"multiprocessors":             resource.MustParse("108"),
+		"example.com/multiprocessors": resource.MustParse("54"),
+	}
+
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{keys: []string{"memory"}, want: "+40.00Gi"},
+		{keys: []string{"gpu.nvidia.com/memory"}, want: "+40.00Gi"},
+		{keys: []string{"memory", "gpu.nvidia.com/memory"}, want: "+40.00Gi"},
+		{keys: []string{"gpu.nvidia.com/multiprocessors"}, want: "+multiprocessors=108"},
+		// names qualified with another domain are collected as published
+		{keys: []string{"example.com/multiprocessors"}, want: "+example.com/multiprocessors=54"},
+		{keys: []string{"other.com/memory"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := formatCapacity("gpu.nvidia.com", capacity, tt.keys, numberFormat{}); got != tt.want {
+			t.Errorf("formatCapacity(%q) = %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package dratest runs integration tests against a real API server with the
+// resource.k8s.io API enabled, using envtest. Tests are skipped unless the
+// envtest binaries are available, see Start.
+//
+// A typical test starts an environment, creates fixtures and points a
+// client at it:
+//
+//	env := dratest.Start(t)
+//	env.Create(t,
+//		fixtures.NewNode("node-1"),
+//		fixtures.NewResourceSlice("node-1", "gpu.example.com", fixtures.NewDevice("gpu-0", "")),
+//	)
+//	c, err := client.NewResourceClient(env.KubeconfigPath)
+package dratest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// AssetsEnv names the environment variable pointing at the directory with
+// the kube-apiserver and etcd binaries, as installed by setup-envtest.
+const AssetsEnv = "KUBEBUILDER_ASSETS"
+
+// Environment is a running API server and etcd.
+type Environment struct {
+	// Config connects to the API server as an administrator.
+	Config *rest.Config
+	// Clientset is a clientset using Config.
+	Clientset kubernetes.Interface
+	// KubeconfigPath is a kubeconfig file for Config, for code that loads
+	// its configuration from disk.
+	KubeconfigPath string
+}
+
+// Start starts an API server with resource.k8s.io/v1beta1 and the
+// DynamicResourceAllocation feature gate enabled, and stops it when the test
+// finishes. The test is skipped if AssetsEnv is not set.
+func Start(t testing.TB) *Environment {
+	t.Helper()
+	if os.Getenv(AssetsEnv) == "" {
+		t.Skipf("%s is not set, skipping integration test", AssetsEnv)
+	}
+
+	testEnv := &envtest.Environment{}
+	testEnv.ControlPlane.GetAPIServer().Configure().
+		Append("runtime-config", "resource.k8s.io/v1beta1=true").
+		Append("feature-gates", "DynamicResourceAllocation=true")
+
+	config, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("failed to start envtest: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Errorf("failed to stop envtest: %v", err)
+		}
+	})
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatalf("failed to create clientset: %v", err)
+	}
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, testEnv.KubeConfig, 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	return &Environment{Config: config, Clientset: clientset, KubeconfigPath: kubeconfigPath}
+}
+
+// Create creates the given nodes, pods, ResourceSlices and ResourceClaims,
+// including their status, and the namespaces they live in. Other object
+// types fail the test.
+func (e *Environment) Create(t testing.TB, objects ...runtime.Object) {
+	t.Helper()
+	ctx := context.Background()
+	for _, obj := range objects {
+		var err error
+		switch obj := obj.(type) {
+		case *corev1.Node:
+			var created *corev1.Node
+			created, err = e.Clientset.CoreV1().Nodes().Create(ctx, obj, metav1.CreateOptions{})
+			if err == nil {
+				created.Status = obj.Status
+				_, err = e.Clientset.CoreV1().Nodes().UpdateStatus(ctx, created, metav1.UpdateOptions{})
+			}
+		case *corev1.Pod:
+			e.ensureNamespace(t, obj.Namespace)
+			var created *corev1.Pod
+			created, err = e.Clientset.CoreV1().Pods(obj.Namespace).Create(ctx, obj, metav1.CreateOptions{})
+			if err == nil {
+				created.Status = obj.Status
+				_, err = e.Clientset.CoreV1().Pods(obj.Namespace).UpdateStatus(ctx, created, metav1.UpdateOptions{})
+			}
+		case *resourcev1beta1.ResourceSlice:
+			_, err = e.Clientset.ResourceV1beta1().ResourceSlices().Create(ctx, obj, metav1.CreateOptions{})
+		case *resourcev1beta1.ResourceClaim:
+			e.ensureNamespace(t, obj.Namespace)
+			var created *resourcev1beta1.ResourceClaim
+			created, err = e.Clientset.ResourceV1beta1().ResourceClaims(obj.Namespace).Create(ctx, obj, metav1.CreateOptions{})
+			if err == nil && obj.Status.Allocation != nil {
+				created.Status = obj.Status
+				_, err = e.Clientset.ResourceV1beta1().ResourceClaims(obj.Namespace).UpdateStatus(ctx, created, metav1.UpdateOptions{})
+			}
+		default:
+			t.Fatalf("dratest: unsupported object type %T", obj)
+		}
+		if err != nil {
+			t.Fatalf("failed to create %T: %v", obj, err)
+		}
+	}
+}
+
+func (e *Environment) ensureNamespace(t testing.TB, name string) {
+	t.Helper()
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	_, err := e.Clientset.CoreV1().Namespaces().Create(context.Background(), ns, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("failed to create namespace %s: %v", name, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package fixtures builds the Kubernetes objects DRA tests commonly need:
+// nodes, ResourceSlices, ResourceClaims and pods. It depends on the API types
+// only, so unit tests can use it without pulling in envtest like the dratest
+// package does.
+package fixtures
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Request is the name of the single device request of claims built by
+// NewResourceClaim.
+const Request = "device"
+
+// NewNode returns a ready node with 8 CPUs, 32Gi of memory and 100G of
+// storage.
+func NewNode(name string) *corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:     resource.MustParse("8"),
+		corev1.ResourceMemory:  resource.MustParse("32Gi"),
+		corev1.ResourceStorage: resource.MustParse("100G"),
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: resources.DeepCopy(),
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+// NewDevice returns a device with the given productName attribute, or no
+// attributes if productName is empty.
+func NewDevice(name, productName string) resourcev1beta1.Device {
+	dev := resourcev1beta1.Device{Name: name, Basic: &resourcev1beta1.BasicDevice{}}
+	if productName != "" {
+		dev.Basic.Attributes = map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+			"productName": {StringValue: &productName},
+		}
+	}
+	return dev
+}
+
+// NewResourceSlice returns a slice publishing the devices as a pool named
+// after the node, made of this single slice.
+func NewResourceSlice(nodeName, driver string, devices ...resourcev1beta1.Device) *resourcev1beta1.ResourceSlice {
+	return &resourcev1beta1.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName + "-" + driver},
+		Spec: resourcev1beta1.ResourceSliceSpec{
+			NodeName: nodeName,
+			Driver:   driver,
+			Pool:     resourcev1beta1.ResourcePool{Name: nodeName, ResourceSliceCount: 1},
+			Devices:  devices,
+		},
+	}
+}
+
+// NewResourceClaim returns a claim with a single request for a device of
+// the given class, allocated to the given devices if any are passed.
+func NewResourceClaim(namespace, name, deviceClassName string, allocated ...resourcev1beta1.DeviceRequestAllocationResult) *resourcev1beta1.ResourceClaim {
+	claim := &resourcev1beta1.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: resourcev1beta1.ResourceClaimSpec{
+			Devices: resourcev1beta1.DeviceClaim{
+				Requests: []resourcev1beta1.DeviceRequest{
+					{Name: Request, DeviceClassName: deviceClassName},
+				},
+			},
+		},
+	}
+	if len(allocated) > 0 {
+		claim.Status.Allocation = &resourcev1beta1.AllocationResult{
+			Devices: resourcev1beta1.DeviceAllocationResult{Results: allocated},
+		}
+	}
+	return claim
+}
+
+// Allocated returns the allocation result of a device for Request.
+func Allocated(driver, pool, device string) resourcev1beta1.DeviceRequestAllocationResult {
+	return resourcev1beta1.DeviceRequestAllocationResult{Request: Request, Driver: driver, Pool: pool, Device: device}
+}
+
+// NewPod returns a pod consuming the named claims, running on the node or
+// pending if nodeName is empty.
+func NewPod(namespace, name, nodeName string, claimNames ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			NodeName:   nodeName,
+			Containers: []corev1.Container{{Name: "main", Image: "busybox"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	for _, claimName := range claimNames {
+		pod.Spec.ResourceClaims = append(pod.Spec.ResourceClaims, corev1.PodResourceClaim{Name: claimName, ResourceClaimName: &claimName})
+	}
+	if nodeName != "" {
+		pod.Status.Phase = corev1.PodRunning
+	}
+	return pod
+}
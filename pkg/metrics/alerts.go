@@ -0,0 +1,105 @@
+package metrics
+
+import "fmt"
+
+// PrometheusRule is the subset of the monitoring.coreos.com/v1 PrometheusRule
+// resource needed to publish alert rules.
+type PrometheusRule struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   PrometheusRuleMeta `json:"metadata"`
+	Spec       PrometheusRuleSpec `json:"spec"`
+}
+
+type PrometheusRuleMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type PrometheusRuleSpec struct {
+	Groups []RuleGroup `json:"groups"`
+}
+
+type RuleGroup struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+type Rule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AlertRules returns the alerts on the exported metrics: a product with no
+// available device left, claims pending for a long time, pools whose slices
+// are stale, and drivers republishing their slices constantly.
+func AlertRules(name, namespace string) PrometheusRule {
+	return PrometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata: PrometheusRuleMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": "k8s-dra-resources"},
+		},
+		Spec: PrometheusRuleSpec{
+			Groups: []RuleGroup{{
+				Name: "dra-resources",
+				Rules: []Rule{
+					{
+						Alert: "DRADevicesExhausted",
+						Expr:  fmt.Sprintf("sum by (product) (%s) == 0 and sum by (product) (%s) > 0", DevicesAvailable, DevicesTotal),
+						For:   "15m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "No {{ $labels.product }} devices are available",
+							"description": "Every {{ $labels.product }} device in the cluster is allocated; new claims for it stay pending.",
+						},
+					},
+					{
+						Alert: "DRAResourceClaimsPending",
+						Expr:  fmt.Sprintf("%s > 0", ResourceClaimsPending),
+						For:   "30m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "ResourceClaims in {{ $labels.namespace }} are pending",
+							"description": "{{ $value }} ResourceClaims in namespace {{ $labels.namespace }} have not been allocated for 30 minutes.",
+						},
+					},
+					{
+						Alert: "DRAResourceSlicesStale",
+						Expr:  fmt.Sprintf("%s == 1", PoolIncomplete),
+						For:   "10m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "Pool {{ $labels.pool }} of {{ $labels.driver }} is incomplete",
+							"description": "Not all ResourceSlices of pool {{ $labels.pool }} on node {{ $labels.node }} are published; the scheduler does not allocate from it.",
+						},
+					},
+					{
+						Alert: "DRAResourceSliceChurn",
+						Expr:  fmt.Sprintf("rate(%s[15m]) > 1", ResourceSliceUpdates),
+						For:   "30m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "{{ $labels.driver }} republishes its ResourceSlices constantly",
+							"description": "ResourceSlices of {{ $labels.driver }} are updated {{ $value | humanize }} times per second, which loads the API server and the scheduler; check the driver's logs.",
+						},
+					},
+				},
+			}},
+		},
+	}
+}
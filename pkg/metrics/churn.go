@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// SliceChurn counts ResourceSlice updates per driver. Drivers normally only
+// republish their slices when devices change; a driver constantly rewriting
+// them loads the API server and the scheduler, and usually misbehaves.
+type SliceChurn struct {
+	mu sync.Mutex
+	// total counts the updates since the tracker was created, recent those
+	// since the last call to Recent.
+	total  map[string]int64
+	recent map[string]int64
+}
+
+// NewSliceChurn returns a tracker without updates.
+func NewSliceChurn() *SliceChurn {
+	return &SliceChurn{total: make(map[string]int64), recent: make(map[string]int64)}
+}
+
+// Record counts an update of a ResourceSlice of driver. It is safe for
+// concurrent use.
+func (c *SliceChurn) Record(driver string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total[driver]++
+	c.recent[driver]++
+}
+
+// Recent returns the updates per driver since the previous call, and starts
+// counting anew.
+func (c *SliceChurn) Recent() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	recent := c.recent
+	c.recent = make(map[string]int64)
+	return recent
+}
+
+// Write writes the update counter of every driver seen to w.
+func (c *SliceChurn) Write(w io.Writer) error {
+	c.mu.Lock()
+	drivers := make([]string, 0, len(c.total))
+	for driver := range c.total {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	counts := make([]int64, len(drivers))
+	for i, driver := range drivers {
+		counts[i] = c.total[driver]
+	}
+	c.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n", ResourceSliceUpdates, help[ResourceSliceUpdates], ResourceSliceUpdates)
+	for i, driver := range drivers {
+		fmt.Fprintf(bw, "%s{%s} %d\n", ResourceSliceUpdates, formatLabels("driver", driver), counts[i])
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,112 @@
+// Package metrics renders the cluster's DRA state in the Prometheus text
+// exposition format. The metric names are shared with the generated alert
+// rules so the exporter and the alerts stay consistent.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Names of the exported metrics.
+const (
+	// DevicesTotal is the number of devices per node and product.
+	DevicesTotal = "dra_devices_total"
+	// DevicesAvailable is the number of unallocated devices per node and
+	// product.
+	DevicesAvailable = "dra_devices_available"
+	// DevicesUnhealthy is the number of devices per node and product reported
+	// unhealthy by the driver's decorator.
+	DevicesUnhealthy = "dra_devices_unhealthy"
+	// PoolIncomplete is 1 for pools whose ResourceSlices have not all been
+	// published, typically because the driver stopped republishing them.
+	PoolIncomplete = "dra_pool_incomplete"
+	// ResourceClaimsPending is the number of unallocated claims per namespace.
+	ResourceClaimsPending = "dra_resourceclaims_pending"
+	// ResourceSliceUpdates counts the ResourceSlices added, modified or
+	// deleted per driver since serve started.
+	ResourceSliceUpdates = "dra_resourceslice_updates_total"
+)
+
+// gauges lists the metrics Write exports.
+var gauges = []string{DevicesTotal, DevicesAvailable, DevicesUnhealthy, PoolIncomplete, ResourceClaimsPending}
+
+// Names lists every exported metric.
+var Names = append(gauges[:len(gauges):len(gauges)], ResourceSliceUpdates)
+
+var help = map[string]string{
+	DevicesTotal:          "Number of DRA devices per node and product.",
+	DevicesAvailable:      "Number of unallocated DRA devices per node and product.",
+	DevicesUnhealthy:      "Number of unhealthy DRA devices per node and product.",
+	PoolIncomplete:        "Whether not all ResourceSlices of the pool are published (1) or not (0).",
+	ResourceClaimsPending: "Number of unallocated ResourceClaims per namespace.",
+	ResourceSliceUpdates:  "Number of ResourceSlices added, modified or deleted per driver.",
+}
+
+// sample is a single labelled value of a metric.
+type sample struct {
+	labels string
+	value  int
+}
+
+// Write writes the metrics for the given nodes and claims to w.
+func Write(w io.Writer, nodes []*types.NodeInfo, claims []*types.ClaimInfo) error {
+	samples := make(map[string][]sample)
+	for _, node := range nodes {
+		for _, dev := range node.Devices {
+			labels := formatLabels("node", node.NodeName, "product", dev.ProductName)
+			samples[DevicesTotal] = append(samples[DevicesTotal], sample{labels, dev.TotalCount})
+			samples[DevicesAvailable] = append(samples[DevicesAvailable], sample{labels, dev.AvailableCount})
+			samples[DevicesUnhealthy] = append(samples[DevicesUnhealthy], sample{labels, dev.UnhealthyCount})
+		}
+		for _, pool := range node.Pools {
+			incomplete := 0
+			if !pool.Complete() {
+				incomplete = 1
+			}
+			labels := formatLabels("node", node.NodeName, "driver", pool.Driver, "pool", pool.Name)
+			samples[PoolIncomplete] = append(samples[PoolIncomplete], sample{labels, incomplete})
+		}
+	}
+
+	pending := make(map[string]int)
+	for _, claim := range claims {
+		if !claim.Allocated {
+			pending[claim.Namespace]++
+		}
+	}
+	for namespace, count := range pending {
+		samples[ResourceClaimsPending] = append(samples[ResourceClaimsPending], sample{formatLabels("namespace", namespace), count})
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range gauges {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", name, help[name], name)
+		sort.Slice(samples[name], func(i, j int) bool {
+			return samples[name][i].labels < samples[name][j].labels
+		})
+		for _, s := range samples[name] {
+			fmt.Fprintf(bw, "%s{%s} %d\n", name, s.labels, s.value)
+		}
+	}
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels renders alternating label names and values.
+func formatLabels(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1]))
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	nodes := []*types.NodeInfo{{
+		NodeName: "node-1",
+		Devices: []types.Device{
+			{ProductName: "NVIDIA A100", TotalCount: 4, AvailableCount: 1, UnhealthyCount: 1},
+		},
+		Pools: []types.Pool{
+			{Driver: "gpu.nvidia.com", Name: "node-1", ResourceSliceCount: 2, ObservedSliceCount: 1},
+		},
+	}}
+	claims := []*types.ClaimInfo{
+		{Namespace: "team-a", Name: "claim-1"},
+		{Namespace: "team-a", Name: "claim-2"},
+		{Namespace: "team-b", Name: "claim-3", Allocated: true},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, nodes, claims); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	expected := `# HELP dra_devices_total Number of DRA devices per node and product.
+# TYPE dra_devices_total gauge
+dra_devices_total{node="node-1",product="NVIDIA A100"} 4
+# HELP dra_devices_available Number of unallocated DRA devices per node and product.
+# TYPE dra_devices_available gauge
+dra_devices_available{node="node-1",product="NVIDIA A100"} 1
+# HELP dra_devices_unhealthy Number of unhealthy DRA devices per node and product.
+# TYPE dra_devices_unhealthy gauge
+dra_devices_unhealthy{node="node-1",product="NVIDIA A100"} 1
+# HELP dra_pool_incomplete Whether not all ResourceSlices of the pool are published (1) or not (0).
+# TYPE dra_pool_incomplete gauge
+dra_pool_incomplete{node="node-1",driver="gpu.nvidia.com",pool="node-1"} 1
+# HELP dra_resourceclaims_pending Number of unallocated ResourceClaims per namespace.
+# TYPE dra_resourceclaims_pending gauge
+dra_resourceclaims_pending{namespace="team-a"} 2
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestSliceChurn(t *testing.T) {
+	churn := NewSliceChurn()
+	for range 3 {
+		churn.Record("gpu.example.com")
+	}
+	churn.Record("net.example.com")
+
+	if diff := cmp.Diff(churn.Recent(), map[string]int64{"gpu.example.com": 3, "net.example.com": 1}); diff != "" {
+		t.Errorf("Recent() mismatch (-got +want):\n%s", diff)
+	}
+	churn.Record("gpu.example.com")
+	if diff := cmp.Diff(churn.Recent(), map[string]int64{"gpu.example.com": 1}); diff != "" {
+		t.Errorf("Recent() after reset mismatch (-got +want):\n%s", diff)
+	}
+
+	// the counter keeps counting across Recent calls
+	var buf bytes.Buffer
+	if err := churn.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	expected := `# HELP dra_resourceslice_updates_total Number of ResourceSlices added, modified or deleted per driver.
+# TYPE dra_resourceslice_updates_total counter
+dra_resourceslice_updates_total{driver="gpu.example.com"} 4
+dra_resourceslice_updates_total{driver="net.example.com"} 1
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestAlertRulesUseExportedMetrics(t *testing.T) {
+	exported := make(map[string]bool)
+	for _, name := range Names {
+		exported[name] = true
+	}
+
+	metricName := regexp.MustCompile(`\bdra_[a-z_]+`)
+	for _, group := range AlertRules("dra-resources", "monitoring").Spec.Groups {
+		for _, rule := range group.Rules {
+			names := metricName.FindAllString(rule.Expr, -1)
+			if len(names) == 0 {
+				t.Errorf("alert %s does not use any exported metric: %s", rule.Alert, rule.Expr)
+			}
+			for _, name := range names {
+				if !exported[name] {
+					t.Errorf("alert %s uses %s, which is not exported (known: %s)", rule.Alert, name, strings.Join(Names, ", "))
+				}
+			}
+		}
+	}
+}
+
+func TestQueryNodeUtilization(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"Hostname":"node-1"},"value":[1700000000,"87.5"]},
+			{"metric":{"Hostname":"node-2"},"value":[1700000000,"0"]},
+			{"metric":{},"value":[1700000000,"50"]}
+		]}}`)
+	}))
+	defer server.Close()
+
+	got, err := QueryNodeUtilization(context.Background(), server.Client(), server.URL+"/", DefaultUtilizationQuery, DefaultUtilizationLabel)
+	if err != nil {
+		t.Fatalf("QueryNodeUtilization() error = %v", err)
+	}
+	if query != DefaultUtilizationQuery {
+		t.Errorf("query = %q, want %q", query, DefaultUtilizationQuery)
+	}
+	expected := map[string]float64{"node-1": 87.5, "node-2": 0}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestQueryNodeUtilizationErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+	}{
+		{"failed query", `{"status":"error","error":"parse error"}`},
+		{"not a vector", `{"status":"success","data":{"resultType":"matrix","result":[]}}`},
+		{"invalid value", `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"Hostname":"node-1"},"value":[1700000000,"n/a"]}]}}`},
+		{"not json", `<html></html>`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.response)
+			}))
+			defer server.Close()
+
+			if _, err := QueryNodeUtilization(context.Background(), server.Client(), server.URL, DefaultUtilizationQuery, DefaultUtilizationLabel); err == nil {
+				t.Error("QueryNodeUtilization() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultUtilizationQuery averages the GPU utilization reported by the NVIDIA
+// DCGM exporter per node, in percent.
+const DefaultUtilizationQuery = "avg by (Hostname) (DCGM_FI_DEV_GPU_UTIL)"
+
+// DefaultUtilizationLabel is the label of the DCGM exporter naming the node.
+const DefaultUtilizationLabel = "Hostname"
+
+// queryResponse is the response of the Prometheus instant query API.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]any            `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// QueryNodeUtilization runs an instant query against the Prometheus HTTP API
+// at prometheusURL and returns the value of each series, keyed by the value of
+// its nodeLabel label. The query must return a vector; series without the
+// label are ignored.
+func QueryNodeUtilization(ctx context.Context, client *http.Client, prometheusURL, query, nodeLabel string) (map[string]float64, error) {
+	endpoint := strings.TrimSuffix(prometheusURL, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Prometheus request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus query returned a %s, expected a vector", result.Data.ResultType)
+	}
+
+	utilization := make(map[string]float64, len(result.Data.Result))
+	for _, sample := range result.Data.Result {
+		node := sample.Metric[nodeLabel]
+		if node == "" {
+			continue
+		}
+		s, ok := sample.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample value %v for node %s", sample.Value[1], node)
+		}
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample value %q for node %s: %w", s, node, err)
+		}
+		utilization[node] = value
+	}
+	return utilization, nil
+}
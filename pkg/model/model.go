@@ -0,0 +1,222 @@
+// Package model is the internal representation of the resource.k8s.io
+// objects the tool reads. It mirrors the structure of the API, like the
+// internal types of Kubernetes itself, but holds only the fields the tool
+// uses, with the layout of v1beta1: requests carry their class and selectors
+// directly and devices their attributes. Converters from every served API
+// version keep the rest of the code independent of the version a cluster
+// serves, so upstream graduations only need a new converter.
+package model
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// QualifiedName is the name of a device attribute or capacity, optionally
+// prefixed by a domain, e.g. gpu.example.com/model.
+type QualifiedName string
+
+// ResourceSlice is a part of a pool of devices published by a driver.
+type ResourceSlice struct {
+	metav1.ObjectMeta
+	Spec ResourceSliceSpec
+}
+
+type ResourceSliceSpec struct {
+	Driver string
+	Pool   ResourcePool
+	// NodeName is the node the devices are attached to, empty for devices
+	// reachable from several nodes.
+	NodeName       string
+	Devices        []Device
+	SharedCounters []CounterSet
+}
+
+type ResourcePool struct {
+	Name               string
+	Generation         int64
+	ResourceSliceCount int64
+}
+
+// Device is a device of a ResourceSlice. Devices of v1beta1 slices without
+// a basic description have no attributes or capacity.
+type Device struct {
+	Name             string
+	Attributes       map[QualifiedName]DeviceAttribute
+	Capacity         map[QualifiedName]DeviceCapacity
+	ConsumesCounters []DeviceCounterConsumption
+}
+
+// DeviceAttribute holds exactly one of its values.
+type DeviceAttribute struct {
+	IntValue     *int64
+	BoolValue    *bool
+	StringValue  *string
+	VersionValue *string
+}
+
+type DeviceCapacity struct {
+	Value resource.Quantity
+}
+
+// CounterSet is a set of counters shared by the devices of a slice, e.g. the
+// memory slices of a partitionable GPU.
+type CounterSet struct {
+	Name     string
+	Counters map[string]Counter
+}
+
+type Counter struct {
+	Value resource.Quantity
+}
+
+// DeviceCounterConsumption is what a device draws from a counter set.
+type DeviceCounterConsumption struct {
+	CounterSet string
+	Counters   map[string]Counter
+}
+
+// ResourceClaim is a request for devices and, once allocated, the devices
+// allocated for it.
+type ResourceClaim struct {
+	metav1.ObjectMeta
+	Spec   ResourceClaimSpec
+	Status ResourceClaimStatus
+}
+
+type ResourceClaimSpec struct {
+	Devices DeviceClaim
+}
+
+type DeviceClaim struct {
+	Requests []DeviceRequest
+	Config   []DeviceClaimConfiguration
+}
+
+// DeviceRequest is a request of a claim. It either names a DeviceClass or
+// lists alternatives in FirstAvailable.
+type DeviceRequest struct {
+	Name            string
+	DeviceClassName string
+	Selectors       []DeviceSelector
+	AllocationMode  DeviceAllocationMode
+	Count           int64
+	AdminAccess     *bool
+	FirstAvailable  []DeviceSubRequest
+}
+
+type DeviceSubRequest struct {
+	Name            string
+	DeviceClassName string
+	Selectors       []DeviceSelector
+	AllocationMode  DeviceAllocationMode
+	Count           int64
+}
+
+type DeviceAllocationMode string
+
+const (
+	DeviceAllocationModeExactCount DeviceAllocationMode = "ExactCount"
+	DeviceAllocationModeAll        DeviceAllocationMode = "All"
+)
+
+type DeviceSelector struct {
+	CEL *CELDeviceSelector
+}
+
+type CELDeviceSelector struct {
+	Expression string
+}
+
+type DeviceClaimConfiguration struct {
+	Requests []string
+	DeviceConfiguration
+}
+
+type DeviceConfiguration struct {
+	Opaque *OpaqueDeviceConfiguration
+}
+
+type OpaqueDeviceConfiguration struct {
+	Driver     string
+	Parameters runtime.RawExtension
+}
+
+type ResourceClaimStatus struct {
+	Allocation  *AllocationResult
+	ReservedFor []ResourceClaimConsumerReference
+}
+
+type AllocationResult struct {
+	Devices DeviceAllocationResult
+}
+
+type DeviceAllocationResult struct {
+	Results []DeviceRequestAllocationResult
+	Config  []DeviceAllocationConfiguration
+}
+
+// DeviceRequestAllocationResult is a device allocated for a request, named
+// request/subrequest for alternatives.
+type DeviceRequestAllocationResult struct {
+	Request string
+	Driver  string
+	Pool    string
+	Device  string
+	// AdminAccess is set for devices allocated for monitoring or maintenance,
+	// which other claims may still be allocated.
+	AdminAccess *bool
+}
+
+type AllocationConfigSource string
+
+const (
+	AllocationConfigSourceClass AllocationConfigSource = "FromClass"
+	AllocationConfigSourceClaim AllocationConfigSource = "FromClaim"
+)
+
+type DeviceAllocationConfiguration struct {
+	Source   AllocationConfigSource
+	Requests []string
+	DeviceConfiguration
+}
+
+// ResourceClaimReservedForMaxSize is the maximum number of consumers a claim
+// can be reserved for, the same in every API version.
+const ResourceClaimReservedForMaxSize = 256
+
+// ResourceClaimConsumerReference names a consumer of a claim, usually a pod.
+type ResourceClaimConsumerReference struct {
+	APIGroup string
+	Resource string
+	Name     string
+	UID      k8stypes.UID
+}
+
+// DeviceClass selects devices and configures them for the claims requesting
+// it.
+type DeviceClass struct {
+	metav1.ObjectMeta
+	Spec DeviceClassSpec
+}
+
+type DeviceClassSpec struct {
+	Selectors []DeviceSelector
+	Config    []DeviceClassConfiguration
+}
+
+type DeviceClassConfiguration struct {
+	DeviceConfiguration
+}
+
+// ResourceClaimTemplate is the template claims are generated from for pods.
+type ResourceClaimTemplate struct {
+	metav1.ObjectMeta
+	Spec ResourceClaimTemplateSpec
+}
+
+type ResourceClaimTemplateSpec struct {
+	Spec ResourceClaimSpec
+}
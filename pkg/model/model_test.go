@@ -0,0 +1,307 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	resourcev1 "k8s.io/api/resource/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var quantityComparer = cmp.Comparer(func(x, y resource.Quantity) bool { return x.Equal(y) })
+
+func stringPtr(s string) *string { return &s }
+
+func TestResourceSliceVersions(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "node-1-gpu"}
+	v1beta1 := &resourcev1beta1.ResourceSlice{
+		ObjectMeta: meta,
+		Spec: resourcev1beta1.ResourceSliceSpec{
+			Driver:   "gpu.example.com",
+			Pool:     resourcev1beta1.ResourcePool{Name: "node-1", Generation: 2, ResourceSliceCount: 1},
+			NodeName: "node-1",
+			SharedCounters: []resourcev1beta1.CounterSet{{
+				Name:     "gpu-0-memory",
+				Counters: map[string]resourcev1beta1.Counter{"memory": {Value: resource.MustParse("80Gi")}},
+			}},
+			Devices: []resourcev1beta1.Device{
+				{
+					Name: "gpu-0-mig-0",
+					Basic: &resourcev1beta1.BasicDevice{
+						Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+							"productName": {StringValue: stringPtr("H100")},
+						},
+						Capacity: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceCapacity{
+							"memory": {Value: resource.MustParse("40Gi")},
+						},
+						ConsumesCounters: []resourcev1beta1.DeviceCounterConsumption{{
+							CounterSet: "gpu-0-memory",
+							Counters:   map[string]resourcev1beta1.Counter{"memory": {Value: resource.MustParse("40Gi")}},
+						}},
+					},
+				},
+				{Name: "gpu-1"},
+			},
+		},
+	}
+	v1beta2 := &resourcev1beta2.ResourceSlice{
+		ObjectMeta: meta,
+		Spec: resourcev1beta2.ResourceSliceSpec{
+			Driver:   "gpu.example.com",
+			Pool:     resourcev1beta2.ResourcePool{Name: "node-1", Generation: 2, ResourceSliceCount: 1},
+			NodeName: stringPtr("node-1"),
+			SharedCounters: []resourcev1beta2.CounterSet{{
+				Name:     "gpu-0-memory",
+				Counters: map[string]resourcev1beta2.Counter{"memory": {Value: resource.MustParse("80Gi")}},
+			}},
+			Devices: []resourcev1beta2.Device{
+				{
+					Name: "gpu-0-mig-0",
+					Attributes: map[resourcev1beta2.QualifiedName]resourcev1beta2.DeviceAttribute{
+						"productName": {StringValue: stringPtr("H100")},
+					},
+					Capacity: map[resourcev1beta2.QualifiedName]resourcev1beta2.DeviceCapacity{
+						"memory": {Value: resource.MustParse("40Gi")},
+					},
+					ConsumesCounters: []resourcev1beta2.DeviceCounterConsumption{{
+						CounterSet: "gpu-0-memory",
+						Counters:   map[string]resourcev1beta2.Counter{"memory": {Value: resource.MustParse("40Gi")}},
+					}},
+				},
+				{Name: "gpu-1"},
+			},
+		},
+	}
+	v1 := &resourcev1.ResourceSlice{
+		ObjectMeta: meta,
+		Spec: resourcev1.ResourceSliceSpec{
+			Driver:   "gpu.example.com",
+			Pool:     resourcev1.ResourcePool{Name: "node-1", Generation: 2, ResourceSliceCount: 1},
+			NodeName: stringPtr("node-1"),
+			SharedCounters: []resourcev1.CounterSet{{
+				Name:     "gpu-0-memory",
+				Counters: map[string]resourcev1.Counter{"memory": {Value: resource.MustParse("80Gi")}},
+			}},
+			Devices: []resourcev1.Device{
+				{
+					Name: "gpu-0-mig-0",
+					Attributes: map[resourcev1.QualifiedName]resourcev1.DeviceAttribute{
+						"productName": {StringValue: stringPtr("H100")},
+					},
+					Capacity: map[resourcev1.QualifiedName]resourcev1.DeviceCapacity{
+						"memory": {Value: resource.MustParse("40Gi")},
+					},
+					ConsumesCounters: []resourcev1.DeviceCounterConsumption{{
+						CounterSet: "gpu-0-memory",
+						Counters:   map[string]resourcev1.Counter{"memory": {Value: resource.MustParse("40Gi")}},
+					}},
+				},
+				{Name: "gpu-1"},
+			},
+		},
+	}
+
+	expected := ResourceSlice{
+		ObjectMeta: meta,
+		Spec: ResourceSliceSpec{
+			Driver:   "gpu.example.com",
+			Pool:     ResourcePool{Name: "node-1", Generation: 2, ResourceSliceCount: 1},
+			NodeName: "node-1",
+			SharedCounters: []CounterSet{{
+				Name:     "gpu-0-memory",
+				Counters: map[string]Counter{"memory": {Value: resource.MustParse("80Gi")}},
+			}},
+			Devices: []Device{
+				{
+					Name:       "gpu-0-mig-0",
+					Attributes: map[QualifiedName]DeviceAttribute{"productName": {StringValue: stringPtr("H100")}},
+					Capacity:   map[QualifiedName]DeviceCapacity{"memory": {Value: resource.MustParse("40Gi")}},
+					ConsumesCounters: []DeviceCounterConsumption{{
+						CounterSet: "gpu-0-memory",
+						Counters:   map[string]Counter{"memory": {Value: resource.MustParse("40Gi")}},
+					}},
+				},
+				{Name: "gpu-1"},
+			},
+		},
+	}
+	if diff := cmp.Diff(FromV1beta1ResourceSlice(v1beta1), expected, quantityComparer); diff != "" {
+		t.Errorf("v1beta1 mismatch (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(FromV1beta2ResourceSlice(v1beta2), expected, quantityComparer); diff != "" {
+		t.Errorf("v1beta2 mismatch (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(FromV1ResourceSlice(v1), expected, quantityComparer); diff != "" {
+		t.Errorf("v1 mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestResourceClaimVersions(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "training"}
+	parameters := runtime.RawExtension{Raw: []byte(`{"sharing":{"strategy":"TimeSlicing"}}`)}
+	v1beta1 := &resourcev1beta1.ResourceClaim{
+		ObjectMeta: meta,
+		Spec: resourcev1beta1.ResourceClaimSpec{Devices: resourcev1beta1.DeviceClaim{
+			Requests: []resourcev1beta1.DeviceRequest{
+				{
+					Name:            "gpu",
+					DeviceClassName: "gpu.example.com",
+					AllocationMode:  resourcev1beta1.DeviceAllocationModeExactCount,
+					Count:           2,
+					Selectors: []resourcev1beta1.DeviceSelector{{
+						CEL: &resourcev1beta1.CELDeviceSelector{Expression: `device.driver == "gpu.example.com"`},
+					}},
+				},
+				{
+					Name: "nic",
+					FirstAvailable: []resourcev1beta1.DeviceSubRequest{
+						{Name: "fast", DeviceClassName: "fast-nic", AllocationMode: resourcev1beta1.DeviceAllocationModeAll},
+					},
+				},
+			},
+			Config: []resourcev1beta1.DeviceClaimConfiguration{{
+				Requests: []string{"gpu"},
+				DeviceConfiguration: resourcev1beta1.DeviceConfiguration{
+					Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{Driver: "gpu.example.com", Parameters: parameters},
+				},
+			}},
+		}},
+		Status: resourcev1beta1.ResourceClaimStatus{
+			Allocation: &resourcev1beta1.AllocationResult{Devices: resourcev1beta1.DeviceAllocationResult{
+				Results: []resourcev1beta1.DeviceRequestAllocationResult{
+					{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+					{Request: "nic/fast", Driver: "net.example.com", Pool: "node-1", Device: "nic-0"},
+				},
+				Config: []resourcev1beta1.DeviceAllocationConfiguration{{
+					Source:   resourcev1beta1.AllocationConfigSourceClaim,
+					Requests: []string{"gpu"},
+					DeviceConfiguration: resourcev1beta1.DeviceConfiguration{
+						Opaque: &resourcev1beta1.OpaqueDeviceConfiguration{Driver: "gpu.example.com", Parameters: parameters},
+					},
+				}},
+			}},
+			ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{{Resource: "pods", Name: "trainer", UID: "uid-1"}},
+		},
+	}
+	v1beta2 := &resourcev1beta2.ResourceClaim{
+		ObjectMeta: meta,
+		Spec: resourcev1beta2.ResourceClaimSpec{Devices: resourcev1beta2.DeviceClaim{
+			Requests: []resourcev1beta2.DeviceRequest{
+				{
+					Name: "gpu",
+					Exactly: &resourcev1beta2.ExactDeviceRequest{
+						DeviceClassName: "gpu.example.com",
+						AllocationMode:  resourcev1beta2.DeviceAllocationModeExactCount,
+						Count:           2,
+						Selectors: []resourcev1beta2.DeviceSelector{{
+							CEL: &resourcev1beta2.CELDeviceSelector{Expression: `device.driver == "gpu.example.com"`},
+						}},
+					},
+				},
+				{
+					Name: "nic",
+					FirstAvailable: []resourcev1beta2.DeviceSubRequest{
+						{Name: "fast", DeviceClassName: "fast-nic", AllocationMode: resourcev1beta2.DeviceAllocationModeAll},
+					},
+				},
+			},
+			Config: []resourcev1beta2.DeviceClaimConfiguration{{
+				Requests: []string{"gpu"},
+				DeviceConfiguration: resourcev1beta2.DeviceConfiguration{
+					Opaque: &resourcev1beta2.OpaqueDeviceConfiguration{Driver: "gpu.example.com", Parameters: parameters},
+				},
+			}},
+		}},
+		Status: resourcev1beta2.ResourceClaimStatus{
+			Allocation: &resourcev1beta2.AllocationResult{Devices: resourcev1beta2.DeviceAllocationResult{
+				Results: []resourcev1beta2.DeviceRequestAllocationResult{
+					{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+					{Request: "nic/fast", Driver: "net.example.com", Pool: "node-1", Device: "nic-0"},
+				},
+				Config: []resourcev1beta2.DeviceAllocationConfiguration{{
+					Source:   resourcev1beta2.AllocationConfigSourceClaim,
+					Requests: []string{"gpu"},
+					DeviceConfiguration: resourcev1beta2.DeviceConfiguration{
+						Opaque: &resourcev1beta2.OpaqueDeviceConfiguration{Driver: "gpu.example.com", Parameters: parameters},
+					},
+				}},
+			}},
+			ReservedFor: []resourcev1beta2.ResourceClaimConsumerReference{{Resource: "pods", Name: "trainer", UID: "uid-1"}},
+		},
+	}
+	v1 := &resourcev1.ResourceClaim{
+		ObjectMeta: meta,
+		Spec: resourcev1.ResourceClaimSpec{Devices: resourcev1.DeviceClaim{
+			Requests: []resourcev1.DeviceRequest{
+				{
+					Name: "gpu",
+					Exactly: &resourcev1.ExactDeviceRequest{
+						DeviceClassName: "gpu.example.com",
+						AllocationMode:  resourcev1.DeviceAllocationModeExactCount,
+						Count:           2,
+						Selectors: []resourcev1.DeviceSelector{{
+							CEL: &resourcev1.CELDeviceSelector{Expression: `device.driver == "gpu.example.com"`},
+						}},
+					},
+				},
+				{
+					Name: "nic",
+					FirstAvailable: []resourcev1.DeviceSubRequest{
+						{Name: "fast", DeviceClassName: "fast-nic", AllocationMode: resourcev1.DeviceAllocationModeAll},
+					},
+				},
+			},
+			Config: []resourcev1.DeviceClaimConfiguration{{
+				Requests: []string{"gpu"},
+				DeviceConfiguration: resourcev1.DeviceConfiguration{
+					Opaque: &resourcev1.OpaqueDeviceConfiguration{Driver: "gpu.example.com", Parameters: parameters},
+				},
+			}},
+		}},
+		Status: resourcev1.ResourceClaimStatus{
+			Allocation: &resourcev1.AllocationResult{Devices: resourcev1.DeviceAllocationResult{
+				Results: []resourcev1.DeviceRequestAllocationResult{
+					{Request: "gpu", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+					{Request: "nic/fast", Driver: "net.example.com", Pool: "node-1", Device: "nic-0"},
+				},
+				Config: []resourcev1.DeviceAllocationConfiguration{{
+					Source:   resourcev1.AllocationConfigSourceClaim,
+					Requests: []string{"gpu"},
+					DeviceConfiguration: resourcev1.DeviceConfiguration{
+						Opaque: &resourcev1.OpaqueDeviceConfiguration{Driver: "gpu.example.com", Parameters: parameters},
+					},
+				}},
+			}},
+			ReservedFor: []resourcev1.ResourceClaimConsumerReference{{Resource: "pods", Name: "trainer", UID: "uid-1"}},
+		},
+	}
+
+	got1, got2 := FromV1beta1ResourceClaim(v1beta1), FromV1beta2ResourceClaim(v1beta2)
+	if diff := cmp.Diff(got1, got2); diff != "" {
+		t.Errorf("v1beta1 and v1beta2 differ (-v1beta1 +v1beta2):\n%s", diff)
+	}
+	if diff := cmp.Diff(FromV1ResourceClaim(v1), got2); diff != "" {
+		t.Errorf("v1 and v1beta2 differ (-v1 +v1beta2):\n%s", diff)
+	}
+	if got := got1.Spec.Devices.Requests[0]; got.DeviceClassName != "gpu.example.com" || got.Count != 2 || len(got.Selectors) != 1 {
+		t.Errorf("request gpu = %+v, want its class, count and selector", got)
+	}
+	if got := got2.Spec.Devices.Requests[1].FirstAvailable; len(got) != 1 || got[0].AllocationMode != DeviceAllocationModeAll {
+		t.Errorf("subrequests of nic = %+v, want fast with allocation mode All", got)
+	}
+}
+
+func TestAttributesRoundTrip(t *testing.T) {
+	in := map[QualifiedName]DeviceAttribute{"gpu.example.com/index": {IntValue: new(int64)}}
+	got := ToV1Attributes(in)
+	if attr, ok := got["gpu.example.com/index"]; !ok || attr.IntValue == nil || *attr.IntValue != 0 {
+		t.Errorf("ToV1Attributes() = %+v, want index 0", got)
+	}
+	if diff := cmp.Diff(fromV1Attributes(got), in); diff != "" {
+		t.Errorf("round trip mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	resourcev1 "k8s.io/api/resource/v1"
+)
+
+// FromV1ResourceSlice converts a v1 ResourceSlice.
+func FromV1ResourceSlice(in *resourcev1.ResourceSlice) ResourceSlice {
+	out := ResourceSlice{
+		ObjectMeta: in.ObjectMeta,
+		Spec: ResourceSliceSpec{
+			Driver: in.Spec.Driver,
+			Pool: ResourcePool{
+				Name:               in.Spec.Pool.Name,
+				Generation:         in.Spec.Pool.Generation,
+				ResourceSliceCount: in.Spec.Pool.ResourceSliceCount,
+			},
+		},
+	}
+	if in.Spec.NodeName != nil {
+		out.Spec.NodeName = *in.Spec.NodeName
+	}
+	for _, set := range in.Spec.SharedCounters {
+		out.Spec.SharedCounters = append(out.Spec.SharedCounters, CounterSet{Name: set.Name, Counters: fromV1Counters(set.Counters)})
+	}
+	for _, dev := range in.Spec.Devices {
+		device := Device{Name: dev.Name, Attributes: fromV1Attributes(dev.Attributes)}
+		if dev.Capacity != nil {
+			device.Capacity = make(map[QualifiedName]DeviceCapacity, len(dev.Capacity))
+			for name, capacity := range dev.Capacity {
+				device.Capacity[QualifiedName(name)] = DeviceCapacity{Value: capacity.Value}
+			}
+		}
+		for _, consumption := range dev.ConsumesCounters {
+			device.ConsumesCounters = append(device.ConsumesCounters, DeviceCounterConsumption{
+				CounterSet: consumption.CounterSet,
+				Counters:   fromV1Counters(consumption.Counters),
+			})
+		}
+		out.Spec.Devices = append(out.Spec.Devices, device)
+	}
+	return out
+}
+
+// FromV1ResourceSlices converts a list of v1 ResourceSlices.
+func FromV1ResourceSlices(in []resourcev1.ResourceSlice) []ResourceSlice {
+	out := make([]ResourceSlice, len(in))
+	for i := range in {
+		out[i] = FromV1ResourceSlice(&in[i])
+	}
+	return out
+}
+
+func fromV1Attributes(in map[resourcev1.QualifiedName]resourcev1.DeviceAttribute) map[QualifiedName]DeviceAttribute {
+	if in == nil {
+		return nil
+	}
+	out := make(map[QualifiedName]DeviceAttribute, len(in))
+	for name, attr := range in {
+		out[QualifiedName(name)] = DeviceAttribute{
+			IntValue:     attr.IntValue,
+			BoolValue:    attr.BoolValue,
+			StringValue:  attr.StringValue,
+			VersionValue: attr.VersionValue,
+		}
+	}
+	return out
+}
+
+func fromV1Counters(in map[string]resourcev1.Counter) map[string]Counter {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]Counter, len(in))
+	for name, counter := range in {
+		out[name] = Counter{Value: counter.Value}
+	}
+	return out
+}
+
+// FromV1ResourceClaim converts a v1 ResourceClaim.
+func FromV1ResourceClaim(in *resourcev1.ResourceClaim) ResourceClaim {
+	out := ResourceClaim{
+		ObjectMeta: in.ObjectMeta,
+		Spec:       FromV1ResourceClaimSpec(&in.Spec),
+	}
+	if alloc := in.Status.Allocation; alloc != nil {
+		out.Status.Allocation = &AllocationResult{}
+		for _, result := range alloc.Devices.Results {
+			out.Status.Allocation.Devices.Results = append(out.Status.Allocation.Devices.Results, DeviceRequestAllocationResult{
+				Request:     result.Request,
+				Driver:      result.Driver,
+				Pool:        result.Pool,
+				Device:      result.Device,
+				AdminAccess: result.AdminAccess,
+			})
+		}
+		for _, config := range alloc.Devices.Config {
+			out.Status.Allocation.Devices.Config = append(out.Status.Allocation.Devices.Config, DeviceAllocationConfiguration{
+				Source:              AllocationConfigSource(config.Source),
+				Requests:            config.Requests,
+				DeviceConfiguration: fromV1Configuration(config.DeviceConfiguration),
+			})
+		}
+	}
+	for _, ref := range in.Status.ReservedFor {
+		out.Status.ReservedFor = append(out.Status.ReservedFor, ResourceClaimConsumerReference{
+			APIGroup: ref.APIGroup,
+			Resource: ref.Resource,
+			Name:     ref.Name,
+			UID:      ref.UID,
+		})
+	}
+	return out
+}
+
+// FromV1ResourceClaims converts a list of v1 ResourceClaims.
+func FromV1ResourceClaims(in []resourcev1.ResourceClaim) []ResourceClaim {
+	out := make([]ResourceClaim, len(in))
+	for i := range in {
+		out[i] = FromV1ResourceClaim(&in[i])
+	}
+	return out
+}
+
+// FromV1ResourceClaimSpec converts the spec of a v1 ResourceClaim
+// or ResourceClaimTemplate.
+func FromV1ResourceClaimSpec(in *resourcev1.ResourceClaimSpec) ResourceClaimSpec {
+	var out ResourceClaimSpec
+	for _, req := range in.Devices.Requests {
+		request := DeviceRequest{Name: req.Name}
+		if exact := req.Exactly; exact != nil {
+			request.DeviceClassName = exact.DeviceClassName
+			request.Selectors = fromV1Selectors(exact.Selectors)
+			request.AllocationMode = DeviceAllocationMode(exact.AllocationMode)
+			request.Count = exact.Count
+			request.AdminAccess = exact.AdminAccess
+		}
+		for _, sub := range req.FirstAvailable {
+			request.FirstAvailable = append(request.FirstAvailable, DeviceSubRequest{
+				Name:            sub.Name,
+				DeviceClassName: sub.DeviceClassName,
+				Selectors:       fromV1Selectors(sub.Selectors),
+				AllocationMode:  DeviceAllocationMode(sub.AllocationMode),
+				Count:           sub.Count,
+			})
+		}
+		out.Devices.Requests = append(out.Devices.Requests, request)
+	}
+	for _, config := range in.Devices.Config {
+		out.Devices.Config = append(out.Devices.Config, DeviceClaimConfiguration{
+			Requests:            config.Requests,
+			DeviceConfiguration: fromV1Configuration(config.DeviceConfiguration),
+		})
+	}
+	return out
+}
+
+func fromV1Selectors(in []resourcev1.DeviceSelector) []DeviceSelector {
+	var out []DeviceSelector
+	for _, selector := range in {
+		var s DeviceSelector
+		if selector.CEL != nil {
+			s.CEL = &CELDeviceSelector{Expression: selector.CEL.Expression}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func fromV1Configuration(in resourcev1.DeviceConfiguration) DeviceConfiguration {
+	var out DeviceConfiguration
+	if in.Opaque != nil {
+		out.Opaque = &OpaqueDeviceConfiguration{Driver: in.Opaque.Driver, Parameters: in.Opaque.Parameters}
+	}
+	return out
+}
+
+// FromV1DeviceClass converts a v1 DeviceClass.
+func FromV1DeviceClass(in *resourcev1.DeviceClass) DeviceClass {
+	out := DeviceClass{
+		ObjectMeta: in.ObjectMeta,
+		Spec:       DeviceClassSpec{Selectors: fromV1Selectors(in.Spec.Selectors)},
+	}
+	for _, config := range in.Spec.Config {
+		out.Spec.Config = append(out.Spec.Config, DeviceClassConfiguration{
+			DeviceConfiguration: fromV1Configuration(config.DeviceConfiguration),
+		})
+	}
+	return out
+}
+
+// FromV1ResourceClaimTemplate converts a v1 ResourceClaimTemplate.
+func FromV1ResourceClaimTemplate(in *resourcev1.ResourceClaimTemplate) ResourceClaimTemplate {
+	return ResourceClaimTemplate{
+		ObjectMeta: in.ObjectMeta,
+		Spec:       ResourceClaimTemplateSpec{Spec: FromV1ResourceClaimSpec(&in.Spec.Spec)},
+	}
+}
+
+// ToV1Attributes converts device attributes back to v1, which the CEL
+// environment of k8s.io/dynamic-resource-allocation evaluates.
+func ToV1Attributes(in map[QualifiedName]DeviceAttribute) map[resourcev1.QualifiedName]resourcev1.DeviceAttribute {
+	if in == nil {
+		return nil
+	}
+	out := make(map[resourcev1.QualifiedName]resourcev1.DeviceAttribute, len(in))
+	for name, attr := range in {
+		out[resourcev1.QualifiedName(name)] = resourcev1.DeviceAttribute{
+			IntValue:     attr.IntValue,
+			BoolValue:    attr.BoolValue,
+			StringValue:  attr.StringValue,
+			VersionValue: attr.VersionValue,
+		}
+	}
+	return out
+}
+
+// ToV1Capacity converts device capacity back to v1, see ToV1Attributes.
+func ToV1Capacity(in map[QualifiedName]DeviceCapacity) map[resourcev1.QualifiedName]resourcev1.DeviceCapacity {
+	if in == nil {
+		return nil
+	}
+	out := make(map[resourcev1.QualifiedName]resourcev1.DeviceCapacity, len(in))
+	for name, capacity := range in {
+		out[resourcev1.QualifiedName(name)] = resourcev1.DeviceCapacity{Value: capacity.Value}
+	}
+	return out
+}
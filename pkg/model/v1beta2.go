@@ -0,0 +1,199 @@
+package model
+
+import (
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+)
+
+// FromV1beta2ResourceSlice converts a v1beta2 ResourceSlice.
+func FromV1beta2ResourceSlice(in *resourcev1beta2.ResourceSlice) ResourceSlice {
+	out := ResourceSlice{
+		ObjectMeta: in.ObjectMeta,
+		Spec: ResourceSliceSpec{
+			Driver: in.Spec.Driver,
+			Pool: ResourcePool{
+				Name:               in.Spec.Pool.Name,
+				Generation:         in.Spec.Pool.Generation,
+				ResourceSliceCount: in.Spec.Pool.ResourceSliceCount,
+			},
+		},
+	}
+	if in.Spec.NodeName != nil {
+		out.Spec.NodeName = *in.Spec.NodeName
+	}
+	for _, set := range in.Spec.SharedCounters {
+		out.Spec.SharedCounters = append(out.Spec.SharedCounters, CounterSet{Name: set.Name, Counters: fromV1beta2Counters(set.Counters)})
+	}
+	for _, dev := range in.Spec.Devices {
+		device := Device{Name: dev.Name, Attributes: fromV1beta2Attributes(dev.Attributes)}
+		if dev.Capacity != nil {
+			device.Capacity = make(map[QualifiedName]DeviceCapacity, len(dev.Capacity))
+			for name, capacity := range dev.Capacity {
+				device.Capacity[QualifiedName(name)] = DeviceCapacity{Value: capacity.Value}
+			}
+		}
+		for _, consumption := range dev.ConsumesCounters {
+			device.ConsumesCounters = append(device.ConsumesCounters, DeviceCounterConsumption{
+				CounterSet: consumption.CounterSet,
+				Counters:   fromV1beta2Counters(consumption.Counters),
+			})
+		}
+		out.Spec.Devices = append(out.Spec.Devices, device)
+	}
+	return out
+}
+
+// FromV1beta2ResourceSlices converts a list of v1beta2 ResourceSlices.
+func FromV1beta2ResourceSlices(in []resourcev1beta2.ResourceSlice) []ResourceSlice {
+	out := make([]ResourceSlice, len(in))
+	for i := range in {
+		out[i] = FromV1beta2ResourceSlice(&in[i])
+	}
+	return out
+}
+
+func fromV1beta2Attributes(in map[resourcev1beta2.QualifiedName]resourcev1beta2.DeviceAttribute) map[QualifiedName]DeviceAttribute {
+	if in == nil {
+		return nil
+	}
+	out := make(map[QualifiedName]DeviceAttribute, len(in))
+	for name, attr := range in {
+		out[QualifiedName(name)] = DeviceAttribute{
+			IntValue:     attr.IntValue,
+			BoolValue:    attr.BoolValue,
+			StringValue:  attr.StringValue,
+			VersionValue: attr.VersionValue,
+		}
+	}
+	return out
+}
+
+func fromV1beta2Counters(in map[string]resourcev1beta2.Counter) map[string]Counter {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]Counter, len(in))
+	for name, counter := range in {
+		out[name] = Counter{Value: counter.Value}
+	}
+	return out
+}
+
+// FromV1beta2ResourceClaim converts a v1beta2 ResourceClaim.
+func FromV1beta2ResourceClaim(in *resourcev1beta2.ResourceClaim) ResourceClaim {
+	out := ResourceClaim{
+		ObjectMeta: in.ObjectMeta,
+		Spec:       FromV1beta2ResourceClaimSpec(&in.Spec),
+	}
+	if alloc := in.Status.Allocation; alloc != nil {
+		out.Status.Allocation = &AllocationResult{}
+		for _, result := range alloc.Devices.Results {
+			out.Status.Allocation.Devices.Results = append(out.Status.Allocation.Devices.Results, DeviceRequestAllocationResult{
+				Request:     result.Request,
+				Driver:      result.Driver,
+				Pool:        result.Pool,
+				Device:      result.Device,
+				AdminAccess: result.AdminAccess,
+			})
+		}
+		for _, config := range alloc.Devices.Config {
+			out.Status.Allocation.Devices.Config = append(out.Status.Allocation.Devices.Config, DeviceAllocationConfiguration{
+				Source:              AllocationConfigSource(config.Source),
+				Requests:            config.Requests,
+				DeviceConfiguration: fromV1beta2Configuration(config.DeviceConfiguration),
+			})
+		}
+	}
+	for _, ref := range in.Status.ReservedFor {
+		out.Status.ReservedFor = append(out.Status.ReservedFor, ResourceClaimConsumerReference{
+			APIGroup: ref.APIGroup,
+			Resource: ref.Resource,
+			Name:     ref.Name,
+			UID:      ref.UID,
+		})
+	}
+	return out
+}
+
+// FromV1beta2ResourceClaims converts a list of v1beta2 ResourceClaims.
+func FromV1beta2ResourceClaims(in []resourcev1beta2.ResourceClaim) []ResourceClaim {
+	out := make([]ResourceClaim, len(in))
+	for i := range in {
+		out[i] = FromV1beta2ResourceClaim(&in[i])
+	}
+	return out
+}
+
+// FromV1beta2ResourceClaimSpec converts the spec of a v1beta2 ResourceClaim
+// or ResourceClaimTemplate.
+func FromV1beta2ResourceClaimSpec(in *resourcev1beta2.ResourceClaimSpec) ResourceClaimSpec {
+	var out ResourceClaimSpec
+	for _, req := range in.Devices.Requests {
+		request := DeviceRequest{Name: req.Name}
+		if exact := req.Exactly; exact != nil {
+			request.DeviceClassName = exact.DeviceClassName
+			request.Selectors = fromV1beta2Selectors(exact.Selectors)
+			request.AllocationMode = DeviceAllocationMode(exact.AllocationMode)
+			request.Count = exact.Count
+			request.AdminAccess = exact.AdminAccess
+		}
+		for _, sub := range req.FirstAvailable {
+			request.FirstAvailable = append(request.FirstAvailable, DeviceSubRequest{
+				Name:            sub.Name,
+				DeviceClassName: sub.DeviceClassName,
+				Selectors:       fromV1beta2Selectors(sub.Selectors),
+				AllocationMode:  DeviceAllocationMode(sub.AllocationMode),
+				Count:           sub.Count,
+			})
+		}
+		out.Devices.Requests = append(out.Devices.Requests, request)
+	}
+	for _, config := range in.Devices.Config {
+		out.Devices.Config = append(out.Devices.Config, DeviceClaimConfiguration{
+			Requests:            config.Requests,
+			DeviceConfiguration: fromV1beta2Configuration(config.DeviceConfiguration),
+		})
+	}
+	return out
+}
+
+func fromV1beta2Selectors(in []resourcev1beta2.DeviceSelector) []DeviceSelector {
+	var out []DeviceSelector
+	for _, selector := range in {
+		var s DeviceSelector
+		if selector.CEL != nil {
+			s.CEL = &CELDeviceSelector{Expression: selector.CEL.Expression}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func fromV1beta2Configuration(in resourcev1beta2.DeviceConfiguration) DeviceConfiguration {
+	var out DeviceConfiguration
+	if in.Opaque != nil {
+		out.Opaque = &OpaqueDeviceConfiguration{Driver: in.Opaque.Driver, Parameters: in.Opaque.Parameters}
+	}
+	return out
+}
+
+// FromV1beta2DeviceClass converts a v1beta2 DeviceClass.
+func FromV1beta2DeviceClass(in *resourcev1beta2.DeviceClass) DeviceClass {
+	out := DeviceClass{
+		ObjectMeta: in.ObjectMeta,
+		Spec:       DeviceClassSpec{Selectors: fromV1beta2Selectors(in.Spec.Selectors)},
+	}
+	for _, config := range in.Spec.Config {
+		out.Spec.Config = append(out.Spec.Config, DeviceClassConfiguration{
+			DeviceConfiguration: fromV1beta2Configuration(config.DeviceConfiguration),
+		})
+	}
+	return out
+}
+
+// FromV1beta2ResourceClaimTemplate converts a v1beta2 ResourceClaimTemplate.
+func FromV1beta2ResourceClaimTemplate(in *resourcev1beta2.ResourceClaimTemplate) ResourceClaimTemplate {
+	return ResourceClaimTemplate{
+		ObjectMeta: in.ObjectMeta,
+		Spec:       ResourceClaimTemplateSpec{Spec: FromV1beta2ResourceClaimSpec(&in.Spec.Spec)},
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Kinds of conditions.
+const (
+	// KindDevicesExhausted is a product with no available device left in the
+	// cluster.
+	KindDevicesExhausted = "devices-exhausted"
+	// KindDriverDown is a driver whose pools are incomplete on several nodes,
+	// as when its kubelet plugin stopped publishing ResourceSlices.
+	KindDriverDown = "driver-down"
+)
+
+// DefaultDriverDownNodes is the number of nodes with incomplete pools of a
+// driver at which it is considered down.
+const DefaultDriverDownNodes = 3
+
+// Condition is a critical state of the cluster that pages someone until it
+// clears.
+type Condition struct {
+	Kind string
+	// Key identifies the condition across evaluations. It is the
+	// deduplication key of the incident, so repeated triggers update a single
+	// incident and a resolve closes it.
+	Key     string
+	Summary string
+	Details map[string]string
+}
+
+// Conditions returns the critical conditions of the cluster, sorted by key:
+// products of which every device is allocated, and drivers with incomplete
+// pools on at least driverDownNodes nodes.
+func Conditions(nodes []*types.NodeInfo, driverDownNodes int) []Condition {
+	type availability struct{ total, available int }
+	products := make(map[string]*availability)
+	downNodes := make(map[string][]string)
+	for _, node := range nodes {
+		for _, dev := range node.Devices {
+			p, ok := products[dev.ProductName]
+			if !ok {
+				p = &availability{}
+				products[dev.ProductName] = p
+			}
+			p.total += dev.TotalCount
+			p.available += dev.AvailableCount
+		}
+		down := make(map[string]bool)
+		for _, pool := range node.Pools {
+			if !pool.Complete() && !down[pool.Driver] {
+				down[pool.Driver] = true
+				downNodes[pool.Driver] = append(downNodes[pool.Driver], node.NodeName)
+			}
+		}
+	}
+
+	var conditions []Condition
+	for product, p := range products {
+		if p.total == 0 || p.available > 0 {
+			continue
+		}
+		conditions = append(conditions, Condition{
+			Kind:    KindDevicesExhausted,
+			Key:     "dra/" + KindDevicesExhausted + "/" + product,
+			Summary: fmt.Sprintf("All %d %s devices in the cluster are allocated", p.total, product),
+			Details: map[string]string{"product": product, "devices": fmt.Sprint(p.total)},
+		})
+	}
+	for driver, nodeNames := range downNodes {
+		if len(nodeNames) < driverDownNodes {
+			continue
+		}
+		sort.Strings(nodeNames)
+		conditions = append(conditions, Condition{
+			Kind:    KindDriverDown,
+			Key:     "dra/" + KindDriverDown + "/" + driver,
+			Summary: fmt.Sprintf("Driver %s has incomplete ResourceSlices on %d nodes", driver, len(nodeNames)),
+			Details: map[string]string{"driver": driver, "nodes": strings.Join(nodeNames, ",")},
+		})
+	}
+	sort.Slice(conditions, func(i, j int) bool { return conditions[i].Key < conditions[j].Key })
+	return conditions
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConditions(t *testing.T) {
+	stale := types.Pool{Driver: "gpu.example.com", Name: "pool", ResourceSliceCount: 2, ObservedSliceCount: 1}
+	complete := types.Pool{Driver: "net.example.com", Name: "pool", ResourceSliceCount: 1, ObservedSliceCount: 1}
+	nodes := []*types.NodeInfo{
+		{
+			NodeName: "node-1",
+			Devices: []types.Device{
+				{ProductName: "NVIDIA A100", TotalCount: 4, AvailableCount: 0},
+				{ProductName: "NVIDIA L4", TotalCount: 2, AvailableCount: 0},
+			},
+			Pools: []types.Pool{stale, stale, complete},
+		},
+		{
+			NodeName: "node-2",
+			Devices:  []types.Device{{ProductName: "NVIDIA L4", TotalCount: 2, AvailableCount: 1}},
+			Pools:    []types.Pool{stale},
+		},
+		{NodeName: "node-3", Pools: []types.Pool{complete}},
+	}
+
+	tests := []struct {
+		name            string
+		driverDownNodes int
+		expected        []Condition
+	}{
+		{
+			name:            "driver down",
+			driverDownNodes: 2,
+			expected: []Condition{
+				{
+					Kind:    KindDevicesExhausted,
+					Key:     "dra/devices-exhausted/NVIDIA A100",
+					Summary: "All 4 NVIDIA A100 devices in the cluster are allocated",
+					Details: map[string]string{"product": "NVIDIA A100", "devices": "4"},
+				},
+				{
+					Kind:    KindDriverDown,
+					Key:     "dra/driver-down/gpu.example.com",
+					Summary: "Driver gpu.example.com has incomplete ResourceSlices on 2 nodes",
+					Details: map[string]string{"driver": "gpu.example.com", "nodes": "node-1,node-2"},
+				},
+			},
+		},
+		{
+			name:            "below driver down threshold",
+			driverDownNodes: 3,
+			expected: []Condition{
+				{
+					Kind:    KindDevicesExhausted,
+					Key:     "dra/devices-exhausted/NVIDIA A100",
+					Summary: "All 4 NVIDIA A100 devices in the cluster are allocated",
+					Details: map[string]string{"product": "NVIDIA A100", "devices": "4"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Conditions(nodes, tt.driverDownNodes)
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package notify delivers reports and alerts to people outside the cluster.
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is an email with a body and optional attachments.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	// Body is the content of the email, of BodyType, e.g. text/html.
+	Body        string
+	BodyType    string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Bytes renders the message in MIME format, dated date.
+func (m *Message) Bytes(date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	header := []struct{ key, value string }{
+		{"From", m.From},
+		{"To", strings.Join(m.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": writer.Boundary()})},
+	}
+	var head bytes.Buffer
+	for _, h := range header {
+		fmt.Fprintf(&head, "%s: %s\r\n", h.key, h.value)
+	}
+	head.WriteString("\r\n")
+
+	bodyType := m.BodyType
+	if bodyType == "" {
+		bodyType = "text/plain"
+	}
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(bodyType, map[string]string{"charset": "utf-8"})},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email body: %w", err)
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(m.Body)); err != nil {
+		return nil, fmt.Errorf("failed to write email body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write email body: %w", err)
+	}
+
+	for _, attachment := range m.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create attachment %s: %w", attachment.Filename, err)
+		}
+		if err := writeBase64Lines(part, attachment.Data); err != nil {
+			return nil, fmt.Errorf("failed to write attachment %s: %w", attachment.Filename, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write email: %w", err)
+	}
+	return append(head.Bytes(), buf.Bytes()...), nil
+}
+
+// writeBase64Lines writes data base64-encoded in lines of 76 characters, the
+// limit of RFC 2045.
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(76, len(encoded))
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:n]); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// SMTPSender sends messages through an SMTP server, upgrading the connection
+// with STARTTLS when the server supports it.
+type SMTPSender struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth, which net/smtp only
+	// sends over TLS or to localhost. Without a username, mail is sent
+	// unauthenticated.
+	Username string
+	Password string
+
+	// sendMail is smtp.SendMail, replaced in tests.
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSender returns a sender for the SMTP server at host:port.
+func NewSMTPSender(host string, port int, username, password string) *SMTPSender {
+	return &SMTPSender{Host: host, Port: port, Username: username, Password: password, sendMail: smtp.SendMail}
+}
+
+// Send sends a message to its recipients.
+func (s *SMTPSender) Send(m *Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	to := make([]string, len(m.To))
+	for i, recipient := range m.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		to[i] = address.Address
+	}
+	msg, err := m.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	if err := s.sendMail(addr, auth, from.Address, to, msg); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", addr, err)
+	}
+	return nil
+}
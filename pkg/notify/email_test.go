@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessageBytes(t *testing.T) {
+	m := &Message{
+		From:     "DRA reports <dra@example.com>",
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "GPU capacity – week 23",
+		Body:     "<p>See the attached report.</p>",
+		BodyType: "text/html",
+		Attachments: []Attachment{
+			{Filename: "capacity.csv", ContentType: "text/csv", Data: []byte("NODE,DEVICES\nnode-1,None\n")},
+		},
+	}
+	data, err := m.Bytes(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("failed to decode subject: %v", err)
+	}
+	header := map[string]string{
+		"From":    msg.Header.Get("From"),
+		"To":      msg.Header.Get("To"),
+		"Subject": subject,
+		"Date":    msg.Header.Get("Date"),
+	}
+	expectedHeader := map[string]string{
+		"From":    "DRA reports <dra@example.com>",
+		"To":      "a@example.com, b@example.com",
+		"Subject": "GPU capacity – week 23",
+		"Date":    "Mon, 02 Jun 2025 07:00:00 +0000",
+	}
+	if diff := cmp.Diff(header, expectedHeader); diff != "" {
+		t.Errorf("header mismatch (-got +want):\n%s", diff)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+	type part struct {
+		ContentType string
+		Filename    string
+		Content     string
+	}
+	var parts []part
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		content, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		// multipart.Reader decodes quoted-printable but not base64
+		if p.Header.Get("Content-Transfer-Encoding") == "base64" {
+			content, err = base64.StdEncoding.DecodeString(strings.ReplaceAll(string(content), "\r\n", ""))
+			if err != nil {
+				t.Fatalf("failed to decode part: %v", err)
+			}
+		}
+		parts = append(parts, part{p.Header.Get("Content-Type"), p.FileName(), string(content)})
+	}
+	expectedParts := []part{
+		{ContentType: "text/html; charset=utf-8", Content: "<p>See the attached report.</p>"},
+		{ContentType: "text/csv", Filename: "capacity.csv", Content: "NODE,DEVICES\nnode-1,None\n"},
+	}
+	if diff := cmp.Diff(parts, expectedParts); diff != "" {
+		t.Errorf("parts mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	type call struct {
+		Addr string
+		Auth bool
+		From string
+		To   []string
+	}
+	tests := []struct {
+		name     string
+		username string
+		to       []string
+		expected *call
+		err      string
+	}{
+		{
+			name:     "authenticated",
+			username: "reports",
+			to:       []string{"Capacity <gpu-capacity@example.com>"},
+			expected: &call{Addr: "smtp.example.com:587", Auth: true, From: "dra@example.com", To: []string{"gpu-capacity@example.com"}},
+		},
+		{
+			name:     "unauthenticated",
+			to:       []string{"gpu-capacity@example.com"},
+			expected: &call{Addr: "smtp.example.com:587", From: "dra@example.com", To: []string{"gpu-capacity@example.com"}},
+		},
+		{
+			name: "invalid recipient",
+			to:   []string{"not an address"},
+			err:  "invalid recipient",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *call
+			sender := NewSMTPSender("smtp.example.com", 587, tt.username, "secret")
+			sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				got = &call{Addr: addr, Auth: a != nil, From: from, To: to}
+				return nil
+			}
+			err := sender.Send(&Message{From: "DRA reports <dra@example.com>", To: tt.to, Subject: "report", Body: "hello"})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Send() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Default API endpoints of the paging services.
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com"
+)
+
+// Pager opens and closes incidents for conditions, keyed by Condition.Key.
+type Pager interface {
+	Trigger(ctx context.Context, c Condition) error
+	Resolve(ctx context.Context, c Condition) error
+}
+
+// PagerDuty sends events to the PagerDuty Events API v2.
+type PagerDuty struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string
+	URL        string
+	// Source names the cluster in incidents.
+	Source string
+	Client *http.Client
+}
+
+// NewPagerDuty returns a pager for the PagerDuty service of routingKey.
+func NewPagerDuty(routingKey, source string) *PagerDuty {
+	return &PagerDuty{RoutingKey: routingKey, URL: DefaultPagerDutyURL, Source: source, Client: http.DefaultClient}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Trigger opens or updates the incident of c.
+func (p *PagerDuty) Trigger(ctx context.Context, c Condition) error {
+	return p.send(ctx, pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    c.Key,
+		Payload: &pagerDutyPayload{
+			Summary:       c.Summary,
+			Source:        p.Source,
+			Severity:      "critical",
+			Component:     c.Kind,
+			CustomDetails: c.Details,
+		},
+	})
+}
+
+// Resolve closes the incident of c.
+func (p *PagerDuty) Resolve(ctx context.Context, c Condition) error {
+	return p.send(ctx, pagerDutyEvent{RoutingKey: p.RoutingKey, EventAction: "resolve", DedupKey: c.Key})
+}
+
+func (p *PagerDuty) send(ctx context.Context, event pagerDutyEvent) error {
+	if err := postJSON(ctx, p.Client, strings.TrimSuffix(p.URL, "/")+"/v2/enqueue", nil, event); err != nil {
+		return fmt.Errorf("failed to send PagerDuty %s event for %s: %w", event.EventAction, event.DedupKey, err)
+	}
+	return nil
+}
+
+// Opsgenie creates and closes alerts with the Opsgenie Alert API.
+type Opsgenie struct {
+	APIKey string
+	// URL is DefaultOpsgenieURL, or https://api.eu.opsgenie.com for
+	// accounts in the EU.
+	URL    string
+	Source string
+	Client *http.Client
+}
+
+// NewOpsgenie returns a pager creating Opsgenie alerts with apiKey.
+func NewOpsgenie(apiKey, source string) *Opsgenie {
+	return &Opsgenie{APIKey: apiKey, URL: DefaultOpsgenieURL, Source: source, Client: http.DefaultClient}
+}
+
+// opsgenieMessageLimit is the maximum length of an alert message.
+const opsgenieMessageLimit = 130
+
+type opsgenieAlert struct {
+	Message  string            `json:"message"`
+	Alias    string            `json:"alias"`
+	Source   string            `json:"source,omitempty"`
+	Priority string            `json:"priority"`
+	Tags     []string          `json:"tags,omitempty"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// Trigger creates the alert of c; Opsgenie deduplicates open alerts by alias.
+func (o *Opsgenie) Trigger(ctx context.Context, c Condition) error {
+	message := c.Summary
+	if len(message) > opsgenieMessageLimit {
+		message = message[:opsgenieMessageLimit-3] + "..."
+	}
+	alert := opsgenieAlert{
+		Message:  message,
+		Alias:    c.Key,
+		Source:   o.Source,
+		Priority: "P1",
+		Tags:     []string{c.Kind},
+		Details:  c.Details,
+	}
+	if err := postJSON(ctx, o.Client, strings.TrimSuffix(o.URL, "/")+"/v2/alerts", o.header(), alert); err != nil {
+		return fmt.Errorf("failed to create Opsgenie alert %s: %w", c.Key, err)
+	}
+	return nil
+}
+
+// Resolve closes the alert of c.
+func (o *Opsgenie) Resolve(ctx context.Context, c Condition) error {
+	endpoint := strings.TrimSuffix(o.URL, "/") + "/v2/alerts/" + url.PathEscape(c.Key) + "/close?identifierType=alias"
+	if err := postJSON(ctx, o.Client, endpoint, o.header(), map[string]string{"source": o.Source}); err != nil {
+		return fmt.Errorf("failed to close Opsgenie alert %s: %w", c.Key, err)
+	}
+	return nil
+}
+
+func (o *Opsgenie) header() http.Header {
+	return http.Header{"Authorization": {"GenieKey " + o.APIKey}}
+}
+
+// postJSON posts body as JSON to endpoint and fails unless the response is
+// successful.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// request is a request received by the test server.
+type request struct {
+	Path          string
+	Authorization string
+	Body          map[string]any
+}
+
+// recordingServer returns a server recording requests and answering them
+// with status.
+func recordingServer(t *testing.T, status int, requests *[]request) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid request body %q: %v", data, err)
+		}
+		*requests = append(*requests, request{Path: r.URL.RequestURI(), Authorization: r.Header.Get("Authorization"), Body: body})
+		w.WriteHeader(status)
+		io.WriteString(w, `{"status":"ok"}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var testCondition = Condition{
+	Kind:    KindDevicesExhausted,
+	Key:     "dra/devices-exhausted/NVIDIA A100",
+	Summary: "All 4 NVIDIA A100 devices in the cluster are allocated",
+	Details: map[string]string{"product": "NVIDIA A100"},
+}
+
+func TestPagerDuty(t *testing.T) {
+	var requests []request
+	server := recordingServer(t, http.StatusAccepted, &requests)
+	pager := NewPagerDuty("routing-key", "prod")
+	pager.URL = server.URL
+
+	ctx := context.Background()
+	if err := pager.Trigger(ctx, testCondition); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if err := pager.Resolve(ctx, testCondition); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	expected := []request{
+		{
+			Path: "/v2/enqueue",
+			Body: map[string]any{
+				"routing_key":  "routing-key",
+				"event_action": "trigger",
+				"dedup_key":    "dra/devices-exhausted/NVIDIA A100",
+				"payload": map[string]any{
+					"summary":        "All 4 NVIDIA A100 devices in the cluster are allocated",
+					"source":         "prod",
+					"severity":       "critical",
+					"component":      "devices-exhausted",
+					"custom_details": map[string]any{"product": "NVIDIA A100"},
+				},
+			},
+		},
+		{
+			Path: "/v2/enqueue",
+			Body: map[string]any{
+				"routing_key":  "routing-key",
+				"event_action": "resolve",
+				"dedup_key":    "dra/devices-exhausted/NVIDIA A100",
+			},
+		},
+	}
+	if diff := cmp.Diff(requests, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestOpsgenie(t *testing.T) {
+	var requests []request
+	server := recordingServer(t, http.StatusAccepted, &requests)
+	pager := NewOpsgenie("api-key", "prod")
+	pager.URL = server.URL
+
+	ctx := context.Background()
+	if err := pager.Trigger(ctx, testCondition); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if err := pager.Resolve(ctx, testCondition); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	expected := []request{
+		{
+			Path:          "/v2/alerts",
+			Authorization: "GenieKey api-key",
+			Body: map[string]any{
+				"message":  "All 4 NVIDIA A100 devices in the cluster are allocated",
+				"alias":    "dra/devices-exhausted/NVIDIA A100",
+				"source":   "prod",
+				"priority": "P1",
+				"tags":     []any{"devices-exhausted"},
+				"details":  map[string]any{"product": "NVIDIA A100"},
+			},
+		},
+		{
+			Path:          "/v2/alerts/dra%2Fdevices-exhausted%2FNVIDIA%20A100/close?identifierType=alias",
+			Authorization: "GenieKey api-key",
+			Body:          map[string]any{"source": "prod"},
+		},
+	}
+	if diff := cmp.Diff(requests, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	var requests []request
+	server := recordingServer(t, http.StatusBadRequest, &requests)
+	pager := NewPagerDuty("invalid", "prod")
+	pager.URL = server.URL
+
+	err := pager.Trigger(context.Background(), testCondition)
+	expected := `failed to send PagerDuty trigger event for dra/devices-exhausted/NVIDIA A100: HTTP 400: {"status":"ok"}`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Trigger() error = %v, want %s", err, expected)
+	}
+}
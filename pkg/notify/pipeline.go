@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Route sends conditions of the given kinds, or of every kind if none are
+// given, to channels.
+type Route struct {
+	Kinds    []string
+	Channels []string
+}
+
+// Delivery is a notification sent, or attempted, by a pipeline.
+type Delivery struct {
+	Channel string
+	// Resolve is set for resolutions, and unset for triggers.
+	Resolve   bool
+	Condition Condition
+	Err       error
+}
+
+// Pipeline delivers conditions to channels without spamming them: each
+// active condition is triggered once per channel, failed deliveries are
+// retried on the next evaluation, and a cleared condition is only resolved
+// once it stayed clear for the cooldown, so flapping conditions keep their
+// incident open instead of opening new ones. Triggers beyond the rate limit
+// of a channel wait for a later evaluation. NextDue tells when an evaluation
+// has something to send even if the conditions did not change.
+type Pipeline struct {
+	channels map[string]Pager
+	routes   []Route
+	cooldown time.Duration
+	// rateLimit is the maximum number of triggers per channel and hour, 0
+	// for no limit.
+	rateLimit int
+
+	now        func() time.Time
+	conditions map[string]*conditionState
+	// sent holds the times of recent triggers per channel.
+	sent map[string][]time.Time
+	// heldUntil is when the earliest trigger held back by the rate limit
+	// may be sent, zero if none is.
+	heldUntil time.Time
+}
+
+type conditionState struct {
+	condition Condition
+	// clearedAt is when the condition was last seen clearing, zero while it
+	// is active.
+	clearedAt time.Time
+	// triggered holds the channels the condition was delivered to.
+	triggered map[string]bool
+}
+
+// kinds are the known kinds of conditions.
+var kinds = map[string]bool{KindDevicesExhausted: true, KindDriverDown: true}
+
+// NewPipeline returns a pipeline delivering to channels, keyed by name,
+// following routes. Without routes, every condition goes to every channel.
+func NewPipeline(channels map[string]Pager, routes []Route, cooldown time.Duration, rateLimit int) (*Pipeline, error) {
+	for i, route := range routes {
+		if len(route.Channels) == 0 {
+			return nil, fmt.Errorf("route %d has no channels", i+1)
+		}
+		for _, channel := range route.Channels {
+			if _, ok := channels[channel]; !ok {
+				return nil, fmt.Errorf("route %d sends to channel %q, which is not configured", i+1, channel)
+			}
+		}
+		for _, kind := range route.Kinds {
+			if !kinds[kind] {
+				return nil, fmt.Errorf("route %d has unknown condition kind %q, expected %s or %s", i+1, kind, KindDevicesExhausted, KindDriverDown)
+			}
+		}
+	}
+	return &Pipeline{
+		channels:   channels,
+		routes:     routes,
+		cooldown:   cooldown,
+		rateLimit:  rateLimit,
+		now:        time.Now,
+		conditions: make(map[string]*conditionState),
+		sent:       make(map[string][]time.Time),
+	}, nil
+}
+
+// channelsFor returns the channels a condition of kind is routed to, sorted.
+func (p *Pipeline) channelsFor(kind string) []string {
+	selected := make(map[string]bool)
+	if len(p.routes) == 0 {
+		for channel := range p.channels {
+			selected[channel] = true
+		}
+	}
+	for _, route := range p.routes {
+		matches := len(route.Kinds) == 0
+		for _, k := range route.Kinds {
+			matches = matches || k == kind
+		}
+		if matches {
+			for _, channel := range route.Channels {
+				selected[channel] = true
+			}
+		}
+	}
+	channels := make([]string, 0, len(selected))
+	for channel := range selected {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Evaluate takes the currently active conditions and sends the triggers and
+// resolutions due, returning them in order.
+func (p *Pipeline) Evaluate(ctx context.Context, active []Condition) []Delivery {
+	now := p.now()
+	var deliveries []Delivery
+	p.heldUntil = time.Time{}
+
+	current := make(map[string]bool, len(active))
+	for _, c := range active {
+		current[c.Key] = true
+		state, ok := p.conditions[c.Key]
+		if !ok {
+			state = &conditionState{triggered: make(map[string]bool)}
+			p.conditions[c.Key] = state
+		}
+		state.condition = c
+		state.clearedAt = time.Time{}
+		for _, channel := range p.channelsFor(c.Kind) {
+			if state.triggered[channel] {
+				continue
+			}
+			if !p.allow(channel, now) {
+				if until := p.sent[channel][0].Add(time.Hour); p.heldUntil.IsZero() || until.Before(p.heldUntil) {
+					p.heldUntil = until
+				}
+				continue
+			}
+			err := p.channels[channel].Trigger(ctx, c)
+			if err == nil {
+				state.triggered[channel] = true
+				p.sent[channel] = append(p.sent[channel], now)
+			}
+			deliveries = append(deliveries, Delivery{Channel: channel, Condition: c, Err: err})
+		}
+	}
+
+	keys := make([]string, 0, len(p.conditions))
+	for key := range p.conditions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		state := p.conditions[key]
+		if current[key] {
+			continue
+		}
+		if state.clearedAt.IsZero() {
+			state.clearedAt = now
+		}
+		if now.Sub(state.clearedAt) < p.cooldown {
+			continue
+		}
+		channels := make([]string, 0, len(state.triggered))
+		for channel := range state.triggered {
+			channels = append(channels, channel)
+		}
+		sort.Strings(channels)
+		for _, channel := range channels {
+			err := p.channels[channel].Resolve(ctx, state.condition)
+			if err == nil {
+				delete(state.triggered, channel)
+			}
+			deliveries = append(deliveries, Delivery{Channel: channel, Resolve: true, Condition: state.condition, Err: err})
+		}
+		if len(state.triggered) == 0 {
+			delete(p.conditions, key)
+		}
+	}
+	return deliveries
+}
+
+// NextDue returns when the next evaluation has something to send with the
+// same conditions: the earliest time a cleared condition's cooldown expires
+// or a trigger held back by the rate limit may be sent. It returns false if
+// nothing is pending, and a time in the past while failed resolutions await
+// a retry.
+func (p *Pipeline) NextDue() (time.Time, bool) {
+	due := p.heldUntil
+	for _, state := range p.conditions {
+		if state.clearedAt.IsZero() {
+			continue
+		}
+		if at := state.clearedAt.Add(p.cooldown); due.IsZero() || at.Before(due) {
+			due = at
+		}
+	}
+	return due, !due.IsZero()
+}
+
+// allow reports whether channel may receive another trigger at now under the
+// rate limit.
+func (p *Pipeline) allow(channel string, now time.Time) bool {
+	if p.rateLimit <= 0 {
+		return true
+	}
+	recent := p.sent[channel][:0]
+	for _, t := range p.sent[channel] {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	p.sent[channel] = recent
+	return len(recent) < p.rateLimit
+}
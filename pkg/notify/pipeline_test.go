@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakePager records the keys it triggered and resolved, failing while err
+// is set.
+type fakePager struct {
+	calls []string
+	err   error
+}
+
+func (f *fakePager) Trigger(ctx context.Context, c Condition) error {
+	f.calls = append(f.calls, "trigger "+c.Key)
+	return f.err
+}
+
+func (f *fakePager) Resolve(ctx context.Context, c Condition) error {
+	f.calls = append(f.calls, "resolve "+c.Key)
+	return f.err
+}
+
+func TestPipeline(t *testing.T) {
+	exhausted := Condition{Kind: KindDevicesExhausted, Key: "exhausted"}
+	driverDown := Condition{Kind: KindDriverDown, Key: "down"}
+	otherDown := Condition{Kind: KindDriverDown, Key: "other-down"}
+
+	// each step evaluates the active conditions a minute after the last
+	type step struct {
+		active  []Condition
+		failing bool
+	}
+	tests := []struct {
+		name      string
+		routes    []Route
+		cooldown  time.Duration
+		rateLimit int
+		steps     []step
+		expected  map[string][]string
+	}{
+		{
+			name: "deduplicates and resolves",
+			steps: []step{
+				{active: []Condition{exhausted}},
+				{active: []Condition{exhausted}},
+				{},
+			},
+			expected: map[string][]string{
+				"pagerDuty": {"trigger exhausted", "resolve exhausted"},
+				"opsgenie":  {"trigger exhausted", "resolve exhausted"},
+			},
+		},
+		{
+			name:   "routes by kind",
+			routes: []Route{{Kinds: []string{KindDriverDown}, Channels: []string{"pagerDuty"}}, {Channels: []string{"opsgenie"}}},
+			steps:  []step{{active: []Condition{exhausted, driverDown}}},
+			expected: map[string][]string{
+				"pagerDuty": {"trigger down"},
+				"opsgenie":  {"trigger exhausted", "trigger down"},
+			},
+		},
+		{
+			name:     "cooldown keeps flapping condition open",
+			routes:   []Route{{Channels: []string{"pagerDuty"}}},
+			cooldown: 2 * time.Minute,
+			steps: []step{
+				{active: []Condition{exhausted}},
+				{},
+				{active: []Condition{exhausted}},
+				{},
+				{},
+				{},
+			},
+			expected: map[string][]string{
+				"pagerDuty": {"trigger exhausted", "resolve exhausted"},
+			},
+		},
+		{
+			name:      "rate limit defers triggers",
+			routes:    []Route{{Channels: []string{"pagerDuty"}}},
+			rateLimit: 2,
+			steps: []step{
+				{active: []Condition{exhausted, driverDown, otherDown}},
+				{active: []Condition{exhausted, driverDown, otherDown}},
+			},
+			expected: map[string][]string{
+				"pagerDuty": {"trigger exhausted", "trigger down"},
+			},
+		},
+		{
+			name:   "retries failed deliveries",
+			routes: []Route{{Channels: []string{"pagerDuty"}}},
+			steps: []step{
+				{active: []Condition{exhausted}, failing: true},
+				{active: []Condition{exhausted}},
+				{active: []Condition{exhausted}},
+			},
+			expected: map[string][]string{
+				"pagerDuty": {"trigger exhausted", "trigger exhausted"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagers := map[string]*fakePager{"pagerDuty": {}, "opsgenie": {}}
+			channels := map[string]Pager{"pagerDuty": pagers["pagerDuty"], "opsgenie": pagers["opsgenie"]}
+			pipeline, err := NewPipeline(channels, tt.routes, tt.cooldown, tt.rateLimit)
+			if err != nil {
+				t.Fatalf("NewPipeline() error = %v", err)
+			}
+			now := time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)
+			pipeline.now = func() time.Time { return now }
+			for _, s := range tt.steps {
+				for _, pager := range pagers {
+					pager.err = nil
+					if s.failing {
+						pager.err = errors.New("unavailable")
+					}
+				}
+				pipeline.Evaluate(context.Background(), s.active)
+				now = now.Add(time.Minute)
+			}
+
+			got := make(map[string][]string)
+			for name, pager := range pagers {
+				if len(pager.calls) > 0 {
+					got[name] = pager.calls
+				}
+			}
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPipelineNextDue(t *testing.T) {
+	exhausted := Condition{Kind: KindDevicesExhausted, Key: "exhausted"}
+	driverDown := Condition{Kind: KindDriverDown, Key: "down"}
+	pager := &fakePager{}
+	pipeline, err := NewPipeline(map[string]Pager{"pagerDuty": pager}, nil, 5*time.Minute, 1)
+	if err != nil {
+		t.Fatalf("NewPipeline() error = %v", err)
+	}
+	start := time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)
+	now := start
+	pipeline.now = func() time.Time { return now }
+
+	if _, ok := pipeline.NextDue(); ok {
+		t.Errorf("expected nothing due before any evaluation")
+	}
+
+	// the second trigger is held back by the rate limit for an hour
+	pipeline.Evaluate(context.Background(), []Condition{exhausted, driverDown})
+	if due, ok := pipeline.NextDue(); !ok || !due.Equal(start.Add(time.Hour)) {
+		t.Errorf("NextDue() = %v, %v, want %v", due, ok, start.Add(time.Hour))
+	}
+
+	// the cleared condition is due when its cooldown expires
+	now = start.Add(time.Minute)
+	pipeline.Evaluate(context.Background(), []Condition{driverDown})
+	due, ok := pipeline.NextDue()
+	if !ok || !due.Equal(now.Add(5*time.Minute)) {
+		t.Errorf("NextDue() = %v, %v, want %v", due, ok, now.Add(5*time.Minute))
+	}
+
+	// evaluating then resolves it without any change of the conditions
+	now = due
+	pipeline.Evaluate(context.Background(), []Condition{driverDown})
+	expected := []string{"trigger exhausted", "resolve exhausted"}
+	if diff := cmp.Diff(pager.calls, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestNewPipelineErrors(t *testing.T) {
+	channels := map[string]Pager{"pagerDuty": &fakePager{}}
+	tests := []struct {
+		name   string
+		routes []Route
+		err    string
+	}{
+		{
+			name:   "unknown channel",
+			routes: []Route{{Channels: []string{"slack"}}},
+			err:    `route 1 sends to channel "slack", which is not configured`,
+		},
+		{
+			name:   "unknown kind",
+			routes: []Route{{Kinds: []string{"gpu-hot"}, Channels: []string{"pagerDuty"}}},
+			err:    `route 1 has unknown condition kind "gpu-hot", expected devices-exhausted or driver-down`,
+		},
+		{
+			name:   "no channels",
+			routes: []Route{{Kinds: []string{KindDriverDown}}},
+			err:    "route 1 has no channels",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPipeline(channels, tt.routes, 0, 0)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("NewPipeline() error = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
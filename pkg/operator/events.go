@@ -0,0 +1,100 @@
+// Package operator holds the logic of the long-running operator mode, which
+// reacts to changes of the cluster's DRA state.
+package operator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Event reasons emitted on nodes.
+const (
+	// ReasonDevicesExhausted is emitted when the last available device of a
+	// product on a node is allocated.
+	ReasonDevicesExhausted = "DRADevicesExhausted"
+	// ReasonResourceSlicesStale is emitted when a pool on a node stops having
+	// all of its ResourceSlices published.
+	ReasonResourceSlicesStale = "DRAResourceSlicesStale"
+)
+
+// Event is a Kubernetes Event to emit on a node.
+type Event struct {
+	NodeName string
+	// Type is corev1.EventTypeNormal or corev1.EventTypeWarning.
+	Type    string
+	Reason  string
+	Message string
+}
+
+// Transitions compares two consecutive observations of the nodes and returns
+// the events for conditions that newly appeared in cur: products whose
+// available devices on a node dropped to zero, and pools that became
+// incomplete. A nil prev treats every current condition as new.
+func Transitions(prev, cur []*types.NodeInfo) []Event {
+	prevNodes := make(map[string]*types.NodeInfo)
+	for _, node := range prev {
+		prevNodes[node.NodeName] = node
+	}
+
+	var events []Event
+	for _, node := range cur {
+		before := prevNodes[node.NodeName]
+
+		for _, dev := range node.Devices {
+			if dev.TotalCount == 0 || dev.AvailableCount > 0 {
+				continue
+			}
+			if before != nil && exhausted(before, dev.ProductName) {
+				continue
+			}
+			events = append(events, Event{
+				NodeName: node.NodeName,
+				Type:     corev1.EventTypeWarning,
+				Reason:   ReasonDevicesExhausted,
+				Message:  fmt.Sprintf("All %d %s devices on the node are allocated", dev.TotalCount, dev.ProductName),
+			})
+		}
+
+		for _, pool := range node.Pools {
+			if pool.Complete() {
+				continue
+			}
+			if before != nil && incomplete(before, pool) {
+				continue
+			}
+			events = append(events, Event{
+				NodeName: node.NodeName,
+				Type:     corev1.EventTypeWarning,
+				Reason:   ReasonResourceSlicesStale,
+				Message: fmt.Sprintf("Pool %s of driver %s has %d of %d ResourceSlices published; the scheduler does not allocate from it",
+					pool.Name, pool.Driver, pool.ObservedSliceCount, pool.ResourceSliceCount),
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].NodeName < events[j].NodeName
+	})
+	return events
+}
+
+func exhausted(node *types.NodeInfo, productName string) bool {
+	for _, dev := range node.Devices {
+		if dev.ProductName == productName {
+			return dev.TotalCount > 0 && dev.AvailableCount == 0
+		}
+	}
+	return false
+}
+
+func incomplete(node *types.NodeInfo, pool types.Pool) bool {
+	for _, p := range node.Pools {
+		if p.Driver == pool.Driver && p.Name == pool.Name {
+			return !p.Complete()
+		}
+	}
+	return false
+}
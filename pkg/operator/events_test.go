@@ -0,0 +1,69 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTransitions(t *testing.T) {
+	node := func(available int, observedSlices int64) *types.NodeInfo {
+		return &types.NodeInfo{
+			NodeName: "node-1",
+			Devices:  []types.Device{{ProductName: "NVIDIA A100", TotalCount: 2, AvailableCount: available}},
+			Pools:    []types.Pool{{Driver: "gpu.nvidia.com", Name: "node-1", ResourceSliceCount: 2, ObservedSliceCount: observedSlices}},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		prev     []*types.NodeInfo
+		cur      []*types.NodeInfo
+		expected []string
+	}{
+		{
+			name: "nothing changed",
+			prev: []*types.NodeInfo{node(1, 2)},
+			cur:  []*types.NodeInfo{node(1, 2)},
+		},
+		{
+			name:     "availability drops to zero",
+			prev:     []*types.NodeInfo{node(1, 2)},
+			cur:      []*types.NodeInfo{node(0, 2)},
+			expected: []string{ReasonDevicesExhausted},
+		},
+		{
+			name: "still exhausted",
+			prev: []*types.NodeInfo{node(0, 2)},
+			cur:  []*types.NodeInfo{node(0, 2)},
+		},
+		{
+			name:     "pool becomes incomplete",
+			prev:     []*types.NodeInfo{node(1, 2)},
+			cur:      []*types.NodeInfo{node(1, 1)},
+			expected: []string{ReasonResourceSlicesStale},
+		},
+		{
+			name:     "first observation",
+			cur:      []*types.NodeInfo{node(0, 1)},
+			expected: []string{ReasonDevicesExhausted, ReasonResourceSlicesStale},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reasons []string
+			for _, event := range Transitions(tc.prev, tc.cur) {
+				if event.Type != corev1.EventTypeWarning || event.NodeName != "node-1" {
+					t.Errorf("unexpected event %+v", event)
+				}
+				reasons = append(reasons, event.Reason)
+			}
+			if diff := cmp.Diff(reasons, tc.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package recorder
+
+import (
+	"context"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// Backlog records snapshots through a Recorder and keeps the ones the store
+// failed to take, retrying them in order, so a recorder running for weeks
+// outlives outages of its database or remote-write endpoint. Beyond its
+// capacity, the oldest pending snapshots are dropped.
+type Backlog struct {
+	rec      *Recorder
+	capacity int
+	pending  []snapshot
+	// Dropped counts the snapshots dropped because the backlog was full.
+	Dropped int
+}
+
+// snapshot is the state recorded at a time; nodesRecorded is set once its
+// allocation is stored, so a retry does not store it twice.
+type snapshot struct {
+	t             time.Time
+	nodes         []*types.NodeInfo
+	devices       []types.DeviceInfo
+	nodesRecorded bool
+}
+
+// NewBacklog returns a backlog holding up to capacity pending snapshots.
+func NewBacklog(rec *Recorder, capacity int) *Backlog {
+	return &Backlog{rec: rec, capacity: capacity}
+}
+
+// Record queues the allocation of the nodes and the states of the devices at
+// time t, then stores the pending snapshots. It returns the error of the
+// first one that could not be stored, which stays pending with the later
+// ones.
+func (b *Backlog) Record(ctx context.Context, t time.Time, nodes []*types.NodeInfo, devices []types.DeviceInfo) error {
+	b.pending = append(b.pending, snapshot{t: t, nodes: nodes, devices: devices})
+	if len(b.pending) > b.capacity {
+		b.Dropped += len(b.pending) - b.capacity
+		b.pending = b.pending[len(b.pending)-b.capacity:]
+	}
+	return b.Flush(ctx)
+}
+
+// Flush stores the pending snapshots, oldest first, stopping at the first
+// error.
+func (b *Backlog) Flush(ctx context.Context) error {
+	for len(b.pending) > 0 {
+		s := &b.pending[0]
+		if !s.nodesRecorded {
+			if err := b.rec.Record(ctx, s.t, s.nodes); err != nil {
+				return err
+			}
+			s.nodesRecorded = true
+		}
+		consumers := make(map[string][]string)
+		for _, dev := range s.devices {
+			if dev.Claim != "" {
+				consumers[dev.Claim] = dev.Consumers
+			}
+		}
+		if err := b.rec.RecordDevices(ctx, s.t, s.devices, consumers); err != nil {
+			return err
+		}
+		b.pending = b.pending[1:]
+	}
+	return nil
+}
+
+// Pending returns the number of snapshots not stored yet.
+func (b *Backlog) Pending() int {
+	return len(b.pending)
+}
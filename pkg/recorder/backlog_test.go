@@ -0,0 +1,75 @@
+package recorder
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/golang/snappy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBacklog(t *testing.T) {
+	// failing decides which pushes the endpoint rejects; accepted ones are
+	// recorded as metric@time
+	var failing func(name string) bool
+	var accepted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request, err := snappy.Decode(nil, body)
+		if err != nil {
+			t.Errorf("failed to decode the request: %v", err)
+		}
+		samples := decodeWriteRequest(t, request)
+		if failing(samples[0].Labels["__name__"]) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		accepted = append(accepted, samples[0].Labels["__name__"]+"@"+time.UnixMilli(samples[0].Timestamp).UTC().Format("15:04"))
+	}))
+	defer server.Close()
+
+	r, err := Open(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	backlog := NewBacklog(r, 2)
+
+	ctx := context.Background()
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	nodes := []*types.NodeInfo{{NodeName: "node-1", Devices: []types.Device{{ProductName: "H100", TotalCount: 8}}}}
+	devices := []types.DeviceInfo{{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0"}}
+
+	// while the endpoint is down, the latest snapshots are kept
+	failing = func(string) bool { return true }
+	for i := range 3 {
+		if err := backlog.Record(ctx, start.Add(time.Duration(i)*time.Minute), nodes, devices); err == nil {
+			t.Fatal("Record() succeeded while the endpoint is down")
+		}
+	}
+	if backlog.Pending() != 2 || backlog.Dropped != 1 {
+		t.Errorf("Pending() = %d, Dropped = %d, want 2 pending and 1 dropped", backlog.Pending(), backlog.Dropped)
+	}
+
+	// allocation stored before the devices failed is not stored again
+	failing = func(name string) bool { return name == DeviceUnhealthy }
+	if err := backlog.Flush(ctx); err == nil {
+		t.Fatal("Flush() succeeded while device pushes fail")
+	}
+	failing = func(string) bool { return false }
+	if err := backlog.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if backlog.Pending() != 0 {
+		t.Errorf("Pending() = %d after a successful flush", backlog.Pending())
+	}
+	expected := []string{"dra_devices_total@12:01", DeviceUnhealthy + "@12:01", "dra_devices_total@12:02", DeviceUnhealthy + "@12:02"}
+	if diff := cmp.Diff(accepted, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,186 @@
+package recorder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// deviceID identifies a device the way allocation results do.
+type deviceID struct{ driver, pool, device string }
+
+// deviceState is what a row of device_states records about a device.
+type deviceState struct {
+	node, claim, consumers string
+	unhealthy              bool
+}
+
+// RecordDevices stores who holds each device and whether it is healthy at
+// time t. consumers maps claims (namespace/name) to the pods they are
+// reserved for. Only changes are stored: a device keeps its state until a
+// later snapshot shows another one or no longer lists the device.
+func (r *Recorder) RecordDevices(ctx context.Context, t time.Time, devices []types.DeviceInfo, consumers map[string][]string) error {
+	if err := r.store.recordDevices(ctx, t, devices, consumers); err != nil {
+		return fmt.Errorf("failed to record devices: %w", err)
+	}
+	return nil
+}
+
+// DeviceHistory returns the recorded states of a device, oldest first.
+func (r *Recorder) DeviceHistory(ctx context.Context, driver, pool, device string) ([]types.DeviceState, error) {
+	return r.store.deviceHistory(ctx, deviceID{driver, pool, device})
+}
+
+// DeviceHistories returns the recorded states of all devices that lasted
+// until since or later, grouped by device and sorted by driver, pool and
+// device name. The first state of a device may have begun before since.
+func (r *Recorder) DeviceHistories(ctx context.Context, since time.Time) ([]types.DeviceHistory, error) {
+	return r.store.deviceHistories(ctx, since)
+}
+
+func (s *sqlStore) recordDevices(ctx context.Context, t time.Time, devices []types.DeviceInfo, consumers map[string][]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT "+s.rowID+", driver, pool, device, node, claim, consumers, unhealthy FROM device_states WHERE until IS NULL")
+	if err != nil {
+		return err
+	}
+	type openState struct {
+		rowid int64
+		state deviceState
+	}
+	open := make(map[deviceID]openState)
+	for rows.Next() {
+		var id deviceID
+		var o openState
+		if err := rows.Scan(&o.rowid, &id.driver, &id.pool, &id.device, &o.state.node, &o.state.claim, &o.state.consumers, &o.state.unhealthy); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read device history: %w", err)
+		}
+		open[id] = o
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read device history: %w", err)
+	}
+
+	closeState, err := tx.PrepareContext(ctx, s.bind("UPDATE device_states SET until = ? WHERE "+s.rowID+" = ?"))
+	if err != nil {
+		return err
+	}
+	defer closeState.Close()
+	insertState, err := tx.PrepareContext(ctx, s.bind("INSERT INTO device_states (driver, pool, device, node, claim, consumers, unhealthy, since) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"))
+	if err != nil {
+		return err
+	}
+	defer insertState.Close()
+
+	for _, dev := range devices {
+		id := deviceID{dev.Driver, dev.Pool, dev.Name}
+		state := deviceState{node: dev.NodeName, claim: dev.Claim, unhealthy: dev.Unhealthy}
+		if dev.Claim != "" {
+			state.consumers = strings.Join(consumers[dev.Claim], ",")
+		}
+		prev, ok := open[id]
+		delete(open, id)
+		if ok && prev.state == state {
+			continue
+		}
+		if ok {
+			if _, err := closeState.ExecContext(ctx, t.Unix(), prev.rowid); err != nil {
+				return err
+			}
+		}
+		if _, err := insertState.ExecContext(ctx, id.driver, id.pool, id.device, state.node, state.claim, state.consumers, state.unhealthy, t.Unix()); err != nil {
+			return err
+		}
+	}
+	// devices no longer published, e.g. of removed nodes
+	for _, prev := range open {
+		if _, err := closeState.ExecContext(ctx, t.Unix(), prev.rowid); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqlStore) deviceHistory(ctx context.Context, id deviceID) ([]types.DeviceState, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind(`
+		SELECT node, claim, consumers, unhealthy, since, until FROM device_states
+		WHERE driver = ? AND pool = ? AND device = ?
+		ORDER BY since`), id.driver, id.pool, id.device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []types.DeviceState
+	for rows.Next() {
+		var state types.DeviceState
+		var consumers string
+		var since int64
+		var until sql.NullInt64
+		if err := rows.Scan(&state.NodeName, &state.Claim, &consumers, &state.Unhealthy, &since, &until); err != nil {
+			return nil, fmt.Errorf("failed to read device history: %w", err)
+		}
+		state.Since = time.Unix(since, 0).UTC()
+		if until.Valid {
+			state.Until = time.Unix(until.Int64, 0).UTC()
+		}
+		if consumers != "" {
+			state.Consumers = strings.Split(consumers, ",")
+		}
+		history = append(history, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read device history: %w", err)
+	}
+	return history, nil
+}
+
+func (s *sqlStore) deviceHistories(ctx context.Context, since time.Time) ([]types.DeviceHistory, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind(`
+		SELECT driver, pool, device, node, claim, consumers, unhealthy, since, until FROM device_states
+		WHERE until IS NULL OR until >= ?
+		ORDER BY driver, pool, device, since`), since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device history: %w", err)
+	}
+	defer rows.Close()
+
+	var histories []types.DeviceHistory
+	for rows.Next() {
+		var id deviceID
+		var state types.DeviceState
+		var consumers string
+		var since int64
+		var until sql.NullInt64
+		if err := rows.Scan(&id.driver, &id.pool, &id.device, &state.NodeName, &state.Claim, &consumers, &state.Unhealthy, &since, &until); err != nil {
+			return nil, fmt.Errorf("failed to read device history: %w", err)
+		}
+		state.Since = time.Unix(since, 0).UTC()
+		if until.Valid {
+			state.Until = time.Unix(until.Int64, 0).UTC()
+		}
+		if consumers != "" {
+			state.Consumers = strings.Split(consumers, ",")
+		}
+		if n := len(histories); n == 0 || histories[n-1].Driver != id.driver || histories[n-1].Pool != id.pool || histories[n-1].Device != id.device {
+			histories = append(histories, types.DeviceHistory{Driver: id.driver, Pool: id.pool, Device: id.device})
+		}
+		last := &histories[len(histories)-1]
+		last.States = append(last.States, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read device history: %w", err)
+	}
+	return histories, nil
+}
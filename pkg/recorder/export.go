@@ -0,0 +1,167 @@
+package recorder
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Tables of the history that can be exported.
+const (
+	// TableAllocation holds the total and allocated devices of each node and
+	// product, one row per node and product of every snapshot.
+	TableAllocation = "allocation"
+	// TableDevices holds the states of each device, one row per state.
+	TableDevices = "devices"
+)
+
+// Export formats.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// AllocationRow is a row of the exported allocation table.
+type AllocationRow struct {
+	Time      time.Time `parquet:"time"`
+	Node      string    `parquet:"node,dict"`
+	Product   string    `parquet:"product,dict"`
+	Total     int64     `parquet:"total"`
+	Allocated int64     `parquet:"allocated"`
+}
+
+// DeviceStateRow is a row of the exported devices table. Until is nil for
+// current states.
+type DeviceStateRow struct {
+	Driver    string     `parquet:"driver,dict"`
+	Pool      string     `parquet:"pool,dict"`
+	Device    string     `parquet:"device"`
+	Node      string     `parquet:"node,dict"`
+	Claim     string     `parquet:"claim"`
+	Consumers []string   `parquet:"consumers,list"`
+	Unhealthy bool       `parquet:"unhealthy"`
+	Since     time.Time  `parquet:"since"`
+	Until     *time.Time `parquet:"until,optional"`
+}
+
+// Export writes the rows of the table recorded since the given time to w,
+// in CSV with a header row or as a Parquet file, for analysis in notebooks.
+// Device states are exported if they lasted until since or later.
+func (r *Recorder) Export(ctx context.Context, w io.Writer, table, format string, since time.Time) error {
+	if format != FormatCSV && format != FormatParquet {
+		return fmt.Errorf("unknown export format %q, expected %s or %s", format, FormatCSV, FormatParquet)
+	}
+	switch table {
+	case TableAllocation:
+		rows, err := r.store.allocationRows(ctx, since)
+		if err != nil {
+			return fmt.Errorf("failed to read allocation history: %w", err)
+		}
+		if format == FormatParquet {
+			return writeParquet(w, rows)
+		}
+		return writeCSV(w, []string{"time", "node", "product", "total", "allocated"}, rows, func(row AllocationRow) []string {
+			return []string{formatTime(row.Time), row.Node, row.Product, strconv.FormatInt(row.Total, 10), strconv.FormatInt(row.Allocated, 10)}
+		})
+	case TableDevices:
+		histories, err := r.store.deviceHistories(ctx, since)
+		if err != nil {
+			return fmt.Errorf("failed to read device history: %w", err)
+		}
+		var rows []DeviceStateRow
+		for _, history := range histories {
+			for _, state := range history.States {
+				row := DeviceStateRow{
+					Driver:    history.Driver,
+					Pool:      history.Pool,
+					Device:    history.Device,
+					Node:      state.NodeName,
+					Claim:     state.Claim,
+					Consumers: state.Consumers,
+					Unhealthy: state.Unhealthy,
+					Since:     state.Since,
+				}
+				if !state.Until.IsZero() {
+					until := state.Until
+					row.Until = &until
+				}
+				rows = append(rows, row)
+			}
+		}
+		if format == FormatParquet {
+			return writeParquet(w, rows)
+		}
+		header := []string{"driver", "pool", "device", "node", "claim", "consumers", "unhealthy", "since", "until"}
+		return writeCSV(w, header, rows, func(row DeviceStateRow) []string {
+			until := ""
+			if row.Until != nil {
+				until = formatTime(*row.Until)
+			}
+			return []string{row.Driver, row.Pool, row.Device, row.Node, row.Claim, strings.Join(row.Consumers, " "),
+				strconv.FormatBool(row.Unhealthy), formatTime(row.Since), until}
+		})
+	default:
+		return fmt.Errorf("unknown history table %q, expected %s or %s", table, TableAllocation, TableDevices)
+	}
+}
+
+// formatTime formats exported times in UTC, which spreadsheets and pandas
+// parse alike.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeCSV[T any](w io.Writer, header []string, rows []T, record func(T) []string) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, row := range rows {
+		cw.Write(record(row))
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+func writeParquet[T any](w io.Writer, rows []T) error {
+	pw := parquet.NewGenericWriter[T](w, parquet.Compression(&parquet.Zstd))
+	if _, err := pw.Write(rows); err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+	if err := pw.Close(); err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) allocationRows(ctx context.Context, since time.Time) ([]AllocationRow, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind(`
+		SELECT time, node, product, total, allocated FROM allocation_samples
+		WHERE time >= ? ORDER BY time, node, product`), since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []AllocationRow
+	for rows.Next() {
+		var row AllocationRow
+		var unix int64
+		if err := rows.Scan(&unix, &row.Node, &row.Product, &row.Total, &row.Allocated); err != nil {
+			return nil, err
+		}
+		row.Time = time.Unix(unix, 0).UTC()
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func (s *remoteWriteStore) allocationRows(context.Context, time.Time) ([]AllocationRow, error) {
+	return nil, ErrWriteOnly
+}
@@ -0,0 +1,95 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestExport(t *testing.T) {
+	testStores(t, func(t *testing.T, r *Recorder) {
+		ctx := context.Background()
+		start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		for i, available := range []int{4, 3, 1} {
+			nodes := []*types.NodeInfo{{NodeName: "node-1", Devices: []types.Device{{ProductName: "H100", TotalCount: 4, AvailableCount: available}}}}
+			if err := r.Record(ctx, start.Add(time.Duration(i)*time.Hour), nodes); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+		device := func(claim string, unhealthy bool) []types.DeviceInfo {
+			return []types.DeviceInfo{{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", Claim: claim, Unhealthy: unhealthy}}
+		}
+		consumers := map[string][]string{"team-a/train": {"team-a/train-0", "team-a/train-1"}}
+		if err := r.RecordDevices(ctx, start, device("team-a/train", false), consumers); err != nil {
+			t.Fatalf("RecordDevices() error = %v", err)
+		}
+		if err := r.RecordDevices(ctx, start.Add(2*time.Hour), device("", true), consumers); err != nil {
+			t.Fatalf("RecordDevices() error = %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := r.Export(ctx, &buf, TableAllocation, FormatCSV, start.Add(time.Hour)); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		expected := "time,node,product,total,allocated\n" +
+			"2025-06-01T13:00:00Z,node-1,H100,4,1\n" +
+			"2025-06-01T14:00:00Z,node-1,H100,4,3\n"
+		if diff := cmp.Diff(buf.String(), expected); diff != "" {
+			t.Errorf("allocation CSV mismatch (-got +want):\n%s", diff)
+		}
+
+		buf.Reset()
+		if err := r.Export(ctx, &buf, TableDevices, FormatCSV, start); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		expected = "driver,pool,device,node,claim,consumers,unhealthy,since,until\n" +
+			"gpu.example.com,node-1,gpu-0,node-1,team-a/train,team-a/train-0 team-a/train-1,false,2025-06-01T12:00:00Z,2025-06-01T14:00:00Z\n" +
+			"gpu.example.com,node-1,gpu-0,node-1,,,true,2025-06-01T14:00:00Z,\n"
+		if diff := cmp.Diff(buf.String(), expected); diff != "" {
+			t.Errorf("devices CSV mismatch (-got +want):\n%s", diff)
+		}
+
+		buf.Reset()
+		if err := r.Export(ctx, &buf, TableAllocation, FormatParquet, start); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		allocation, err := parquet.Read[AllocationRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("failed to read Parquet: %v", err)
+		}
+		expectedAllocation := []AllocationRow{
+			{Time: start, Node: "node-1", Product: "H100", Total: 4},
+			{Time: start.Add(time.Hour), Node: "node-1", Product: "H100", Total: 4, Allocated: 1},
+			{Time: start.Add(2 * time.Hour), Node: "node-1", Product: "H100", Total: 4, Allocated: 3},
+		}
+		if diff := cmp.Diff(allocation, expectedAllocation); diff != "" {
+			t.Errorf("allocation Parquet mismatch (-got +want):\n%s", diff)
+		}
+
+		buf.Reset()
+		if err := r.Export(ctx, &buf, TableDevices, FormatParquet, start); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		states, err := parquet.Read[DeviceStateRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("failed to read Parquet: %v", err)
+		}
+		until := start.Add(2 * time.Hour)
+		expectedStates := []DeviceStateRow{
+			{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0", Node: "node-1", Claim: "team-a/train", Consumers: consumers["team-a/train"], Since: start, Until: &until},
+			{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0", Node: "node-1", Consumers: []string{}, Unhealthy: true, Since: until},
+		}
+		if diff := cmp.Diff(states, expectedStates); diff != "" {
+			t.Errorf("devices Parquet mismatch (-got +want):\n%s", diff)
+		}
+
+		if err := r.Export(ctx, &buf, "pods", FormatCSV, start); err == nil {
+			t.Error("Export() of an unknown table succeeded")
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Package recorder keeps a history of device allocation, so views can show
+// trends and the past holders of a device without an external time series
+// database. The history is stored in a SQLite database by default; teams
+// wanting central storage across clusters can use PostgreSQL, or push the
+// samples to a Prometheus remote-write endpoint.
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// ErrWriteOnly is returned when reading the history of a store that can only
+// be written to, such as a remote-write endpoint.
+var ErrWriteOnly = errors.New("the recorder store is write-only")
+
+// store is where a Recorder keeps the history.
+type store interface {
+	record(ctx context.Context, t time.Time, nodes []*types.NodeInfo) error
+	recordDevices(ctx context.Context, t time.Time, devices []types.DeviceInfo, consumers map[string][]string) error
+	trends(ctx context.Context, since, until time.Time, buckets int) ([]types.AllocationTrend, error)
+	deviceHistory(ctx context.Context, id deviceID) ([]types.DeviceState, error)
+	deviceHistories(ctx context.Context, since time.Time) ([]types.DeviceHistory, error)
+	compact(ctx context.Context, now time.Time, retention Retention) (CompactResult, error)
+	allocationRows(ctx context.Context, since time.Time) ([]AllocationRow, error)
+	close() error
+}
+
+// Recorder stores snapshots of the cluster's device allocation.
+type Recorder struct {
+	store store
+}
+
+// Open opens the history store at location: a postgres:// or postgresql://
+// URL for a PostgreSQL database, an http:// or https:// URL of a Prometheus
+// remote-write endpoint, or else the path of a SQLite database, created if
+// needed. externalLabels are added to every series pushed to a remote-write
+// endpoint, e.g. cluster=prod to tell clusters apart, and ignored otherwise.
+func Open(location string, externalLabels map[string]string) (*Recorder, error) {
+	var s store
+	var err error
+	switch {
+	case strings.HasPrefix(location, "postgres://") || strings.HasPrefix(location, "postgresql://"):
+		s, err = openSQL(postgres, location)
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		s = newRemoteWriteStore(location, externalLabels)
+	default:
+		s, err = openSQL(sqlite, location)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{store: s}, nil
+}
+
+// Close closes the store.
+func (r *Recorder) Close() error {
+	return r.store.close()
+}
+
+// Record stores the total and allocated devices of each node and product at
+// time t.
+func (r *Recorder) Record(ctx context.Context, t time.Time, nodes []*types.NodeInfo) error {
+	if err := r.store.record(ctx, t, nodes); err != nil {
+		return fmt.Errorf("failed to record allocation: %w", err)
+	}
+	return nil
+}
+
+// Trends returns the allocation of each node and product recorded in the
+// history, resampled into buckets of equal length between since and until,
+// sorted by node and product. Samples are only recorded when the allocation
+// may have changed, so each sample holds until the next one.
+func (r *Recorder) Trends(ctx context.Context, since, until time.Time, buckets int) ([]types.AllocationTrend, error) {
+	return r.store.trends(ctx, since, until, buckets)
+}
+
+func (s *sqlStore) trends(ctx context.Context, since, until time.Time, buckets int) ([]types.AllocationTrend, error) {
+	// the last sample before the window gives the allocation at its start
+	rows, err := s.db.QueryContext(ctx, s.bind(`
+		SELECT time, node, product, total, allocated FROM allocation_samples
+		WHERE time <= ? AND time >= COALESCE((SELECT MAX(time) FROM allocation_samples WHERE time <= ?), 0)
+		ORDER BY time`), until.Unix(), since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query allocation history: %w", err)
+	}
+	defer rows.Close()
+
+	type series struct{ node, product string }
+	trends := make(map[series]*types.AllocationTrend)
+	bucketLength := until.Sub(since) / time.Duration(buckets)
+	// fill sets the history of a series from the bucket of time unix on, until
+	// overwritten by a later sample
+	fill := func(trend *types.AllocationTrend, unix int64, value float64) {
+		first := 0
+		if t := time.Unix(unix, 0); t.After(since) {
+			first = min(int(t.Sub(since)/bucketLength), buckets-1)
+		}
+		for i := first; i < buckets; i++ {
+			trend.History[i] = value
+		}
+	}
+
+	// the samples of a snapshot share its time; series missing from a
+	// snapshot, e.g. of a removed node, have no allocation from then on
+	snapshot, present := int64(-1), make(map[series]bool)
+	endSnapshot := func() {
+		for s, trend := range trends {
+			if !present[s] {
+				fill(trend, snapshot, -1)
+			}
+		}
+		present = make(map[series]bool)
+	}
+	for rows.Next() {
+		var unix int64
+		var s series
+		var total, allocated int
+		if err := rows.Scan(&unix, &s.node, &s.product, &total, &allocated); err != nil {
+			return nil, fmt.Errorf("failed to read allocation history: %w", err)
+		}
+		if unix != snapshot {
+			if snapshot >= 0 {
+				endSnapshot()
+			}
+			snapshot = unix
+		}
+		present[s] = true
+
+		trend, ok := trends[s]
+		if !ok {
+			trend = &types.AllocationTrend{NodeName: s.node, ProductName: s.product, History: make([]float64, buckets)}
+			for i := range trend.History {
+				trend.History[i] = -1
+			}
+			trends[s] = trend
+		}
+		trend.Allocated, trend.Total = allocated, total
+		fraction := 0.0
+		if total > 0 {
+			fraction = float64(allocated) / float64(total)
+		}
+		fill(trend, unix, fraction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read allocation history: %w", err)
+	}
+	if snapshot >= 0 {
+		endSnapshot()
+	}
+
+	result := make([]types.AllocationTrend, 0, len(trends))
+	for _, trend := range trends {
+		result = append(result, *trend)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NodeName != result[j].NodeName {
+			return result[i].NodeName < result[j].NodeName
+		}
+		return result[i].ProductName < result[j].ProductName
+	})
+	return result, nil
+}
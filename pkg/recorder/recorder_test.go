@@ -0,0 +1,193 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTrends(t *testing.T) {
+	testStores(t, func(t *testing.T, r *Recorder) {
+		node := func(name string, total, available int) *types.NodeInfo {
+			return &types.NodeInfo{NodeName: name, Devices: []types.Device{{ProductName: "gpu", TotalCount: total, AvailableCount: available}}}
+		}
+		ctx := context.Background()
+		start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		snapshots := []struct {
+			offset time.Duration
+			nodes  []*types.NodeInfo
+		}{
+			{-30 * time.Minute, []*types.NodeInfo{node("node-1", 4, 4), node("removed", 2, 0)}},
+			{-10 * time.Minute, []*types.NodeInfo{node("node-1", 4, 3), node("removed", 2, 0)}},
+			{15 * time.Minute, []*types.NodeInfo{node("node-1", 4, 2), node("removed", 2, 1)}},
+			{25 * time.Minute, []*types.NodeInfo{node("node-1", 4, 0), node("added", 8, 8)}},
+			{70 * time.Minute, []*types.NodeInfo{node("node-1", 4, 4)}},
+		}
+		for _, s := range snapshots {
+			if err := r.Record(ctx, start.Add(s.offset), s.nodes); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+
+		got, err := r.Trends(ctx, start, start.Add(time.Hour), 6)
+		if err != nil {
+			t.Fatalf("Trends() error = %v", err)
+		}
+		expected := []types.AllocationTrend{
+			{NodeName: "added", ProductName: "gpu", Total: 8, History: []float64{-1, -1, 0, 0, 0, 0}},
+			{NodeName: "node-1", ProductName: "gpu", Allocated: 4, Total: 4, History: []float64{0.25, 0.5, 1, 1, 1, 1}},
+			{NodeName: "removed", ProductName: "gpu", Allocated: 1, Total: 2, History: []float64{1, 0.5, -1, -1, -1, -1}},
+		}
+		if diff := cmp.Diff(got, expected); diff != "" {
+			t.Errorf("mismatch (-got +want):\n%s", diff)
+		}
+	})
+}
+
+func TestDeviceHistory(t *testing.T) {
+	testStores(t, func(t *testing.T, r *Recorder) {
+		device := func(claim string, unhealthy bool) types.DeviceInfo {
+			return types.DeviceInfo{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", Claim: claim, Unhealthy: unhealthy}
+		}
+		other := types.DeviceInfo{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-1"}
+		consumers := map[string][]string{"team-a/train": {"team-a/train-0", "team-a/train-1"}}
+		ctx := context.Background()
+		start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		snapshots := []struct {
+			offset  time.Duration
+			devices []types.DeviceInfo
+		}{
+			{0, []types.DeviceInfo{device("", false), other}},
+			{time.Minute, []types.DeviceInfo{device("team-a/train", false), other}},
+			// unchanged snapshots add nothing
+			{2 * time.Minute, []types.DeviceInfo{device("team-a/train", false), other}},
+			{3 * time.Minute, []types.DeviceInfo{device("team-a/train", true), other}},
+			{4 * time.Minute, []types.DeviceInfo{device("team-b/infer", false), other}},
+			// the device disappears, e.g. with its node
+			{5 * time.Minute, []types.DeviceInfo{other}},
+			{6 * time.Minute, []types.DeviceInfo{device("", false), other}},
+		}
+		for _, s := range snapshots {
+			if err := r.RecordDevices(ctx, start.Add(s.offset), s.devices, consumers); err != nil {
+				t.Fatalf("RecordDevices() error = %v", err)
+			}
+		}
+
+		got, err := r.DeviceHistory(ctx, "gpu.example.com", "node-1", "gpu-0")
+		if err != nil {
+			t.Fatalf("DeviceHistory() error = %v", err)
+		}
+		at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+		train := []string{"team-a/train-0", "team-a/train-1"}
+		expected := []types.DeviceState{
+			{Since: at(0), Until: at(1), NodeName: "node-1"},
+			{Since: at(1), Until: at(3), NodeName: "node-1", Claim: "team-a/train", Consumers: train},
+			{Since: at(3), Until: at(4), NodeName: "node-1", Claim: "team-a/train", Consumers: train, Unhealthy: true},
+			{Since: at(4), Until: at(5), NodeName: "node-1", Claim: "team-b/infer"},
+			{Since: at(6), NodeName: "node-1"},
+		}
+		if diff := cmp.Diff(got, expected); diff != "" {
+			t.Errorf("mismatch (-got +want):\n%s", diff)
+		}
+
+		got, err = r.DeviceHistory(ctx, "gpu.example.com", "node-1", "gpu-1")
+		if err != nil {
+			t.Fatalf("DeviceHistory() error = %v", err)
+		}
+		if diff := cmp.Diff(got, []types.DeviceState{{Since: at(0), NodeName: "node-1"}}); diff != "" {
+			t.Errorf("mismatch (-got +want):\n%s", diff)
+		}
+
+		histories, err := r.DeviceHistories(ctx, at(5))
+		if err != nil {
+			t.Fatalf("DeviceHistories() error = %v", err)
+		}
+		expectedHistories := []types.DeviceHistory{
+			{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0", States: expected[3:]},
+			{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1", States: []types.DeviceState{{Since: at(0), NodeName: "node-1"}}},
+		}
+		if diff := cmp.Diff(histories, expectedHistories); diff != "" {
+			t.Errorf("mismatch (-got +want):\n%s", diff)
+		}
+	})
+}
+
+func TestCompact(t *testing.T) {
+	testStores(t, func(t *testing.T, r *Recorder) {
+		ctx := context.Background()
+		now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+		nodes := []*types.NodeInfo{{NodeName: "node-1", Devices: []types.Device{{ProductName: "gpu", TotalCount: 4, AvailableCount: 2}}}}
+		samples := []time.Duration{
+			-72 * time.Hour,
+			-50 * time.Hour,
+			// the last snapshot before the retention period is kept
+			-49*time.Hour - 30*time.Minute,
+			// downsampled to the last snapshot of the hour
+			-30*time.Hour - 20*time.Minute,
+			-30*time.Hour - 10*time.Minute,
+			-2 * time.Hour,
+			-time.Hour - 59*time.Minute,
+		}
+		for _, offset := range samples {
+			if err := r.Record(ctx, now.Add(offset), nodes); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+		device := func(claim string) []types.DeviceInfo {
+			return []types.DeviceInfo{{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", Claim: claim}}
+		}
+		for _, s := range []struct {
+			offset time.Duration
+			claim  string
+		}{
+			{-72 * time.Hour, "team-a/train"},
+			{-60 * time.Hour, "team-b/infer"},
+			{-time.Hour, "team-c/eval"},
+		} {
+			if err := r.RecordDevices(ctx, now.Add(s.offset), device(s.claim), nil); err != nil {
+				t.Fatalf("RecordDevices() error = %v", err)
+			}
+		}
+
+		got, err := r.Compact(ctx, now, Retention{Keep: 48 * time.Hour, DownsampleAfter: 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("Compact() error = %v", err)
+		}
+		if diff := cmp.Diff(got, CompactResult{Samples: 3, DeviceStates: 1}); diff != "" {
+			t.Errorf("mismatch (-got +want):\n%s", diff)
+		}
+
+		rows, err := r.store.(*sqlStore).db.QueryContext(ctx, "SELECT time FROM allocation_samples ORDER BY time")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var kept []time.Time
+		for rows.Next() {
+			var unix int64
+			if err := rows.Scan(&unix); err != nil {
+				t.Fatal(err)
+			}
+			kept = append(kept, time.Unix(unix, 0).UTC())
+		}
+		expected := []time.Time{now.Add(samples[2]), now.Add(samples[4]), now.Add(samples[5]), now.Add(samples[6])}
+		if diff := cmp.Diff(kept, expected); diff != "" {
+			t.Errorf("kept samples mismatch (-got +want):\n%s", diff)
+		}
+
+		history, err := r.DeviceHistory(ctx, "gpu.example.com", "node-1", "gpu-0")
+		if err != nil {
+			t.Fatalf("DeviceHistory() error = %v", err)
+		}
+		expectedHistory := []types.DeviceState{
+			{Since: now.Add(-60 * time.Hour), Until: now.Add(-time.Hour), NodeName: "node-1", Claim: "team-b/infer"},
+			{Since: now.Add(-time.Hour), NodeName: "node-1", Claim: "team-c/eval"},
+		}
+		if diff := cmp.Diff(history, expectedHistory); diff != "" {
+			t.Errorf("history mismatch (-got +want):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/metrics"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Names of the series pushed for device states; the allocation per node and
+// product is pushed as the metrics of the exporter.
+const (
+	// DeviceAllocated is 1 for the claim a device is allocated to.
+	DeviceAllocated = "dra_device_allocated"
+	// DeviceUnhealthy is 1 for devices reported unhealthy, 0 otherwise.
+	DeviceUnhealthy = "dra_device_unhealthy"
+)
+
+// remoteWriteStore pushes the samples to a Prometheus remote-write endpoint,
+// following version 1.0 of the protocol. Samples are pushed whenever the
+// recorder records, so series have gaps while the cluster does not change;
+// query them with last_over_time. The history cannot be read back.
+type remoteWriteStore struct {
+	client *http.Client
+	url    string
+	labels map[string]string
+}
+
+func newRemoteWriteStore(url string, externalLabels map[string]string) *remoteWriteStore {
+	return &remoteWriteStore{client: http.DefaultClient, url: url, labels: externalLabels}
+}
+
+func (s *remoteWriteStore) close() error {
+	return nil
+}
+
+func (s *remoteWriteStore) record(ctx context.Context, t time.Time, nodes []*types.NodeInfo) error {
+	var series []timeSeries
+	for _, node := range nodes {
+		for _, dev := range node.Devices {
+			series = append(series,
+				s.series(metrics.DevicesTotal, float64(dev.TotalCount), "node", node.NodeName, "product", dev.ProductName),
+				s.series(metrics.DevicesAvailable, float64(dev.AvailableCount), "node", node.NodeName, "product", dev.ProductName),
+			)
+		}
+	}
+	return s.push(ctx, t, series)
+}
+
+func (s *remoteWriteStore) recordDevices(ctx context.Context, t time.Time, devices []types.DeviceInfo, _ map[string][]string) error {
+	var series []timeSeries
+	for _, dev := range devices {
+		unhealthy := 0.0
+		if dev.Unhealthy {
+			unhealthy = 1
+		}
+		series = append(series, s.series(DeviceUnhealthy, unhealthy, "node", dev.NodeName, "driver", dev.Driver, "pool", dev.Pool, "device", dev.Name))
+		if dev.Claim != "" {
+			series = append(series, s.series(DeviceAllocated, 1, "node", dev.NodeName, "driver", dev.Driver, "pool", dev.Pool, "device", dev.Name, "claim", dev.Claim))
+		}
+	}
+	return s.push(ctx, t, series)
+}
+
+func (s *remoteWriteStore) trends(context.Context, time.Time, time.Time, int) ([]types.AllocationTrend, error) {
+	return nil, ErrWriteOnly
+}
+
+func (s *remoteWriteStore) deviceHistory(context.Context, deviceID) ([]types.DeviceState, error) {
+	return nil, ErrWriteOnly
+}
+
+func (s *remoteWriteStore) deviceHistories(context.Context, time.Time) ([]types.DeviceHistory, error) {
+	return nil, ErrWriteOnly
+}
+
+// timeSeries is a series with a single sample; the labels are sorted by
+// name, as the protocol requires.
+type timeSeries struct {
+	labels []label
+	value  float64
+}
+
+type label struct{ name, value string }
+
+// series returns a series of the metric with the label name-value pairs and
+// the external labels.
+func (s *remoteWriteStore) series(name string, value float64, labelPairs ...string) timeSeries {
+	labels := []label{{"__name__", name}}
+	for i := 0; i+1 < len(labelPairs); i += 2 {
+		labels = append(labels, label{labelPairs[i], labelPairs[i+1]})
+	}
+	for name, value := range s.labels {
+		labels = append(labels, label{name, value})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return timeSeries{labels: labels, value: value}
+}
+
+// push sends the series, sampled at time t, in a single write request.
+func (s *remoteWriteStore) push(ctx context.Context, t time.Time, series []timeSeries) error {
+	if len(series) == 0 {
+		return nil
+	}
+	body := snappy.Encode(nil, encodeWriteRequest(t, series))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote-write endpoint returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// encodeWriteRequest encodes the series as a prometheus.WriteRequest
+// protobuf message.
+func encodeWriteRequest(t time.Time, series []timeSeries) []byte {
+	var request []byte
+	for _, ts := range series {
+		var message []byte
+		for _, l := range ts.labels {
+			var labelMessage []byte
+			labelMessage = protowire.AppendTag(labelMessage, 1, protowire.BytesType)
+			labelMessage = protowire.AppendString(labelMessage, l.name)
+			labelMessage = protowire.AppendTag(labelMessage, 2, protowire.BytesType)
+			labelMessage = protowire.AppendString(labelMessage, l.value)
+			message = protowire.AppendTag(message, 1, protowire.BytesType)
+			message = protowire.AppendBytes(message, labelMessage)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(ts.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(t.UnixMilli()))
+		message = protowire.AppendTag(message, 2, protowire.BytesType)
+		message = protowire.AppendBytes(message, sample)
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, message)
+	}
+	return request
+}
@@ -0,0 +1,150 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/golang/snappy"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// pushedSample is a sample decoded from a write request.
+type pushedSample struct {
+	Labels    map[string]string
+	Value     float64
+	Timestamp int64
+}
+
+// decodeWriteRequest decodes the series of a prometheus.WriteRequest.
+func decodeWriteRequest(t *testing.T, b []byte) []pushedSample {
+	t.Helper()
+	// fields calls fn with the number and bytes or fixed/varint value of
+	// each field of a message
+	fields := func(b []byte, fn func(num protowire.Number, b []byte, v uint64)) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			if n < 0 {
+				t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+			}
+			b = b[n:]
+			switch typ {
+			case protowire.BytesType:
+				v, n := protowire.ConsumeBytes(b)
+				fn(num, v, 0)
+				b = b[n:]
+			case protowire.Fixed64Type:
+				v, n := protowire.ConsumeFixed64(b)
+				fn(num, nil, v)
+				b = b[n:]
+			case protowire.VarintType:
+				v, n := protowire.ConsumeVarint(b)
+				fn(num, nil, v)
+				b = b[n:]
+			default:
+				t.Fatalf("unexpected wire type %v", typ)
+			}
+		}
+	}
+
+	var samples []pushedSample
+	fields(b, func(_ protowire.Number, series []byte, _ uint64) {
+		s := pushedSample{Labels: make(map[string]string)}
+		fields(series, func(num protowire.Number, b []byte, _ uint64) {
+			switch num {
+			case 1:
+				var name, value string
+				fields(b, func(num protowire.Number, b []byte, _ uint64) {
+					if num == 1 {
+						name = string(b)
+					} else {
+						value = string(b)
+					}
+				})
+				s.Labels[name] = value
+			case 2:
+				fields(b, func(num protowire.Number, _ []byte, v uint64) {
+					if num == 1 {
+						s.Value = math.Float64frombits(v)
+					} else {
+						s.Timestamp = int64(v)
+					}
+				})
+			}
+		})
+		samples = append(samples, s)
+	})
+	return samples
+}
+
+func TestRemoteWrite(t *testing.T) {
+	var pushed []pushedSample
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		request, err := snappy.Decode(nil, body)
+		if err != nil {
+			t.Errorf("failed to decode the request: %v", err)
+		}
+		pushed = append(pushed, decodeWriteRequest(t, request)...)
+	}))
+	defer server.Close()
+
+	r, err := Open(server.URL, map[string]string{"cluster": "prod"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	nodes := []*types.NodeInfo{{NodeName: "node-1", Devices: []types.Device{{ProductName: "H100", TotalCount: 8, AvailableCount: 3}}}}
+	if err := r.Record(ctx, now, nodes); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	devices := []types.DeviceInfo{{NodeName: "node-1", Driver: "gpu.example.com", Pool: "node-1", Name: "gpu-0", Claim: "team-a/train", Unhealthy: true}}
+	if err := r.RecordDevices(ctx, now, devices, nil); err != nil {
+		t.Fatalf("RecordDevices() error = %v", err)
+	}
+
+	ms := now.UnixMilli()
+	expected := []pushedSample{
+		{Labels: map[string]string{"__name__": "dra_devices_total", "cluster": "prod", "node": "node-1", "product": "H100"}, Value: 8, Timestamp: ms},
+		{Labels: map[string]string{"__name__": "dra_devices_available", "cluster": "prod", "node": "node-1", "product": "H100"}, Value: 3, Timestamp: ms},
+		{Labels: map[string]string{"__name__": DeviceUnhealthy, "cluster": "prod", "node": "node-1", "driver": "gpu.example.com", "pool": "node-1", "device": "gpu-0"}, Value: 1, Timestamp: ms},
+		{Labels: map[string]string{"__name__": DeviceAllocated, "cluster": "prod", "node": "node-1", "driver": "gpu.example.com", "pool": "node-1", "device": "gpu-0", "claim": "team-a/train"}, Value: 1, Timestamp: ms},
+	}
+	if diff := cmp.Diff(pushed, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := r.Trends(ctx, now.Add(-time.Hour), now, 6); !errors.Is(err, ErrWriteOnly) {
+		t.Errorf("Trends() error = %v, want ErrWriteOnly", err)
+	}
+}
+
+func TestRemoteWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	r, err := Open(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	nodes := []*types.NodeInfo{{NodeName: "node-1", Devices: []types.Device{{ProductName: "H100", TotalCount: 8}}}}
+	err = r.Record(context.Background(), time.Now(), nodes)
+	if err == nil || err.Error() != "failed to record allocation: remote-write endpoint returned HTTP 400: out of order sample" {
+		t.Errorf("Record() error = %v, want the endpoint's message", err)
+	}
+}
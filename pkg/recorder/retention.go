@@ -0,0 +1,112 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultDownsampleInterval is the interval allocation samples are thinned to
+// when a retention downsamples without naming one.
+const DefaultDownsampleInterval = time.Hour
+
+// Retention bounds the recorded history.
+type Retention struct {
+	// Keep is how long history is kept, 0 to keep it forever.
+	Keep time.Duration
+	// DownsampleAfter is the age from which allocation samples are thinned
+	// to the last snapshot of every DownsampleInterval, 0 to keep every
+	// sample. Device states only record changes and are not downsampled.
+	DownsampleAfter    time.Duration
+	DownsampleInterval time.Duration
+}
+
+// IsZero reports whether the retention keeps the whole history.
+func (r Retention) IsZero() bool {
+	return r.Keep == 0 && r.DownsampleAfter == 0
+}
+
+// CompactResult counts the history removed by Compact.
+type CompactResult struct {
+	// Samples is the number of allocation samples removed, by retention or
+	// downsampling, and DeviceStates the number of device states that ended
+	// before the retention period.
+	Samples      int64
+	DeviceStates int64
+}
+
+// Compact removes the history the retention does not keep as of now. The
+// last snapshot before the retention period is kept, as it holds the
+// allocation at the period's start. Remote-write stores leave retention to
+// the remote storage and return ErrWriteOnly.
+func (r *Recorder) Compact(ctx context.Context, now time.Time, retention Retention) (CompactResult, error) {
+	if retention.DownsampleInterval == 0 {
+		retention.DownsampleInterval = DefaultDownsampleInterval
+	}
+	result, err := r.store.compact(ctx, now, retention)
+	if err != nil {
+		return result, fmt.Errorf("failed to compact history: %w", err)
+	}
+	return result, nil
+}
+
+func (s *sqlStore) compact(ctx context.Context, now time.Time, retention Retention) (CompactResult, error) {
+	var result CompactResult
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	if retention.Keep > 0 {
+		cutoff := now.Add(-retention.Keep).Unix()
+		res, err := tx.ExecContext(ctx, s.bind(`
+			DELETE FROM allocation_samples
+			WHERE time < (SELECT MAX(time) FROM allocation_samples WHERE time <= ?)`), cutoff)
+		if err != nil {
+			return result, err
+		}
+		if result.Samples, err = res.RowsAffected(); err != nil {
+			return result, err
+		}
+		res, err = tx.ExecContext(ctx, s.bind("DELETE FROM device_states WHERE until IS NOT NULL AND until < ?"), cutoff)
+		if err != nil {
+			return result, err
+		}
+		if result.DeviceStates, err = res.RowsAffected(); err != nil {
+			return result, err
+		}
+	}
+	if retention.DownsampleAfter > 0 {
+		cutoff := now.Add(-retention.DownsampleAfter).Unix()
+		interval := int64(retention.DownsampleInterval / time.Second)
+		res, err := tx.ExecContext(ctx, s.bind(`
+			DELETE FROM allocation_samples
+			WHERE time < ? AND time NOT IN (
+				SELECT MAX(time) FROM allocation_samples WHERE time < ? GROUP BY time / ?
+			)`), cutoff, cutoff, interval)
+		if err != nil {
+			return result, err
+		}
+		downsampled, err := res.RowsAffected()
+		if err != nil {
+			return result, err
+		}
+		result.Samples += downsampled
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+
+	if s.vacuum != "" && result.Samples+result.DeviceStates > 0 {
+		// give the freed pages back to the file system
+		if _, err := s.db.ExecContext(ctx, s.vacuum); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (s *remoteWriteStore) compact(context.Context, time.Time, Retention) (CompactResult, error) {
+	return CompactResult{}, ErrWriteOnly
+}
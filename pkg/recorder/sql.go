@@ -0,0 +1,157 @@
+package recorder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	_ "github.com/lib/pq"  // registers the "postgres" driver
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// dialect holds what differs between the SQL databases the history can be
+// stored in.
+type dialect struct {
+	driver string
+	schema string
+	// rowID is the column identifying a row of device_states.
+	rowID string
+	// numberedParams is set for databases taking $1, $2, ... instead of ?
+	// as query parameters.
+	numberedParams bool
+	// vacuum shrinks the database after compaction, if it does not do so
+	// on its own.
+	vacuum string
+}
+
+var sqlite = dialect{
+	driver: "sqlite",
+	schema: `
+CREATE TABLE IF NOT EXISTS allocation_samples (
+	time      INTEGER NOT NULL,
+	node      TEXT    NOT NULL,
+	product   TEXT    NOT NULL,
+	total     INTEGER NOT NULL,
+	allocated INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS allocation_samples_time ON allocation_samples (time);
+CREATE TABLE IF NOT EXISTS device_states (
+	driver    TEXT    NOT NULL,
+	pool      TEXT    NOT NULL,
+	device    TEXT    NOT NULL,
+	node      TEXT    NOT NULL,
+	claim     TEXT    NOT NULL,
+	consumers TEXT    NOT NULL,
+	unhealthy INTEGER NOT NULL,
+	since     INTEGER NOT NULL,
+	until     INTEGER
+);
+CREATE INDEX IF NOT EXISTS device_states_device ON device_states (driver, pool, device, since);
+CREATE INDEX IF NOT EXISTS device_states_open ON device_states (until);
+`,
+	rowID:  "rowid",
+	vacuum: "VACUUM",
+}
+
+// postgres stores the history of each cluster in the tables of the schema
+// the connection URL selects with search_path, public by default.
+var postgres = dialect{
+	driver: "postgres",
+	schema: `
+CREATE TABLE IF NOT EXISTS allocation_samples (
+	time      BIGINT  NOT NULL,
+	node      TEXT    NOT NULL,
+	product   TEXT    NOT NULL,
+	total     INTEGER NOT NULL,
+	allocated INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS allocation_samples_time ON allocation_samples (time);
+CREATE TABLE IF NOT EXISTS device_states (
+	id        BIGSERIAL PRIMARY KEY,
+	driver    TEXT    NOT NULL,
+	pool      TEXT    NOT NULL,
+	device    TEXT    NOT NULL,
+	node      TEXT    NOT NULL,
+	claim     TEXT    NOT NULL,
+	consumers TEXT    NOT NULL,
+	unhealthy BOOLEAN NOT NULL,
+	since     BIGINT  NOT NULL,
+	until     BIGINT
+);
+CREATE INDEX IF NOT EXISTS device_states_device ON device_states (driver, pool, device, since);
+CREATE INDEX IF NOT EXISTS device_states_open ON device_states (until);
+`,
+	rowID:          "id",
+	numberedParams: true,
+}
+
+// sqlStore keeps the history in a SQL database.
+type sqlStore struct {
+	db *sql.DB
+	dialect
+}
+
+// openSQL opens the database at dataSource, creating the tables if needed.
+func openSQL(d dialect, dataSource string) (*sqlStore, error) {
+	db, err := sql.Open(d.driver, dataSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	if d.driver == sqlite.driver {
+		// a single connection serializes writes, which SQLite requires anyway
+		db.SetMaxOpenConns(1)
+	}
+	if _, err := db.Exec(d.schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history database schema: %w", err)
+	}
+	return &sqlStore{db: db, dialect: d}, nil
+}
+
+// bind rewrites the ? parameters of query into the dialect's.
+func (d dialect) bind(query string) string {
+	if !d.numberedParams {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *sqlStore) close() error {
+	return s.db.Close()
+}
+
+func (s *sqlStore) record(ctx context.Context, t time.Time, nodes []*types.NodeInfo) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, s.bind("INSERT INTO allocation_samples (time, node, product, total, allocated) VALUES (?, ?, ?, ?, ?)"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, node := range nodes {
+		for _, dev := range node.Devices {
+			if _, err := stmt.ExecContext(ctx, t.Unix(), node.NodeName, dev.ProductName, dev.TotalCount, dev.TotalCount-dev.AvailableCount); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
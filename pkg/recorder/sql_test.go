@@ -0,0 +1,71 @@
+package recorder
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// postgresDSNEnv names the environment variable holding the URL of a
+// PostgreSQL database the tests may create schemas in, e.g.
+// postgres://postgres@localhost/postgres?sslmode=disable. Without it, the
+// tests run against SQLite only.
+const postgresDSNEnv = "DRA_TEST_POSTGRES_DSN"
+
+// testStores runs test against an empty SQLite database and, if
+// DRA_TEST_POSTGRES_DSN is set, an empty schema of the PostgreSQL database,
+// dropped afterwards.
+func testStores(t *testing.T, test func(t *testing.T, r *Recorder)) {
+	t.Run("sqlite", func(t *testing.T) {
+		r, err := Open(filepath.Join(t.TempDir(), "history.db"), nil)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer r.Close()
+		test(t, r)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv(postgresDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", postgresDSNEnv)
+		}
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		schema := fmt.Sprintf("recorder_test_%d", time.Now().UnixNano())
+		if _, err := db.Exec("CREATE SCHEMA " + schema); err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+		defer db.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+		u, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatalf("invalid %s: %v", postgresDSNEnv, err)
+		}
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		r, err := Open(u.String(), nil)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer r.Close()
+		test(t, r)
+	})
+}
+
+func TestBind(t *testing.T) {
+	query := "UPDATE device_states SET until = ? WHERE id = ?"
+	if got := sqlite.bind(query); got != query {
+		t.Errorf("sqlite.bind() = %q, want the query unchanged", got)
+	}
+	if got, want := postgres.bind(query), "UPDATE device_states SET until = $1 WHERE id = $2"; got != want {
+		t.Errorf("postgres.bind() = %q, want %q", got, want)
+	}
+}
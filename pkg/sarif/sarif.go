@@ -0,0 +1,302 @@
+// Package sarif renders findings in the Static Analysis Results Interchange
+// Format (SARIF) 2.1.0, so code-review and security tooling can surface them.
+// Cluster objects have no source file, so findings carry logical locations
+// naming the Kubernetes object instead.
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+const (
+	schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	version   = "2.1.0"
+	toolName  = "k8s-dra-resources"
+	toolURI   = "https://github.com/dharmjit/k8s-dra-resources"
+)
+
+// Log is a SARIF log with a single run.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []Rule `json:"rules"`
+}
+
+type Rule struct {
+	ID               string  `json:"id"`
+	ShortDescription Message `json:"shortDescription"`
+}
+
+type Result struct {
+	RuleID    string     `json:"ruleId"`
+	Level     string     `json:"level"`
+	Message   Message    `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Location struct {
+	LogicalLocations []LogicalLocation `json:"logicalLocations"`
+}
+
+// LogicalLocation names a Kubernetes object, e.g. Kind "resourceclaim" and
+// FullyQualifiedName "default/my-claim".
+type LogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// newLog returns a log of the tool with the given rules and results.
+func newLog(rules []Rule, results []Result) Log {
+	if results == nil {
+		results = []Result{}
+	}
+	return Log{
+		Schema:  schemaURI,
+		Version: version,
+		Runs: []Run{{
+			Tool:    Tool{Driver: Driver{Name: toolName, InformationURI: toolURI, Rules: rules}},
+			Results: results,
+		}},
+	}
+}
+
+// Write writes the log as indented JSON.
+func Write(w io.Writer, log Log) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("failed to write SARIF log: %w", err)
+	}
+	return nil
+}
+
+// FromCheckResults reports every violation of a check as an error result of
+// the check's rule.
+func FromCheckResults(results []types.CheckResult) Log {
+	var rules []Rule
+	var sarifResults []Result
+	for _, result := range results {
+		rules = append(rules, Rule{ID: result.Name, ShortDescription: Message{Text: result.Description}})
+		for _, failure := range result.Failures {
+			sarifResults = append(sarifResults, Result{
+				RuleID:  result.Name,
+				Level:   "error",
+				Message: Message{Text: failure},
+			})
+		}
+	}
+	return newLog(rules, sarifResults)
+}
+
+// FromSpotRisk reports every claim on spot capacity as a warning.
+func FromSpotRisk(risks []types.SpotRisk) Log {
+	const ruleID = "claim-on-spot-capacity"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "allocated claim holds devices on spot or preemptible nodes"}}}
+	var results []Result
+	for _, risk := range risks {
+		text := fmt.Sprintf("claim %s/%s holds devices on spot nodes %s", risk.Namespace, risk.Name, strings.Join(risk.Nodes, ", "))
+		if len(risk.Owners) > 0 {
+			text += ", interrupting " + strings.Join(risk.Owners, ", ")
+		}
+		results = append(results, Result{
+			RuleID:    ruleID,
+			Level:     "warning",
+			Message:   Message{Text: text},
+			Locations: []Location{objectLocation("resourceclaim", risk.Namespace, risk.Name)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromClassDrift reports every DeviceClass that differs between contexts as
+// a warning.
+func FromClassDrift(drifts []types.ClassDrift) Log {
+	const ruleID = "deviceclass-drift"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "DeviceClass is missing from or defined differently in some contexts"}}}
+	var results []Result
+	for _, drift := range drifts {
+		var parts []string
+		if len(drift.Missing) > 0 {
+			parts = append(parts, "missing in "+strings.Join(drift.Missing, ", "))
+		}
+		if len(drift.Variants) > 1 {
+			var variants []string
+			for _, variant := range drift.Variants {
+				variants = append(variants, strings.Join(variant.Contexts, ", "))
+			}
+			parts = append(parts, fmt.Sprintf("%d definitions (%s)", len(drift.Variants), strings.Join(variants, " vs. ")))
+		}
+		results = append(results, Result{
+			RuleID:    ruleID,
+			Level:     "warning",
+			Message:   Message{Text: fmt.Sprintf("DeviceClass %s: %s", drift.Name, strings.Join(parts, "; "))},
+			Locations: []Location{objectLocation("deviceclass", "", drift.Name)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromDrainCandidates reports every drain candidate as a note, since it is a
+// suggestion rather than a problem.
+func FromDrainCandidates(candidates []types.DrainCandidate) Log {
+	const ruleID = "drain-candidate"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "node can be drained with little disruption to device consumers"}}}
+	var results []Result
+	for _, candidate := range candidates {
+		results = append(results, Result{
+			RuleID:    ruleID,
+			Level:     "note",
+			Message:   Message{Text: fmt.Sprintf("node %s has %d of %d devices allocated, draining it evicts %d pods", candidate.NodeName, candidate.AllocatedDevices, candidate.TotalDevices, candidate.ConsumerPods)},
+			Locations: []Location{objectLocation("node", "", candidate.NodeName)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromLabelMismatches reports every GPU node label disagreeing with the
+// published devices as a warning.
+func FromLabelMismatches(mismatches []types.LabelMismatch) Log {
+	const ruleID = "gpu-label-mismatch"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "GPU node label disagrees with the devices the node publishes"}}}
+	var results []Result
+	for _, mismatch := range mismatches {
+		results = append(results, Result{
+			RuleID:    ruleID,
+			Level:     "warning",
+			Message:   Message{Text: fmt.Sprintf("node %s has label %s=%s, but publishes %s", mismatch.NodeName, mismatch.Label, mismatch.Value, mismatch.Published)},
+			Locations: []Location{objectLocation("node", "", mismatch.NodeName)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromCUDAIncompatibilities reports every container needing a newer CUDA
+// version than its devices' driver supports as an error.
+func FromCUDAIncompatibilities(incompatible []types.CUDAIncompatibility) Log {
+	const ruleID = "cuda-driver-too-old"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "container needs a newer CUDA version than the driver of its devices supports"}}}
+	var results []Result
+	for _, c := range incompatible {
+		results = append(results, Result{
+			RuleID: ruleID,
+			Level:  "error",
+			Message: Message{Text: fmt.Sprintf("container %s of pod %s/%s needs CUDA %s (from %s), but the driver of %s on node %s supports CUDA %s",
+				c.Container, c.Namespace, c.Pod, c.RequiredCUDA, c.Source, strings.Join(c.Devices, ", "), c.NodeName, c.SupportedCUDA)},
+			Locations: []Location{objectLocation("pod", c.Namespace, c.Pod)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromCounterOvercommit reports every overcommitted shared counter as an
+// error.
+func FromCounterOvercommit(overcommit []types.CounterOvercommit) Log {
+	const ruleID = "shared-counter-overcommitted"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "allocated devices consume more of a shared counter than it provides"}}}
+	var results []Result
+	for _, o := range overcommit {
+		results = append(results, Result{
+			RuleID: ruleID,
+			Level:  "error",
+			Message: Message{Text: fmt.Sprintf("counter %s/%s of pool %s/%s is overcommitted: %s allocated of %s, by claims %s",
+				o.CounterSet, o.Counter, o.Driver, o.Pool, o.Allocated.String(), o.Capacity.String(), strings.Join(o.Claims, ", "))},
+			Locations: []Location{objectLocation("resourcepool", o.Driver, o.Pool)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromStranded reports every node with stranded devices as a warning.
+func FromStranded(stranded []types.StrandedDevices) Log {
+	const ruleID = "stranded-devices"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "free devices cannot be used because their node lacks CPU or memory"}}}
+	var results []Result
+	for _, s := range stranded {
+		results = append(results, Result{
+			RuleID:    ruleID,
+			Level:     "warning",
+			Message:   Message{Text: fmt.Sprintf("node %s has %d of %d free %s devices stranded, limited by %s", s.NodeName, s.StrandedDevices, s.FreeDevices, s.ProductName, s.LimitedBy)},
+			Locations: []Location{objectLocation("node", "", s.NodeName)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromKubeletDeviceMismatches reports every container whose devices differ
+// from its claim's allocation as an error.
+func FromKubeletDeviceMismatches(mismatches []types.KubeletDeviceMismatch) Log {
+	const ruleID = "kubelet-device-mismatch"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "devices the kubelet reports for a container differ from its claim's allocation"}}}
+	var results []Result
+	for _, m := range mismatches {
+		claim := m.Claim
+		if m.Request != "" {
+			claim += "/" + m.Request
+		}
+		results = append(results, Result{
+			RuleID: ruleID,
+			Level:  "error",
+			Message: Message{Text: fmt.Sprintf("container %s of pod %s/%s on node %s has %s for claim %s, allocated %s",
+				m.Container, m.Namespace, m.Pod, m.NodeName, listOrNone(m.Reported), claim, listOrNone(m.Allocated))},
+			Locations: []Location{objectLocation("pod", m.Namespace, m.Pod)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+// FromFlakyDevices reports every flaky device as a warning.
+func FromFlakyDevices(flaky []types.FlakyDevice) Log {
+	const ruleID = "flaky-device"
+	rules := []Rule{{ID: ruleID, ShortDescription: Message{Text: "device shows unusual allocation churn or repeated health flaps"}}}
+	var results []Result
+	for _, f := range flaky {
+		device := f.Driver + "/" + f.Pool + "/" + f.Device
+		results = append(results, Result{
+			RuleID:    ruleID,
+			Level:     "warning",
+			Message:   Message{Text: fmt.Sprintf("device %s on node %s: %s", device, f.NodeName, strings.Join(f.Reasons, "; "))},
+			Locations: []Location{objectLocation("device", "", device)},
+		})
+	}
+	return newLog(rules, results)
+}
+
+func listOrNone(values []string) string {
+	if len(values) == 0 {
+		return "no devices"
+	}
+	return strings.Join(values, ", ")
+}
+
+func objectLocation(kind, namespace, name string) Location {
+	fullName := name
+	if namespace != "" {
+		fullName = namespace + "/" + name
+	}
+	return Location{LogicalLocations: []LogicalLocation{{Name: name, FullyQualifiedName: fullName, Kind: kind}}}
+}
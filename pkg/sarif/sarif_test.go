@@ -0,0 +1,145 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestFromCheckResults(t *testing.T) {
+	got := FromCheckResults([]types.CheckResult{
+		{Name: "devices-healthy", Description: "no device is reported unhealthy"},
+		{Name: "claims-allocated", Description: "every ResourceClaim is allocated", Failures: []string{"claim a/b is pending"}},
+	})
+
+	expected := []Result{{RuleID: "claims-allocated", Level: "error", Message: Message{Text: "claim a/b is pending"}}}
+	if diff := cmp.Diff(got.Runs[0].Results, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+	if len(got.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Errorf("expected a rule per check, got %v", got.Runs[0].Tool.Driver.Rules)
+	}
+}
+
+func TestFromSpotRisk(t *testing.T) {
+	got := FromSpotRisk([]types.SpotRisk{
+		{Namespace: "ml", Name: "train", Nodes: []string{"spot-1"}, Owners: []string{"Job/train"}},
+	})
+
+	expected := []Result{{
+		RuleID:  "claim-on-spot-capacity",
+		Level:   "warning",
+		Message: Message{Text: "claim ml/train holds devices on spot nodes spot-1, interrupting Job/train"},
+		Locations: []Location{{LogicalLocations: []LogicalLocation{
+			{Name: "train", FullyQualifiedName: "ml/train", Kind: "resourceclaim"},
+		}}},
+	}}
+	if diff := cmp.Diff(got.Runs[0].Results, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestFindingConverters(t *testing.T) {
+	node := func(name string) []Location {
+		return []Location{objectLocation("node", "", name)}
+	}
+	tests := []struct {
+		name     string
+		log      Log
+		expected []Result
+	}{
+		{
+			name: "drain candidates",
+			log:  FromDrainCandidates([]types.DrainCandidate{{NodeName: "gpu-1", TotalDevices: 8, AllocatedDevices: 2, ConsumerPods: 1}}),
+			expected: []Result{{RuleID: "drain-candidate", Level: "note",
+				Message: Message{Text: "node gpu-1 has 2 of 8 devices allocated, draining it evicts 1 pods"}, Locations: node("gpu-1")}},
+		},
+		{
+			name: "gpu labels",
+			log:  FromLabelMismatches([]types.LabelMismatch{{NodeName: "gpu-1", Label: "nvidia.com/gpu.count", Value: "8", Published: "4"}}),
+			expected: []Result{{RuleID: "gpu-label-mismatch", Level: "warning",
+				Message: Message{Text: "node gpu-1 has label nvidia.com/gpu.count=8, but publishes 4"}, Locations: node("gpu-1")}},
+		},
+		{
+			name: "cuda compat",
+			log: FromCUDAIncompatibilities([]types.CUDAIncompatibility{{
+				Namespace: "ml", Pod: "train", Container: "main", NodeName: "gpu-1", RequiredCUDA: "12.4", Source: "image",
+				Devices: []string{"gpu-0"}, SupportedCUDA: "12.2",
+			}}),
+			expected: []Result{{RuleID: "cuda-driver-too-old", Level: "error",
+				Message:   Message{Text: "container main of pod ml/train needs CUDA 12.4 (from image), but the driver of gpu-0 on node gpu-1 supports CUDA 12.2"},
+				Locations: []Location{objectLocation("pod", "ml", "train")}}},
+		},
+		{
+			name: "overcommit",
+			log: FromCounterOvercommit([]types.CounterOvercommit{{
+				Driver: "gpu.nvidia.com", Pool: "gpu-1", CounterSet: "gpu-0", Counter: "memory",
+				Capacity: resource.MustParse("80Gi"), Allocated: resource.MustParse("100Gi"), Claims: []string{"ml/a", "ml/b"},
+			}}),
+			expected: []Result{{RuleID: "shared-counter-overcommitted", Level: "error",
+				Message:   Message{Text: "counter gpu-0/memory of pool gpu.nvidia.com/gpu-1 is overcommitted: 100Gi allocated of 80Gi, by claims ml/a, ml/b"},
+				Locations: []Location{objectLocation("resourcepool", "gpu.nvidia.com", "gpu-1")}}},
+		},
+		{
+			name: "stranded",
+			log:  FromStranded([]types.StrandedDevices{{NodeName: "gpu-1", ProductName: "H100", FreeDevices: 4, StrandedDevices: 3, LimitedBy: "cpu"}}),
+			expected: []Result{{RuleID: "stranded-devices", Level: "warning",
+				Message: Message{Text: "node gpu-1 has 3 of 4 free H100 devices stranded, limited by cpu"}, Locations: node("gpu-1")}},
+		},
+		{
+			name: "kubelet devices",
+			log: FromKubeletDeviceMismatches([]types.KubeletDeviceMismatch{{
+				Namespace: "ml", Pod: "train", Container: "main", NodeName: "gpu-1", Claim: "gpus", Request: "gpu",
+				Allocated: []string{"gpu.nvidia.com/gpu-1/gpu-0"},
+			}}),
+			expected: []Result{{RuleID: "kubelet-device-mismatch", Level: "error",
+				Message:   Message{Text: "container main of pod ml/train on node gpu-1 has no devices for claim gpus/gpu, allocated gpu.nvidia.com/gpu-1/gpu-0"},
+				Locations: []Location{objectLocation("pod", "ml", "train")}}},
+		},
+		{
+			name: "flaky devices",
+			log: FromFlakyDevices([]types.FlakyDevice{{
+				Driver: "gpu.nvidia.com", Pool: "gpu-1", Device: "gpu-0", NodeName: "gpu-1", Reasons: []string{"3 health flaps"},
+			}}),
+			expected: []Result{{RuleID: "flaky-device", Level: "warning",
+				Message:   Message{Text: "device gpu.nvidia.com/gpu-1/gpu-0 on node gpu-1: 3 health flaps"},
+				Locations: []Location{objectLocation("device", "", "gpu.nvidia.com/gpu-1/gpu-0")}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.log.Runs[0].Results, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+			rules := tt.log.Runs[0].Tool.Driver.Rules
+			if len(rules) != 1 || rules[0].ID != tt.expected[0].RuleID {
+				t.Errorf("expected the single rule %s, got %v", tt.expected[0].RuleID, rules)
+			}
+		})
+	}
+}
+
+func TestWriteEmptyLog(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FromSpotRisk(nil)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to decode SARIF log: %v", err)
+	}
+	if decoded["version"] != "2.1.0" {
+		t.Errorf("expected version 2.1.0, got %v", decoded["version"])
+	}
+	// an empty results array tells consumers the run found nothing
+	results := decoded["runs"].([]any)[0].(map[string]any)["results"]
+	if results == nil {
+		t.Errorf("expected an empty results array, got none")
+	}
+}
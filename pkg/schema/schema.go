@@ -0,0 +1,123 @@
+// Package schema publishes JSON Schemas for the machine-readable output of
+// the tool and wraps output in its versioned envelope.
+//
+// The schemas are generated from the types in pkg/types and committed, so a
+// change to the output structure shows up as a change to the published
+// schema. Within an output version fields are only ever added; removing or
+// renaming a field requires a new version. Consumers should therefore ignore
+// properties they do not know. The tests enforce this against copies of the
+// released schemas in testdata/released.
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+const (
+	// VersionV0 is the original unversioned output: a bare JSON array.
+	VersionV0 = "v0"
+	// VersionV1Alpha1 wraps output in an envelope with apiVersion, kind and
+	// schema metadata.
+	VersionV1Alpha1 = "v1alpha1"
+
+	// DefaultVersion is the output version used unless another is requested.
+	DefaultVersion = VersionV1Alpha1
+
+	// Group is the apiVersion group of enveloped output.
+	Group = "dra-resources.dharmjit.github.io"
+)
+
+//go:embed v0/*.json v1alpha1/*.json
+var files embed.FS
+
+// kinds maps each output version and kind to the Go value it is rendered from.
+var kinds = map[string]map[string]any{
+	VersionV0: {
+		"nodes": []*types.NodeInfo{},
+	},
+	VersionV1Alpha1: {
+		"nodes": types.NodeList{},
+	},
+}
+
+// Versions returns all supported output versions.
+func Versions() []string {
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Kinds returns the names of all output kinds with a published schema in the
+// given version.
+func Kinds(version string) []string {
+	names := make([]string, 0, len(kinds[version]))
+	for name := range kinds[version] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(version, kind string) (any, error) {
+	versionKinds, ok := kinds[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown output version %q, known versions: %v", version, Versions())
+	}
+	v, ok := versionKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("no schema for output kind %q, known kinds: %v", kind, Kinds(version))
+	}
+	return v, nil
+}
+
+// Get returns the published schema for an output kind.
+func Get(version, kind string) ([]byte, error) {
+	if _, err := lookup(version, kind); err != nil {
+		return nil, err
+	}
+	return files.ReadFile(version + "/" + kind + ".json")
+}
+
+// Generate builds the schema for an output kind from its Go types.
+func Generate(version, kind string) (map[string]any, error) {
+	v, err := lookup(version, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{defs: make(map[string]any)}
+	root := g.schemaFor(reflect.TypeOf(v))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = fmt.Sprintf("https://github.com/dharmjit/k8s-dra-resources/schema/%s/%s.json", version, kind)
+	root["title"] = kind
+	root["$defs"] = g.defs
+	return root, nil
+}
+
+// Nodes renders the node output in the requested output version.
+func Nodes(version string, nodes []*types.NodeInfo) (any, error) {
+	switch version {
+	case VersionV0:
+		return nodes, nil
+	case VersionV1Alpha1:
+		if nodes == nil {
+			nodes = []*types.NodeInfo{}
+		}
+		return types.NodeList{
+			APIVersion: Group + "/" + version,
+			Kind:       "NodeList",
+			Metadata:   types.ListMeta{SchemaVersion: version},
+			Items:      nodes,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown output version %q, known versions: %v", version, Versions())
+	}
+}
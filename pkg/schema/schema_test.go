@@ -0,0 +1,299 @@
+package schema
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the published schema files")
+
+// TestPublishedSchemas fails when the output types change without the
+// published schemas being regenerated with -update.
+func TestPublishedSchemas(t *testing.T) {
+	for _, version := range Versions() {
+		for _, kind := range Kinds(version) {
+			t.Run(version+"/"+kind, func(t *testing.T) {
+				generated, err := Generate(version, kind)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				want, err := json.MarshalIndent(generated, "", "  ")
+				if err != nil {
+					t.Fatalf("failed to marshal schema: %v", err)
+				}
+				want = append(want, '\n')
+
+				path := filepath.Join(version, kind+".json")
+				if *update {
+					if err := os.WriteFile(path, want, 0o644); err != nil {
+						t.Fatalf("failed to write %s: %v", path, err)
+					}
+					return
+				}
+
+				got, err := Get(version, kind)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				if diff := cmp.Diff(string(got), string(want)); diff != "" {
+					t.Errorf("published schema %s is out of date, run go test ./pkg/schema -update (-published +generated):\n%s", path, diff)
+				}
+			})
+		}
+	}
+}
+
+// TestReleasedSchemas fails when the output types remove, retype or stop
+// requiring a property of a released schema. testdata/released holds the
+// schemas as released; unlike the published ones, -update leaves them alone.
+func TestReleasedSchemas(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "released", "*", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list released schemas: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no released schemas in testdata/released")
+	}
+	for _, path := range paths {
+		version := filepath.Base(filepath.Dir(path))
+		kind := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(version+"/"+kind, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", path, err)
+			}
+			var released map[string]any
+			if err := json.Unmarshal(data, &released); err != nil {
+				t.Fatalf("failed to decode %s: %v", path, err)
+			}
+			generated, err := Generate(version, kind)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			// compare the schemas as published, not the Go values
+			if generated, err = roundTrip(generated); err != nil {
+				t.Fatalf("failed to round-trip schema: %v", err)
+			}
+
+			for _, change := range breakingChanges(released, generated) {
+				t.Errorf("%s: %s; fields may only be added within an output version", path, change)
+			}
+		})
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	released := map[string]any{
+		"$ref": "#/$defs/List",
+		"$defs": map[string]any{
+			"List": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"items": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/Item"}}},
+				"required":   []any{"items"},
+			},
+			"Item": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":  map[string]any{"type": "string"},
+					"count": map[string]any{"type": "integer"},
+				},
+				"required": []any{"name", "count"},
+			},
+		},
+	}
+	// withItem returns the released schema with Item replaced.
+	withItem := func(properties map[string]any, required ...any) map[string]any {
+		generated, err := roundTrip(released)
+		if err != nil {
+			t.Fatalf("failed to copy schema: %v", err)
+		}
+		generated["$defs"].(map[string]any)["Item"] = map[string]any{"type": "object", "properties": properties, "required": required}
+		return generated
+	}
+
+	tests := []struct {
+		name      string
+		generated map[string]any
+		expected  []string
+	}{
+		{
+			name:      "unchanged",
+			generated: released,
+		},
+		{
+			name: "added property",
+			generated: withItem(map[string]any{
+				"name":  map[string]any{"type": "string"},
+				"count": map[string]any{"type": "integer"},
+				"owner": map[string]any{"type": "string"},
+			}, "name", "count", "owner"),
+		},
+		{
+			name:      "removed property",
+			generated: withItem(map[string]any{"name": map[string]any{"type": "string"}}, "name"),
+			expected:  []string{"$.items[].count: removed"},
+		},
+		{
+			name: "retyped property",
+			generated: withItem(map[string]any{
+				"name":  map[string]any{"type": "string"},
+				"count": map[string]any{"type": "string"},
+			}, "name", "count"),
+			expected: []string{"$.items[].count: type changed from integer to string"},
+		},
+		{
+			name: "optional property",
+			generated: withItem(map[string]any{
+				"name":  map[string]any{"type": "string"},
+				"count": map[string]any{"type": "integer"},
+			}, "name"),
+			expected: []string{"$.items[].count: no longer required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := breakingChanges(released, tt.generated)
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+// breakingChanges lists the properties of the released schema that the
+// generated schema removes, retypes or no longer requires.
+func breakingChanges(released, generated map[string]any) []string {
+	c := &schemaComparison{
+		releasedDefs:  defsOf(released),
+		generatedDefs: defsOf(generated),
+		seen:          make(map[string]bool),
+	}
+	c.compare("$", released, generated)
+	return c.changes
+}
+
+type schemaComparison struct {
+	releasedDefs, generatedDefs map[string]any
+	// seen holds the released definitions already compared, which stops
+	// recursion through self-referencing types.
+	seen    map[string]bool
+	changes []string
+}
+
+func (c *schemaComparison) compare(path string, released, generated map[string]any) {
+	released, name := resolve(c.releasedDefs, released)
+	generated, _ = resolve(c.generatedDefs, generated)
+	if name != "" {
+		if c.seen[name] {
+			return
+		}
+		c.seen[name] = true
+	}
+
+	if released["type"] != generated["type"] {
+		c.changes = append(c.changes, fmt.Sprintf("%s: type changed from %v to %v", path, released["type"], generated["type"]))
+		return
+	}
+	if items, ok := released["items"].(map[string]any); ok {
+		c.compare(path+"[]", items, subschema(generated, "items"))
+	}
+	if values, ok := released["additionalProperties"].(map[string]any); ok {
+		c.compare(path+"{}", values, subschema(generated, "additionalProperties"))
+	}
+
+	releasedProperties := subschema(released, "properties")
+	generatedProperties := subschema(generated, "properties")
+	names := make([]string, 0, len(releasedProperties))
+	for name := range releasedProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	generatedRequired := make(map[any]bool)
+	for _, name := range asSlice(generated["required"]) {
+		generatedRequired[name] = true
+	}
+	releasedRequired := make(map[any]bool)
+	for _, name := range asSlice(released["required"]) {
+		releasedRequired[name] = true
+	}
+	for _, name := range names {
+		property, ok := generatedProperties[name].(map[string]any)
+		if !ok {
+			c.changes = append(c.changes, fmt.Sprintf("%s.%s: removed", path, name))
+			continue
+		}
+		if releasedRequired[name] && !generatedRequired[name] {
+			c.changes = append(c.changes, fmt.Sprintf("%s.%s: no longer required", path, name))
+		}
+		c.compare(path+"."+name, releasedProperties[name].(map[string]any), property)
+	}
+}
+
+// resolve follows a $ref to its definition, returning the definition and its
+// name, or the schema itself and an empty name.
+func resolve(defs, schema map[string]any) (map[string]any, string) {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema, ""
+	}
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	def, _ := defs[name].(map[string]any)
+	return def, name
+}
+
+func defsOf(schema map[string]any) map[string]any {
+	return subschema(schema, "$defs")
+}
+
+func subschema(schema map[string]any, key string) map[string]any {
+	sub, _ := schema[key].(map[string]any)
+	return sub
+}
+
+func asSlice(v any) []any {
+	values, _ := v.([]any)
+	return values
+}
+
+// roundTrip returns the schema as decoded from its JSON encoding.
+func roundTrip(schema map[string]any) (map[string]any, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]any
+	err = json.Unmarshal(data, &decoded)
+	return decoded, err
+}
+
+func TestNodes(t *testing.T) {
+	nodes := []*types.NodeInfo{{NodeName: "node-1"}}
+
+	got, err := Nodes(VersionV1Alpha1, nodes)
+	if err != nil {
+		t.Fatalf("Nodes() error = %v", err)
+	}
+	expected := types.NodeList{
+		APIVersion: "dra-resources.dharmjit.github.io/v1alpha1",
+		Kind:       "NodeList",
+		Metadata:   types.ListMeta{SchemaVersion: "v1alpha1"},
+		Items:      nodes,
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := Nodes("v2", nodes); err == nil {
+		t.Errorf("expected an error for an unknown output version")
+	}
+}
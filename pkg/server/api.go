@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/schema"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+)
+
+// NewAPI returns the handler of the read-only JSON API:
+//
+//   - /api/v1/nodes lists the node inventory, as -o json does, to every
+//     token. Claims and pods on the nodes are only listed for namespaces of
+//     the token. The node and labelSelector parameters restrict the nodes.
+//   - /api/v1/claims lists the ResourceClaims of the namespaces of the token,
+//     or of the namespace parameter if the token covers it.
+//   - /api/v1/availability lists the cluster-wide device availability per
+//     product to every token. It names no nodes or claims, so users limited
+//     to their namespace can see it without listing claims of all
+//     namespaces themselves.
+//
+// With a nil auth, the API is open and every request sees every namespace.
+func NewAPI(client resourceClient.ResourceClient, auth *Authenticator) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/nodes", authenticated(auth, func(w http.ResponseWriter, r *http.Request, scope *Scope) {
+		nodeInfoList, err := client.GetK8sResources(r.Context(), resourceClient.ListOptions{
+			NodeName:     r.URL.Query().Get("node"),
+			NodeSelector: r.URL.Query().Get("labelSelector"),
+		})
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		if !scope.AllowsAll() {
+			nodeInfoList = scopeNodes(nodeInfoList, scope)
+		}
+		out, err := schema.Nodes(schema.DefaultVersion, nodeInfoList)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		writeJSON(w, out)
+	}))
+	mux.HandleFunc("GET /api/v1/claims", authenticated(auth, func(w http.ResponseWriter, r *http.Request, scope *Scope) {
+		var namespaces []string
+		switch namespace := r.URL.Query().Get("namespace"); {
+		case namespace != "" && !scope.Allows(namespace):
+			http.Error(w, "token has no access to namespace "+namespace, http.StatusForbidden)
+			return
+		case namespace != "":
+			namespaces = []string{namespace}
+		case scope.AllowsAll():
+			namespaces = []string{""}
+		default:
+			for namespace := range scope.namespaces {
+				namespaces = append(namespaces, namespace)
+			}
+			sort.Strings(namespaces)
+		}
+
+		claims := []*types.ClaimInfo{}
+		for _, namespace := range namespaces {
+			namespaceClaims, err := client.GetResourceClaims(r.Context(), resourceClient.ListOptions{Namespace: namespace})
+			if err != nil {
+				serverError(w, err)
+				return
+			}
+			claims = append(claims, namespaceClaims...)
+		}
+		writeJSON(w, claims)
+	}))
+	mux.HandleFunc("GET /api/v1/availability", authenticated(auth, func(w http.ResponseWriter, r *http.Request, _ *Scope) {
+		products, err := client.GetProductAvailability(r.Context())
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		writeJSON(w, products)
+	}))
+	return mux
+}
+
+// RequireAllNamespaces wraps handler to only serve tokens covering every
+// namespace, e.g. for metrics of the whole cluster. With a nil auth, handler
+// is returned unchanged.
+func RequireAllNamespaces(auth *Authenticator, handler http.HandlerFunc) http.HandlerFunc {
+	if auth == nil {
+		return handler
+	}
+	return authenticated(auth, func(w http.ResponseWriter, r *http.Request, scope *Scope) {
+		if !scope.AllowsAll() {
+			http.Error(w, "token has no access to every namespace", http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// authenticated wraps handler to reject requests without a known token.
+func authenticated(auth *Authenticator, handler func(w http.ResponseWriter, r *http.Request, scope *Scope)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope := unrestricted
+		if auth != nil {
+			var ok bool
+			if scope, ok = auth.Authenticate(r); !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing or unknown bearer token", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler(w, r, scope)
+	}
+}
+
+// scopeNodes returns copies of the nodes listing only the claims and pods of
+// namespaces in scope, so teams do not see each other's workloads.
+func scopeNodes(nodes []*types.NodeInfo, scope *Scope) []*types.NodeInfo {
+	scoped := make([]*types.NodeInfo, len(nodes))
+	for i, node := range nodes {
+		copied := *node
+		copied.DeviceConsumers = inScope(node.DeviceConsumers, scope)
+		copied.AllocatedClaims = inScope(node.AllocatedClaims, scope)
+		scoped[i] = &copied
+	}
+	return scoped
+}
+
+// inScope filters namespace/name references to the namespaces in scope.
+func inScope(refs []string, scope *Scope) []string {
+	var filtered []string
+	for _, ref := range refs {
+		namespace, _, _ := strings.Cut(ref, "/")
+		if scope.Allows(namespace) {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func serverError(w http.ResponseWriter, err error) {
+	log.Printf("Error serving API request: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
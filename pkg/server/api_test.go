@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resourceClient "github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/dratest/fixtures"
+	"github.com/dharmjit/k8s-dra-resources/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPI(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		fixtures.NewNode("node-1"),
+		fixtures.NewResourceSlice("node-1", "gpu.nvidia.com",
+			fixtures.NewDevice("gpu-0", "NVIDIA A100"),
+			fixtures.NewDevice("gpu-1", "NVIDIA A100"),
+		),
+		fixtures.NewResourceClaim("team-a", "claim-a", "gpu.nvidia.com",
+			fixtures.Allocated("gpu.nvidia.com", "node-1", "gpu-0"),
+		),
+		fixtures.NewResourceClaim("team-b", "claim-b", "gpu.nvidia.com",
+			fixtures.Allocated("gpu.nvidia.com", "node-1", "gpu-1"),
+		),
+	)
+	client := resourceClient.NewResourceClientForClientset(clientset, "default")
+	auth, err := NewAuthenticator([]Token{
+		{Name: "team-a", SHA256: hash("key-a"), Namespaces: []string{"team-a"}},
+		{Name: "platform", SHA256: hash("key-platform"), Namespaces: []string{AllNamespaces}},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	api := NewAPI(client, auth)
+	metrics := RequireAllNamespaces(auth, func(w http.ResponseWriter, r *http.Request) {})
+
+	// response holds the status and the namespace/name of the claims listed
+	// by the claims endpoint, or the allocated claims of the nodes endpoint
+	type response struct {
+		Status int
+		Claims []string
+	}
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		path     string
+		key      string
+		expected response
+	}{
+		{
+			name:     "no token",
+			handler:  api,
+			path:     "/api/v1/claims",
+			expected: response{Status: http.StatusUnauthorized},
+		},
+		{
+			name:     "unknown token",
+			handler:  api,
+			path:     "/api/v1/claims",
+			key:      "key-unknown",
+			expected: response{Status: http.StatusUnauthorized},
+		},
+		{
+			name:     "claims of own namespaces",
+			handler:  api,
+			path:     "/api/v1/claims",
+			key:      "key-a",
+			expected: response{Status: http.StatusOK, Claims: []string{"team-a/claim-a"}},
+		},
+		{
+			name:     "claims of other namespace",
+			handler:  api,
+			path:     "/api/v1/claims?namespace=team-b",
+			key:      "key-a",
+			expected: response{Status: http.StatusForbidden},
+		},
+		{
+			name:     "claims of every namespace",
+			handler:  api,
+			path:     "/api/v1/claims",
+			key:      "key-platform",
+			expected: response{Status: http.StatusOK, Claims: []string{"team-a/claim-a", "team-b/claim-b"}},
+		},
+		{
+			name:     "claims of one namespace",
+			handler:  api,
+			path:     "/api/v1/claims?namespace=team-b",
+			key:      "key-platform",
+			expected: response{Status: http.StatusOK, Claims: []string{"team-b/claim-b"}},
+		},
+		{
+			name:     "nodes scoped",
+			handler:  api,
+			path:     "/api/v1/nodes",
+			key:      "key-a",
+			expected: response{Status: http.StatusOK, Claims: []string{"team-a/claim-a"}},
+		},
+		{
+			name:     "nodes unscoped",
+			handler:  api,
+			path:     "/api/v1/nodes",
+			key:      "key-platform",
+			expected: response{Status: http.StatusOK, Claims: []string{"team-a/claim-a", "team-b/claim-b"}},
+		},
+		{
+			name:     "metrics need every namespace",
+			handler:  metrics,
+			path:     "/metrics",
+			key:      "key-a",
+			expected: response{Status: http.StatusForbidden},
+		},
+		{
+			name:     "metrics",
+			handler:  metrics,
+			path:     "/metrics",
+			key:      "key-platform",
+			expected: response{Status: http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, req)
+
+			got := response{Status: rec.Code}
+			if rec.Code == http.StatusOK {
+				switch tt.path {
+				case "/api/v1/nodes":
+					var nodes types.NodeList
+					if err := json.Unmarshal(rec.Body.Bytes(), &nodes); err != nil {
+						t.Fatalf("failed to decode response: %v", err)
+					}
+					for _, node := range nodes.Items {
+						got.Claims = append(got.Claims, node.AllocatedClaims...)
+					}
+				case "/metrics":
+				default:
+					var claims []*types.ClaimInfo
+					if err := json.Unmarshal(rec.Body.Bytes(), &claims); err != nil {
+						t.Fatalf("failed to decode response: %v", err)
+					}
+					for _, claim := range claims {
+						got.Claims = append(got.Claims, claim.Namespace+"/"+claim.Name)
+					}
+				}
+			}
+			if diff := cmp.Diff(got, tt.expected); diff != "" {
+				t.Errorf("mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFetchProductAvailability(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		fixtures.NewResourceSlice("node-1", "gpu.nvidia.com",
+			fixtures.NewDevice("gpu-0", "NVIDIA A100"),
+			fixtures.NewDevice("gpu-1", "NVIDIA A100"),
+		),
+		fixtures.NewResourceClaim("team-b", "claim-b", "gpu.nvidia.com",
+			fixtures.Allocated("gpu.nvidia.com", "node-1", "gpu-1"),
+		),
+	)
+	client := resourceClient.NewResourceClientForClientset(clientset, "default")
+	auth, err := NewAuthenticator([]Token{{Name: "team-a", SHA256: hash("key-a"), Namespaces: []string{"team-a"}}})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	srv := httptest.NewServer(NewAPI(client, auth))
+	defer srv.Close()
+
+	// a token of another namespace still sees the availability
+	got, err := FetchProductAvailability(context.Background(), srv.Client(), srv.URL, "key-a")
+	if err != nil {
+		t.Fatalf("FetchProductAvailability() error = %v", err)
+	}
+	expected := []types.ProductAvailability{{ProductName: "NVIDIA A100", TotalCount: 2, AvailableCount: 1}}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("mismatch (-got +want):\n%s", diff)
+	}
+
+	if _, err := FetchProductAvailability(context.Background(), srv.Client(), srv.URL, "key-unknown"); err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Errorf("FetchProductAvailability() error = %v, want HTTP 401", err)
+	}
+}
+
+func TestNewAuthenticatorErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []Token
+		err    string
+	}{
+		{
+			name:   "invalid hash",
+			tokens: []Token{{Name: "team-a", SHA256: "secret", Namespaces: []string{"team-a"}}},
+			err:    `token "team-a": sha256 must be a hex-encoded SHA-256 hash`,
+		},
+		{
+			name:   "no namespaces",
+			tokens: []Token{{Name: "team-a", SHA256: hash("key-a")}},
+			err:    `token "team-a" has no namespaces, use "*" for all`,
+		},
+		{
+			name: "duplicate",
+			tokens: []Token{
+				{Name: "team-a", SHA256: hash("key-a"), Namespaces: []string{"team-a"}},
+				{Name: "team-a", SHA256: hash("key-b"), Namespaces: []string{"team-b"}},
+			},
+			err: `token "team-a" is defined twice`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuthenticator(tt.tokens)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("NewAuthenticator() error = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
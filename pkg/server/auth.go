@@ -0,0 +1,101 @@
+// Package server implements the HTTP API of serve mode.
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AllNamespaces in the namespaces of a token grants access to every
+// namespace.
+const AllNamespaces = "*"
+
+// Token is an API key and the namespaces whose claims it may read. Only the
+// SHA-256 hash of the key is kept, so configuration does not hold secrets.
+type Token struct {
+	// Name identifies the token, e.g. the team, in logs.
+	Name string
+	// SHA256 is the hex-encoded SHA-256 hash of the key.
+	SHA256     string
+	Namespaces []string
+}
+
+// Scope is what an authenticated request may read.
+type Scope struct {
+	Name string
+	// namespaces is nil for access to every namespace.
+	namespaces map[string]bool
+}
+
+// unrestricted is the scope of requests when no tokens are configured.
+var unrestricted = &Scope{}
+
+// AllowsAll reports whether the scope covers every namespace.
+func (s *Scope) AllowsAll() bool {
+	return s.namespaces == nil
+}
+
+// Allows reports whether the scope covers namespace.
+func (s *Scope) Allows(namespace string) bool {
+	return s.namespaces == nil || s.namespaces[namespace]
+}
+
+// Authenticator maps bearer tokens to scopes.
+type Authenticator struct {
+	hashes [][sha256.Size]byte
+	scopes []*Scope
+}
+
+// NewAuthenticator returns an authenticator accepting tokens.
+func NewAuthenticator(tokens []Token) (*Authenticator, error) {
+	a := &Authenticator{}
+	names := make(map[string]bool)
+	for _, token := range tokens {
+		if token.Name == "" {
+			return nil, fmt.Errorf("token has no name")
+		}
+		if names[token.Name] {
+			return nil, fmt.Errorf("token %q is defined twice", token.Name)
+		}
+		names[token.Name] = true
+		decoded, err := hex.DecodeString(token.SHA256)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("token %q: sha256 must be a hex-encoded SHA-256 hash", token.Name)
+		}
+		if len(token.Namespaces) == 0 {
+			return nil, fmt.Errorf("token %q has no namespaces, use %q for all", token.Name, AllNamespaces)
+		}
+
+		scope := &Scope{Name: token.Name, namespaces: make(map[string]bool)}
+		for _, namespace := range token.Namespaces {
+			if namespace == AllNamespaces {
+				scope.namespaces = nil
+				break
+			}
+			scope.namespaces[namespace] = true
+		}
+		a.hashes = append(a.hashes, [sha256.Size]byte(decoded))
+		a.scopes = append(a.scopes, scope)
+	}
+	return a, nil
+}
+
+// Authenticate returns the scope of the bearer token of r, or false if it
+// has none or an unknown one.
+func (a *Authenticator) Authenticate(r *http.Request) (*Scope, bool) {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		return nil, false
+	}
+	hash := sha256.Sum256([]byte(key))
+	for i := range a.hashes {
+		if subtle.ConstantTimeCompare(hash[:], a.hashes[i][:]) == 1 {
+			return a.scopes[i], true
+		}
+	}
+	return nil, false
+}
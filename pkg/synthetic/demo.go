@@ -0,0 +1,169 @@
+package synthetic
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkDriver publishes the NICs of the demo cluster. It has no decorator,
+// so its devices are grouped under the driver name.
+const NetworkDriver = "net.example.com"
+
+// Demo returns a small cluster exercising every view: several GPU products,
+// an arm64 node, a node partitioned into MIG devices, NICs of a second
+// driver, a cordoned node, a Windows node without DRA drivers and claims that
+// are still pending.
+func Demo() *Cluster {
+	c := Generate(Options{Nodes: 4, DevicesPerNode: 4, Claims: 10})
+
+	// node-0003 is cordoned for maintenance
+	c.Nodes[3].Spec.Unschedulable = true
+	// node-0000 reserves 2Mi huge pages
+	hugepages := corev1.ResourceName(corev1.ResourceHugePagesPrefix + "2Mi")
+	c.Nodes[0].Status.Capacity[hugepages] = resource.MustParse("16Gi")
+	c.Nodes[0].Status.Allocatable[hugepages] = resource.MustParse("16Gi")
+	// node-0001 is an arm64 Grace Hopper node
+	c.Nodes[1].Labels["kubernetes.io/arch"] = "arm64"
+	// node-0002 runs on spot capacity
+	c.Nodes[2].Labels["eks.amazonaws.com/capacityType"] = "SPOT"
+
+	// every node has two NICs
+	for _, node := range c.Nodes {
+		slice := resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: node.Name + "-" + NetworkDriver},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: node.Name,
+				Driver:   NetworkDriver,
+				Pool:     resourcev1beta1.ResourcePool{Name: node.Name, ResourceSliceCount: 1},
+			},
+		}
+		for j := 0; j < 2; j++ {
+			slice.Spec.Devices = append(slice.Spec.Devices, resourcev1beta1.Device{Name: fmt.Sprintf("nic-%d", j)})
+		}
+		c.ResourceSlices = append(c.ResourceSlices, slice)
+	}
+
+	// node-mig has an A100 partitioned into seven 1g.5gb MIG devices, two of
+	// which are in use
+	mig := c.Nodes[0].DeepCopy()
+	mig.Name = "node-mig"
+	c.Nodes = append(c.Nodes, *mig)
+	migSlice := resourcev1beta1.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: mig.Name + "-" + Driver},
+		Spec: resourcev1beta1.ResourceSliceSpec{
+			NodeName: mig.Name,
+			Driver:   Driver,
+			Pool:     resourcev1beta1.ResourcePool{Name: mig.Name, ResourceSliceCount: 1},
+		},
+	}
+	for j := 0; j < 7; j++ {
+		productName := "NVIDIA A100-SXM4-40GB MIG 1g.5gb"
+		profile := "1g.5gb"
+		migSlice.Spec.Devices = append(migSlice.Spec.Devices, resourcev1beta1.Device{
+			Name: fmt.Sprintf("gpu-0-mig-%d", j),
+			Basic: &resourcev1beta1.BasicDevice{
+				Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+					"productName": {StringValue: &productName},
+					"profile":     {StringValue: &profile},
+				},
+				Capacity: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceCapacity{
+					"memory": {Value: resource.MustParse("5Gi")},
+				},
+			},
+		})
+	}
+	c.ResourceSlices = append(c.ResourceSlices, migSlice)
+	for j := 0; j < 2; j++ {
+		c.addClaim(fmt.Sprintf("mig-claim-%d", j), &resourcev1beta1.DeviceRequestAllocationResult{
+			Request: "gpu", Driver: Driver, Pool: mig.Name, Device: fmt.Sprintf("gpu-0-mig-%d", j),
+		}, mig.Name)
+	}
+
+	// node-win runs Windows, where no DRA driver publishes devices, and
+	// reports ephemeral storage only
+	c.Nodes = append(c.Nodes, corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-win",
+			Labels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				"kubernetes.io/os":               "windows",
+				"kubernetes.io/arch":             "amd64",
+			},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("16"),
+				corev1.ResourceMemory:           resource.MustParse("64Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("256Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("16"),
+				corev1.ResourceMemory:           resource.MustParse("62Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("240Gi"),
+			},
+			NodeInfo: corev1.NodeSystemInfo{OperatingSystem: "windows"},
+		},
+	})
+
+	// two claims have not been allocated yet
+	for j := 0; j < 2; j++ {
+		c.addClaim(fmt.Sprintf("pending-claim-%d", j), nil, "")
+	}
+
+	// the drivers published their slices days ago; claims and their pods
+	// were created over the last hours
+	now := time.Now()
+	for i := range c.ResourceSlices {
+		c.ResourceSlices[i].CreationTimestamp = metav1.NewTime(now.Add(-time.Duration(72+i) * time.Hour))
+	}
+	for i := range c.ResourceClaims {
+		created := metav1.NewTime(now.Add(-time.Duration(len(c.ResourceClaims)-i) * 37 * time.Minute))
+		c.ResourceClaims[i].CreationTimestamp = created
+		c.Pods[i].CreationTimestamp = created
+	}
+	return c
+}
+
+// addClaim adds a claim consumed by a pod of the same name. A nil result
+// leaves the claim pending and the pod unscheduled.
+func (c *Cluster) addClaim(name string, result *resourcev1beta1.DeviceRequestAllocationResult, nodeName string) {
+	claim := resourcev1beta1.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: Namespace},
+		Spec: resourcev1beta1.ResourceClaimSpec{
+			Devices: resourcev1beta1.DeviceClaim{
+				Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: Driver}},
+			},
+		},
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: Namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+			ResourceClaims: []corev1.PodResourceClaim{
+				{Name: "gpu", ResourceClaimName: &claim.Name},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	if result != nil {
+		claim.Status = resourcev1beta1.ResourceClaimStatus{
+			Allocation: &resourcev1beta1.AllocationResult{
+				Devices: resourcev1beta1.DeviceAllocationResult{
+					Results: []resourcev1beta1.DeviceRequestAllocationResult{*result},
+				},
+			},
+			ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{
+				{Resource: "pods", Name: pod.Name},
+			},
+		}
+		pod.Spec.NodeName = nodeName
+		pod.Status.Phase = corev1.PodRunning
+	}
+	c.ResourceClaims = append(c.ResourceClaims, claim)
+	c.Pods = append(c.Pods, pod)
+}
@@ -0,0 +1,217 @@
+// Package synthetic generates in-memory clusters of nodes, ResourceSlices,
+// ResourceClaims and pods, for benchmarks and demos that run without a
+// cluster.
+package synthetic
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Driver is the DRA driver publishing the generated devices.
+const Driver = "gpu.nvidia.com"
+
+// Namespace holds the generated claims and pods.
+const Namespace = "default"
+
+// Options sizes a generated cluster.
+type Options struct {
+	// Nodes is the number of nodes.
+	Nodes int
+	// DevicesPerNode is the number of devices published by each node.
+	DevicesPerNode int
+	// Claims is the number of claims. Each claim requests one device and is
+	// consumed by one pod; claims beyond the number of devices stay pending.
+	Claims int
+}
+
+// Cluster holds the objects of a generated cluster.
+type Cluster struct {
+	DeviceClasses  []resourcev1beta1.DeviceClass
+	Nodes          []corev1.Node
+	ResourceSlices []resourcev1beta1.ResourceSlice
+	ResourceClaims []resourcev1beta1.ResourceClaim
+	Pods           []corev1.Pod
+}
+
+// zones are assigned to nodes in turn; there are fewer zones than products so
+// products end up spread over several zones.
+var zones = []string{"us-east-1a", "us-east-1b"}
+
+// products are assigned to nodes in turn so the cluster has a mix of them.
+var products = []struct {
+	name         string
+	memory       string
+	vbios        string
+	oldVBIOS     string
+	nodeGroup    string
+	instanceType string
+}{
+	{"NVIDIA A100-SXM4-80GB", "80Gi", "92.00.36.00.01", "92.00.25.00.08", "gpu-a100", "p4de.24xlarge"},
+	{"NVIDIA H100 80GB HBM3", "80Gi", "96.00.74.00.0D", "96.00.30.00.01", "gpu-h100", "p5.48xlarge"},
+	{"NVIDIA L4", "24Gi", "95.04.29.00.06", "95.04.1A.00.02", "gpu-l4", "g6.12xlarge"},
+}
+
+// Generate builds a cluster of the given size. The result is deterministic:
+// claims are spread over the nodes round-robin, so the first claims land on
+// distinct nodes.
+func Generate(opts Options) *Cluster {
+	c := &Cluster{
+		DeviceClasses: []resourcev1beta1.DeviceClass{{
+			ObjectMeta: metav1.ObjectMeta{Name: Driver},
+			Spec: resourcev1beta1.DeviceClassSpec{
+				Selectors: []resourcev1beta1.DeviceSelector{
+					{CEL: &resourcev1beta1.CELDeviceSelector{Expression: fmt.Sprintf("device.driver == %q", Driver)}},
+				},
+			},
+		}},
+	}
+	for i := 0; i < opts.Nodes; i++ {
+		name := nodeName(i)
+		product := products[i%len(products)]
+		c.Nodes = append(c.Nodes, corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					"node-role.kubernetes.io/worker":   "",
+					"kubernetes.io/os":                 "linux",
+					"kubernetes.io/arch":               "amd64",
+					"topology.kubernetes.io/zone":      zones[i%len(zones)],
+					"eks.amazonaws.com/nodegroup":      product.nodeGroup,
+					"node.kubernetes.io/instance-type": product.instanceType,
+				},
+			},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:     resource.MustParse("64"),
+					corev1.ResourceMemory:  resource.MustParse("512Gi"),
+					corev1.ResourceStorage: resource.MustParse("1Ti"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:     resource.MustParse("64"),
+					corev1.ResourceMemory:  resource.MustParse("512Gi"),
+					corev1.ResourceStorage: resource.MustParse("1Ti"),
+				},
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		})
+
+		slice := resourcev1beta1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-" + Driver},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				NodeName: name,
+				Driver:   Driver,
+				Pool:     resourcev1beta1.ResourcePool{Name: name, ResourceSliceCount: 1},
+			},
+		}
+		// one node in four still runs an older driver and VBIOS
+		driverVersion, vbiosVersion := "550.54.15", product.vbios
+		if i%4 == 3 {
+			driverVersion, vbiosVersion = "535.104.5", product.oldVBIOS
+		}
+		for j := 0; j < opts.DevicesPerNode; j++ {
+			productName := product.name
+			slice.Spec.Devices = append(slice.Spec.Devices, resourcev1beta1.Device{
+				Name: deviceName(j),
+				Basic: &resourcev1beta1.BasicDevice{
+					Attributes: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceAttribute{
+						"productName":   {StringValue: &productName},
+						"driverVersion": {VersionValue: &driverVersion},
+						"vbiosVersion":  {StringValue: &vbiosVersion},
+					},
+					Capacity: map[resourcev1beta1.QualifiedName]resourcev1beta1.DeviceCapacity{
+						"memory": {Value: resource.MustParse(product.memory)},
+					},
+				},
+			})
+		}
+		c.ResourceSlices = append(c.ResourceSlices, slice)
+	}
+
+	for i := 0; i < opts.Claims; i++ {
+		claim := resourcev1beta1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("claim-%05d", i), Namespace: Namespace},
+			Spec: resourcev1beta1.ResourceClaimSpec{
+				Devices: resourcev1beta1.DeviceClaim{
+					Requests: []resourcev1beta1.DeviceRequest{{Name: "gpu", DeviceClassName: Driver}},
+				},
+			},
+		}
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%05d", i), Namespace: Namespace},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+					},
+				}},
+				ResourceClaims: []corev1.PodResourceClaim{
+					{Name: "gpu", ResourceClaimName: &claim.Name},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		}
+
+		if opts.Nodes > 0 && i < opts.Nodes*opts.DevicesPerNode {
+			node := nodeName(i % opts.Nodes)
+			claim.Status = resourcev1beta1.ResourceClaimStatus{
+				Allocation: &resourcev1beta1.AllocationResult{
+					Devices: resourcev1beta1.DeviceAllocationResult{
+						Results: []resourcev1beta1.DeviceRequestAllocationResult{
+							{Request: "gpu", Driver: Driver, Pool: node, Device: deviceName(i / opts.Nodes)},
+						},
+					},
+				},
+				ReservedFor: []resourcev1beta1.ResourceClaimConsumerReference{
+					{Resource: "pods", Name: pod.Name},
+				},
+			}
+			pod.Spec.NodeName = node
+			pod.Status.Phase = corev1.PodRunning
+		}
+
+		c.ResourceClaims = append(c.ResourceClaims, claim)
+		c.Pods = append(c.Pods, pod)
+	}
+	return c
+}
+
+// Objects returns the objects of the cluster, e.g. to seed a fake clientset.
+func (c *Cluster) Objects() []runtime.Object {
+	var objects []runtime.Object
+	for i := range c.DeviceClasses {
+		objects = append(objects, &c.DeviceClasses[i])
+	}
+	for i := range c.Nodes {
+		objects = append(objects, &c.Nodes[i])
+	}
+	for i := range c.ResourceSlices {
+		objects = append(objects, &c.ResourceSlices[i])
+	}
+	for i := range c.ResourceClaims {
+		objects = append(objects, &c.ResourceClaims[i])
+	}
+	for i := range c.Pods {
+		objects = append(objects, &c.Pods[i])
+	}
+	return objects
+}
+
+func nodeName(i int) string {
+	return fmt.Sprintf("node-%04d", i)
+}
+
+func deviceName(i int) string {
+	return fmt.Sprintf("gpu-%d", i)
+}
@@ -0,0 +1,65 @@
+package synthetic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dharmjit/k8s-dra-resources/pkg/client"
+	"github.com/dharmjit/k8s-dra-resources/pkg/model"
+	"github.com/dharmjit/k8s-dra-resources/pkg/synthetic"
+)
+
+func TestGenerate(t *testing.T) {
+	cluster := synthetic.Generate(synthetic.Options{Nodes: 3, DevicesPerNode: 2, Claims: 8})
+
+	nodeInfoList, err := client.Aggregate(context.Background(), cluster.Nodes, model.FromV1beta1ResourceSlices(cluster.ResourceSlices), model.FromV1beta1ResourceClaims(cluster.ResourceClaims), cluster.Pods)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if len(nodeInfoList) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(nodeInfoList))
+	}
+
+	// 6 devices in total, so 6 of the 8 claims are allocated
+	var total, available int
+	for _, nodeInfo := range nodeInfoList {
+		for _, dev := range nodeInfo.Devices {
+			total += dev.TotalCount
+			available += dev.AvailableCount
+		}
+	}
+	if total != 6 || available != 0 {
+		t.Errorf("expected 6 devices with none available, got %d total and %d available", total, available)
+	}
+}
+
+func TestDemo(t *testing.T) {
+	cluster := synthetic.Demo()
+
+	nodeInfoList, err := client.Aggregate(context.Background(), cluster.Nodes, model.FromV1beta1ResourceSlices(cluster.ResourceSlices), model.FromV1beta1ResourceClaims(cluster.ResourceClaims), cluster.Pods)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	products := make(map[string]bool)
+	for _, nodeInfo := range nodeInfoList {
+		for _, dev := range nodeInfo.Devices {
+			products[dev.ProductName] = true
+		}
+	}
+	for _, product := range []string{"NVIDIA A100-SXM4-40GB MIG 1g.5gb", synthetic.NetworkDriver} {
+		if !products[product] {
+			t.Errorf("expected devices of product %q, got %v", product, products)
+		}
+	}
+
+	var pending int
+	for _, claim := range cluster.ResourceClaims {
+		if claim.Status.Allocation == nil {
+			pending++
+		}
+	}
+	if pending == 0 {
+		t.Error("expected pending claims")
+	}
+}
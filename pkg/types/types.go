@@ -1,29 +1,868 @@
 package types
 
-import "k8s.io/apimachinery/pkg/api/resource"
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
 
 // NodeInfo holds all information about a node, including capacity and devices.
 type NodeInfo struct {
-	NodeName     string
-	NodeRole     string
-	NodeCapacity NodeCapacity
-	Devices      []Device
+	NodeName string `json:"nodeName"`
+	NodeRole string `json:"nodeRole"`
+	// NodePool is the managed node pool (EKS node group, GKE node pool, AKS
+	// agent pool or Karpenter NodePool) of the node, if any.
+	NodePool string `json:"nodePool,omitempty"`
+	// InstanceType is the cloud instance type of the node, e.g. p5.48xlarge.
+	InstanceType string `json:"instanceType,omitempty"`
+	// Spot is set for nodes on interruptible spot or preemptible capacity.
+	Spot bool `json:"spot,omitempty"`
+	// OS is the operating system of the node, e.g. linux or windows.
+	OS string `json:"os,omitempty"`
+	// Arch is the CPU architecture of the node, e.g. amd64 or arm64.
+	Arch string `json:"arch,omitempty"`
+	// Labels are the labels of the node, used to group nodes by zone or
+	// node pool. They are left out of JSON, which would otherwise carry
+	// every label of every node.
+	Labels map[string]string `json:"-"`
+	// Unschedulable is set for cordoned nodes; NotReady is set when the node
+	// reports a Ready condition other than True or carries a not-ready taint.
+	Unschedulable bool         `json:"unschedulable,omitempty"`
+	NotReady      bool         `json:"notReady,omitempty"`
+	NodeCapacity  NodeCapacity `json:"nodeCapacity"`
+	Devices       []Device     `json:"devices"`
+	Pools         []Pool       `json:"pools,omitempty"`
+	// DeviceConsumers lists the running pods (namespace/name) on the node
+	// that reference ResourceClaims.
+	DeviceConsumers []string `json:"deviceConsumers,omitempty"`
+	// AllocatedClaims lists the ResourceClaims (namespace/name) holding
+	// devices on the node.
+	AllocatedClaims []string `json:"allocatedClaims,omitempty"`
+}
+
+// Schedulable reports whether the scheduler can place new pods, and
+// therefore new device allocations, on the node.
+func (n *NodeInfo) Schedulable() bool {
+	return !n.Unschedulable && !n.NotReady
 }
 
 // NodeCapacity holds the capacity information for a node.
 type NodeCapacity struct {
-	TotalCPU         resource.Quantity
-	AvailableCPU     resource.Quantity
-	TotalMemory      resource.Quantity
-	AvailableMemory  resource.Quantity
-	TotalStorage     resource.Quantity
-	AvailableStorage resource.Quantity
+	TotalCPU         resource.Quantity `json:"totalCPU"`
+	AvailableCPU     resource.Quantity `json:"availableCPU"`
+	TotalMemory      resource.Quantity `json:"totalMemory"`
+	AvailableMemory  resource.Quantity `json:"availableMemory"`
+	TotalStorage     resource.Quantity `json:"totalStorage"`
+	AvailableStorage resource.Quantity `json:"availableStorage"`
+	// Resources holds the other resources the node reports, such as
+	// hugepages-2Mi, ephemeral-storage or extended resources, by name.
+	Resources map[string]ResourceCapacity `json:"resources,omitempty"`
+}
+
+// ResourceCapacity is the total and available amount of a node resource.
+type ResourceCapacity struct {
+	Total     resource.Quantity `json:"total"`
+	Available resource.Quantity `json:"available"`
+}
+
+// NodeGroup sums the capacity and devices of the nodes sharing a label value.
+type NodeGroup struct {
+	// Value is the label value shared by the nodes of the group.
+	Value        string       `json:"value"`
+	Nodes        int          `json:"nodes"`
+	NodeCapacity NodeCapacity `json:"nodeCapacity"`
+	Devices      []Device     `json:"devices"`
+}
+
+// AvailabilityMatrix holds the device counts per product (rows) and value
+// of a node label such as the zone (columns).
+type AvailabilityMatrix struct {
+	Label   string      `json:"label"`
+	Columns []string    `json:"columns"`
+	Rows    []MatrixRow `json:"rows"`
+}
+
+// MatrixRow holds the device counts of a product, one cell per column of
+// the matrix.
+type MatrixRow struct {
+	ProductName string        `json:"productName"`
+	Cells       []DeviceCount `json:"cells"`
+}
+
+// DeviceCount is a number of devices and how many of them are available.
+type DeviceCount struct {
+	TotalCount     int `json:"totalCount"`
+	AvailableCount int `json:"availableCount"`
 }
 
 // Device contains the relevant information for a device.
 type Device struct {
-	ProductName    string
-	TotalCount     int
-	AvailableCount int
-	Memory         resource.Quantity
+	// Driver is the driver publishing the devices.
+	Driver         string `json:"driver,omitempty"`
+	ProductName    string `json:"productName"`
+	TotalCount     int    `json:"totalCount"`
+	AvailableCount int    `json:"availableCount"`
+	// UnhealthyCount is the number of devices a driver decorator reported
+	// as unhealthy.
+	UnhealthyCount int               `json:"unhealthyCount,omitempty"`
+	Memory         resource.Quantity `json:"memory"`
+	// Capacity holds every capacity entry the driver publishes for the device,
+	// keyed by capacity name (e.g. "memory", "multiprocessors").
+	Capacity map[string]resource.Quantity `json:"capacity,omitempty"`
+}
+
+// Pool summarizes a resource pool published by a driver on a node. A pool can
+// be split across several ResourceSlices.
+type Pool struct {
+	Driver     string `json:"driver"`
+	Name       string `json:"name"`
+	Generation int64  `json:"generation"`
+	// ResourceSliceCount is the number of slices the driver advertises for
+	// the pool; ObservedSliceCount is the number actually listed.
+	ResourceSliceCount int64 `json:"resourceSliceCount"`
+	ObservedSliceCount int64 `json:"observedSliceCount"`
+	TotalCount         int   `json:"totalCount"`
+	AvailableCount     int   `json:"availableCount"`
+	// Created is the creation time of the oldest listed slice of the pool.
+	Created time.Time `json:"created,omitzero"`
+}
+
+// Complete reports whether every slice of the pool has been observed. The
+// scheduler does not allocate from incomplete pools.
+func (p Pool) Complete() bool {
+	return p.ObservedSliceCount >= p.ResourceSliceCount
+}
+
+// ClaimInfo holds the information about a ResourceClaim.
+type ClaimInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// UID and ResourceVersion identify the listed revision of the claim, so
+	// deleting it fails once it changed; they are not part of the output.
+	UID             string `json:"-"`
+	ResourceVersion string `json:"-"`
+	Allocated       bool   `json:"allocated"`
+	// Requests summarizes each device request of the claim, e.g.
+	// 2x gpu.example.com[memory>=40Gi].
+	Requests []string `json:"requests,omitempty"`
+	// Devices lists the allocated devices as driver/pool/device.
+	Devices []string `json:"devices,omitempty"`
+	// Consumers lists the pods (or other resources) the claim is reserved for.
+	Consumers []string `json:"consumers,omitempty"`
+	// ConsumersFull is set when the claim is reserved for as many consumers
+	// as the API allows, so further pods referencing it cannot be scheduled.
+	ConsumersFull bool `json:"consumersFull,omitempty"`
+	// Created is the creation time of the claim.
+	Created time.Time `json:"created,omitzero"`
+	// Reservation is set for claims carrying reservation annotations.
+	Reservation *Reservation `json:"reservation,omitempty"`
+}
+
+// ClaimDescription is a ResourceClaim with the details of its allocation.
+type ClaimDescription struct {
+	ClaimInfo
+	// Results lists the device allocated for each request.
+	Results []AllocatedDevice `json:"results,omitempty"`
+	// AllocationConfigs is the driver configuration recorded in the
+	// allocation result, which the drivers apply when preparing the devices.
+	AllocationConfigs []AllocationConfig `json:"allocationConfigs,omitempty"`
+}
+
+// AllocatedDevice is the device allocated for a request of a claim.
+type AllocatedDevice struct {
+	// Request is the name of the request, request/subrequest for
+	// alternatives.
+	Request string `json:"request"`
+	// Device is the device as driver/pool/device.
+	Device string `json:"device"`
+}
+
+// AllocationConfig is an opaque driver configuration of an allocation
+// result.
+type AllocationConfig struct {
+	// Source is FromClass for configuration of a DeviceClass, FromClaim for
+	// the claim's own.
+	Source string `json:"source"`
+	// Requests lists the requests the configuration applies to; all if
+	// empty.
+	Requests   []string `json:"requests,omitempty"`
+	Driver     string   `json:"driver"`
+	Parameters string   `json:"parameters"`
+	// Fields are the parameters decoded by the driver's config decoder, if
+	// one is registered.
+	Fields []ConfigField `json:"fields,omitempty"`
+}
+
+// ConfigField is one setting decoded from opaque driver configuration.
+type ConfigField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Reservation records who holds a claim's devices, why and until when, from
+// the reservation annotations of the claim.
+type Reservation struct {
+	Team    string    `json:"team,omitempty"`
+	Purpose string    `json:"purpose,omitempty"`
+	Expires time.Time `json:"expires,omitzero"`
+	// InvalidExpiry holds an expiry annotation that is not a date or an RFC
+	// 3339 time.
+	InvalidExpiry string `json:"invalidExpiry,omitempty"`
+}
+
+// HeldClaimGroup is a group of claims, e.g. of one namespace or team, that
+// have held devices for a long time.
+type HeldClaimGroup struct {
+	Group  string       `json:"group"`
+	Claims []*ClaimInfo `json:"claims"`
+	// Devices is the number of devices held by the claims.
+	Devices int `json:"devices"`
+}
+
+// ReservationStatus is a reservation that has expired or expires soon.
+type ReservationStatus struct {
+	Namespace   string      `json:"namespace"`
+	Name        string      `json:"name"`
+	Reservation Reservation `json:"reservation"`
+	// State is expired, expiring, active or invalid.
+	State string `json:"state"`
+	// Devices lists the devices the claim holds as driver/pool/device.
+	Devices []string `json:"devices,omitempty"`
+}
+
+// Reasons a pod with ResourceClaims is not scheduled yet.
+const (
+	// PodSchedulingGated pods have scheduling gates the scheduler waits for.
+	PodSchedulingGated = "SchedulingGated"
+	// PodClaimGenerationFailed pods have a claim the resourceclaim controller
+	// failed to generate from its template, e.g. because the template does
+	// not exist.
+	PodClaimGenerationFailed = "ClaimGenerationFailed"
+	// PodClaimMissing pods reference a claim that does not exist, or has not
+	// been generated from its template yet.
+	PodClaimMissing = "ClaimMissing"
+	// PodInsufficientDevices pods have an unallocated claim no node has
+	// enough available devices for.
+	PodInsufficientDevices = "InsufficientDevices"
+	// PodClaimUnallocated pods have an unallocated claim that devices are
+	// available for, e.g. while the scheduler is allocating it.
+	PodClaimUnallocated = "ClaimUnallocated"
+)
+
+// PodInfo describes a pod consuming ResourceClaims.
+type PodInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	NodeName  string `json:"nodeName,omitempty"`
+	Phase     string `json:"phase"`
+	// Claims lists the names of the pod's claims in its namespace; claims
+	// not generated from their template yet are left out.
+	Claims []string `json:"claims,omitempty"`
+	// Reason is why an unscheduled pod is waiting, one of the Pod* reasons,
+	// and Details names the gates or claims involved, or holds the error of
+	// the failed claim generation.
+	Reason  string   `json:"reason,omitempty"`
+	Details []string `json:"details,omitempty"`
+	// PodGroup is the Volcano PodGroup the pod is scheduled with as a gang.
+	PodGroup string `json:"podGroup,omitempty"`
+	// SharedWith lists the other consumers of the pod's claims that are
+	// reserved for more than one consumer, sharing their devices.
+	SharedWith []string `json:"sharedWith,omitempty"`
+}
+
+// DevicePod is a running pod consuming ResourceClaims, with its containers.
+type DevicePod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	NodeName  string `json:"nodeName"`
+	// Claims lists the names of the pod's claims in its namespace.
+	Claims      []string          `json:"claims,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Containers  []ContainerInfo   `json:"containers"`
+}
+
+// ContainerInfo is a container of a pod and its image.
+type ContainerInfo struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// Env holds the environment variables with literal values.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// CUDAIncompatibility is a container requiring a newer CUDA version than the
+// driver of the devices allocated to its pod supports.
+type CUDAIncompatibility struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	NodeName  string `json:"nodeName"`
+	// RequiredCUDA is the CUDA version the container needs, and Source where
+	// it was found, e.g. an annotation or an environment variable.
+	RequiredCUDA string `json:"requiredCUDA"`
+	Source       string `json:"source"`
+	// Devices lists the allocated devices, as driver/pool/device, whose
+	// driver is too old.
+	Devices []string `json:"devices"`
+	// DriverVersion and SupportedCUDA are those of the oldest of the
+	// devices' drivers.
+	DriverVersion string `json:"driverVersion,omitempty"`
+	SupportedCUDA string `json:"supportedCUDA"`
+}
+
+// WorkloadUsage is the device usage of the pods of a workload, such as a
+// Deployment, a JobSet or a PyTorchJob, given as Kind/name.
+type WorkloadUsage struct {
+	Namespace string `json:"namespace"`
+	Workload  string `json:"workload"`
+	DeviceUsage
+	// Steps breaks the usage of an Argo Workflow down by step.
+	Steps []WorkloadStep `json:"steps,omitempty"`
+}
+
+// WorkloadStep is the device usage of the pods of a workflow step.
+type WorkloadStep struct {
+	Name string `json:"name"`
+	DeviceUsage
+}
+
+// DeviceUsage counts the pods of a workload and the claims they consume.
+type DeviceUsage struct {
+	Pods        int `json:"pods"`
+	PendingPods int `json:"pendingPods"`
+	Claims      int `json:"claims"`
+	// PendingClaims counts the claims not allocated yet, and Devices the
+	// devices allocated to the others.
+	PendingClaims int      `json:"pendingClaims"`
+	Devices       int      `json:"devices"`
+	Nodes         []string `json:"nodes,omitempty"`
+}
+
+// PodGroupInfo describes a Volcano PodGroup, whose pods are scheduled
+// together as a gang.
+type PodGroupInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	MinMember int32  `json:"minMember"`
+	// Members counts the group's pods consuming ResourceClaims that have not
+	// finished, and Waiting names those not scheduled yet.
+	Members int      `json:"members"`
+	Waiting []string `json:"waiting,omitempty"`
+	// Devices is the number of devices the unallocated claims of the waiting
+	// pods request collectively, and Available the number of available
+	// devices of the requested classes.
+	Devices   int `json:"devices"`
+	Available int `json:"available"`
+}
+
+// QueueDemand compares the devices of a DeviceClass that the workloads of a
+// Kueue ClusterQueue request with the quota of the queue and the devices in
+// the cluster.
+type QueueDemand struct {
+	ClusterQueue string `json:"clusterQueue"`
+	DeviceClass  string `json:"deviceClass"`
+	// Quota is the nominal quota of the resource the class maps to, summed
+	// over flavors, or nil if the queue has no quota for it.
+	Quota *int64 `json:"quota,omitempty"`
+	// Admitted and Pending are the devices requested by admitted and by
+	// pending workloads.
+	Admitted         int `json:"admitted"`
+	Pending          int `json:"pending"`
+	PendingWorkloads int `json:"pendingWorkloads"`
+	// ClusterTotal and ClusterAvailable count the devices in the cluster the
+	// class selects.
+	ClusterTotal     int `json:"clusterTotal"`
+	ClusterAvailable int `json:"clusterAvailable"`
+}
+
+// NodeUsage is the device allocation of a node and, when GPU metrics are
+// available, the actual utilization of its devices.
+type NodeUsage struct {
+	NodeName         string  `json:"nodeName"`
+	TotalDevices     int     `json:"totalDevices"`
+	AllocatedDevices int     `json:"allocatedDevices"`
+	AllocatedPercent float64 `json:"allocatedPercent"`
+	// Utilization is the average utilization of the node's devices in
+	// percent, or nil without metrics for the node.
+	Utilization *float64 `json:"utilization,omitempty"`
+}
+
+// AllocationTrend is the recorded allocation of a product's devices on a
+// node over a period.
+type AllocationTrend struct {
+	NodeName    string `json:"nodeName"`
+	ProductName string `json:"productName"`
+	// Allocated and Total are the devices of the latest sample.
+	Allocated int `json:"allocated"`
+	Total     int `json:"total"`
+	// History holds the fraction of devices allocated in each interval of the
+	// period, oldest first, or -1 where nothing was recorded.
+	History []float64 `json:"history"`
+}
+
+// DeviceState is a period during which a device had the same holder and
+// health, as recorded by the history database.
+type DeviceState struct {
+	Since time.Time `json:"since"`
+	// Until is zero for the current state.
+	Until    time.Time `json:"until,omitzero"`
+	NodeName string    `json:"nodeName"`
+	// Claim is the namespace/name of the claim holding the device, or empty
+	// while it was available.
+	Claim string `json:"claim,omitempty"`
+	// Consumers lists the pods the claim was reserved for.
+	Consumers []string `json:"consumers,omitempty"`
+	Unhealthy bool     `json:"unhealthy,omitempty"`
+}
+
+// DeviceHistory is the recorded history of a device, oldest state first.
+type DeviceHistory struct {
+	Driver string        `json:"driver"`
+	Pool   string        `json:"pool"`
+	Device string        `json:"device"`
+	States []DeviceState `json:"states"`
+}
+
+// FlakyDevice is a device suspected to be faulty because its recorded
+// history shows unusual allocation churn or repeated health flaps.
+type FlakyDevice struct {
+	Driver      string `json:"driver"`
+	Pool        string `json:"pool"`
+	Device      string `json:"device"`
+	NodeName    string `json:"nodeName"`
+	ProductName string `json:"productName,omitempty"`
+	// Allocations is the number of claims the device was allocated to in
+	// the analyzed period.
+	Allocations int `json:"allocations"`
+	// MedianAllocations is the median of Allocations over the devices of
+	// the same product, or driver if the product is unknown.
+	MedianAllocations float64 `json:"medianAllocations"`
+	// HealthFlaps is the number of times the device became unhealthy in the
+	// analyzed period.
+	HealthFlaps int `json:"healthFlaps"`
+	// Unhealthy is set if the device is unhealthy now.
+	Unhealthy bool     `json:"unhealthy,omitempty"`
+	Reasons   []string `json:"reasons"`
+}
+
+// DrainCandidate describes how disruptive draining a node with devices would
+// be for device-consuming workloads.
+type DrainCandidate struct {
+	NodeName         string `json:"nodeName"`
+	TotalDevices     int    `json:"totalDevices"`
+	AllocatedDevices int    `json:"allocatedDevices"`
+	// ConsumerPods is the number of device-consuming pods a drain would evict.
+	ConsumerPods int `json:"consumerPods"`
+}
+
+// Free reports whether none of the node's devices are in use.
+func (c DrainCandidate) Free() bool {
+	return c.AllocatedDevices == 0 && c.ConsumerPods == 0
+}
+
+// StrandedDevices are free devices of a product on a node that pods of a
+// reference shape cannot use, because the node lacks the CPU or memory to
+// place enough of them.
+type StrandedDevices struct {
+	NodeName        string `json:"nodeName"`
+	ProductName     string `json:"productName"`
+	FreeDevices     int    `json:"freeDevices"`
+	StrandedDevices int    `json:"strandedDevices"`
+	// FittingPods is the number of reference pods the node still fits, and
+	// LimitedBy the resource limiting it, cpu or memory.
+	FittingPods     int               `json:"fittingPods"`
+	LimitedBy       string            `json:"limitedBy"`
+	AvailableCPU    resource.Quantity `json:"availableCPU"`
+	AvailableMemory resource.Quantity `json:"availableMemory"`
+}
+
+// NodeImpact previews what draining a node would disrupt.
+type NodeImpact struct {
+	NodeName string          `json:"nodeName"`
+	Claims   []string        `json:"claims,omitempty"`
+	Pods     []string        `json:"pods,omitempty"`
+	Products []ProductImpact `json:"products"`
+}
+
+// ProductImpact describes the cluster-wide capacity a product loses when a
+// node is drained, and whether the displaced allocations fit elsewhere.
+type ProductImpact struct {
+	ProductName string `json:"productName"`
+	// LostDevices is the number of devices of the product on the node and
+	// DisplacedDevices the number of them currently allocated.
+	LostDevices      int `json:"lostDevices"`
+	DisplacedDevices int `json:"displacedDevices"`
+	// ClusterTotal and AvailableElsewhere count devices of the product in the
+	// whole cluster and free on other schedulable nodes.
+	ClusterTotal       int `json:"clusterTotal"`
+	AvailableElsewhere int `json:"availableElsewhere"`
+}
+
+// Fits reports whether the displaced allocations could be placed on other
+// nodes, ignoring CPU and memory constraints.
+func (p ProductImpact) Fits() bool {
+	return p.DisplacedDevices <= p.AvailableElsewhere
+}
+
+// ProductAvailability holds cluster-wide device counts for one product.
+type ProductAvailability struct {
+	ProductName    string `json:"productName"`
+	TotalCount     int    `json:"totalCount"`
+	AvailableCount int    `json:"availableCount"`
+}
+
+// ProductInventory summarizes the devices of one product across the cluster.
+type ProductInventory struct {
+	Driver      string `json:"driver"`
+	ProductName string `json:"productName"`
+	// Nodes is the number of nodes with at least one device of the product.
+	Nodes          int `json:"nodes"`
+	TotalCount     int `json:"totalCount"`
+	AllocatedCount int `json:"allocatedCount"`
+	UnhealthyCount int `json:"unhealthyCount"`
+	AvailableCount int `json:"availableCount"`
+}
+
+// ClaimTemplateStats summarizes the claims generated from a
+// ResourceClaimTemplate.
+type ClaimTemplateStats struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Claims is the number of existing claims generated for pods from the
+	// template.
+	Claims    int `json:"claims"`
+	Allocated int `json:"allocated"`
+	Pending   int `json:"pending"`
+	// MissingDeviceClasses lists the DeviceClasses referenced by the template
+	// that do not exist. Claims from such templates can never be allocated.
+	MissingDeviceClasses []string `json:"missingDeviceClasses,omitempty"`
+}
+
+// ClaimMutations explains how a stored ResourceClaim differs from what it was
+// created from, and the configuration its DeviceClasses add to it.
+type ClaimMutations struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Source is what the claim is compared against: its
+	// ResourceClaimTemplate, its last applied configuration, or empty if
+	// neither is known.
+	Source  string        `json:"source,omitempty"`
+	Changes []FieldChange `json:"changes,omitempty"`
+	// ClassConfigs is the configuration of the DeviceClasses of the
+	// requests, passed to the drivers along with the claim's own.
+	ClassConfigs []ClassConfig `json:"classConfigs,omitempty"`
+}
+
+// FieldChange is a field of a claim's spec that differs from its source.
+type FieldChange struct {
+	// Path is the field in JSON notation, e.g.
+	// devices.requests[0].allocationMode.
+	Path string `json:"path"`
+	// From and To are the JSON values in the source and the stored claim,
+	// empty if unset.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Reason is defaulted for fields set to their API default, and added,
+	// removed or changed otherwise, e.g. by a mutating admission webhook.
+	Reason string `json:"reason"`
+}
+
+// ClassConfig is an opaque driver configuration of a DeviceClass applying to
+// a request of a claim.
+type ClassConfig struct {
+	Request     string `json:"request"`
+	DeviceClass string `json:"deviceClass"`
+	Driver      string `json:"driver"`
+	Parameters  string `json:"parameters"`
+}
+
+// AllocatedRatio returns the fraction of the template's claims that are
+// allocated, or 0 if it has none.
+func (s ClaimTemplateStats) AllocatedRatio() float64 {
+	if s.Claims == 0 {
+		return 0
+	}
+	return float64(s.Allocated) / float64(s.Claims)
+}
+
+// DeviceClassInfo holds the parts of a DeviceClass that decide which devices
+// it selects and how they are configured.
+type DeviceClassInfo struct {
+	Name string `json:"name"`
+	// Selectors are the CEL expressions of the class.
+	Selectors []string `json:"selectors,omitempty"`
+	// Config holds the opaque driver configuration, one "driver: parameters"
+	// entry per configuration.
+	Config []string `json:"config,omitempty"`
+}
+
+// ClassConsumers lists the claims and ResourceClaimTemplates referencing a
+// DeviceClass, the blast radius of changing the class.
+type ClassConsumers struct {
+	Class  DeviceClassInfo `json:"class"`
+	Claims []ClassConsumer `json:"claims"`
+	// Templates lists the ResourceClaimTemplates (namespace/name)
+	// referencing the class; claims generated from them later pick up any
+	// change.
+	Templates []string `json:"templates,omitempty"`
+}
+
+// ClassConsumer is a claim with at least one request for a DeviceClass.
+type ClassConsumer struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Allocated bool   `json:"allocated"`
+	// Devices is the number of devices allocated for the requests of the
+	// class.
+	Devices int `json:"devices"`
+	// Consumers lists the pods (or other resources) the claim is reserved for.
+	Consumers []string `json:"consumers,omitempty"`
+	// Owners lists the workloads owning the consuming pods as Kind/name.
+	Owners []string `json:"owners,omitempty"`
+}
+
+// ClassChange is the outcome of simulating a change to a DeviceClass.
+type ClassChange struct {
+	Class string `json:"class"`
+	// Unmatched lists the devices the current class selects and the new
+	// definition does not. Allocated ones keep their allocation, but are not
+	// allocated for the class again.
+	Unmatched []DeviceInfo `json:"unmatched"`
+	// Matched lists the devices the new definition selects and the current
+	// class does not.
+	Matched []DeviceInfo `json:"matched"`
+	// Satisfiable lists the pending claims (namespace/name) that the new
+	// definition would make allocatable.
+	Satisfiable []string `json:"satisfiable"`
+}
+
+// ClassDrift describes a DeviceClass that is not defined identically in every
+// compared cluster.
+type ClassDrift struct {
+	Name string `json:"name"`
+	// Missing lists the contexts that do not define the class.
+	Missing []string `json:"missing,omitempty"`
+	// Variants groups the contexts defining the class by their definition.
+	Variants []ClassVariant `json:"variants"`
+}
+
+// ClassVariant is one definition of a DeviceClass and the contexts using it.
+type ClassVariant struct {
+	Contexts []string        `json:"contexts"`
+	Class    DeviceClassInfo `json:"class"`
+}
+
+// DeviceInfo describes a single device published in a ResourceSlice.
+type DeviceInfo struct {
+	NodeName    string `json:"nodeName,omitempty"`
+	Driver      string `json:"driver"`
+	Pool        string `json:"pool"`
+	Name        string `json:"name"`
+	ProductName string `json:"productName"`
+	Unhealthy   bool   `json:"unhealthy,omitempty"`
+	// Spot is set for devices on spot or preemptible nodes.
+	Spot bool `json:"spot,omitempty"`
+	// Claim is the namespace/name of the claim the device is allocated to,
+	// or empty if the device is available, and Request the request of the
+	// claim it was allocated for.
+	Claim   string `json:"claim,omitempty"`
+	Request string `json:"request,omitempty"`
+	// SharedClaims lists the other claims the device is allocated to, as
+	// namespace/name, when several claims share it.
+	SharedClaims []string `json:"sharedClaims,omitempty"`
+	// Consumers lists the pods (or other resources) the claims are reserved
+	// for; several consumers share the device.
+	Consumers []string `json:"consumers,omitempty"`
+	// Attributes holds the device attributes by their unqualified name.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// DeviceDescription is a device together with recent log lines of its
+// driver, collected when the device is unhealthy.
+type DeviceDescription struct {
+	DeviceInfo
+	DriverLogs *DriverLogs `json:"driverLogs,omitempty"`
+}
+
+// DriverLogs holds the last log lines of a driver's kubelet plugin pod on a
+// node.
+type DriverLogs struct {
+	Namespace string   `json:"namespace"`
+	Pod       string   `json:"pod"`
+	Container string   `json:"container"`
+	Lines     []string `json:"lines"`
+}
+
+// NodeFeature is a software version or hardware component of a node, as
+// reported by a Node Feature Discovery or GPU Feature Discovery label.
+type NodeFeature struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Label is the node label the feature was read from.
+	Label string `json:"label"`
+}
+
+// LabelMismatch is a node label that disagrees with what the node's
+// ResourceSlices publish.
+type LabelMismatch struct {
+	NodeName string `json:"nodeName"`
+	Label    string `json:"label"`
+	// Value is the value of the label on the node.
+	Value string `json:"value"`
+	// Published is what the ResourceSlices of the node publish instead, e.g.
+	// the number of GPUs or their product names.
+	Published string `json:"published"`
+}
+
+// CounterOvercommit is a shared counter, such as the memory of a GPU
+// partitioned into slices, of which the allocated devices consume more than
+// the device provides.
+type CounterOvercommit struct {
+	NodeName   string `json:"nodeName,omitempty"`
+	Driver     string `json:"driver"`
+	Pool       string `json:"pool"`
+	CounterSet string `json:"counterSet"`
+	Counter    string `json:"counter"`
+	// Capacity is the value of the counter, Allocated the sum consumed by
+	// the allocated devices.
+	Capacity  resource.Quantity `json:"capacity"`
+	Allocated resource.Quantity `json:"allocated"`
+	// Devices lists the allocated devices consuming the counter, and Claims
+	// the claims (namespace/name) they are allocated to.
+	Devices []string `json:"devices"`
+	Claims  []string `json:"claims"`
+}
+
+// KubeletDeviceMismatch is a claim request of a container for which the
+// kubelet reports other devices in the container's status than the claim's
+// allocation records, e.g. after a driver or kubelet restart lost track of a
+// prepared device.
+type KubeletDeviceMismatch struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	NodeName  string `json:"nodeName"`
+	// Claim is the name of the claim in the pod's namespace, and Request the
+	// request the kubelet reports, empty for all requests of the claim.
+	Claim   string `json:"claim"`
+	Request string `json:"request,omitempty"`
+	// Allocated lists the devices, as driver/pool/device, the claim's
+	// allocation records for the request, and Reported those the kubelet
+	// reports.
+	Allocated []string `json:"allocated"`
+	Reported  []string `json:"reported"`
+}
+
+// DuplicateDevice is a device name a driver publishes more than once on the
+// same node, within a pool or across its pools.
+type DuplicateDevice struct {
+	NodeName string `json:"nodeName"`
+	Driver   string `json:"driver"`
+	Device   string `json:"device"`
+	// Pools lists the pool of every occurrence, so a pool repeats for
+	// duplicates within it.
+	Pools []string `json:"pools"`
+}
+
+// SpotRisk describes an allocated claim holding devices on spot or
+// preemptible nodes, which the cloud provider can reclaim at short notice.
+type SpotRisk struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Nodes lists the spot nodes of the claim's devices.
+	Nodes []string `json:"nodes"`
+	// Devices lists the devices on spot nodes as driver/pool/device.
+	Devices []string `json:"devices"`
+	Pods    []string `json:"pods,omitempty"`
+	// Owners lists the workloads owning the pods as Kind/name.
+	Owners []string `json:"owners,omitempty"`
+}
+
+// CheckResult is the outcome of a cluster check.
+type CheckResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Failures describes every violation; it is empty if the check passed.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Passed reports whether the check found no violations.
+func (r CheckResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// AttributeInventory holds the distribution of one device attribute, such as
+// a driver version, across the devices of a driver.
+type AttributeInventory struct {
+	Driver string `json:"driver"`
+	// ProductName is set when the inventory is broken down per product, as
+	// for firmware versions, which are only comparable within a product.
+	ProductName string           `json:"productName,omitempty"`
+	Attribute   string           `json:"attribute"`
+	Values      []AttributeValue `json:"values"`
+}
+
+// AttributeValue is one value of an inventoried attribute and where it is
+// found.
+type AttributeValue struct {
+	Value   string   `json:"value"`
+	Devices int      `json:"devices"`
+	Nodes   []string `json:"nodes"`
+	// Outdated is set for versions older than the newest one published by
+	// the driver, or by the product if the inventory is per product.
+	Outdated bool `json:"outdated,omitempty"`
+}
+
+// ListMeta holds metadata about a machine-readable output document.
+type ListMeta struct {
+	// SchemaVersion is the version of the schema the document conforms to.
+	SchemaVersion string `json:"schemaVersion"`
+}
+
+// NodeList is the versioned envelope of the node output.
+type NodeList struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   ListMeta    `json:"metadata"`
+	Items      []*NodeInfo `json:"items"`
+}
+
+// Report is a set of report sections produced by an analyzer.
+type Report struct {
+	Sections []ReportSection `json:"sections"`
+}
+
+// ReportSection is one part of a report: free text, a table, or both.
+type ReportSection struct {
+	Title   string     `json:"title"`
+	Text    string     `json:"text,omitempty"`
+	Columns []string   `json:"columns,omitempty"`
+	Rows    [][]string `json:"rows,omitempty"`
+}
+
+// PlannedRequest is an API request a command would make, with the number of
+// objects it would return.
+type PlannedRequest struct {
+	Verb          string `json:"verb"`
+	Resource      string `json:"resource"`
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+	// Limit is the page size of paginated lists.
+	Limit int64 `json:"limit,omitempty"`
+	// Objects is the number of objects the request returns or changes, if
+	// Estimated. The API server does not count the objects matching a
+	// selector, nor the changes a watch delivers.
+	Objects   int64 `json:"objects"`
+	Estimated bool  `json:"estimated"`
+}
+
+// Pages returns the number of list calls an estimated request takes.
+func (r PlannedRequest) Pages() int64 {
+	if r.Limit <= 0 || r.Objects <= r.Limit {
+		return 1
+	}
+	return (r.Objects + r.Limit - 1) / r.Limit
 }